@@ -4,9 +4,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"project/api/requests"
-	"project/internal/initializers"
-	"project/internal/models"
-	"time"
+	"project/api/responses"
+	"project/services"
 )
 
 // @Summary Crée un nouveau stand
@@ -17,39 +16,33 @@ import (
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param stand body requests.StandRequest true "Stand à créer"
-// @Success 201 {object} models.Stand
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /create-stand [post]
-func CreateStand(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+// @Success 201 {object} Envelope{data=models.Stand}
+// @Failure 400 {object} apperror.Response "Données invalides"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/stands [post]
+func (h *Controller) CreateStand(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
-	currentUser := user.(models.User)
-	if currentUser.Role != 1 && currentUser.Role != 3 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You don't have permission to do that"})
+	var standData requests.StandRequest
+	if !bind(c, &standData) {
 		return
 	}
 
-	var standData requests.StandRequest
-	stand := models.Stand{
+	stand, err := h.services.Stands.Create(user, services.StandInput{
 		Name:         standData.Name,
 		Type:         standData.Type,
 		JetonsRequis: standData.JetonsRequis,
-		UserID:       currentUser.ID,
-	}
-	if err := c.ShouldBind(&stand); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	if err := initializers.DB.Create(&stand).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	})
+	if err != nil {
+		fail(c, err)
 		return
 	}
-	c.JSON(http.StatusCreated, gin.H{"stand": stand})
+	respond(c, http.StatusCreated, stand)
 }
 
 // @Summary Récupère tous les produits
@@ -58,27 +51,35 @@ func CreateStand(c *gin.Context) {
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Produce json
-// @Success 200 {object} []models.Stand
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /stands [get]
-func GetAllStands(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not logged"})
+// @Param limit query int false "Taille de la page (1 à 200)" default(50)
+// @Param offset query int false "Nombre de lignes à sauter" default(0)
+// @Param sort query string false "Tri, séparé par des virgules, - pour décroissant : id, name, type, jetons_requis, conso" default(id)
+// @Param q query string false "Recherche insensible à la casse sur le nom"
+// @Param type query string false "Filtre sur le type de stand"
+// @Param kermesse_id query int false "Stands de la kermesse"
+// @Success 200 {object} Envelope{data=[]models.Stand,meta=Meta}
+// @Failure 400 {object} apperror.Response "Paramètres de liste invalides"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/stands [get]
+func (h *Controller) GetAllStands(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
-	currentUser := user.(models.User)
-	if currentUser.Role != 1 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You don't have permission to do that"})
+	q, ok := listQuery(c, services.StandListing)
+	if !ok {
 		return
 	}
-	var stands []models.Stand
-	if err := initializers.DB.Find(&stands).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+	page, err := h.services.Stands.List(user, q)
+	if err != nil {
+		fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"stands": stands})
+	respondPage(c, page, q)
 }
 
 // @Summary Récupère un stand par ID
@@ -88,24 +89,27 @@ func GetAllStands(c *gin.Context) {
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param id path int true "ID de l'utilisateur"
-// @Success 200 {object} models.Stand
-// @Failure 404 {object} gin.H "Stand non trouvé"
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /stands/{id} [get]
-func GetStandById(c *gin.Context) {
-	_, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not logged"})
+// @Success 200 {object} Envelope{data=models.Stand}
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 404 {object} apperror.Response "Stand non trouvé"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/stands/{id} [get]
+func (h *Controller) GetStandById(c *gin.Context) {
+	if _, ok := currentUser(c); !ok {
+		return
+	}
+	standID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
-	standID := c.Param("id")
-	var standRetrieved models.Stand
-	if err := initializers.DB.First(&standRetrieved, standID).Preload("Stock").Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	standRetrieved, err := h.services.Stands.Get(standID)
+	if err != nil {
+		fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"stand": standRetrieved})
+	respond(c, http.StatusOK, standRetrieved)
 }
 
 // @Summary Met à jour un stand par ID
@@ -116,39 +120,40 @@ func GetStandById(c *gin.Context) {
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param id path int true "ID de l'utilisateur"
-// @Param stand body models.Stand true "Stand à mettre à jours"
-// true "Stand à mettre à jour"
-// @Success 200 {object} models.Stand
-// @Failure 404 {object} gin.H "Stand non trouvé"
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /stands/{id}/update [put]
-func UpdateStand(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not logged"})
+// @Param stand body requests.UpdateStandRequest true "Stand à mettre à jour"
+// @Success 200 {object} Envelope{data=models.Stand}
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Stand non trouvé"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/stands/{id} [put]
+func (h *Controller) UpdateStand(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-
-	standID := c.Param("id")
-	if err := initializers.DB.First("id = ?", standID).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	standID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
-	var standRetrieved models.Stand
-	currentUser := user.(models.User)
-	if currentUser.Role != 1 || currentUser.ID != standRetrieved.UserID {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You don't have permission to do that"})
+	var standData requests.UpdateStandRequest
+	if !bind(c, &standData) {
+		return
 	}
 
-	if err := initializers.DB.Save(&standRetrieved).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	standRetrieved, err := h.services.Stands.Update(user, standID, services.StandInput{
+		Name:         standData.Name,
+		Type:         standData.Type,
+		JetonsRequis: standData.JetonsRequis,
+	})
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "stand mis à jours",
-		"stand":   standRetrieved})
+	respond(c, http.StatusOK, standRetrieved)
 }
 
 // @Summary Supprime un stand par ID
@@ -158,30 +163,28 @@ func UpdateStand(c *gin.Context) {
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param id path int true "ID de du stand"
-// @Success 204 {object} nil
-// @Failure 404 {object} gin.H "stand non trouvé"
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /stands/{id}/delete [delete]
-func DeleteStand(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not logged"})
+// @Success 204 "Stand supprimé"
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "stand non trouvé"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/stands/{id} [delete]
+func (h *Controller) DeleteStand(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-
-	currentUser := user.(models.User)
-	if currentUser.Role != 1 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You don't have permission to do that"})
+	standID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
-	var stand models.Stand
-	standID := c.Param("id")
-	if err := initializers.DB.Delete(stand, standID).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "stand not found"})
+	if err := h.services.Stands.Delete(c.Request.Context(), user, standID); err != nil {
+		fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "stand supprimé"})
+	c.Status(http.StatusNoContent)
 }
 
 // @Summary Interagir avec un stand
@@ -192,143 +195,84 @@ func DeleteStand(c *gin.Context) {
 // @Security Bearer
 // @Param id path int true "ID du stand"
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
-// @Success 200 {object} models.Stand
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /stands/{id}/interact [post]
-func InteractWithStand(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not logged"})
-		return
-	}
-
-	standID := c.Param("id")
-	var stand models.Stand
-	if err := initializers.DB.First(&stand, "id = ?", standID).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	currentUser := user.(models.User)
-	if currentUser.Jetons <= stand.JetonsRequis {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You don't have enough coins to do that"})
+// @Param Idempotency-Key header string false "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine"
+// @Success 200 {object} Envelope{data=responses.InteractionResponse}
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 404 {object} apperror.Response "Stand non trouvé"
+// @Failure 409 {object} apperror.Response "Requête de même Idempotency-Key en cours"
+// @Failure 422 {object} apperror.Response "Pas assez de jetons"
+// @Failure 413 {object} apperror.Response "Corps de requête trop grand"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/stands/{id}/interactions [post]
+func (h *Controller) InteractWithStand(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-
-	stand.Conso = currentUser.Jetons - stand.JetonsRequis
-	if err := initializers.DB.Save(&stand).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	standID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
-	currentUser.Jetons -= stand.JetonsRequis
-	if err := initializers.DB.Save(&currentUser).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	interaction, err := h.services.Stands.Interact(user, standID)
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
-	var historique = models.History{
-		Date:      time.Now(),
-		NbJetons:  stand.JetonsRequis,
-		StandName: stand.Name,
-		UserID:    currentUser.ID,
-	}
-
-	if err := initializers.DB.Create(&historique).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"stand":       stand.Conso,
-		"jetons user": currentUser.Jetons,
+	respond(c, http.StatusOK, responses.InteractionResponse{
+		History: interaction.History,
+		Stand:   interaction.StandConso,
+		Jetons:  interaction.Jetons,
 	})
 }
 
 // @Summary Achat d'un produit sur un stand
-// @Description Permet à un utilisateur d'acheter un produit sur un stand avec ses jetons
+// @Description Permet à un utilisateur d'acheter un produit sur un stand avec ses jetons. Avec card_code, le teneur du stand débite la carte prépayée d'un visiteur.
 // @Tags Stand
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
-// @Param stand_id path uint true "ID du stand"
+// @Param Idempotency-Key header string false "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine"
+// @Param id path uint true "ID du stand"
 // @Param product_id path uint true "ID du produit"
 // @Param quantity body requests.QuantityProductRequest true  "Quantité de produit à acheter"
-// @Success 200 {object} gin.H "Success"
-// @Failure 400 {object} gin.H "Bad Request"
-// @Failure 401 {object} gin.H "Unauthorized"
-// @Router /stands/{stand_id}/products/{product_id}/buy [post]
-func BuyProduct(c *gin.Context) {
-	currentUser, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-		return
-	}
-	user := currentUser.(models.User)
-
-	standID := c.Param("stand_id")
-	productID := c.Param("product_id")
-
-	var stand models.Stand
-	var product models.Product
-	var quantity requests.QuantityProductRequest
-
-	if err := c.BindJSON(&quantity); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+// @Success 200 {object} Envelope{data=models.History} "Success"
+// @Failure 400 {object} apperror.Response "Bad Request"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Stand, produit ou carte non trouvé"
+// @Failure 409 {object} apperror.Response "Requête de même Idempotency-Key en cours"
+// @Failure 422 {object} apperror.Response "Stock ou jetons insuffisants, carte inutilisable"
+// @Failure 413 {object} apperror.Response "Corps de requête trop grand"
+// @Router /api/v1/stands/{id}/products/{product_id}/purchases [post]
+func (h *Controller) BuyProduct(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-
-	if err := initializers.DB.Preload("Stock").First(&stand, standID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "stand not found"})
-		return
-	}
-	if err := initializers.DB.First(&product, productID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+	standID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
-
-	if product.Nb_Products < uint64(quantity.Quantity) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "insufficient stock"})
+	productID, ok := paramID(c, "product_id")
+	if !ok {
 		return
 	}
 
-	totalJetons := product.JetonsRequis * quantity.Quantity
-
-	if user.Jetons < totalJetons {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "not enough tokens"})
-		return
-	}
-
-	user.Jetons -= totalJetons
-	stand.Conso += totalJetons
-	product.Nb_Products -= uint64(quantity.Quantity)
-
-	if err := initializers.DB.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	if err := initializers.DB.Save(&stand).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	if err := initializers.DB.Save(&product).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var quantity requests.QuantityProductRequest
+	if !bind(c, &quantity) {
 		return
 	}
 
-	// Historiser la transaction
-	historique := models.History{
-		UserID:    user.ID,
-		NbJetons:  totalJetons,
-		StandName: stand.Name,
-		Date:      time.Now(),
-	}
-	if err := initializers.DB.Create(&historique).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	historique, err := h.services.Purchases.BuyProduct(user, standID, productID, quantity.Quantity, quantity.CardCode)
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "purchase successful"})
+	respond(c, http.StatusOK, historique)
 }
 
 // @Summary Attribue des points à un utilisateur depuis un stand
@@ -337,53 +281,38 @@ func BuyProduct(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
-// @Param stand_id path uint true "ID du stand"
+// @Param id path uint true "ID du stand"
 // @Param user_id path uint true "ID de l'utilisateur"
 // @Param points body requests.GivePointsRequest true "Nombre de points à attribuer"
-// @Success 200 {object} gin.H "Success"
-// @Failure 400 {object} gin.H "Bad Request"
-// @Failure 401 {object} gin.H "Unauthorized"
-// @Router /stands/{stand_id}/users/{user_id}/points [post]
-func GivePoints(c *gin.Context) {
-	currentUser, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+// @Success 200 {object} Envelope{data=responses.GivePointsResponse}
+// @Failure 400 {object} apperror.Response "Bad Request"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Stand ou utilisateur non trouvé"
+// @Router /api/v1/stands/{id}/users/{user_id}/points [post]
+func (h *Controller) GivePoints(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-	standOwner := currentUser.(models.User)
-
-	// Récupérer les paramètres du chemin
-	standID := c.Param("stand_id")
-	userID := c.Param("user_id")
-
-	// Vérifier que l'utilisateur connecté est bien le propriétaire du stand
-	var stand models.Stand
-	if err := initializers.DB.First(&stand, standID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "stand not found"})
+	standID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
-	if stand.UserID != standOwner.ID {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not the owner of this stand"})
+	userID, ok := paramID(c, "user_id")
+	if !ok {
 		return
 	}
 
 	var body requests.GivePointsRequest
-	if err := c.BindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
-		return
-	}
-
-	var user models.User
-	if err := initializers.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	if !bind(c, &body) {
 		return
 	}
 
-	user.PtsAttribues += body.Points
-	if err := initializers.DB.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if _, err := h.services.Stands.GivePoints(user, standID, userID, body.Points); err != nil {
+		fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "points successfully given", "points_given": body.Points})
+	respond(c, http.StatusOK, responses.GivePointsResponse{UserID: userID, PointsGiven: body.Points})
 }
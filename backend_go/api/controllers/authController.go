@@ -2,15 +2,11 @@ package controllers
 
 import (
 	"net/http"
-	"os"
 	"project/api/requests"
-	"project/internal/initializers"
-	"project/internal/models"
-	"time"
+	"project/api/responses"
+	"project/services"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // @Summary Allow you to register as a new User
@@ -19,43 +15,24 @@ import (
 // @Accept json
 // @Produce json
 // @Param user body requests.SignupRequest true "User data"
-// @Success 201 {object} requests.SignupRequest "User created"
-// @Failure 400 {object} gin.H "Bad request"
-// @Failure 404 {object} gin.H "Bad request"
-// @Failure 409 {object} gin.H "Conflict"
-// @Failure 500 {object} gin.H "Internal server error"
-// @Router /signup [post]
-func Signup(c *gin.Context) {
+// @Success 201 {object} Envelope{data=responses.Profile} "User created"
+// @Failure 400 {object} apperror.Response "Bad request"
+// @Failure 409 {object} apperror.Response "Email already used"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /api/v1/auth/signup [post]
+func (h *Controller) Signup(c *gin.Context) {
 	var signupReq requests.SignupRequest
-
-	if err := c.ShouldBindJSON(&signupReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bind(c, &signupReq) {
 		return
 	}
 
-	var userFound models.User
-	initializers.DB.Where("email = ?", signupReq.Email).Find(&userFound)
-	if userFound.ID != 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "email already used"})
-		return
-	}
-
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(signupReq.Password), bcrypt.DefaultCost)
+	user, err := h.services.Auth.Signup(signupInput(signupReq))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		fail(c, err)
 		return
 	}
-
-	user := models.User{
-		Firstname: signupReq.Firstname,
-		Lastname:  signupReq.Lastname,
-		Password:  string(passwordHash),
-		Email:     signupReq.Email,
-		Picture:   signupReq.Picture,
-	}
 	/*mailer2.SendGoMail(user.Email, "Inscription", "./pkg/mailer/templates/registry.html", user)*/
-	initializers.DB.Create(&user)
-	c.JSON(http.StatusCreated, gin.H{"user": user})
+	respond(c, http.StatusCreated, responses.NewProfile(*user, h.services.Pictures))
 }
 
 // @Summary Allow you to log and have an JWT Token
@@ -64,68 +41,36 @@ func Signup(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param user body requests.LoginRequest true "User data"
-// @Success 200 {object} gin.H "Connexion réussie"
-// @Failure 400 {object} gin.H "Bad request"
-// @Failure 404 {object} gin.H "Bad request"
-// @Failure 409 {object} gin.H "Conflict"
-// @Failure 500 {object} gin.H "Internal server error"
-// @Router /login [post]
-func Login(c *gin.Context) {
+// @Success 200 {object} Envelope{data=responses.LoginResponse} "Connexion réussie"
+// @Failure 400 {object} apperror.Response "Bad request"
+// @Failure 401 {object} apperror.Response "Invalid email or password"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /api/v1/auth/login [post]
+func (h *Controller) Login(c *gin.Context) {
 	var loginReq requests.LoginRequest
-
-	err := c.ShouldBindJSON(&loginReq)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	var userFound models.User
-	initializers.DB.Where("email=?", loginReq.Email).Find(&userFound)
-
-	if userFound.ID == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "user not found"})
+	if !bind(c, &loginReq) {
 		return
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(userFound.Password), []byte(loginReq.Password)); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid password"})
-		return
-	}
-
-	generateToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"id":  userFound.ID,
-		"exp": time.Now().Add(time.Hour * 24).Unix(),
-	})
-
-	token, err := generateToken.SignedString([]byte(os.Getenv("SECRET")))
-
+	token, err := h.services.Auth.Login(loginReq.Email, loginReq.Password)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to generate token"})
+		fail(c, err)
+		return
 	}
 
-	c.JSON(200, gin.H{
-		"token": token,
-	})
+	respond(c, http.StatusOK, responses.LoginResponse{Token: token})
 }
 
 // @Summary Logout
 // @Description Inform the client to delete the token
 // @Tags Auth
-// @Accept json
-// @Produce json
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)//
-// @Success 200 {object} gin.H "Déconnexion réussie"
-// @Failure 400 {object} gin.H "Bad request"
-// @Failure 404 {object} gin.H "Bad request"
-// @Failure 409 {object} gin.H "Conflict"
-// @Failure 500 {object} gin.H "Internal server error"
-// @Router /logout [post]
-func Logout(c *gin.Context) {
+// @Success 204 "Déconnexion réussie"
+// @Router /api/v1/auth/logout [post]
+func (h *Controller) Logout(c *gin.Context) {
 	// Aucune action particulière nécessaire côté serveur pour les JWT
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Successfully logged out",
-	})
+	c.Status(http.StatusNoContent)
 }
 
 // @Summary Récupère le profil de l'utilisateur actuellement connecté
@@ -135,27 +80,22 @@ func Logout(c *gin.Context) {
 // @Produce json
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
-// @Success 200 {object} gin.H "Success"
-// @Failure 401 {object} gin.H "Unauthorized"
-// @Router /profile [get]
-func UserProfile(c *gin.Context) {
-	currentUser, _ := c.Get("currentUser")
-	user := currentUser.(models.User)
-
-	var userProfile models.User
+// @Success 200 {object} Envelope{data=responses.Profile} "Success"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Router /api/v1/me [get]
+func (h *Controller) UserProfile(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
 
-	if err := initializers.DB.Preload("Parents").
-		Preload("Enfants").
-		Preload("Kermesses").
-		Preload("Stands").
-		Preload("Transactions").
-		Preload("Historique").
-		First(&userProfile, "id = ?", user.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	userProfile, err := h.services.Auth.Profile(user)
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user": userProfile})
+	respond(c, http.StatusOK, responses.NewProfile(*userProfile, h.services.Pictures))
 }
 
 // @Summary Mise à jour du profil
@@ -166,51 +106,38 @@ func UserProfile(c *gin.Context) {
 // @Security Bearer
 // @Param Authorization header string true "Insérez votre jeton d'accès" default(Bearer <Ajouter le jeton d'accès ici>)
 // @Param User body requests.SignupRequest true "Les données du profil à mettre à jour"
-// @Success 200 {object} gin.H "Profil mis à jour avec succès"
-// @Failure 400 {object} gin.H "Erreur de validation"
-// @Failure 401 {object} gin.H "Non autorisé"
-// @Failure 500 {object} gin.H "Erreur du serveur"
-// @Router /profile/update [put]
-func UpdateProfile(c *gin.Context) {
+// @Success 200 {object} Envelope{data=responses.Profile} "Profil mis à jour avec succès"
+// @Failure 400 {object} apperror.Response "Erreur de validation"
+// @Failure 401 {object} apperror.Response "Non autorisé"
+// @Failure 409 {object} apperror.Response "Email déjà utilisé"
+// @Failure 500 {object} apperror.Response "Erreur du serveur"
+// @Router /api/v1/me [put]
+func (h *Controller) UpdateProfile(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
 	var signupReq requests.SignupRequest
-	currentUser, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	if !bind(c, &signupReq) {
 		return
 	}
 
-	if err := c.ShouldBindJSON(&signupReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	updated, err := h.services.Auth.UpdateProfile(user, signupInput(signupReq))
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
-	user := currentUser.(models.User)
-
-	if signupReq.Firstname != "" {
-		user.Firstname = signupReq.Firstname
-	}
-	if signupReq.Lastname != "" {
-		user.Lastname = signupReq.Lastname
-	}
-	if signupReq.Email != "" {
-		user.Email = signupReq.Email
-	}
-	if signupReq.Picture != "" {
-		user.Picture = signupReq.Picture
-	}
-	if signupReq.Password != "" {
-		passwordHash, err := bcrypt.GenerateFromPassword([]byte(signupReq.Password), bcrypt.DefaultCost)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		user.Password = string(passwordHash)
-	}
+	respond(c, http.StatusOK, responses.NewProfile(*updated, h.services.Pictures))
+}
 
-	if err := initializers.DB.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update profile"})
-		return
+func signupInput(req requests.SignupRequest) services.SignupInput {
+	return services.SignupInput{
+		Firstname: req.Firstname,
+		Lastname:  req.Lastname,
+		Email:     req.Email,
+		Password:  req.Password,
+		Picture:   req.Picture,
+		Role:      req.Role,
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Profile updated successfully", "user": user})
 }
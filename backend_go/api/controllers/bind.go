@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"project/internal/apperror"
+)
+
+// ErrInvalidBody est retourné quand le corps n'est pas un JSON lisible
+var ErrInvalidBody = apperror.Validation("invalid_body", "request body is not valid JSON")
+
+func init() {
+	// Les erreurs de validation nomment les champs comme le JSON, pas comme les structures Go
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// bind lit le corps JSON dans req ; en cas d'erreur, elle est signalée champ par champ
+func bind(c *gin.Context, req any) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		fail(c, bindingError(err))
+		return false
+	}
+	return true
+}
+
+func bindingError(err error) *apperror.Error {
+	var invalid validator.ValidationErrors
+	if errors.As(err, &invalid) {
+		fields := make([]apperror.FieldError, 0, len(invalid))
+		for _, fe := range invalid {
+			fields = append(fields, apperror.FieldError{
+				Field:   fieldPath(fe.Namespace()),
+				Code:    fe.Tag(),
+				Message: fieldMessage(fe),
+				Param:   fe.Param(),
+			})
+		}
+		return apperror.Validation("invalid_fields", "some fields are invalid", fields...)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return apperror.Validation("invalid_fields", "some fields are invalid", apperror.FieldError{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: "must be of type " + typeErr.Type.String(),
+			Param:   typeErr.Type.String(),
+		})
+	}
+	return ErrInvalidBody.Wrap(err)
+}
+
+// fieldPath retire le nom de la structure : "SignupRequest.email" devient "email"
+func fieldPath(namespace string) string {
+	if _, path, found := strings.Cut(namespace, "."); found {
+		return path
+	}
+	return namespace
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	case "min", "gte":
+		return "must be at least " + fe.Param()
+	case "max", "lte":
+		return "must be at most " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "lt":
+		return "must be less than " + fe.Param()
+	default:
+		return "is invalid"
+	}
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"project/api/middlewares"
+	"project/api/responses"
+	"project/internal/apperror"
+	"project/internal/listing"
+	"project/internal/models"
+	"project/services"
+	"strconv"
+)
+
+// Controller regroupe les handlers HTTP. Les services lui sont injectés à la construction :
+// un handler lit et valide la requête, appelle un service et renvoie son résultat dans l'enveloppe
+// {"data": ...}. Les erreurs sont signalées avec fail et mises en forme par middlewares.Errors.
+type Controller struct {
+	services *services.Services
+}
+
+func New(s *services.Services) *Controller {
+	return &Controller{services: s}
+}
+
+// Envelope est l'enveloppe de toutes les réponses réussies de l'API. Meta accompagne les listes.
+type Envelope struct {
+	Data any   `json:"data"`
+	Meta *Meta `json:"meta,omitempty"`
+}
+
+// Meta décrit la page d'une liste ; Next est le lien vers la page suivante, absent sur la dernière
+type Meta struct {
+	Total  int64  `json:"total"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+	Next   string `json:"next,omitempty"`
+}
+
+// respond renvoie data dans l'enveloppe de l'API
+func respond(c *gin.Context, status int, data any) {
+	c.JSON(status, Envelope{Data: data})
+}
+
+// respondPage renvoie une page de liste avec son total et le lien vers la page suivante
+func respondPage[T any](c *gin.Context, page listing.Page[T], q listing.Query) {
+	meta := &Meta{Total: page.Total, Limit: q.Limit, Offset: q.Offset}
+	if offset, ok := q.Next(page.Total); ok {
+		next := *c.Request.URL
+		values := next.Query()
+		values.Set(listing.ParamOffset, strconv.Itoa(offset))
+		values.Set(listing.ParamLimit, strconv.Itoa(q.Limit))
+		next.RawQuery = values.Encode()
+		meta.Next = next.RequestURI()
+	}
+	c.JSON(http.StatusOK, Envelope{Data: page.Items, Meta: meta})
+}
+
+// withPictures adapte un mapper de responses qui résout les photos, pour listing.Map
+func withPictures[T, U any](pictures responses.Pictures, fn func(T, responses.Pictures) U) func(T) U {
+	return func(item T) U {
+		return fn(item, pictures)
+	}
+}
+
+// listQuery lit la pagination, le tri et les filtres d'une liste décrite par spec, ou signale l'erreur
+func listQuery(c *gin.Context, spec listing.Spec) (listing.Query, bool) {
+	q, err := listing.Parse(c.Request.URL.Query(), spec)
+	if err != nil {
+		fail(c, err)
+		return q, false
+	}
+	return q, true
+}
+
+// fail signale l'erreur de la requête et interrompt le traitement
+func fail(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
+
+// currentUser retourne l'utilisateur authentifié par CheckAuth, ou signale l'erreur
+func currentUser(c *gin.Context) (models.User, bool) {
+	user, exists := c.Get("currentUser")
+	if !exists {
+		fail(c, middlewares.ErrNotAuthenticated)
+		return models.User{}, false
+	}
+	return user.(models.User), true
+}
+
+// paramID lit un identifiant numérique du chemin, ou signale l'erreur
+func paramID(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		fail(c, apperror.Validation("invalid_parameter", "invalid "+name, apperror.FieldError{
+			Field:   name,
+			Code:    "numeric",
+			Message: name + " must be a positive integer",
+		}))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -2,9 +2,9 @@ package controllers
 
 import (
 	"github.com/gin-gonic/gin"
-	"net/http"
-	"project/internal/initializers"
-	"project/internal/models"
+	"project/api/responses"
+	"project/internal/listing"
+	"project/services"
 )
 
 // @Summary Récupère tous les utilisateurs avec le rôle d'élève
@@ -13,21 +13,29 @@ import (
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Produce json
-// @Success 200 {object} []models.User
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /students [get]
-func GetStudents(c *gin.Context) {
-	_, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not logged"})
+// @Param limit query int false "Taille de la page (1 à 200)" default(50)
+// @Param offset query int false "Nombre de lignes à sauter" default(0)
+// @Param sort query string false "Tri, séparé par des virgules, - pour décroissant : id, firstname, lastname, email, jetons" default(lastname,firstname)
+// @Param kermesse_id query int false "Élèves participant à la kermesse"
+// @Success 200 {object} Envelope{data=[]responses.PublicUser,meta=Meta}
+// @Failure 400 {object} apperror.Response "Paramètres de liste invalides"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/students [get]
+func (h *Controller) GetStudents(c *gin.Context) {
+	if _, ok := currentUser(c); !ok {
 		return
 	}
 
-	var students []models.User
+	q, ok := listQuery(c, services.StudentListing)
+	if !ok {
+		return
+	}
 
-	if err := initializers.DB.Where("role = ?", 5).Find(&students).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des élèves"})
+	page, err := h.services.Users.Students(q)
+	if err != nil {
+		fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"students": students})
+	respondPage(c, listing.Map(page, withPictures(h.services.Pictures, responses.NewPublicUser)), q)
 }
@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// @Summary Bilan financier d'une kermesse
+// @Description Ventes en ligne, en caisse et sur cartes prépayées, remboursements, dons, dépenses sur les stands et soldes des cartes prépayées
+// @Tags Kermesse
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param id path int true "Kermesse ID"
+// @Success 200 {object} Envelope{data=services.KermesseFinances}
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Kermesse not found"
+// @Router /api/v1/kermesses/{id}/finances [get]
+func (h *Controller) GetKermesseFinances(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	kermesseID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	finances, err := h.services.Finances.KermesseFinances(user, kermesseID)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, finances)
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"project/api/responses"
+	"project/internal/logging"
+)
+
+// Délai au-delà duquel la base est considérée comme indisponible
+const readinessTimeout = 2 * time.Second
+
+// @Summary Vérifie que le processus répond
+// @Tags Health
+// @Produce json
+// @Success 200 {object} responses.HealthResponse
+// @Router /healthz [get]
+func (h *Controller) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, responses.HealthResponse{Status: "ok"})
+}
+
+// @Summary Vérifie que l'API peut servir des requêtes
+// @Description La base de données doit répondre et toutes les migrations doivent y être appliquées
+// @Tags Health
+// @Produce json
+// @Success 200 {object} responses.HealthResponse
+// @Failure 503 {object} responses.HealthResponse "Base injoignable ou migrations en attente"
+// @Router /readyz [get]
+func (h *Controller) Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	if err := h.services.Health.Ready(ctx); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusServiceUnavailable, responses.HealthResponse{Status: "unavailable", RequestID: logging.RequestID(c.Request.Context())})
+		return
+	}
+	c.JSON(http.StatusOK, responses.HealthResponse{Status: "ok"})
+}
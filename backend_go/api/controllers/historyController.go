@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"project/api/requests"
+)
+
+// @Summary Annule un achat ou une interaction
+// @Description Rend les jetons à l'utilisateur, restaure le stock et la conso du stand et crée une entrée d'annulation liée. Le teneur du stand peut annuler pendant 15 minutes, les organisateurs et admins à tout moment.
+// @Tags History
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param Idempotency-Key header string false "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine"
+// @Param id path int true "ID de l'entrée d'historique"
+// @Param reversal body requests.ReverseRequest false "Motif de l'annulation"
+// @Success 200 {object} Envelope{data=models.History}
+// @Failure 400 {object} apperror.Response "Entrée non annulable"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Entrée non trouvée"
+// @Failure 409 {object} apperror.Response "Déjà annulée"
+// @Failure 422 {object} apperror.Response "Idempotency-Key déjà utilisée pour une autre requête"
+// @Failure 413 {object} apperror.Response "Corps de requête trop grand"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/history/{id}/reverse [post]
+func (h *Controller) ReverseHistory(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	historyID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req requests.ReverseRequest
+	if c.Request.ContentLength > 0 && !bind(c, &req) {
+		return
+	}
+
+	reversal, err := h.services.Reversals.Reverse(c.Request.Context(), user, historyID, req.Reason)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, reversal)
+}
+
+// @Summary Historique des opérations d'un stand
+// @Description Liste les achats, interactions et annulations d'un stand (teneur, organisateurs et admins)
+// @Tags History
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param id path int true "ID du stand"
+// @Success 200 {object} Envelope{data=[]models.History}
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Stand non trouvé"
+// @Router /api/v1/stands/{id}/history [get]
+func (h *Controller) GetStandHistory(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	standID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	history, err := h.services.Reversals.GetStandHistory(user, standID)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, history)
+}
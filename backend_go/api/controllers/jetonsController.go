@@ -3,7 +3,6 @@ package controllers
 import (
 	"github.com/gin-gonic/gin"
 	"net/http"
-	"project/internal/initializers"
 	"project/internal/models"
 )
 
@@ -15,52 +14,43 @@ import (
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param user body models.Jetons true "Jeton à créer"
-// @Success 201 {object} models.Jetons
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /create-jeton  [post]
-func CreateJetons(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-		return
-	}
-	currentUser := user.(models.User)
-	if currentUser.Role != 1 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to perform this action"})
+// @Success 201 {object} Envelope{data=models.Jetons}
+// @Failure 400 {object} apperror.Response "Données invalides"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/jetons [post]
+func (h *Controller) CreateJetons(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 	var jetons models.Jetons
-	if err := c.BindJSON(&jetons); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bind(c, &jetons) {
 		return
 	}
-	if err := initializers.DB.Create(&jetons).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	pack, err := h.services.JetonsPacks.Create(user, jetons)
+	if err != nil {
+		fail(c, err)
 		return
 	}
-	c.JSON(http.StatusCreated, gin.H{"data": jetons})
+	respond(c, http.StatusCreated, pack)
 }
 
 // @Summary Récupère tous les jetons
 // @Description Récupère la liste de tous les jetons
 // @Tags Jeton
 // @Produce json
-// @Success 200 {object} []models.Jetons
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /jetons [get]
-func GetJetons(c *gin.Context) {
-	/*	_, exists := c.Get("currentUser")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-			return
-		}*/
-
-	var jetons []models.Jetons
-	if err := initializers.DB.Find(&jetons).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+// @Success 200 {object} Envelope{data=[]models.Jetons}
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/jetons [get]
+func (h *Controller) GetJetons(c *gin.Context) {
+	jetons, err := h.services.JetonsPacks.List()
+	if err != nil {
+		fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"jetons": jetons})
+	respond(c, http.StatusOK, jetons)
 }
 
 // @Summary Met à jour un jeton par ID
@@ -72,41 +62,35 @@ func GetJetons(c *gin.Context) {
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param id path int true "ID de l'utilisateur"
 // @Param user body models.Jetons true "Utilisateur à mettre à jour"
-// @Success 200 {object} models.Jetons
-// @Failure 404 {object} gin.H "Jeton non trouvé"
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /jetons/{id}/update [put]
-func UpdateJeton(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+// @Success 200 {object} Envelope{data=models.Jetons}
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Jeton non trouvé"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/jetons/{id} [put]
+func (h *Controller) UpdateJeton(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-
-	jetonID := c.Param("id")
-	var jeton models.Jetons
-	if err := initializers.DB.First(&jeton, jetonID).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	jetonID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
-	currentUser := user.(models.User)
-	if currentUser.Role != 1 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to perform this action"})
-		return
-	}
-	var updatedJeton models.Jetons
-	if err := c.BindJSON(&updatedJeton); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var changes models.Jetons
+	if !bind(c, &changes) {
 		return
 	}
 
-	if err := initializers.DB.Save(&updatedJeton).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	updatedJeton, err := h.services.JetonsPacks.Update(user, jetonID, changes)
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": updatedJeton})
+	respond(c, http.StatusOK, updatedJeton)
 }
 
 // @Summary Supprime un jeton par ID
@@ -116,34 +100,27 @@ func UpdateJeton(c *gin.Context) {
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param id path int true "ID du jeton"
-// @Success 204 {object} nil
-// @Failure 404 {object} gin.H "Jeton non trouvé"
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /jetons/{id}/delete [delete]
-func DeleteJeton(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+// @Success 204 "Jeton supprimé"
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Jeton non trouvé"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/jetons/{id} [delete]
+func (h *Controller) DeleteJeton(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-
-	jetonID := c.Param("id")
-	var jeton models.Jetons
-	if err := initializers.DB.First(&jeton, "id = ?", jetonID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "jeton not found"})
-		return
-	}
-
-	currentUser := user.(models.User)
-	if currentUser.Role != 1 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to perform this action"})
+	jetonID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
-	if err := initializers.DB.Delete(&jeton).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la suppression du jeton"})
+	if err := h.services.JetonsPacks.Delete(c.Request.Context(), user, jetonID); err != nil {
+		fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "jeton supprimé avec succès"})
+	c.Status(http.StatusNoContent)
 }
@@ -4,8 +4,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"project/api/requests"
-	"project/internal/initializers"
-	"project/internal/models"
+	"project/api/responses"
+	"project/internal/listing"
+	"project/services"
 )
 
 // @Summary Créé une kermesse
@@ -16,46 +17,30 @@ import (
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param kermesse body requests.KermeseRequest true "Données de la kermesse"
-// @Success 201 {object} gin.H "Groupe créé"
-// @Failure 400 {object} gin.H "Bad request"
-// @Failure 401 {object} gin.H "Unauthorized"
-// @Failure 404 {object} gin.H "Voyage non trouvé"
-// @Failure 409 {object} gin.H "Conflict"
-// @Failure 500 {object} gin.H "Internal server error"
-// @Router /create-kermesse [post]
-func CreateKermesse(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not logged"})
-		return
-	}
-
-	currentUser := user.(models.User)
-	if currentUser.Role != 1 && currentUser.Role != 2 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You do not have permission to do that"})
+// @Success 201 {object} Envelope{data=responses.KermesseResponse} "Kermesse créée"
+// @Failure 400 {object} apperror.Response "Bad request"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /api/v1/kermesses [post]
+func (h *Controller) CreateKermesse(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
 	var kermesseData requests.KermeseRequest
-	kermesse := models.Kermesse{
-		Name:   kermesseData.Name,
-		UserID: currentUser.ID,
-	}
-
-	if err := c.ShouldBind(&kermesse); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bind(c, &kermesseData) {
 		return
 	}
-	if err := initializers.DB.Create(&kermesse).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+	kermesse, err := h.services.Kermesses.Create(user, kermesseData.Name, kermesseData.Picture)
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":  "Kermesse créée avec succès",
-		"kermesse": kermesse,
-	})
-
+	respond(c, http.StatusCreated, responses.NewKermesse(*kermesse, h.services.Pictures))
 }
 
 // @Summary Get all Kermesses based on user role
@@ -63,56 +48,35 @@ func CreateKermesse(c *gin.Context) {
 // @Tags Kermesse
 // @Accept json
 // @Produce json
+// @Param limit query int false "Taille de la page (1 à 200)" default(50)
+// @Param offset query int false "Nombre de lignes à sauter" default(0)
+// @Param sort query string false "Tri, séparé par des virgules, - pour décroissant : id, name, status, closed_at" default(id)
+// @Param q query string false "Recherche insensible à la casse sur le nom"
+// @Param status query string false "Filtre sur le statut" Enums(open, closed)
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
-// @Success 200 {object} []models.Kermesse "List of kermesses"
-// @Failure 401 {object} gin.H "User not logged"
-// @Failure 500 {object} gin.H "Internal server error"
-// @Router /kermesses [get]
-func GetAllKermesses(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not logged"})
+// @Success 200 {object} Envelope{data=[]responses.KermesseResponse,meta=Meta} "List of kermesses"
+// @Failure 400 {object} apperror.Response "Paramètres de liste invalides"
+// @Failure 401 {object} apperror.Response "User not logged"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /api/v1/kermesses [get]
+func (h *Controller) GetAllKermesses(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
-	var kermesses []models.Kermesse
-	currentUser := user.(models.User)
-
-	if currentUser.Role == 1 {
-		if err := initializers.DB.Preload("Organisateurs").Preload("Participants").Preload("Stands").Find(&kermesses).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusOK, gin.H{"kermesses": kermesses})
+	q, ok := listQuery(c, services.KermesseListing)
+	if !ok {
 		return
 	}
 
-	if currentUser.Role == 2 {
-		if err := initializers.DB.Preload("Organisateurs").Preload("Participants").Preload("Stands").
-			Where("user_id = ?", currentUser.ID).Or("id IN (SELECT kermesse_id FROM kermesse_organisateurs WHERE user_id = ?)", currentUser.ID).
-			Find(&kermesses).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusOK, gin.H{"kermesses": kermesses})
+	page, err := h.services.Kermesses.List(user, q)
+	if err != nil {
+		fail(c, err)
 		return
 	}
-
-	if currentUser.Role >= 3 {
-		if err := initializers.DB.
-			Where("id IN (SELECT kermesse_id FROM kermesse_participants WHERE user_id = ?)", currentUser.ID).
-			Find(&kermesses).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-
-		if len(kermesses) == 0 {
-			c.JSON(http.StatusOK, gin.H{"message": "Aucune kermesse trouvée pour cet utilisateur"})
-			return
-		}
-	}
-	c.JSON(http.StatusOK, gin.H{"kermesses": kermesses})
+	respondPage(c, listing.Map(page, withPictures(h.services.Pictures, responses.NewKermesse)), q)
 }
 
 // @Summary Get a Kermesse by its ID
@@ -123,117 +87,109 @@ func GetAllKermesses(c *gin.Context) {
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param id path int true "Kermesse ID"
-// @Success 200 {object} models.Kermesse "Kermesse found"
-// @Failure 401 {object} gin.H "User not logged"
-// @Failure 403 {object} gin.H "Forbidden"
-// @Failure 404 {object} gin.H "Kermesse not found"
-// @Router /kermesses/{id} [get]
-func GetKermesseById(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not logged"})
+// @Success 200 {object} Envelope{data=responses.KermesseResponse} "Kermesse found"
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "User not logged"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Kermesse not found"
+// @Router /api/v1/kermesses/{id} [get]
+func (h *Controller) GetKermesseById(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-
-	var kermesse models.Kermesse
-	id := c.Param("id")
-
-	if err := initializers.DB.Preload("Organisateurs").Preload("Participants").Preload("Stands").
-		Where("id = ?", id).First(&kermesse).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Kermesse not found"})
+	kermesseID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
-	currentUser := user.(models.User)
-
-	// Vérifier les permissions
-	if currentUser.Role == 1 || // Admin
-		kermesse.UserID == currentUser.ID || // Créateur de la kermesse
-		initializers.DB.Model(&kermesse).Where("id IN (SELECT kermesse_id FROM kermesse_organisateurs WHERE user_id = ?)", currentUser.ID).RowsAffected > 0 || // Organisateur
-		initializers.DB.Model(&kermesse).Where("id IN (SELECT kermesse_id FROM kermesse_participants WHERE user_id = ?)", currentUser.ID).RowsAffected > 0 { // Participant
-		c.JSON(http.StatusOK, gin.H{"kermesse": kermesse})
-	} else {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have access to this kermesse"})
+	kermesse, err := h.services.Kermesses.Get(user, kermesseID)
+	if err != nil {
+		fail(c, err)
+		return
 	}
+	respond(c, http.StatusOK, responses.NewKermesse(*kermesse, h.services.Pictures))
 }
 
-// @Summary Ajouter des stands à la kermesse
-// @Description Permet d'ajouter des users (partcicpants ou organisateurs)
+// @Summary Liste les stands d'une kermesse
+// @Description Récupère les stands rattachés à la kermesse, pour ceux qui peuvent voir la kermesse
 // @Tags Kermesse
-// @Accept json
 // @Produce json
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param id path int true "Kermesse ID"
-// @Param kermesse body requests.AddStandRequest true "Données du groupe"
-// @Success 200 {object} gin.H "Stand ajouté"
-// @Failure 400 {object} gin.H "Bad request"
-// @Failure 401 {object} gin.H "Unauthorized"
-// @Failure 404 {object} gin.H "kermesse non trouvé"
-// @Failure 409 {object} gin.H "Conflict"
-// @Failure 500 {object} gin.H "Internal server error"
-// @Router /kermesses/{id}/add-stands [post]
-func AddStand(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not logged"})
+// @Param limit query int false "Taille de la page (1 à 200)" default(50)
+// @Param offset query int false "Nombre de lignes à sauter" default(0)
+// @Param sort query string false "Tri, séparé par des virgules, - pour décroissant : id, name, type, jetons_requis, conso" default(id)
+// @Param q query string false "Recherche insensible à la casse sur le nom"
+// @Param type query string false "Filtre sur le type de stand"
+// @Success 200 {object} Envelope{data=[]models.Stand,meta=Meta}
+// @Failure 400 {object} apperror.Response "Paramètres de liste invalides"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "kermesse non trouvé"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /api/v1/kermesses/{id}/stands [get]
+func (h *Controller) GetKermesseStands(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-
-	kermesseID := c.Param("id")
-	var kermesse models.Kermesse
-
-	if err := initializers.DB.Preload("Organisateurs").First(&kermesse, "id = ?", kermesseID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Kermesse not found"})
+	kermesseID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
-
-	currentUser := user.(models.User)
-
-	isOrganisateur := false
-	for _, organisateur := range kermesse.Organisateurs {
-		if organisateur.ID == currentUser.ID {
-			isOrganisateur = true
-			break
-		}
-	}
-
-	if currentUser.Role != 1 && currentUser.ID != kermesse.UserID && !isOrganisateur {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You don't have the permission to do this"})
+	q, ok := listQuery(c, services.StandListing)
+	if !ok {
 		return
 	}
 
-	var standReq requests.AddStandRequest
-	if err := c.ShouldBindJSON(&standReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+	page, err := h.services.Kermesses.Stands(user, kermesseID, q)
+	if err != nil {
+		fail(c, err)
 		return
 	}
+	respondPage(c, page, q)
+}
 
-	if len(standReq.StandIds) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "StandId is required"})
+// @Summary Ajouter des stands à la kermesse
+// @Description Permet d'ajouter des users (partcicpants ou organisateurs)
+// @Tags Kermesse
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param id path int true "Kermesse ID"
+// @Param kermesse body requests.AddStandRequest true "Données du groupe"
+// @Success 200 {object} Envelope{data=[]models.Stand} "Stands ajoutés"
+// @Failure 400 {object} apperror.Response "Bad request"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "kermesse non trouvé"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /api/v1/kermesses/{id}/stands [post]
+func (h *Controller) AddStand(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-
-	var stands []models.Stand
-	if err := initializers.DB.Where("id IN ?", standReq.StandIds).Find(&stands).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des enfants"})
+	kermesseID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
-	if len(stands) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Aucun enfant trouvé pour les IDs donnés"})
+	var standReq requests.AddStandRequest
+	if !bind(c, &standReq) {
 		return
 	}
 
-	if err := initializers.DB.Model(&kermesse).Association("Stands").Append(&stands); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error adding stand to kermesse"})
+	stands, err := h.services.Kermesses.AddStands(c.Request.Context(), user, kermesseID, standReq.StandIds)
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Stand added successfully",
-		"stand":   stands,
-	})
+	respond(c, http.StatusOK, stands)
 }
 
 // @Summary Ajouter des users à la kermesse
@@ -245,98 +201,35 @@ func AddStand(c *gin.Context) {
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param id path int true "Kermesse ID"
 // @Param kermesse body requests.AddUserRequest true "Données du groupe"
-// @Success 200 {object} gin.H "User ajouté(s)"
-// @Failure 400 {object} gin.H "Bad request"
-// @Failure 401 {object} gin.H "Unauthorized"
-// @Failure 404 {object} gin.H "kermesse non trouvé"
-// @Failure 409 {object} gin.H "Conflict"
-// @Failure 500 {object} gin.H "Internal server error"
-// @Router /kermesses/{id}/add-users [post]
-func AddParticipantAndOrga(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not logged"})
+// @Success 200 {object} Envelope{data=[]responses.PublicUser} "User ajouté(s)"
+// @Failure 400 {object} apperror.Response "Bad request"
+// @Failure 401 {object} apperror.Response "Unauthorized"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "kermesse non trouvé"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /api/v1/kermesses/{id}/members [post]
+func (h *Controller) AddParticipantAndOrga(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-
-	kermesseID := c.Param("id")
-	var kermesse models.Kermesse
-
-	if err := initializers.DB.Preload("Organisateurs").First(&kermesse, "id = ?", kermesseID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Kermesse not found"})
-		return
-	}
-
-	var users []models.User
-	currentUser := user.(models.User)
-
-	isOrganisateur := false
-	for _, organisateur := range kermesse.Organisateurs {
-		if organisateur.ID == currentUser.ID {
-			isOrganisateur = true
-			break
-		}
-	}
-
-	if currentUser.Role != 1 && currentUser.ID != kermesse.UserID && !isOrganisateur {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You don't have the permission to do this"})
+	kermesseID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
 	var userReq requests.AddUserRequest
-	if err := c.ShouldBindJSON(&userReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+	if !bind(c, &userReq) {
 		return
 	}
 
-	if userReq.Type == "participants" {
-		if len(userReq.UserIds) == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "UserIds are required"})
-			return
-		}
-
-		if err := initializers.DB.Where("id IN ?", userReq.UserIds).Find(&users).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des enfants"})
-			return
-		}
-
-		if len(users) == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Aucun enfant trouvé pour les IDs donnés"})
-			return
-		}
-
-		if err := initializers.DB.Model(&kermesse).Association("Participants").Append(&users); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error adding a participant to kermesse"})
-			return
-		}
-	} else if userReq.Type == "organisateurs" {
-		if len(userReq.UserIds) == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "UserIds are required"})
-			return
-		}
-
-		if err := initializers.DB.Where("id IN ?", userReq.UserIds).Find(&users).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des enfants"})
-			return
-		}
-
-		if len(users) == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Aucun enfant trouvé pour les IDs donnés"})
-			return
-		}
-
-		if err := initializers.DB.Model(&kermesse).Association("Participants").Append(&users); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error adding a participant to kermesse"})
-			return
-		}
-
-		if err := initializers.DB.Save(&kermesse).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error adding a participant to kermesse"})
-			return
-		}
+	users, err := h.services.Kermesses.AddMembers(user, kermesseID, userReq.Type, userReq.UserIds)
+	if err != nil {
+		fail(c, err)
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Users added successfully"})
+	respond(c, http.StatusOK, responses.PublicUsers(users, h.services.Pictures))
 }
 
 // @Summary Update a Kermesse
@@ -347,46 +240,36 @@ func AddParticipantAndOrga(c *gin.Context) {
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param id path int true "Kermesse ID"
-// @Param kermesse body models.Kermesse true "Kermesse data"
-// @Success 200 {object} models.Kermesse "Kermesse updated"
-// @Failure 401 {object} gin.H "User not logged"
-// @Failure 403 {object} gin.H "Forbidden"
-// @Failure 404 {object} gin.H "Kermesse not found"
-// @Failure 500 {object} gin.H "Internal server error"
-// @Router /kermesses/{id}/update [put]
-func UpdateKermesse(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not logged"})
+// @Param kermesse body requests.KermeseRequest true "Kermesse data"
+// @Success 200 {object} Envelope{data=responses.KermesseResponse} "Kermesse updated"
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "User not logged"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Kermesse not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /api/v1/kermesses/{id} [put]
+func (h *Controller) UpdateKermesse(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-
-	currentUser := user.(models.User)
-	kermesseID := c.Param("id")
-
-	// Rechercher la kermesse à mettre à jour
-	var kermesse models.Kermesse
-	if err := initializers.DB.Where("id = ?", kermesseID).First(&kermesse).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Kermesse not found"})
-		return
-	}
-
-	if currentUser.Role != 1 && kermesse.UserID != currentUser.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to update this kermesse"})
+	kermesseID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
-	if err := c.ShouldBindJSON(&kermesse); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var kermesseData requests.KermeseRequest
+	if !bind(c, &kermesseData) {
 		return
 	}
 
-	if err := initializers.DB.Save(&kermesse).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update kermesse"})
+	kermesse, err := h.services.Kermesses.Update(user, kermesseID, kermesseData.Name, kermesseData.Picture)
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"kermesse": kermesse})
+	respond(c, http.StatusOK, responses.NewKermesse(*kermesse, h.services.Pictures))
 }
 
 // @Summary Delete a Kermesse
@@ -397,37 +280,61 @@ func UpdateKermesse(c *gin.Context) {
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param id path int true "Kermesse ID"
-// @Success 200 {object} gin.H "Kermesse delete"
-// @Failure 401 {object} gin.H "User not logged"
-// @Failure 403 {object} gin.H "Forbidden"
-// @Failure 404 {object} gin.H "Kermesse not found"
-// @Failure 500 {object} gin.H "Internal server error"
-// @Router /kermesses/{id}/delete [delete]
-func DeleteKermesse(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not logged"})
+// @Success 204 "Kermesse supprimée"
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "User not logged"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Kermesse not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /api/v1/kermesses/{id} [delete]
+func (h *Controller) DeleteKermesse(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	kermesseID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
-	currentUser := user.(models.User)
-	kermesseID := c.Param("id")
-
-	var kermesse models.Kermesse
-	if err := initializers.DB.First(&kermesse, "id = ?", kermesseID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Kermesse not found"})
+	if err := h.services.Kermesses.Delete(c.Request.Context(), user, kermesseID); err != nil {
+		fail(c, err)
 		return
 	}
 
-	if currentUser.Role != 1 && kermesse.UserID != currentUser.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to delete this kermesse"})
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Clôture une kermesse
+// @Description Marque la kermesse comme terminée : les jetons non dépensés peuvent ensuite être remboursés
+// @Tags Kermesse
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param id path int true "Kermesse ID"
+// @Success 200 {object} Envelope{data=responses.KermesseResponse} "Kermesse clôturée"
+// @Failure 400 {object} apperror.Response "Invalid kermesse ID"
+// @Failure 401 {object} apperror.Response "User not logged"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Kermesse not found"
+// @Failure 409 {object} apperror.Response "Kermesse déjà clôturée"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /api/v1/kermesses/{id}/close [post]
+func (h *Controller) CloseKermesse(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	kermesseID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
-	if err := initializers.DB.Delete(&kermesse, kermesseID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not delete kermesse"})
+	kermesse, err := h.services.Kermesses.Close(user, kermesseID)
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "kermesse supprimé avec succès"})
+	respond(c, http.StatusOK, responses.NewKermesse(*kermesse, h.services.Pictures))
 }
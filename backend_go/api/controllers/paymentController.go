@@ -1,100 +1,123 @@
 package controllers
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
-	"github.com/stripe/stripe-go/v72"
-	"github.com/stripe/stripe-go/v72/paymentintent"
+	"io"
 	"net/http"
-	"os"
 	"project/api/requests"
-	"project/internal/initializers"
-	"project/internal/models"
-	"time"
+	"project/api/responses"
+	"project/internal/apperror"
+	"project/internal/payment"
+	"project/services"
 )
 
+// ErrInvalidWebhook est retourné pour un événement de paiement non signé ou non pris en charge
+var ErrInvalidWebhook = apperror.Validation("invalid_webhook", "invalid webhook event")
+
 // @Summary Crée une intention de paiement pour les jetons ou les tickets de tombola
-// @Description Crée une intention de paiement pour acheter des jetons ou des tickets de tombola
+// @Description Crée une intention de paiement et une transaction en attente. Les jetons sont crédités à la confirmation du paiement (POST /payment/{id}/confirm ou webhook).
 // @Tags Payment
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param Idempotency-Key header string false "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine"
 // @Param payment body requests.PaymentRequest true "Paiement des jetons ou tombola"
-// @Success 201 {object} models.Transaction
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /payment [post]
-func Payment(c *gin.Context) {
-	// Vérification de l'utilisateur connecté
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Utilisateur non connecté"})
+// @Success 201 {object} Envelope{data=responses.PaymentResponse}
+// @Failure 400 {object} apperror.Response "Type de paiement invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 409 {object} apperror.Response "Requête de même Idempotency-Key en cours"
+// @Failure 422 {object} apperror.Response "Idempotency-Key déjà utilisée pour une autre requête"
+// @Failure 413 {object} apperror.Response "Corps de requête trop grand"
+// @Failure 503 {object} apperror.Response "Fournisseur de paiement indisponible"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/payments [post]
+func (h *Controller) Payment(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-	currentUser := user.(models.User)
 
 	var paymentReq requests.PaymentRequest
-	stripe.Key = os.Getenv("STRIPE_SECRET_KEY")
+	if !bind(c, &paymentReq) {
+		return
+	}
 
-	// Lier la requête à la structure PaymentRequest
-	if err := c.ShouldBind(&paymentReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	transaction, intent, err := h.services.Payments.CreatePayment(user, paymentReq.Type, paymentReq.Quantity, paymentReq.Price, paymentReq.KermesseID)
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
-	// Créer l'intention de paiement Stripe
-	params := &stripe.PaymentIntentParams{
-		Amount:   stripe.Int64(int64(paymentReq.Price * 100)),
-		Currency: stripe.String(string(stripe.CurrencyEUR)),
-		AutomaticPaymentMethods: &stripe.PaymentIntentAutomaticPaymentMethodsParams{
-			Enabled: stripe.Bool(true),
-		},
+	respond(c, http.StatusCreated, responses.PaymentResponse{PaymentIntent: *intent, Transaction: *transaction})
+}
+
+// @Summary Confirme un paiement
+// @Description Vérifie auprès du fournisseur que le paiement a abouti et crédite les jetons
+// @Tags Payment
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param Idempotency-Key header string false "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine"
+// @Param id path int true "ID de la transaction"
+// @Success 200 {object} Envelope{data=models.Transaction}
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Transaction non trouvée"
+// @Failure 409 {object} apperror.Response "Requête de même Idempotency-Key en cours"
+// @Failure 422 {object} apperror.Response "Idempotency-Key déjà utilisée pour une autre requête"
+// @Failure 413 {object} apperror.Response "Corps de requête trop grand"
+// @Failure 503 {object} apperror.Response "Fournisseur de paiement indisponible"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/payments/{id}/confirm [post]
+func (h *Controller) ConfirmPayment(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	transactionID, ok := paramID(c, "id")
+	if !ok {
+		return
 	}
 
-	pi, err := paymentintent.New(params)
+	transaction, err := h.services.Payments.ConfirmPayment(c.Request.Context(), user, transactionID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		fail(c, err)
 		return
 	}
 
-	// Gestion des paiements selon le type (jetons ou tombola)
-	var transaction models.Transaction
-	if paymentReq.Type == "jetons" {
-		// Mettre à jour le nombre de jetons de l'utilisateur
-		currentUser.Jetons += paymentReq.Quantity
-		if err := initializers.DB.Save(&currentUser).Error; err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+	respond(c, http.StatusOK, transaction)
+}
 
-		// Créer une transaction pour les jetons
-		transaction = models.Transaction{
-			DateTransaction: time.Now(),
-			Price:           float32(paymentReq.Price),
-			Quantity:        paymentReq.Quantity,
-			UserID:          currentUser.ID,
-		}
-	} else if paymentReq.Type == "tombola" {
-		// Créer une transaction pour le ticket de tombola
-		transaction = models.Transaction{
-			DateTransaction: time.Now(),
-			Price:           float32(paymentReq.Price),
-			Quantity:        paymentReq.Quantity,
-			UserID:          currentUser.ID,
-		}
-	} else {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Type de paiement invalide"})
+// @Summary Webhook du fournisseur de paiement
+// @Description Reçoit les événements signés du fournisseur (paiement réussi ou échoué)
+// @Tags Payment
+// @Accept json
+// @Produce json
+// @Param Stripe-Signature header string true "Signature de l'événement"
+// @Success 200 {object} Envelope{data=responses.WebhookResponse}
+// @Failure 400 {object} apperror.Response "Signature invalide"
+// @Router /api/v1/payments/webhook [post]
+func (h *Controller) PaymentWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		fail(c, ErrInvalidBody.Wrap(err))
 		return
 	}
 
-	// Enregistrer la transaction dans la base de données
-	if err := initializers.DB.Create(&transaction).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Erreur lors de la création de la transaction"})
+	err = h.services.Payments.HandleWebhook(c.Request.Context(), payload, c.GetHeader("Stripe-Signature"))
+	switch {
+	case errors.Is(err, payment.ErrInvalidSignature), errors.Is(err, payment.ErrWebhookUnsupported):
+		fail(c, ErrInvalidWebhook.Wrap(err))
+		return
+	case errors.Is(err, services.ErrTransactionNotFound):
+		// Événement d'une intention inconnue : on l'acquitte pour que le fournisseur ne le renvoie pas
+	case err != nil:
+		fail(c, err)
 		return
 	}
 
-	// Répondre avec les détails de l'intention de paiement et de la transaction
-	c.JSON(http.StatusOK, gin.H{
-		"paymentIntent": pi,
-		"transaction":   transaction,
-	})
+	respond(c, http.StatusOK, responses.WebhookResponse{Received: true})
 }
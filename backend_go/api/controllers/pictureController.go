@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"project/api/responses"
+	"project/internal/apperror"
+	"project/internal/storage"
+	"project/services"
+)
+
+// Champ du formulaire multipart qui porte la photo
+const pictureField = "file"
+
+// Marge laissée à l'enveloppe multipart (séparateurs, en-têtes des parties) au-delà de la photo
+const multipartOverhead = 64 << 10
+
+var errMissingPicture = apperror.Validation("missing_file", "a picture file is required", apperror.FieldError{
+	Field:   pictureField,
+	Code:    "required",
+	Message: pictureField + " is required",
+})
+
+// @Summary Change la photo du compte connecté
+// @Description Envoi multipart d'une image JPEG, PNG ou GIF. Elle est réduite, une miniature est produite et les métadonnées EXIF sont retirées.
+// @Tags Auth
+// @Accept multipart/form-data
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param file formData file true "Image JPEG, PNG ou GIF"
+// @Success 200 {object} Envelope{data=responses.PictureResponse} "Liens signés de la photo"
+// @Failure 400 {object} apperror.Response "Fichier absent, illisible ou d'un type non accepté"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 413 {object} apperror.Response "Fichier ou image trop grand"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/me/picture [put]
+func (h *Controller) SetMyPicture(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	file, ok := h.pictureFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	links, err := h.services.Pictures.SetUserPicture(user, file)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, pictureResponse(links))
+}
+
+// @Summary Change la photo d'une kermesse
+// @Description Envoi multipart d'une image, réservé aux organisateurs de la kermesse
+// @Tags Kermesse
+// @Accept multipart/form-data
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param id path int true "ID de la kermesse"
+// @Param file formData file true "Image JPEG, PNG ou GIF"
+// @Success 200 {object} Envelope{data=responses.PictureResponse} "Liens signés de la photo"
+// @Failure 400 {object} apperror.Response "Fichier absent, illisible ou d'un type non accepté"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Réservé aux organisateurs"
+// @Failure 404 {object} apperror.Response "Kermesse non trouvée"
+// @Failure 413 {object} apperror.Response "Fichier ou image trop grand"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/kermesses/{id}/picture [put]
+func (h *Controller) SetKermessePicture(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	kermesseID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	file, ok := h.pictureFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	links, err := h.services.Pictures.SetKermessePicture(user, kermesseID, file)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, pictureResponse(links))
+}
+
+// @Summary Change la photo d'un produit
+// @Description Envoi multipart d'une image, réservé aux administrateurs
+// @Tags Product
+// @Accept multipart/form-data
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param id path int true "ID du produit"
+// @Param file formData file true "Image JPEG, PNG ou GIF"
+// @Success 200 {object} Envelope{data=responses.PictureResponse} "Liens signés de la photo"
+// @Failure 400 {object} apperror.Response "Fichier absent, illisible ou d'un type non accepté"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Réservé aux administrateurs"
+// @Failure 404 {object} apperror.Response "Produit non trouvé"
+// @Failure 413 {object} apperror.Response "Fichier ou image trop grand"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/products/{id}/picture [put]
+func (h *Controller) SetProductPicture(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	productID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	file, ok := h.pictureFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	links, err := h.services.Pictures.SetProductPicture(user, productID, file)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	respond(c, http.StatusOK, pictureResponse(links))
+}
+
+// @Summary Sert un fichier du stockage local
+// @Description Cible des liens signés renvoyés par l'API quand les photos sont stockées localement. Sans authentification : la signature et l'expiration du lien font foi.
+// @Tags File
+// @Produce image/jpeg,image/png
+// @Param key path string true "Clé du fichier"
+// @Param expires query int true "Expiration du lien, en secondes Unix"
+// @Param signature query string true "Signature du lien"
+// @Success 200 {file} file "Le fichier"
+// @Failure 403 {object} apperror.Response "Lien invalide ou expiré"
+// @Failure 404 {object} apperror.Response "Fichier non trouvé"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/files/{key} [get]
+func (h *Controller) ServeFile(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	obj, err := h.services.Pictures.Open(key, c.Query(storage.ParamExpires), c.Query(storage.ParamSignature))
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	defer obj.Body.Close()
+
+	c.Header("Content-Type", obj.ContentType)
+	c.Header("Cache-Control", "private")
+	http.ServeContent(c.Writer, c.Request, "", obj.ModTime, obj.Body)
+}
+
+// pictureFile ouvre la photo du formulaire multipart, en bornant la taille lue, ou signale l'erreur
+func (h *Controller) pictureFile(c *gin.Context) (multipart.File, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.services.Pictures.MaxBytes()+multipartOverhead)
+	header, err := c.FormFile(pictureField)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			fail(c, services.ErrPictureTooLarge)
+		} else {
+			fail(c, errMissingPicture)
+		}
+		return nil, false
+	}
+	file, err := header.Open()
+	if err != nil {
+		fail(c, err)
+		return nil, false
+	}
+	return file, true
+}
+
+func pictureResponse(links *services.PictureLinks) responses.PictureResponse {
+	return responses.PictureResponse{Picture: links.URL, Thumbnail: links.Thumbnail, ExpiresAt: links.ExpiresAt}
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"project/api/requests"
+)
+
+// @Summary Solde d'une carte prépayée
+// @Description Retourne le solde de la carte à partir du code imprimé (sans compte : le code fait office de justificatif)
+// @Tags PrepaidCard
+// @Produce json
+// @Param code path string true "Code de la carte"
+// @Success 200 {object} Envelope{data=models.PrepaidCard}
+// @Failure 404 {object} apperror.Response "Carte non trouvée"
+// @Failure 422 {object} apperror.Response "Carte désactivée"
+// @Router /api/v1/prepaid-cards/{code} [get]
+func (h *Controller) GetPrepaidCard(c *gin.Context) {
+	card, err := h.services.PrepaidCards.Get(c.Param("code"))
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, card)
+}
+
+// @Summary Rattache une carte prépayée à son compte
+// @Description Lie la carte au compte connecté ; avec merge_balance, le solde de la carte est transféré sur le compte
+// @Tags PrepaidCard
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param Idempotency-Key header string false "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine"
+// @Param code path string true "Code de la carte"
+// @Param link body requests.LinkCardRequest false "Transfert du solde"
+// @Success 200 {object} Envelope{data=models.PrepaidCard}
+// @Failure 400 {object} apperror.Response "Requête invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 404 {object} apperror.Response "Carte non trouvée"
+// @Failure 409 {object} apperror.Response "Carte déjà rattachée à un autre compte, ou utilisée pendant le rattachement"
+// @Failure 422 {object} apperror.Response "Carte désactivée"
+// @Failure 413 {object} apperror.Response "Corps de requête trop grand"
+// @Router /api/v1/prepaid-cards/{code}/link [post]
+func (h *Controller) LinkPrepaidCard(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req requests.LinkCardRequest
+	if c.Request.ContentLength > 0 && !bind(c, &req) {
+		return
+	}
+
+	card, err := h.services.PrepaidCards.Link(c.Request.Context(), user, c.Param("code"), req.MergeBalance)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, card)
+}
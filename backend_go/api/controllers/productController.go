@@ -3,8 +3,10 @@ package controllers
 import (
 	"github.com/gin-gonic/gin"
 	"net/http"
-	"project/internal/initializers"
+	"project/api/responses"
+	"project/internal/listing"
 	"project/internal/models"
+	"project/services"
 )
 
 // @Summary Crée un nouveau produit
@@ -15,35 +17,31 @@ import (
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param product body models.Product true "Produit à créer"
-// @Success 201 {object} models.Product
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /create-product  [post]
-func CreateProduct(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not logged"})
-		return
-	}
-
-	currentUser := user.(models.User)
-	if currentUser.Role != 1 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to create a product"})
+// @Success 201 {object} Envelope{data=responses.ProductResponse}
+// @Failure 400 {object} apperror.Response "Données invalides"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Stand non trouvé"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/products [post]
+func (h *Controller) CreateProduct(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
 	var product models.Product
-	if err := c.ShouldBind(&product); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bind(c, &product) {
 		return
 	}
-	if err := initializers.DB.Create(&product).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+	created, err := h.services.Products.Create(user, product)
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "produit créé avec succès",
-		"product": product})
+	respond(c, http.StatusCreated, responses.NewProduct(*created, h.services.Pictures))
 }
 
 // @Summary Récupère tous les produits
@@ -52,27 +50,115 @@ func CreateProduct(c *gin.Context) {
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Produce json
-// @Success 200 {object} []models.Product
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /products [get]
-func GetProducts(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized user"})
+// @Param limit query int false "Taille de la page (1 à 200)" default(50)
+// @Param offset query int false "Nombre de lignes à sauter" default(0)
+// @Param sort query string false "Tri, séparé par des virgules, - pour décroissant : id, name, type, jetons_requis, nb_products, updated_at" default(id)
+// @Param q query string false "Recherche insensible à la casse sur le nom"
+// @Param stand_id query int false "Produits du stand"
+// @Param type query string false "Filtre sur le type de produit"
+// @Success 200 {object} Envelope{data=[]responses.ProductResponse,meta=Meta}
+// @Failure 400 {object} apperror.Response "Paramètres de liste invalides"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/products [get]
+func (h *Controller) GetProducts(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
-	currentUser := user.(models.User)
-	if currentUser.Role != 1 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to perform this action"})
+	q, ok := listQuery(c, services.ProductListing)
+	if !ok {
+		return
 	}
 
-	var products []models.Product
-	if err := initializers.DB.Find(&products).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized user"})
+	page, err := h.services.Products.List(user, q)
+	if err != nil {
+		fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"products": products})
+	respondPage(c, listing.Map(page, withPictures(h.services.Pictures, responses.NewProduct)), q)
+}
+
+// @Summary Liste les produits d'un stand
+// @Description Récupère le stock du stand, visible de tout utilisateur connecté
+// @Tags Product
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param id path int true "ID du stand"
+// @Param limit query int false "Taille de la page (1 à 200)" default(50)
+// @Param offset query int false "Nombre de lignes à sauter" default(0)
+// @Param sort query string false "Tri, séparé par des virgules, - pour décroissant : id, name, type, jetons_requis, nb_products, updated_at" default(id)
+// @Param q query string false "Recherche insensible à la casse sur le nom"
+// @Param type query string false "Filtre sur le type de produit"
+// @Success 200 {object} Envelope{data=[]responses.ProductResponse,meta=Meta}
+// @Failure 400 {object} apperror.Response "Paramètres de liste invalides"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 404 {object} apperror.Response "Stand non trouvé"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/stands/{id}/products [get]
+func (h *Controller) GetStandProducts(c *gin.Context) {
+	if _, ok := currentUser(c); !ok {
+		return
+	}
+	standID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	q, ok := listQuery(c, services.ProductListing)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Products.ListByStand(standID, q)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	respondPage(c, listing.Map(page, withPictures(h.services.Pictures, responses.NewProduct)), q)
+}
+
+// @Summary Ajoute un produit à un stand
+// @Description Crée un produit dans le stock du stand désigné par le chemin ; le stand_id du corps est ignoré
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param id path int true "ID du stand"
+// @Param product body models.Product true "Produit à créer"
+// @Success 201 {object} Envelope{data=responses.ProductResponse}
+// @Failure 400 {object} apperror.Response "Données invalides"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Stand non trouvé"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/stands/{id}/products [post]
+func (h *Controller) CreateStandProduct(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	standID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	var product models.Product
+	if !bind(c, &product) {
+		return
+	}
+	product.StandID = uint64(standID)
+
+	created, err := h.services.Products.Create(user, product)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusCreated, responses.NewProduct(*created, h.services.Pictures))
 }
 
 // @Summary Met à jour un produit par son ID
@@ -84,38 +170,35 @@ func GetProducts(c *gin.Context) {
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param id path int true "ID de l'utilisateur"
 // @Param product body models.Product true "Produit à mettre à jour"
-// @Success 200 {object} models.Product
-// @Failure 404 {object} gin.H "Prodduit non trouvé"
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /products/{id}/update [put]
-func UpdateProduct(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"message": "user not logged"})
+// @Success 200 {object} Envelope{data=responses.ProductResponse}
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Prodduit non trouvé"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/products/{id} [put]
+func (h *Controller) UpdateProduct(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-	currentUser := user.(models.User)
-
-	if currentUser.Role != 1 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to perform this action"})
+	productID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
-	productID := c.Param("id")
-	if err := initializers.DB.First("id = ?", productID).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	var changes models.Product
+	if !bind(c, &changes) {
 		return
 	}
 
-	var product models.Product
-	if err := initializers.DB.Save(&product).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	product, err := h.services.Products.Update(user, productID, changes)
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "product updated",
-		"product": product})
+	respond(c, http.StatusOK, responses.NewProduct(*product, h.services.Pictures))
 }
 
 // @Summary Supprime un produit par ID
@@ -125,32 +208,26 @@ func UpdateProduct(c *gin.Context) {
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param id path int true "ID de du produit"
-// @Success 204 {object} nil
-// @Failure 404 {object} gin.H "produit non trouvé"
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /products/{id}/delete [delete]
-func DeleteProduct(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized user"})
+// @Success 204 "Produit supprimé"
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "produit non trouvé"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/products/{id} [delete]
+func (h *Controller) DeleteProduct(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-	id := c.Param("id")
-	var productFound models.Product
-	if err := initializers.DB.First(&productFound, "id = ?", id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Produdct not found"})
-		return
-	}
-
-	currentUser := user.(models.User)
-	if currentUser.Role != 1 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to perform this action"})
+	productID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
-	if err := initializers.DB.Delete(&productFound, id).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"message": "product not found"})
+	if err := h.services.Products.Delete(c.Request.Context(), user, productID); err != nil {
+		fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "product deleted"})
+	c.Status(http.StatusNoContent)
 }
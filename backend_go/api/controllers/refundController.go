@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"project/api/requests"
+)
+
+// @Summary Aperçu des remboursements de fin de kermesse
+// @Description Calcule pour chaque famille la valeur des jetons non dépensés achetés pour la kermesse, au prix payé lors des achats
+// @Tags Refund
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param id path int true "Kermesse ID"
+// @Success 200 {object} Envelope{data=[]services.FamilyRefund}
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Kermesse not found"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /api/v1/kermesses/{id}/refunds [get]
+func (h *Controller) GetKermesseRefunds(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	kermesseID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	families, err := h.services.Refunds.Preview(user, kermesseID)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, families)
+}
+
+// @Summary Rembourse les jetons non dépensés de toutes les familles
+// @Description Émet, pour chaque famille de la kermesse clôturée, un remboursement carte sur les paiements faits pour cette kermesse et débite les jetons remboursés. Les autres jetons restent sur les comptes.
+// @Tags Refund
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param Idempotency-Key header string false "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine"
+// @Param id path int true "Kermesse ID"
+// @Success 200 {object} Envelope{data=[]services.FamilyRefund}
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Kermesse not found"
+// @Failure 409 {object} apperror.Response "Kermesse non clôturée"
+// @Failure 422 {object} apperror.Response "Idempotency-Key déjà utilisée pour une autre requête"
+// @Failure 413 {object} apperror.Response "Corps de requête trop grand"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /api/v1/kermesses/{id}/refunds [post]
+func (h *Controller) RefundKermesse(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	kermesseID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	families, err := h.services.Refunds.RefundAll(c.Request.Context(), user, kermesseID)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, families)
+}
+
+// @Summary Rembourse ou donne le solde de jetons de sa famille
+// @Description Le parent choisit entre un remboursement carte des jetons non dépensés ou un don à l'école
+// @Tags Refund
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param Idempotency-Key header string false "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine"
+// @Param id path int true "Kermesse ID"
+// @Param settle body requests.SettleTokensRequest true "Don à l'école plutôt que remboursement"
+// @Success 200 {object} Envelope{data=services.FamilyRefund}
+// @Failure 400 {object} apperror.Response "Bad request"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Kermesse not found"
+// @Failure 409 {object} apperror.Response "Kermesse non clôturée"
+// @Failure 422 {object} apperror.Response "Idempotency-Key déjà utilisée pour une autre requête"
+// @Failure 413 {object} apperror.Response "Corps de requête trop grand"
+// @Failure 500 {object} apperror.Response "Internal server error"
+// @Router /api/v1/kermesses/{id}/refunds/me [post]
+func (h *Controller) SettleMyTokens(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	kermesseID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req requests.SettleTokensRequest
+	if !bind(c, &req) {
+		return
+	}
+
+	family, err := h.services.Refunds.SettleFamily(c.Request.Context(), user, kermesseID, req.Donate)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, family)
+}
@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"project/api/requests"
+	"project/api/responses"
+	"project/services"
+)
+
+// @Summary Enregistre un appareil de stand
+// @Description Déclare une tablette ou un téléphone du stand pour la vente hors ligne. Le secret de signature n'est renvoyé qu'à cette occasion.
+// @Tags Sync
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param id path int true "ID du stand"
+// @Param device body requests.RegisterDeviceRequest true "Nom de l'appareil"
+// @Success 201 {object} Envelope{data=responses.RegisterDeviceResponse}
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Stand non trouvé"
+// @Router /api/v1/stands/{id}/devices [post]
+func (h *Controller) RegisterStandDevice(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	standID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req requests.RegisterDeviceRequest
+	if !bind(c, &req) {
+		return
+	}
+
+	device, secret, err := h.services.Sync.RegisterDevice(user, standID, req.Name)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusCreated, responses.RegisterDeviceResponse{Device: *device, Secret: secret})
+}
+
+// @Summary Envoie les opérations enregistrées hors ligne
+// @Description Applique un lot d'achats et d'attributions de points signés par l'appareil. Chaque opération est appliquée, rejetée ou signalée en conflit ; un renvoi de la même opération (même client_id) retourne le résultat déjà enregistré. Une opération invalide ou mal signée n'est pas enregistrée (id 0) et une opération rejetée peut être renvoyée.
+// @Tags Sync
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param id path int true "ID de l'appareil"
+// @Param operations body requests.SyncUploadRequest true "Opérations hors ligne"
+// @Success 200 {object} Envelope{data=[]models.SyncOperation}
+// @Failure 400 {object} apperror.Response "Bad request"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Appareil non trouvé"
+// @Router /api/v1/sync/devices/{id}/operations [post]
+func (h *Controller) UploadSyncOperations(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	deviceID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req requests.SyncUploadRequest
+	if !bind(c, &req) {
+		return
+	}
+
+	operations := make([]services.OfflineOperation, 0, len(req.Operations))
+	for _, op := range req.Operations {
+		operations = append(operations, services.OfflineOperation{
+			ClientID:   op.ClientID,
+			Type:       op.Type,
+			RecordedAt: op.RecordedAt,
+			UserID:     op.UserID,
+			CardCode:   op.CardCode,
+			ProductID:  op.ProductID,
+			Quantity:   op.Quantity,
+			Points:     op.Points,
+			Signature:  op.Signature,
+		})
+	}
+
+	results, err := h.services.Sync.Upload(user, deviceID, operations)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, results)
+}
+
+// @Summary Télécharge les mises à jour du catalogue et des soldes
+// @Description Retourne les produits du stand et les soldes des comptes et cartes prépayées modifiés depuis le curseur, ainsi que le nouveau curseur
+// @Tags Sync
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param id path int true "ID de l'appareil"
+// @Param cursor query string false "Curseur renvoyé par la synchronisation précédente"
+// @Success 200 {object} Envelope{data=services.SyncChanges}
+// @Failure 400 {object} apperror.Response "Curseur invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Appareil non trouvé"
+// @Router /api/v1/sync/devices/{id}/changes [get]
+func (h *Controller) GetSyncChanges(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	deviceID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	changes, err := h.services.Sync.Changes(user, deviceID, c.Query("cursor"))
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, changes)
+}
+
+// @Summary Conflits de synchronisation d'un stand
+// @Description Liste les opérations hors ligne valides qui n'ont pas pu être appliquées (solde ou stock insuffisant)
+// @Tags Sync
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param id path int true "ID du stand"
+// @Success 200 {object} Envelope{data=[]models.SyncOperation}
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Stand non trouvé"
+// @Router /api/v1/stands/{id}/sync-conflicts [get]
+func (h *Controller) GetSyncConflicts(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	standID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	operations, err := h.services.Sync.Conflicts(user, standID)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, operations)
+}
@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"project/api/requests"
+	"project/api/responses"
+	"project/services"
+)
+
+// @Summary Ouvre une session de caisse
+// @Description Le caissier ouvre sa caisse pour une kermesse avec un fond de caisse
+// @Tags Caisse
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param till body requests.OpenTillRequest true "Kermesse et fond de caisse"
+// @Success 201 {object} Envelope{data=models.TillSession}
+// @Failure 400 {object} apperror.Response "Bad request"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 409 {object} apperror.Response "Caisse déjà ouverte"
+// @Router /api/v1/till-sessions [post]
+func (h *Controller) OpenTill(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req requests.OpenTillRequest
+	if !bind(c, &req) {
+		return
+	}
+
+	session, err := h.services.Tills.Open(user, req.KermesseID, req.OpeningFloat)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusCreated, session)
+}
+
+// @Summary Session de caisse en cours
+// @Description Retourne la session ouverte du caissier avec ses ventes et le montant attendu dans le tiroir
+// @Tags Caisse
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Success 200 {object} Envelope{data=services.TillReport}
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 404 {object} apperror.Response "Aucune caisse ouverte"
+// @Router /api/v1/till-sessions/current [get]
+func (h *Controller) GetCurrentTill(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.services.Tills.Current(user)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, report)
+}
+
+// @Summary Vend des jetons en caisse
+// @Description Vend un ou plusieurs packs de jetons payés en espèces ou au terminal carte et crédite le compte de la famille (user_id) ou une carte prépayée (card_code)
+// @Tags Caisse
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param Idempotency-Key header string false "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine"
+// @Param sale body requests.TillSaleRequest true "Client, pack de jetons et moyen de paiement"
+// @Success 201 {object} Envelope{data=models.Transaction}
+// @Failure 400 {object} apperror.Response "Bad request"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 404 {object} apperror.Response "Client, carte, pack ou caisse non trouvé"
+// @Failure 409 {object} apperror.Response "Requête de même Idempotency-Key en cours"
+// @Failure 422 {object} apperror.Response "Idempotency-Key déjà utilisée pour une autre requête"
+// @Failure 413 {object} apperror.Response "Corps de requête trop grand"
+// @Router /api/v1/till-sessions/current/sales [post]
+func (h *Controller) SellJetonsAtTill(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req requests.TillSaleRequest
+	if !bind(c, &req) {
+		return
+	}
+
+	transaction, err := h.services.Tills.SellJetons(c.Request.Context(), user, services.TillSale{
+		UserID:        req.UserID,
+		CardCode:      req.CardCode,
+		JetonsID:      req.JetonsID,
+		Packs:         req.Packs,
+		PaymentMethod: req.PaymentMethod,
+	})
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusCreated, transaction)
+}
+
+// @Summary Émet une carte prépayée
+// @Description Crée une carte prépayée anonyme pour la kermesse de la caisse, chargée immédiatement si un pack de jetons est indiqué
+// @Tags Caisse
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param Idempotency-Key header string false "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine"
+// @Param card body requests.IssueCardRequest false "Pack de jetons et moyen de paiement pour la première recharge"
+// @Success 201 {object} Envelope{data=responses.IssueCardResponse}
+// @Failure 400 {object} apperror.Response "Bad request"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 404 {object} apperror.Response "Pack ou caisse non trouvé"
+// @Failure 409 {object} apperror.Response "Requête de même Idempotency-Key en cours"
+// @Failure 422 {object} apperror.Response "Idempotency-Key déjà utilisée pour une autre requête"
+// @Failure 413 {object} apperror.Response "Corps de requête trop grand"
+// @Router /api/v1/till-sessions/current/cards [post]
+func (h *Controller) IssuePrepaidCard(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req requests.IssueCardRequest
+	if c.Request.ContentLength > 0 && !bind(c, &req) {
+		return
+	}
+
+	card, transaction, err := h.services.Tills.IssueCard(c.Request.Context(), user, services.TillSale{
+		JetonsID:      req.JetonsID,
+		Packs:         req.Packs,
+		PaymentMethod: req.PaymentMethod,
+	})
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusCreated, responses.IssueCardResponse{Card: *card, Transaction: transaction})
+}
+
+// @Summary Ferme la session de caisse
+// @Description Enregistre le comptage du tiroir et calcule l'écart avec le montant attendu
+// @Tags Caisse
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param till body requests.CloseTillRequest true "Comptage du tiroir"
+// @Success 200 {object} Envelope{data=services.TillReport}
+// @Failure 400 {object} apperror.Response "Bad request"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 404 {object} apperror.Response "Aucune caisse ouverte"
+// @Router /api/v1/till-sessions/current/close [post]
+func (h *Controller) CloseTill(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req requests.CloseTillRequest
+	if !bind(c, &req) {
+		return
+	}
+
+	report, err := h.services.Tills.Close(user, *req.ClosingCount)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, report)
+}
+
+// @Summary Rapport d'une session de caisse
+// @Description Ventes, montant attendu, comptage et écart d'une session (caissier, organisateurs et admins)
+// @Tags Caisse
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param id path int true "ID de la session"
+// @Success 200 {object} Envelope{data=services.TillReport}
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Session non trouvée"
+// @Router /api/v1/till-sessions/{id}/report [get]
+func (h *Controller) GetTillReport(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	sessionID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	report, err := h.services.Tills.Report(user, sessionID)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, report)
+}
+
+// @Summary Rapports de caisse d'une kermesse
+// @Description Liste les sessions de caisse d'une kermesse avec leurs écarts (organisateurs et admins)
+// @Tags Caisse
+// @Produce json
+// @Security Bearer
+// @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
+// @Param id path int true "Kermesse ID"
+// @Success 200 {object} Envelope{data=[]services.TillReport}
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Forbidden"
+// @Failure 404 {object} apperror.Response "Kermesse not found"
+// @Router /api/v1/kermesses/{id}/till-sessions [get]
+func (h *Controller) GetKermesseTills(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	kermesseID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	reports, err := h.services.Tills.ListForKermesse(user, kermesseID)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, reports)
+}
@@ -2,9 +2,7 @@ package controllers
 
 import (
 	"github.com/gin-gonic/gin"
-	"net/http"
-	"project/internal/initializers"
-	"project/internal/models"
+	"project/services"
 )
 
 // @Summary Récupère toutes les transactions faites par le user
@@ -13,22 +11,34 @@ import (
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Produce json
-// @Success 200 {object} []models.Transaction
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /transactions [get]
-func GetTransactions(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not loggeg"})
+// @Param limit query int false "Taille de la page (1 à 200)" default(50)
+// @Param offset query int false "Nombre de lignes à sauter" default(0)
+// @Param sort query string false "Tri, séparé par des virgules, - pour décroissant : id, date, price, quantity" default(-date,-id)
+// @Param type query string false "Filtre sur le type" Enums(jetons, tombola, refund, donation)
+// @Param status query string false "Filtre sur le statut" Enums(pending, succeeded, failed)
+// @Param kermesse_id query int false "Transactions de la kermesse"
+// @Param from query string false "Début de la période (2006-01-02 ou RFC 3339)"
+// @Param to query string false "Fin de la période, incluse"
+// @Success 200 {object} Envelope{data=[]models.Transaction,meta=Meta}
+// @Failure 400 {object} apperror.Response "Paramètres de liste invalides"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/transactions [get]
+func (h *Controller) GetTransactions(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
-	currentUser := user.(models.User)
+	q, ok := listQuery(c, services.TransactionListing)
+	if !ok {
+		return
+	}
 
-	var transactions []models.Transaction
-	if err := initializers.DB.Find(&transactions, currentUser.ID).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	page, err := h.services.Payments.Transactions(user, q)
+	if err != nil {
+		fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"transactions": transactions})
+	respondPage(c, page, q)
 }
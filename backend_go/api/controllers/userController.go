@@ -4,8 +4,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"project/api/requests"
-	"project/internal/initializers"
-	"project/internal/models"
+	"project/api/responses"
+	"project/internal/listing"
+	"project/services"
 )
 
 // @Summary Crée un nouvel utilisateur
@@ -16,42 +17,31 @@ import (
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param user body requests.SignupRequest true "Utilisateur à créer"
-// @Success 201 {object} models.User
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /api/users  [post]
-func CreateUser(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-		return
-	}
-
-	currentUser := user.(models.User)
-	//Si le user n'est pas admin
-	if currentUser.Role != 1 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to perform this action"})
+// @Success 201 {object} Envelope{data=responses.AdminUser}
+// @Failure 400 {object} apperror.Response "Requête invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Réservé aux administrateurs"
+// @Failure 409 {object} apperror.Response "Email déjà utilisé"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/users [post]
+func (h *Controller) CreateUser(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
 	var createdUser requests.SignupRequest
-	newUser := models.User{
-		Firstname: createdUser.Firstname,
-		Lastname:  createdUser.Lastname,
-		Email:     createdUser.Email,
-		Password:  createdUser.Password,
-		Picture:   createdUser.Picture,
-		Role:      createdUser.Role,
-	}
-	if err := c.ShouldBindJSON(&newUser); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bind(c, &createdUser) {
 		return
 	}
-	if err := initializers.DB.Create(&newUser).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+	newUser, err := h.services.Users.Create(c.Request.Context(), user, signupInput(createdUser))
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"user": newUser})
+	respond(c, http.StatusCreated, responses.NewAdminUser(*newUser, h.services.Pictures))
 }
 
 // GetUsers - Récupère tous les utilisateurs
@@ -61,27 +51,35 @@ func CreateUser(c *gin.Context) {
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Produce json
-// @Success 200 {object} []models.User
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /api/users [get]
-func GetAllUsers(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+// @Param limit query int false "Taille de la page (1 à 200)" default(50)
+// @Param offset query int false "Nombre de lignes à sauter" default(0)
+// @Param sort query string false "Tri, séparé par des virgules, - pour décroissant : id, firstname, lastname, email, role, jetons" default(id)
+// @Param q query string false "Recherche insensible à la casse sur le prénom, le nom et l'email"
+// @Param role query int false "Filtre sur le rôle"
+// @Param kermesse_id query int false "Membres de la kermesse"
+// @Success 200 {object} Envelope{data=[]responses.AdminUser,meta=Meta}
+// @Failure 400 {object} apperror.Response "Paramètres de liste invalides"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Réservé aux administrateurs"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/users [get]
+func (h *Controller) GetAllUsers(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
-	currentUser := user.(models.User)
-	if currentUser.Role != 1 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to perform this action"})
+	q, ok := listQuery(c, services.UserListing)
+	if !ok {
+		return
 	}
 
-	var userRetrieved []models.User
-	if err := initializers.DB.Find(&userRetrieved).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	page, err := h.services.Users.List(user, q)
+	if err != nil {
+		fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, userRetrieved)
+	respondPage(c, listing.Map(page, withPictures(h.services.Pictures, responses.NewAdminUser)), q)
 }
 
 // @Summary Récupère un utilisateur par ID
@@ -91,38 +89,30 @@ func GetAllUsers(c *gin.Context) {
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param id path int true "ID de l'utilisateur"
-// @Success 200 {object} models.User
-// @Failure 404 {object} gin.H "Utilisateur non trouvé"
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /api/users/{id} [get]
-func GetUser(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+// @Success 200 {object} Envelope{data=responses.AdminUser}
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Réservé aux administrateurs"
+// @Failure 404 {object} apperror.Response "Utilisateur non trouvé"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/users/{id} [get]
+func (h *Controller) GetUser(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-
-	currentUser := user.(models.User)
-	if currentUser.Role != 1 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to perform this action"})
+	userID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
-	userID := c.Param("id")
-	var userRetrieved models.User
-	if err := initializers.DB.First(&userRetrieved, "id = ?", userID).
-		Preload("Parents").
-		Preload("Enfants").
-		Preload("Kermesses").
-		Preload("Stands").
-		Preload("Transactions").
-		Preload("Historique").
-		First(&userRetrieved, "id = ?", userID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	userRetrieved, err := h.services.Users.Get(user, userID)
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, userRetrieved)
+	respond(c, http.StatusOK, responses.NewAdminUser(*userRetrieved, h.services.Pictures))
 }
 
 // @Summary Met à jour un utilisateur par ID
@@ -133,77 +123,72 @@ func GetUser(c *gin.Context) {
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param id path int true "ID de l'utilisateur"
-// @Param user body models.User true "Utilisateur à mettre à jour"
-// @Success 200 {object} models.User
-// @Failure 404 {object} gin.H "Utilisateur non trouvé"
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /api/users/{id} [put]
-func UpdateUser(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+// @Param user body requests.UpdateUserRequest true "Champs à mettre à jour"
+// @Success 200 {object} Envelope{data=responses.AdminUser}
+// @Failure 400 {object} apperror.Response "Requête invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Réservé aux administrateurs"
+// @Failure 404 {object} apperror.Response "Utilisateur non trouvé"
+// @Failure 409 {object} apperror.Response "Email déjà utilisé"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/users/{id} [put]
+func (h *Controller) UpdateUser(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-	currentUser := user.(models.User)
-	if currentUser.Role != 1 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to perform this action"})
+	userID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
-	userID := c.Param("id")
-	var userRetrieved models.User
-	if err := initializers.DB.First(&userRetrieved, "id = ?", userID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var req requests.UpdateUserRequest
+	if !bind(c, &req) {
 		return
 	}
 
-	var updatedUser models.User
-	if err := c.ShouldBindJSON(&updatedUser); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	updatedUser, err := h.services.Users.Update(c.Request.Context(), user, userID, services.SignupInput{
+		Firstname: req.Firstname,
+		Lastname:  req.Lastname,
+		Email:     req.Email,
+		Password:  req.Password,
+		Picture:   req.Picture,
+		Role:      req.Role,
+	})
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
-	if err := initializers.DB.Save(&updatedUser).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, updatedUser)
+	respond(c, http.StatusOK, responses.NewAdminUser(*updatedUser, h.services.Pictures))
 }
 
 // @Summary Supprime un utilisateur par ID
 // @Description Supprime un utilisateur spécifique
 // @Tags User
-// @Produce json
 // @Security Bearer
 // @Param Authorization header string true "Insert your access token" default(Bearer Add access token here)
 // @Param id path int true "ID de l'utilisateur"
-// @Success 204 {object} nil
-// @Failure 404 {object} gin.H "Utilisateur non trouvé"
-// @Failure 500 {object} gin.H "Erreur serveur interne"
-// @Router /api/users/{id} [delete]
-func DeleteUser(c *gin.Context) {
-	user, exists := c.Get("currentUser")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+// @Success 204 "Utilisateur supprimé"
+// @Failure 400 {object} apperror.Response "Paramètre invalide"
+// @Failure 401 {object} apperror.Response "Non connecté"
+// @Failure 403 {object} apperror.Response "Réservé aux administrateurs"
+// @Failure 404 {object} apperror.Response "Utilisateur non trouvé"
+// @Failure 500 {object} apperror.Response "Erreur serveur interne"
+// @Router /api/v1/users/{id} [delete]
+func (h *Controller) DeleteUser(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-	userID := c.Param("id")
-	var userRetrieved models.User
-	currentUser := user.(models.User)
-	if currentUser.Role != 1 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to perform this action"})
-		return
-	}
-
-	if err := initializers.DB.First(&userRetrieved, "id = ?", userID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot find this user"})
+	userID, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
 
-	if err := initializers.DB.Delete(&models.User{}, userID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.services.Users.Delete(c.Request.Context(), user, userID); err != nil {
+		fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"user": "Utilisateur supprimé"})
+	c.Status(http.StatusNoContent)
 }
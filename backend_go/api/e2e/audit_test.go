@@ -0,0 +1,251 @@
+package e2e
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"project/api/middlewares"
+	"project/api/responses"
+	"project/internal/audit"
+	"project/internal/migrate"
+	"project/internal/models"
+	"project/internal/testserver"
+)
+
+// auditLog lit les entrées du journal correspondant à la query string, en administrateur
+func auditLog(t *testing.T, s *testserver.Server, admin *testserver.User, query string) []responses.AuditEntryResponse {
+	t.Helper()
+	var entries []responses.AuditEntryResponse
+	s.Request(http.MethodGet, "/api/v1/audit-log?"+query, admin, nil).
+		Expect(http.StatusOK).
+		Data(&entries)
+	return entries
+}
+
+// Une modification de compte par un admin est journalisée avec son auteur, la requête et les valeurs changées ;
+// le changement de rôle et le nouveau mot de passe ont leurs propres entrées, sans la valeur du mot de passe
+func TestAdminUserUpdateIsAudited(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	admin := s.Admin()
+	parent := s.Parent()
+
+	res := s.Request(http.MethodPut, fmt.Sprintf("/api/v1/users/%d", parent.ID), admin, gin.H{
+		"first_name": "Camille",
+		"role":       models.RoleTeneur,
+		"password":   "nouveau-secret",
+	}).Expect(http.StatusOK)
+	requestID := res.Header().Get(middlewares.RequestIDHeader)
+
+	entries := auditLog(t, s, admin, fmt.Sprintf("entity_type=user&entity_id=%d&sort=id", parent.ID))
+	if len(entries) != 3 {
+		t.Fatalf("3 entrées attendues, %d reçues : %+v", len(entries), entries)
+	}
+	actions := []string{audit.ActionUserRoleChange, audit.ActionUserUpdate, audit.ActionUserPasswordReset}
+	for i, entry := range entries {
+		if entry.Action != actions[i] {
+			t.Errorf("entrée %d : action %q attendue, %q reçue", i, actions[i], entry.Action)
+		}
+		if entry.ActorID == nil || *entry.ActorID != admin.ID || entry.Actor != admin.Email {
+			t.Errorf("entrée %d : auteur %v %q, l'admin %d attendu", i, entry.ActorID, entry.Actor, admin.ID)
+		}
+		if entry.RequestID != requestID || entry.IP == "" {
+			t.Errorf("entrée %d : requête %q depuis %q, requête %q attendue", i, entry.RequestID, entry.IP, requestID)
+		}
+	}
+
+	role := entries[0].Changes["role"]
+	if fmt.Sprint(role.From) != strconv.Itoa(models.RoleParent) || fmt.Sprint(role.To) != strconv.Itoa(models.RoleTeneur) {
+		t.Errorf("rôle %v -> %v attendu %d -> %d", role.From, role.To, models.RoleParent, models.RoleTeneur)
+	}
+	if len(entries[1].Changes) != 1 || entries[1].Changes["firstname"].To != "Camille" {
+		t.Errorf("seul le prénom doit figurer dans la modification : %+v", entries[1].Changes)
+	}
+	if len(entries[2].Changes) != 0 {
+		t.Errorf("le mot de passe ne doit pas apparaître : %+v", entries[2].Changes)
+	}
+
+	// Le journal est réservé aux admins
+	if code := s.Request(http.MethodGet, "/api/v1/audit-log", parent, nil).Expect(http.StatusForbidden).Error().Code; code != "admin_only" {
+		t.Errorf("code %q", code)
+	}
+}
+
+// Paiements, annulations et suppressions sont journalisés au nom de leur auteur
+func TestFinancialActionsAreAudited(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	admin := s.Admin()
+	parent := s.Parent()
+
+	transaction := buyJetons(t, s, parent, 10, 5)
+	payments := auditLog(t, s, admin, "action="+audit.ActionPaymentSucceeded)
+	if len(payments) != 1 || payments[0].EntityID != transaction.ID || payments[0].Actor != parent.Email {
+		t.Fatalf("paiement %d de %s attendu : %+v", transaction.ID, parent.Email, payments)
+	}
+	status := payments[0].Changes["status"]
+	if status.From != models.TransactionStatusPending || status.To != models.TransactionStatusSucceeded {
+		t.Errorf("statut %v -> %v", status.From, status.To)
+	}
+
+	s.Request(http.MethodDelete, fmt.Sprintf("/api/v1/users/%d", parent.ID), admin, nil).Expect(http.StatusNoContent)
+	deleted := auditLog(t, s, admin, fmt.Sprintf("action=%s&entity_id=%d", audit.ActionUserDelete, parent.ID))
+	if len(deleted) != 1 {
+		t.Fatalf("une suppression attendue : %+v", deleted)
+	}
+	if email := deleted[0].Changes["email"]; email.From != parent.Email || email.To != nil {
+		t.Errorf("la suppression doit garder l'ancien compte : %+v", deleted[0].Changes)
+	}
+
+	// L'auteur reste identifiable après la suppression de son compte
+	if entries := auditLog(t, s, admin, fmt.Sprintf("actor_id=%d", parent.ID)); len(entries) != 1 {
+		t.Errorf("le paiement du compte supprimé doit rester au journal : %+v", entries)
+	}
+}
+
+// Le journal est exporté en CSV avec les mêmes filtres, et la base refuse d'en modifier les lignes
+func TestAuditLogExportAndAppendOnly(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	admin := s.Admin()
+	for _, user := range []*testserver.User{s.Parent(), s.Eleve()} {
+		s.Request(http.MethodDelete, fmt.Sprintf("/api/v1/users/%d", user.ID), admin, nil).Expect(http.StatusNoContent)
+	}
+	buyJetons(t, s, s.Parent(), 5, 2.5)
+
+	res := s.Request(http.MethodGet, "/api/v1/audit-log/export?action="+audit.ActionUserDelete, admin, nil).
+		Expect(http.StatusOK)
+	if contentType := res.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/csv") {
+		t.Errorf("Content-Type %q", contentType)
+	}
+	rows, err := csv.NewReader(res.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0][4] != "action" {
+		t.Fatalf("un en-tête et deux suppressions attendus : %q", rows)
+	}
+	for _, row := range rows[1:] {
+		var changes map[string]audit.Change
+		if row[4] != audit.ActionUserDelete || json.Unmarshal([]byte(row[7]), &changes) != nil || changes["email"].From == nil {
+			t.Errorf("ligne inattendue : %q", row)
+		}
+	}
+
+	// Une liste vide reste un CSV avec son en-tête
+	res = s.Request(http.MethodGet, "/api/v1/audit-log/export?action="+audit.ActionStandDelete, admin, nil).
+		Expect(http.StatusOK)
+	if rows, _ := csv.NewReader(res.Body).ReadAll(); len(rows) != 1 {
+		t.Errorf("seul l'en-tête est attendu : %q", rows)
+	}
+	s.Request(http.MethodGet, "/api/v1/audit-log/export?action=unknown", admin, nil).Expect(http.StatusBadRequest)
+
+	if err := s.DB.Exec("UPDATE audit_entries SET actor = 'someone-else'").Error; err == nil {
+		t.Error("la modification d'une entrée doit être refusée")
+	}
+	if err := s.DB.Exec("DELETE FROM audit_entries").Error; err == nil {
+		t.Error("la suppression d'une entrée doit être refusée")
+	}
+	var count int64
+	s.DB.Model(&models.AuditEntry{}).Count(&count)
+	if count != 3 {
+		t.Errorf("3 entrées attendues, %d en base", count)
+	}
+}
+
+// Le rattachement d'un stand à une kermesse vaut approbation et est journalisé au nom de l'organisateur
+func TestStandApprovalIsAudited(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	admin := s.Admin()
+	organisateur := s.Organisateur()
+	kermesse := createKermesse(t, s, organisateur)
+	stand := createStand(t, s, s.Teneur(), 2)
+
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/kermesses/%d/stands", kermesse.ID), organisateur,
+		gin.H{"stand_ids": []uint{stand.ID}}).Expect(http.StatusOK)
+
+	entries := auditLog(t, s, admin, "action="+audit.ActionKermesseStandAdd)
+	if len(entries) != 1 || entries[0].EntityType != audit.EntityKermesse || entries[0].EntityID != kermesse.ID || entries[0].Actor != organisateur.Email {
+		t.Fatalf("approbation du stand par %s attendue : %+v", organisateur.Email, entries)
+	}
+	if id := entries[0].Changes["stand_id"].To; fmt.Sprint(id) != strconv.Itoa(int(stand.ID)) {
+		t.Errorf("stand %v journalisé, %d attendu", id, stand.ID)
+	}
+}
+
+// Une action dont l'entrée ne peut être écrite au journal n'est pas appliquée
+func TestAuditFailureRollsBackTheAction(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	admin := s.Admin()
+	parent := s.Parent()
+	if err := s.DB.Exec(`CREATE TRIGGER trg_audit_entries_unavailable BEFORE INSERT ON audit_entries
+BEGIN
+    SELECT RAISE(ABORT, 'audit indisponible');
+END`).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	s.Request(http.MethodPost, "/api/v1/users", admin, gin.H{
+		"first_name": "Sans", "last_name": "Trace", "email": "sans-trace@example.com", "password": testserver.Password, "role": models.RoleParent,
+	}).Expect(http.StatusInternalServerError)
+	var created int64
+	s.DB.Model(&models.User{}).Where("email = ?", "sans-trace@example.com").Count(&created)
+	if created != 0 {
+		t.Error("le compte ne doit pas être créé sans son entrée au journal")
+	}
+
+	s.Request(http.MethodPut, fmt.Sprintf("/api/v1/users/%d", parent.ID), admin, gin.H{"first_name": "Camille"}).
+		Expect(http.StatusInternalServerError)
+	s.Request(http.MethodDelete, fmt.Sprintf("/api/v1/users/%d", parent.ID), admin, nil).
+		Expect(http.StatusInternalServerError)
+	var user models.User
+	if err := s.DB.First(&user, parent.ID).Error; err != nil || user.Firstname == "Camille" {
+		t.Errorf("le compte doit rester inchangé : %+v, %v", user, err)
+	}
+}
+
+// Le retour arrière de la migration du journal lève le verrou sans effacer les entrées, et la
+// réappliquer le repose
+func TestAuditMigrationDownKeepsTheJournal(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	admin := s.Admin()
+	s.Request(http.MethodDelete, fmt.Sprintf("/api/v1/users/%d", s.Parent().ID), admin, nil).Expect(http.StatusNoContent)
+
+	statuses, err := migrate.Statuses(s.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := 0
+	for _, status := range statuses {
+		if status.Version >= 5 {
+			steps++
+		}
+	}
+	if _, err := migrate.Down(s.DB, steps); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if err := s.DB.Model(&models.AuditEntry{}).Count(&count).Error; err != nil || count != 1 {
+		t.Fatalf("le journal doit survivre au retour arrière : %d entrées, %v", count, err)
+	}
+
+	if _, err := migrate.Up(s.DB); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DB.Exec("DELETE FROM audit_entries").Error; err == nil {
+		t.Error("le journal doit redevenir en ajout seul")
+	}
+	s.DB.Model(&models.AuditEntry{}).Count(&count)
+	if count != 1 {
+		t.Errorf("1 entrée attendue, %d en base", count)
+	}
+}
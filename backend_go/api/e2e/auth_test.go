@@ -0,0 +1,56 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"project/internal/models"
+	"project/internal/testserver"
+)
+
+func TestSignupAndLogin(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+
+	signup := gin.H{
+		"first_name": "Alice",
+		"last_name":  "Martin",
+		"email":      "alice@test.local",
+		"password":   "secret",
+		"role":       1,
+	}
+	var created models.User
+	s.Request(http.MethodPost, "/api/v1/auth/signup", nil, signup).Expect(http.StatusCreated).Data(&created)
+	if created.Role != 0 {
+		t.Fatalf("l'inscription ne doit pas choisir le rôle, reçu %d", created.Role)
+	}
+
+	s.Request(http.MethodPost, "/api/v1/auth/signup", nil, signup).Expect(http.StatusConflict)
+	s.Request(http.MethodPost, "/api/v1/auth/login", nil, gin.H{"email": "alice@test.local", "password": "wrong"}).
+		Expect(http.StatusUnauthorized)
+
+	alice := &testserver.User{User: created, Token: s.Login("alice@test.local", "secret")}
+	var profile models.User
+	s.Request(http.MethodGet, "/api/v1/me", alice, nil).Expect(http.StatusOK).Data(&profile)
+	if profile.Email != "alice@test.local" {
+		t.Fatalf("profil inattendu : %+v", profile)
+	}
+
+	s.Request(http.MethodGet, "/api/v1/me", nil, nil).Expect(http.StatusUnauthorized)
+	s.Request(http.MethodGet, "/api/v1/me", &testserver.User{Token: "invalid"}, nil).Expect(http.StatusUnauthorized)
+}
+
+func TestRolesAreEnforced(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	admin := s.Admin()
+	parent := s.Parent()
+
+	s.Request(http.MethodGet, "/api/v1/users", parent, nil).Expect(http.StatusForbidden)
+	var users []models.User
+	s.Request(http.MethodGet, "/api/v1/users", admin, nil).Expect(http.StatusOK).Data(&users)
+	if len(users) != 2 {
+		t.Fatalf("2 comptes attendus, %d reçus", len(users))
+	}
+}
@@ -0,0 +1,65 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"project/api/middlewares"
+	"project/internal/apperror"
+	"project/internal/testserver"
+)
+
+func TestValidationErrorsListInvalidFields(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+
+	res := s.Request(http.MethodPost, "/api/v1/auth/signup", nil, gin.H{"first_name": "Alice", "email": "alice@test.local"}).
+		Expect(http.StatusBadRequest)
+	body := res.Error()
+	if body.Type != apperror.KindValidation || body.Code != "invalid_fields" {
+		t.Fatalf("erreur de validation attendue : %+v", body)
+	}
+	if body.RequestID == "" || body.RequestID != res.Header().Get(middlewares.RequestIDHeader) {
+		t.Fatalf("l'erreur doit porter l'identifiant de la requête : %+v", body)
+	}
+
+	invalid := map[string]string{}
+	for _, field := range body.Fields {
+		invalid[field.Field] = field.Code
+	}
+	if len(invalid) != 2 || invalid["last_name"] != "required" || invalid["password"] != "required" {
+		t.Fatalf("last_name et password devraient être signalés : %+v", body.Fields)
+	}
+
+	typed := s.Request(http.MethodPost, "/api/v1/auth/login", nil, gin.H{"email": 42, "password": "secret"}).
+		Expect(http.StatusBadRequest).
+		Error()
+	if len(typed.Fields) != 1 || typed.Fields[0].Field != "email" || typed.Fields[0].Code != "type" {
+		t.Fatalf("le champ email devrait être signalé comme mal typé : %+v", typed)
+	}
+}
+
+func TestErrorsAreTyped(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	admin := s.Admin()
+
+	for _, tc := range []struct {
+		method, path string
+		user         *testserver.User
+		status       int
+		code         string
+	}{
+		{http.MethodGet, "/api/v1/me", nil, http.StatusUnauthorized, "missing_token"},
+		{http.MethodGet, "/api/v1/me", &testserver.User{Token: "invalid"}, http.StatusUnauthorized, "invalid_token"},
+		{http.MethodGet, "/api/v1/users/abc", admin, http.StatusBadRequest, "invalid_parameter"},
+		{http.MethodGet, "/api/v1/stands/999999", admin, http.StatusNotFound, "stand_not_found"},
+		{http.MethodGet, "/does-not-exist", nil, http.StatusNotFound, "route_not_found"},
+	} {
+		body := s.Request(tc.method, tc.path, tc.user, nil).Expect(tc.status).Error()
+		if body.Code != tc.code || body.Message == "" {
+			t.Errorf("%s %s : code %q attendu : %+v", tc.method, tc.path, tc.code, body)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"project/internal/testserver"
+)
+
+func TestHealthAndReadiness(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+
+	s.Request(http.MethodGet, "/healthz", nil, nil).Expect(http.StatusOK)
+	s.Request(http.MethodGet, "/readyz", nil, nil).Expect(http.StatusOK)
+
+	// Une migration annulée rend l'API indisponible sans arrêter le processus
+	if err := s.DB.Exec("DELETE FROM schema_migrations WHERE version = (SELECT MAX(version) FROM schema_migrations)").Error; err != nil {
+		t.Fatal(err)
+	}
+	s.Request(http.MethodGet, "/healthz", nil, nil).Expect(http.StatusOK)
+	s.Request(http.MethodGet, "/readyz", nil, nil).Expect(http.StatusServiceUnavailable)
+}
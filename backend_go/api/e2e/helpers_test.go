@@ -0,0 +1,52 @@
+// Tests de bout en bout : chaque test démarre l'API complète sur une base SQLite neuve
+// et la pilote uniquement par HTTP.
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"project/internal/models"
+	"project/internal/testserver"
+)
+
+// createKermesse crée une kermesse au nom de l'organisateur
+func createKermesse(t *testing.T, s *testserver.Server, organisateur *testserver.User) models.Kermesse {
+	t.Helper()
+	var kermesse models.Kermesse
+	s.Request(http.MethodPost, "/api/v1/kermesses", organisateur, gin.H{"name": "Kermesse de printemps"}).
+		Expect(http.StatusCreated).
+		Data(&kermesse)
+	return kermesse
+}
+
+// createStand crée un stand tenu par le teneur
+func createStand(t *testing.T, s *testserver.Server, teneur *testserver.User, jetonsRequis uint) models.Stand {
+	t.Helper()
+	var stand models.Stand
+	s.Request(http.MethodPost, "/api/v1/stands", teneur, gin.H{
+		"name":          "Pêche à la ligne",
+		"type":          "activite",
+		"jetons_requis": jetonsRequis,
+	}).Expect(http.StatusCreated).Data(&stand)
+	return stand
+}
+
+// buyJetons achète des jetons par carte avec le fournisseur fake et confirme le paiement
+func buyJetons(t *testing.T, s *testserver.Server, user *testserver.User, quantity uint, price float32) models.Transaction {
+	t.Helper()
+	var created struct {
+		Transaction models.Transaction `json:"transaction"`
+	}
+	s.Request(http.MethodPost, "/api/v1/payments", user, gin.H{"type": "jetons", "quantity": quantity, "price": price}).
+		Expect(http.StatusCreated).
+		Data(&created)
+
+	var confirmed models.Transaction
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/payments/%d/confirm", created.Transaction.ID), user, nil).
+		Expect(http.StatusOK).
+		Data(&confirmed)
+	return confirmed
+}
@@ -0,0 +1,108 @@
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"project/api/middlewares"
+	"project/internal/testserver"
+)
+
+// familyWithJetons retourne un parent crédité de 10 jetons et son enfant
+func familyWithJetons(t *testing.T, s *testserver.Server) (parent, child *testserver.User) {
+	t.Helper()
+	parent, child = s.Parent(), s.Eleve()
+	buyJetons(t, s, parent, 10, 5)
+	s.Request(http.MethodPost, "/api/v1/me/children", parent, gin.H{"children_ids": []uint{child.ID}}).
+		Expect(http.StatusOK)
+	return parent, child
+}
+
+func withKey(key string) http.Header {
+	return http.Header{middlewares.IdempotencyHeader: {key}}
+}
+
+// Une requête renvoyée avec la même clé rejoue la réponse d'origine sans être traitée une seconde fois ;
+// la même clé sur un autre corps est refusée
+func TestIdempotencyReplayAndReuse(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	parent, child := familyWithJetons(t, s)
+	path := fmt.Sprintf("/api/v1/users/%d/coins", child.ID)
+
+	first := s.RequestWithHeader(http.MethodPost, path, parent, gin.H{"nb_jetons": 3}, withKey("transfert-1")).
+		Expect(http.StatusOK)
+	replayed := s.RequestWithHeader(http.MethodPost, path, parent, gin.H{"nb_jetons": 3}, withKey("transfert-1")).
+		Expect(http.StatusOK)
+	if replayed.Header().Get(middlewares.ReplayedHeader) != "true" || replayed.Body.String() != first.Body.String() {
+		t.Fatalf("la réponse d'origine doit être rejouée : %v %s", replayed.Header(), replayed.Body.String())
+	}
+	if s.Balance(parent) != 7 || s.Balance(child) != 3 {
+		t.Fatalf("un seul transfert attendu, soldes %d et %d", s.Balance(parent), s.Balance(child))
+	}
+
+	reused := s.RequestWithHeader(http.MethodPost, path, parent, gin.H{"nb_jetons": 5}, withKey("transfert-1")).
+		Expect(http.StatusUnprocessableEntity)
+	if code := reused.Error().Code; code != "idempotency_key_reused" {
+		t.Errorf("code %q", code)
+	}
+	if s.Balance(parent) != 7 {
+		t.Fatalf("une clé réutilisée ne doit rien débiter, %d en base", s.Balance(parent))
+	}
+
+	// Les clés sont propres à chaque utilisateur
+	other, otherChild := familyWithJetons(t, s)
+	s.RequestWithHeader(http.MethodPost, fmt.Sprintf("/api/v1/users/%d/coins", otherChild.ID), other, gin.H{"nb_jetons": 5}, withKey("transfert-1")).
+		Expect(http.StatusOK)
+}
+
+// Deux requêtes simultanées de même clé ne sont traitées qu'une fois : l'autre est rejouée ou
+// refusée comme en cours
+func TestIdempotencyConcurrentRequests(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	parent, child := familyWithJetons(t, s)
+	path := fmt.Sprintf("/api/v1/users/%d/coins", child.ID)
+
+	const attempts = 5
+	codes := make([]int, attempts)
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = s.RequestWithHeader(http.MethodPost, path, parent, gin.H{"nb_jetons": 2}, withKey("simultané")).Code
+		}()
+	}
+	wg.Wait()
+
+	for _, code := range codes {
+		if code != http.StatusOK && code != http.StatusConflict {
+			t.Fatalf("200 ou 409 attendus : %v", codes)
+		}
+	}
+	if s.Balance(parent) != 8 || s.Balance(child) != 2 {
+		t.Fatalf("un seul transfert attendu, soldes %d et %d", s.Balance(parent), s.Balance(child))
+	}
+}
+
+// Le corps d'une requête idempotente est lu en entier pour son empreinte : sa taille est bornée
+func TestIdempotencyBodyLimit(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	parent, child := familyWithJetons(t, s)
+
+	res := s.RequestWithHeader(http.MethodPost, fmt.Sprintf("/api/v1/users/%d/coins", child.ID), parent,
+		gin.H{"nb_jetons": 1, "padding": strings.Repeat("x", middlewares.IdempotencyMaxBody)}, withKey("trop-grand")).
+		Expect(http.StatusRequestEntityTooLarge)
+	if code := res.Error().Code; code != "body_too_large" {
+		t.Errorf("code %q", code)
+	}
+	if s.Balance(parent) != 10 {
+		t.Fatalf("une requête refusée ne doit rien débiter, %d en base", s.Balance(parent))
+	}
+}
@@ -0,0 +1,111 @@
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"project/internal/models"
+	"project/internal/testserver"
+)
+
+func TestBuyJetons(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	parent := s.Parent()
+
+	transaction := buyJetons(t, s, parent, 20, 10)
+	if transaction.Status != models.TransactionStatusSucceeded {
+		t.Fatalf("paiement non abouti : %+v", transaction)
+	}
+	if balance := s.Balance(parent); balance != 20 {
+		t.Fatalf("20 jetons attendus, %d en base", balance)
+	}
+
+	// Une seconde confirmation ne crédite pas deux fois
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/payments/%d/confirm", transaction.ID), parent, nil).
+		Expect(http.StatusOK)
+	if balance := s.Balance(parent); balance != 20 {
+		t.Fatalf("20 jetons attendus après une seconde confirmation, %d en base", balance)
+	}
+
+	other := s.Parent()
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/payments/%d/confirm", transaction.ID), other, nil).
+		Expect(http.StatusForbidden)
+}
+
+func TestBuyProduct(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	admin := s.Admin()
+	teneur := s.Teneur()
+	parent := s.Parent()
+
+	stand := createStand(t, s, teneur, 1)
+	var product models.Product
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/stands/%d/products", stand.ID), admin, gin.H{
+		"name":          "Crêpe",
+		"type":          "nourriture",
+		"jetons_requis": 3,
+		"nb_products":   5,
+	}).Expect(http.StatusCreated).Data(&product)
+	buyJetons(t, s, parent, 10, 5)
+
+	path := fmt.Sprintf("/api/v1/stands/%d/products/%d/purchases", stand.ID, product.ID)
+	s.Request(http.MethodPost, path, parent, gin.H{"quantity": 2}).Expect(http.StatusOK)
+	if balance := s.Balance(parent); balance != 4 {
+		t.Fatalf("4 jetons attendus après l'achat, %d en base", balance)
+	}
+
+	// Pas assez de jetons pour deux crêpes de plus : ni le solde ni le stock ne bougent
+	refused := s.Request(http.MethodPost, path, parent, gin.H{"quantity": 2}).Expect(http.StatusUnprocessableEntity).Error()
+	if refused.Type != "insufficient_funds" {
+		t.Fatalf("erreur insufficient_funds attendue : %+v", refused)
+	}
+	if balance := s.Balance(parent); balance != 4 {
+		t.Fatalf("le solde ne doit pas changer après un refus, %d en base", balance)
+	}
+
+	var updated models.Stand
+	s.Request(http.MethodGet, fmt.Sprintf("/api/v1/stands/%d", stand.ID), teneur, nil).Expect(http.StatusOK).Data(&updated)
+	if updated.Conso != 6 || len(updated.Stock) != 1 || updated.Stock[0].Nb_Products != 3 {
+		t.Fatalf("conso 6 et stock 3 attendus : %+v", updated)
+	}
+}
+
+func TestGiveCoinsToChild(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	parent := s.Parent()
+	child := s.Eleve()
+	stranger := s.Eleve()
+	buyJetons(t, s, parent, 10, 5)
+
+	s.Request(http.MethodPost, "/api/v1/me/children", child, gin.H{"children_ids": []uint{stranger.ID}}).
+		Expect(http.StatusForbidden)
+	s.Request(http.MethodPost, "/api/v1/me/children", parent, gin.H{"children_ids": []uint{child.ID}}).
+		Expect(http.StatusOK)
+
+	var transfer struct {
+		ParentCoins uint `json:"parent_coins"`
+		ChildCoins  uint `json:"child_coins"`
+	}
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/users/%d/coins", child.ID), parent, gin.H{"nb_jetons": 4}).
+		Expect(http.StatusOK).
+		Data(&transfer)
+	if transfer.ParentCoins != 6 || transfer.ChildCoins != 4 {
+		t.Fatalf("6 et 4 jetons attendus : %+v", transfer)
+	}
+	if s.Balance(parent) != 6 || s.Balance(child) != 4 {
+		t.Fatalf("soldes en base inattendus : %d et %d", s.Balance(parent), s.Balance(child))
+	}
+
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/users/%d/coins", stranger.ID), parent, gin.H{"nb_jetons": 1}).
+		Expect(http.StatusForbidden)
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/users/%d/coins", child.ID), parent, gin.H{"nb_jetons": 50}).
+		Expect(http.StatusUnprocessableEntity)
+	if s.Balance(parent) != 6 {
+		t.Fatalf("un transfert refusé ne doit pas débiter le parent, %d en base", s.Balance(parent))
+	}
+}
@@ -0,0 +1,38 @@
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"project/internal/models"
+	"project/internal/testserver"
+)
+
+func TestCreateKermesseAndAttachStand(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	organisateur := s.Organisateur()
+	teneur := s.Teneur()
+	parent := s.Parent()
+
+	s.Request(http.MethodPost, "/api/v1/kermesses", parent, gin.H{"name": "Interdite"}).
+		Expect(http.StatusForbidden)
+	kermesse := createKermesse(t, s, organisateur)
+	stand := createStand(t, s, teneur, 2)
+
+	path := fmt.Sprintf("/api/v1/kermesses/%d/stands", kermesse.ID)
+	s.Request(http.MethodPost, path, teneur, gin.H{"stand_ids": []uint{stand.ID}}).
+		Expect(http.StatusForbidden)
+	s.Request(http.MethodPost, path, organisateur, gin.H{"stand_ids": []uint{stand.ID}}).
+		Expect(http.StatusOK)
+
+	var attached models.Kermesse
+	s.Request(http.MethodGet, fmt.Sprintf("/api/v1/kermesses/%d", kermesse.ID), organisateur, nil).
+		Expect(http.StatusOK).
+		Data(&attached)
+	if len(attached.Stands) != 1 || attached.Stands[0].ID != stand.ID {
+		t.Fatalf("le stand %d devrait être rattaché : %+v", stand.ID, attached.Stands)
+	}
+}
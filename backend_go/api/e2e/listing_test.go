@@ -0,0 +1,110 @@
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"project/api/controllers"
+	"project/internal/apperror"
+	"project/internal/models"
+	"project/internal/testserver"
+)
+
+// listPage décode une page de liste avec sa meta
+func listPage[T any](t *testing.T, s *testserver.Server, path string, user *testserver.User) ([]T, controllers.Meta) {
+	t.Helper()
+	var page struct {
+		Data []T              `json:"data"`
+		Meta controllers.Meta `json:"meta"`
+	}
+	s.Request(http.MethodGet, path, user, nil).Expect(http.StatusOK).JSON(&page)
+	return page.Data, page.Meta
+}
+
+func TestListPaginationFollowsNext(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	admin := s.Admin()
+	parents := []*testserver.User{s.Parent(), s.Parent(), s.Parent()}
+	s.Eleve()
+
+	first, meta := listPage[models.User](t, s, "/api/v1/users?role=4&sort=-id&limit=2", admin)
+	if meta.Total != 3 || meta.Limit != 2 || meta.Offset != 0 || len(first) != 2 {
+		t.Fatalf("première page de 2 parents sur 3 attendue : %+v %d", meta, len(first))
+	}
+	if first[0].ID != parents[2].ID || first[1].ID != parents[1].ID {
+		t.Fatalf("parents triés par id décroissant attendus : %d, %d", first[0].ID, first[1].ID)
+	}
+
+	next, err := url.Parse(meta.Next)
+	if err != nil || next.Query().Get("offset") != "2" || next.Query().Get("role") != "4" {
+		t.Fatalf("le lien suivant doit garder les filtres et avancer l'offset : %q", meta.Next)
+	}
+	last, meta := listPage[models.User](t, s, meta.Next, admin)
+	if len(last) != 1 || last[0].ID != parents[0].ID || meta.Next != "" {
+		t.Fatalf("dernière page sans lien suivant attendue : %+v %+v", meta, last)
+	}
+
+	found, meta := listPage[models.User](t, s, "/api/v1/users?q="+url.QueryEscape(parents[1].Email), admin)
+	if meta.Total != 1 || found[0].ID != parents[1].ID {
+		t.Fatalf("la recherche par email doit trouver un seul compte : %+v", found)
+	}
+}
+
+func TestListFilters(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	admin := s.Admin()
+	organisateur := s.Organisateur()
+	teneur := s.Teneur()
+
+	kermesse := createKermesse(t, s, organisateur)
+	cheap := createStand(t, s, teneur, 1)
+	expensive := createStand(t, s, teneur, 5)
+	createStand(t, s, teneur, 3)
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/kermesses/%d/stands", kermesse.ID), organisateur,
+		gin.H{"stand_ids": []uint{cheap.ID, expensive.ID}}).Expect(http.StatusOK)
+
+	stands, meta := listPage[models.Stand](t, s, fmt.Sprintf("/api/v1/stands?kermesse_id=%d&sort=-jetons_requis", kermesse.ID), admin)
+	if meta.Total != 2 || stands[0].ID != expensive.ID || stands[1].ID != cheap.ID {
+		t.Fatalf("les deux stands de la kermesse triés par prix décroissant attendus : %+v", stands)
+	}
+
+	parent := s.Parent()
+	buyJetons(t, s, parent, 10, 5)
+	today := time.Now().UTC().Format("2006-01-02")
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	if _, meta := listPage[models.Transaction](t, s, "/api/v1/transactions?status=succeeded&from="+today, parent); meta.Total != 1 {
+		t.Fatalf("l'achat du jour doit être listé : %+v", meta)
+	}
+	if _, meta := listPage[models.Transaction](t, s, "/api/v1/transactions?from="+tomorrow, parent); meta.Total != 0 {
+		t.Fatalf("aucune transaction à partir de demain attendue : %+v", meta)
+	}
+}
+
+func TestListRejectsInvalidQuery(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	admin := s.Admin()
+
+	body := s.Request(http.MethodGet, "/api/v1/users?limit=0&sort=password&role=abc", admin, nil).
+		Expect(http.StatusBadRequest).
+		Error()
+	if body.Type != apperror.KindValidation || body.Code != "invalid_query" {
+		t.Fatalf("erreur invalid_query attendue : %+v", body)
+	}
+	invalid := map[string]string{}
+	for _, field := range body.Fields {
+		invalid[field.Field] = field.Code
+	}
+	if invalid["limit"] != "range" || invalid["sort"] != "oneof" || invalid["role"] != "numeric" {
+		t.Fatalf("limit, sort et role devraient être signalés : %+v", body.Fields)
+	}
+
+	s.Request(http.MethodGet, "/api/v1/transactions?status=unknown", admin, nil).Expect(http.StatusBadRequest)
+	s.Request(http.MethodGet, "/api/v1/kermesses?from=2024-01-01", admin, nil).Expect(http.StatusBadRequest)
+}
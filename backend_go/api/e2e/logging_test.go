@@ -0,0 +1,64 @@
+package e2e
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"project/api/middlewares"
+	"project/internal/testserver"
+)
+
+func TestRequestIDIsPropagated(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+
+	generated := s.Request(http.MethodGet, "/healthz", nil, nil).Expect(http.StatusOK).Header().Get(middlewares.RequestIDHeader)
+	if generated == "" {
+		t.Fatal("la réponse doit porter un identifiant de requête")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set(middlewares.RequestIDHeader, "trace-42")
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, req)
+	if got := rec.Header().Get(middlewares.RequestIDHeader); got != "trace-42" {
+		t.Fatalf("l'identifiant fourni par le client doit être repris, reçu %q", got)
+	}
+}
+
+func TestRequestsAreLoggedWithUserAndRoute(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	admin := s.Admin()
+
+	requestID := s.Request(http.MethodGet, "/api/v1/users/999999", admin, nil).Header().Get(middlewares.RequestIDHeader)
+
+	logs := s.Logs.String()
+	var found bool
+	scanner := bufio.NewScanner(strings.NewReader(logs))
+	for scanner.Scan() {
+		var line struct {
+			Msg       string  `json:"msg"`
+			RequestID string  `json:"request_id"`
+			Route     string  `json:"route"`
+			UserID    float64 `json:"user_id"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("ligne de log non JSON : %s", scanner.Text())
+		}
+		if line.Msg != "request" || line.RequestID != requestID {
+			continue
+		}
+		found = true
+		if line.Route != "/api/v1/users/:id" || uint(line.UserID) != admin.ID {
+			t.Fatalf("route ou utilisateur absent du log : %s", scanner.Text())
+		}
+	}
+	if !found {
+		t.Fatalf("aucune ligne de log pour la requête %s :\n%s", requestID, logs)
+	}
+}
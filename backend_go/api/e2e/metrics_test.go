@@ -0,0 +1,45 @@
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"project/internal/testserver"
+)
+
+func TestMetricsExposeBusinessActivity(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	organisateur := s.Organisateur()
+	teneur := s.Teneur()
+	parent := s.Parent()
+
+	kermesse := createKermesse(t, s, organisateur)
+	stand := createStand(t, s, teneur, 2)
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/kermesses/%d/stands", kermesse.ID), organisateur,
+		gin.H{"stand_ids": []uint{stand.ID}}).Expect(http.StatusOK)
+
+	buyJetons(t, s, parent, 10, 5)
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/stands/%d/interactions", stand.ID), parent, nil).Expect(http.StatusOK)
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/stands/%d/users/%d/points", stand.ID, parent.ID), teneur,
+		gin.H{"points": 7}).Expect(http.StatusOK)
+
+	body := s.Request(http.MethodGet, "/metrics", nil, nil).Expect(http.StatusOK).Body.String()
+	for _, want := range []string{
+		`kermesse_jetons_sold_total{kermesse="none",provider="fake"} 10`,
+		fmt.Sprintf(`kermesse_jetons_spent_total{stand="%d"} 2`, stand.ID),
+		fmt.Sprintf(`kermesse_points_awarded_total{stand="%d"} 7`, stand.ID),
+		fmt.Sprintf(`kermesse_active_users{kermesse="%d"} 1`, kermesse.ID),
+		`kermesse_http_request_duration_seconds_count{method="POST",route="/api/v1/stands/:id/interactions",status="200"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("métrique absente : %s", want)
+		}
+	}
+	if t.Failed() {
+		t.Log(body)
+	}
+}
@@ -0,0 +1,85 @@
+package e2e
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"project/api/routes"
+	"project/internal/testserver"
+)
+
+// La spécification générée doit décrire exactement les routes servies, alias dépréciés exclus
+func TestSpecCoversRoutes(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	spec, err := testserver.LoadSpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current := *s.App
+	current.Config.LegacyRoutes = false
+	router := gin.New()
+	routes.Register(router, &current)
+
+	served := map[string]bool{}
+	for _, route := range router.Routes() {
+		if _, ok := testserver.Undocumented[route.Method+" "+route.Path]; ok {
+			continue
+		}
+		path := testserver.SpecPath(route.Path)
+		served[route.Method+" "+path] = true
+
+		op, ok := spec.Operation(route.Method, route.Path)
+		if !ok {
+			t.Errorf("%s %s n'est pas documentée", route.Method, path)
+			continue
+		}
+		var declared []string
+		for _, param := range op.Parameters {
+			if param.In == "path" {
+				declared = append(declared, param.Name)
+			}
+		}
+		want := testserver.PathParams(path)
+		slices.Sort(declared)
+		slices.Sort(want)
+		if !slices.Equal(declared, want) {
+			t.Errorf("%s %s : paramètres de chemin documentés %v, attendus %v", route.Method, path, declared, want)
+		}
+	}
+
+	for path, ops := range spec.Paths {
+		for method := range ops {
+			if !served[strings.ToUpper(method)+" "+path] {
+				t.Errorf("%s %s est documentée mais n'est pas servie", strings.ToUpper(method), path)
+			}
+		}
+	}
+	for name := range spec.Definitions {
+		if strings.HasPrefix(name, "gin.") {
+			t.Errorf("la réponse %s n'est pas typée", name)
+		}
+	}
+}
+
+// Le harnais refuse une réponse qui s'écarte de son schéma
+func TestContractRejectsUndocumentedResponse(t *testing.T) {
+	t.Parallel()
+	spec, err := testserver.LoadSpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := spec.ValidateResponse(http.MethodGet, "/healthz", http.StatusOK, []byte(`{"status":"ok"}`)); err != nil {
+		t.Fatalf("réponse conforme refusée : %v", err)
+	}
+	if err := spec.ValidateResponse(http.MethodGet, "/healthz", http.StatusOK, []byte(`{"status":42}`)); err == nil {
+		t.Fatal("un statut mal typé doit être refusé")
+	}
+	if err := spec.ValidateResponse(http.MethodGet, "/healthz", http.StatusTeapot, nil); err == nil {
+		t.Fatal("un statut non documenté doit être refusé")
+	}
+}
@@ -0,0 +1,190 @@
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"project/api/responses"
+	"project/internal/models"
+	"project/internal/testserver"
+	"project/services"
+)
+
+// cardKermesse prépare une kermesse avec un stand et un produit à 3 jetons, un pack de 10 jetons
+// à 5 € et une caisse ouverte
+type cardKermesse struct {
+	kermesse     models.Kermesse
+	organisateur *testserver.User
+	teneur       *testserver.User
+	caissier     *testserver.User
+	stand        models.Stand
+	product      models.Product
+	pack         models.Jetons
+}
+
+func newCardKermesse(t *testing.T, s *testserver.Server) cardKermesse {
+	t.Helper()
+	k := cardKermesse{organisateur: s.Organisateur(), teneur: s.Teneur(), caissier: s.Caissier()}
+	k.kermesse = createKermesse(t, s, k.organisateur)
+	k.stand = createStand(t, s, k.teneur, 1)
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/kermesses/%d/stands", k.kermesse.ID), k.organisateur,
+		gin.H{"stand_ids": []uint{k.stand.ID}}).Expect(http.StatusOK)
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/stands/%d/products", k.stand.ID), s.Admin(), gin.H{
+		"name": "Crêpe", "type": "nourriture", "jetons_requis": 3, "nb_products": 20,
+	}).Expect(http.StatusCreated).Data(&k.product)
+	k.pack = models.Jetons{NbJetons: 10, Price: 5}
+	if err := s.DB.Create(&k.pack).Error; err != nil {
+		t.Fatal(err)
+	}
+	s.Request(http.MethodPost, "/api/v1/till-sessions", k.caissier, gin.H{"kermesse_id": k.kermesse.ID}).
+		Expect(http.StatusCreated)
+	return k
+}
+
+// issueCard émet en caisse une carte chargée d'un pack
+func (k cardKermesse) issueCard(t *testing.T, s *testserver.Server) models.PrepaidCard {
+	t.Helper()
+	var issued responses.IssueCardResponse
+	s.Request(http.MethodPost, "/api/v1/till-sessions/current/cards", k.caissier, gin.H{
+		"jetons_id": k.pack.ID, "packs": 1, "payment_method": models.PaymentMethodCash,
+	}).Expect(http.StatusCreated).Data(&issued)
+	return issued.Card
+}
+
+func (k cardKermesse) buyWithCard(s *testserver.Server, code string, quantity uint) *testserver.Response {
+	return s.Request(http.MethodPost, fmt.Sprintf("/api/v1/stands/%d/products/%d/purchases", k.stand.ID, k.product.ID),
+		k.teneur, gin.H{"quantity": quantity, "card_code": code})
+}
+
+func cardJetons(t *testing.T, s *testserver.Server, code string) uint {
+	t.Helper()
+	var card models.PrepaidCard
+	s.Request(http.MethodGet, "/api/v1/prepaid-cards/"+code, nil, nil).Expect(http.StatusOK).Data(&card)
+	return card.Jetons
+}
+
+// Une carte émise en caisse est rechargée, débitée sur un stand puis rattachée sans fusion :
+// elle garde ses jetons et son historique passe au compte
+func TestPrepaidCardLifecycle(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	k := newCardKermesse(t, s)
+
+	card := k.issueCard(t, s)
+	if card.Jetons != 10 || card.KermesseID != k.kermesse.ID || card.UserID != nil {
+		t.Fatalf("carte de 10 jetons attendue : %+v", card)
+	}
+	s.Request(http.MethodPost, "/api/v1/till-sessions/current/sales", k.caissier, gin.H{
+		"card_code": card.Code, "jetons_id": k.pack.ID, "packs": 2, "payment_method": models.PaymentMethodCardTerminal,
+	}).Expect(http.StatusCreated)
+
+	// Le code se saisit sans tirets ni majuscules
+	typed := strings.ToLower(strings.ReplaceAll(card.Code, "-", ""))
+	if jetons := cardJetons(t, s, typed); jetons != 30 {
+		t.Fatalf("30 jetons attendus après la recharge, %d", jetons)
+	}
+
+	k.buyWithCard(s, typed, 2).Expect(http.StatusOK)
+	if jetons := cardJetons(t, s, card.Code); jetons != 24 {
+		t.Fatalf("24 jetons attendus après l'achat, %d", jetons)
+	}
+	if code := k.buyWithCard(s, card.Code, 9).Expect(http.StatusUnprocessableEntity).Error().Code; code != "not_enough_jetons" {
+		t.Errorf("code %q", code)
+	}
+
+	parent := s.Parent()
+	var linked models.PrepaidCard
+	s.Request(http.MethodPost, "/api/v1/prepaid-cards/"+card.Code+"/link", parent, gin.H{"merge_balance": false}).
+		Expect(http.StatusOK).
+		Data(&linked)
+	if linked.UserID == nil || *linked.UserID != parent.ID || linked.LinkedAt == nil || linked.Jetons != 24 || s.Balance(parent) != 0 {
+		t.Fatalf("carte rattachée avec ses 24 jetons attendue : %+v, solde %d", linked, s.Balance(parent))
+	}
+	var history int64
+	s.DB.Model(&models.History{}).Where("prepaid_card_id = ? AND user_id = ?", card.ID, parent.ID).Count(&history)
+	if history != 1 {
+		t.Errorf("l'achat passé doit apparaître dans l'historique du compte, %d entrées", history)
+	}
+
+	// Rattacher de nouveau est sans effet, un autre compte est refusé
+	s.Request(http.MethodPost, "/api/v1/prepaid-cards/"+card.Code+"/link", parent, nil).Expect(http.StatusOK)
+	if code := s.Request(http.MethodPost, "/api/v1/prepaid-cards/"+card.Code+"/link", s.Parent(), nil).
+		Expect(http.StatusConflict).Error().Code; code != "card_already_linked" {
+		t.Errorf("code %q", code)
+	}
+
+	// Bilan : ventes sur carte, dépenses sur carte et jetons restant sur les cartes
+	var finances services.KermesseFinances
+	s.Request(http.MethodGet, fmt.Sprintf("/api/v1/kermesses/%d/finances", k.kermesse.ID), k.organisateur, nil).
+		Expect(http.StatusOK).
+		Data(&finances)
+	want := services.KermesseFinances{
+		KermesseID:              k.kermesse.ID,
+		PrepaidCardSales:        services.SalesSummary{Amount: 15, Jetons: 30},
+		PrepaidCardSpending:     6,
+		PrepaidCardsIssued:      1,
+		PrepaidCardsLinked:      1,
+		PrepaidCardsOutstanding: 24,
+	}
+	if finances != want {
+		t.Errorf("bilan %+v, %+v attendu", finances, want)
+	}
+	s.Request(http.MethodGet, fmt.Sprintf("/api/v1/kermesses/%d/finances", k.kermesse.ID), parent, nil).
+		Expect(http.StatusForbidden)
+}
+
+// Avec fusion, le solde de la carte passe sur le compte et reste traçable des deux côtés
+func TestPrepaidCardLinkWithMerge(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	k := newCardKermesse(t, s)
+	card := k.issueCard(t, s)
+	parent := s.Parent()
+
+	var linked models.PrepaidCard
+	s.Request(http.MethodPost, "/api/v1/prepaid-cards/"+card.Code+"/link", parent, gin.H{"merge_balance": true}).
+		Expect(http.StatusOK).
+		Data(&linked)
+	if linked.Jetons != 0 || s.Balance(parent) != 10 {
+		t.Fatalf("10 jetons transférés sur le compte attendus : carte %d, compte %d", linked.Jetons, s.Balance(parent))
+	}
+	var adjustments []models.BalanceAdjustment
+	s.DB.Order("id").Find(&adjustments)
+	if len(adjustments) != 2 || adjustments[0].Delta != -10 || adjustments[1].Delta != 10 {
+		t.Fatalf("un débit de la carte et un crédit du compte attendus : %+v", adjustments)
+	}
+	if code := k.buyWithCard(s, card.Code, 1).Expect(http.StatusUnprocessableEntity).Error().Code; code != "not_enough_jetons" {
+		t.Errorf("code %q", code)
+	}
+
+	drifts, err := s.App.Services.Balances.Recompute(false)
+	if err != nil || len(drifts) != 0 {
+		t.Fatalf("aucun écart attendu : %+v, %v", drifts, err)
+	}
+}
+
+// Une carte désactivée, inconnue ou d'une autre kermesse est refusée sur les stands
+func TestPrepaidCardRejected(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	k := newCardKermesse(t, s)
+	other := newCardKermesse(t, s)
+	foreign := other.issueCard(t, s)
+
+	if code := k.buyWithCard(s, foreign.Code, 1).Expect(http.StatusUnprocessableEntity).Error().Code; code != "card_other_kermesse" {
+		t.Errorf("code %q", code)
+	}
+	if code := k.buyWithCard(s, "AAAA-BBBB-CCCC", 1).Expect(http.StatusNotFound).Error().Code; code != "card_not_found" {
+		t.Errorf("code %q", code)
+	}
+
+	card := k.issueCard(t, s)
+	s.DB.Model(&models.PrepaidCard{}).Where("id = ?", card.ID).Update("disabled", true)
+	if code := k.buyWithCard(s, card.Code, 1).Expect(http.StatusUnprocessableEntity).Error().Code; code != "card_disabled" {
+		t.Errorf("code %q", code)
+	}
+	s.Request(http.MethodGet, "/api/v1/prepaid-cards/"+card.Code, nil, nil).Expect(http.StatusUnprocessableEntity)
+}
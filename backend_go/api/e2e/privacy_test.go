@@ -0,0 +1,142 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"project/internal/testserver"
+)
+
+// Aucun schéma de réponse de la spécification ne décrit de champ de mot de passe
+func TestSpecResponsesHaveNoPassword(t *testing.T) {
+	t.Parallel()
+	spec, err := testserver.LoadSpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, ops := range spec.Paths {
+		for method, op := range ops {
+			for status, response := range op.Responses {
+				if field, ok := schemaPasswordField(t, spec, response.Schema, map[string]bool{}); ok {
+					t.Errorf("réponse %s de %s %s : champ %s", status, strings.ToUpper(method), path, field)
+				}
+			}
+		}
+	}
+}
+
+// schemaPasswordField parcourt un schéma en suivant les $ref vers les définitions, une fois chacune
+func schemaPasswordField(t *testing.T, spec *testserver.Spec, raw json.RawMessage, seen map[string]bool) (string, bool) {
+	t.Helper()
+	if len(raw) == 0 {
+		return "", false
+	}
+	var schema any
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		t.Fatal(err)
+	}
+	var walk func(v any) (string, bool)
+	walk = func(v any) (string, bool) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				name := strings.TrimPrefix(ref, "#/definitions/")
+				if seen[name] {
+					return "", false
+				}
+				seen[name] = true
+				return schemaPasswordField(t, spec, spec.Definitions[name], seen)
+			}
+			if properties, ok := v["properties"].(map[string]any); ok {
+				for name := range properties {
+					if strings.Contains(strings.ToLower(name), "password") {
+						return name, true
+					}
+				}
+			}
+			for _, child := range v {
+				if field, ok := walk(child); ok {
+					return field, true
+				}
+			}
+		case []any:
+			for _, child := range v {
+				if field, ok := walk(child); ok {
+					return field, true
+				}
+			}
+		}
+		return "", false
+	}
+	return walk(schema)
+}
+
+// Chaque public voit sa vue du compte : les autres membres ne lisent ni l'email ni le solde
+func TestUserViewsByAudience(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	admin := s.Admin()
+	organisateur := s.Organisateur()
+	parent := s.Parent()
+
+	kermesse := createKermesse(t, s, organisateur)
+	members := fmt.Sprintf("/api/v1/kermesses/%d/members", kermesse.ID)
+	s.Request(http.MethodPost, members, organisateur,
+		gin.H{"type": "organisateurs", "user_ids": []uint{organisateur.ID}}).Expect(http.StatusOK)
+	s.Request(http.MethodPost, members, organisateur,
+		gin.H{"type": "participants", "user_ids": []uint{parent.ID}}).Expect(http.StatusOK)
+
+	var seen struct {
+		Organisateurs []map[string]any `json:"organisateurs"`
+		Participants  []map[string]any `json:"participants"`
+	}
+	s.Request(http.MethodGet, fmt.Sprintf("/api/v1/kermesses/%d", kermesse.ID), parent, nil).
+		Expect(http.StatusOK).
+		Data(&seen)
+	if len(seen.Organisateurs) != 1 || len(seen.Participants) != 1 {
+		t.Fatalf("un organisateur et un participant attendus : %+v", seen)
+	}
+	for _, member := range append(seen.Organisateurs, seen.Participants...) {
+		if _, ok := member["email"]; ok {
+			t.Fatalf("l'email d'un membre ne doit pas être visible : %+v", member)
+		}
+		if _, ok := member["jetons"]; ok {
+			t.Fatalf("le solde d'un membre ne doit pas être visible : %+v", member)
+		}
+	}
+
+	var profile map[string]any
+	s.Request(http.MethodGet, "/api/v1/me", parent, nil).Expect(http.StatusOK).Data(&profile)
+	if profile["email"] != parent.Email {
+		t.Fatalf("le profil doit montrer l'email du titulaire : %+v", profile)
+	}
+	if _, ok := profile["updated_at"]; ok {
+		t.Fatalf("la date de mise à jour est réservée à la vue administrateur : %+v", profile)
+	}
+
+	var adminView map[string]any
+	s.Request(http.MethodGet, fmt.Sprintf("/api/v1/users/%d", parent.ID), admin, nil).Expect(http.StatusOK).Data(&adminView)
+	if adminView["email"] != parent.Email || adminView["updated_at"] == nil {
+		t.Fatalf("la vue administrateur doit montrer l'email et la mise à jour : %+v", adminView)
+	}
+}
+
+// Le harnais repère un mot de passe à n'importe quelle profondeur d'une réponse
+func TestPasswordFieldDetection(t *testing.T) {
+	t.Parallel()
+	var body any
+	if err := json.Unmarshal([]byte(`{"data":[{"id":1,"parents":[{"id":2,"Password":"$2a$10$x"}]}]}`), &body); err != nil {
+		t.Fatal(err)
+	}
+	if field, ok := testserver.PasswordField(body, ""); !ok || field != ".data[0].parents[0].Password" {
+		t.Fatalf("champ imbriqué non détecté : %q", field)
+	}
+	if _, ok := testserver.PasswordField(map[string]any{"data": map[string]any{"id": 1}}, ""); ok {
+		t.Fatal("aucun champ ne devait être signalé")
+	}
+}
@@ -0,0 +1,181 @@
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"project/api/responses"
+	"project/internal/models"
+	"project/internal/testserver"
+	"project/services"
+)
+
+func standConso(t *testing.T, s *testserver.Server, standID uint) uint {
+	t.Helper()
+	var stand models.Stand
+	if err := s.DB.First(&stand, standID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return stand.Conso
+}
+
+func reversePath(id uint) string {
+	return fmt.Sprintf("/api/v1/history/%d/reverse", id)
+}
+
+// L'annulation d'une interaction rend les jetons, restaure la conso et crée une entrée liée ;
+// l'entrée annulée et l'annulation elle-même ne peuvent plus l'être
+func TestReverseInteraction(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	teneur := s.Teneur()
+	parent := s.Parent()
+	stand := createStand(t, s, teneur, 3)
+	buyJetons(t, s, parent, 10, 5)
+
+	var interaction responses.InteractionResponse
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/stands/%d/interactions", stand.ID), parent, nil).
+		Expect(http.StatusOK).
+		Data(&interaction)
+	if interaction.Stand != 3 || interaction.Jetons != 7 {
+		t.Fatalf("conso 3 et 7 jetons attendus : %+v", interaction)
+	}
+
+	var reversal models.History
+	s.Request(http.MethodPost, reversePath(interaction.History.ID), teneur, gin.H{"reason": "erreur de saisie"}).
+		Expect(http.StatusOK).
+		Data(&reversal)
+	if reversal.Type != models.HistoryTypeReversal || reversal.ReversalOfID == nil || *reversal.ReversalOfID != interaction.History.ID || reversal.Reason != "erreur de saisie" {
+		t.Fatalf("entrée d'annulation liée attendue : %+v", reversal)
+	}
+	if s.Balance(parent) != 10 || standConso(t, s, stand.ID) != 0 {
+		t.Fatalf("10 jetons et une conso nulle attendus : %d, %d", s.Balance(parent), standConso(t, s, stand.ID))
+	}
+	var original models.History
+	if s.DB.First(&original, interaction.History.ID); original.ReversedAt == nil {
+		t.Error("l'entrée annulée doit être marquée, pas supprimée")
+	}
+
+	if code := s.Request(http.MethodPost, reversePath(interaction.History.ID), teneur, nil).
+		Expect(http.StatusConflict).Error().Code; code != "already_reversed" {
+		t.Errorf("code %q", code)
+	}
+	if code := s.Request(http.MethodPost, reversePath(reversal.ID), teneur, nil).
+		Expect(http.StatusUnprocessableEntity).Error().Code; code != "not_reversible" {
+		t.Errorf("code %q", code)
+	}
+
+	// Soldes et conso restent cohérents avec l'historique
+	drifts, err := s.App.Services.Balances.Recompute(false)
+	if err != nil || len(drifts) != 0 {
+		t.Fatalf("aucun écart attendu : %+v, %v", drifts, err)
+	}
+}
+
+// L'annulation d'un achat restaure aussi le stock du produit
+func TestReversePurchaseRestoresStock(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	admin := s.Admin()
+	teneur := s.Teneur()
+	parent := s.Parent()
+	stand := createStand(t, s, teneur, 1)
+	var product models.Product
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/stands/%d/products", stand.ID), admin, gin.H{
+		"name": "Crêpe", "type": "nourriture", "jetons_requis": 3, "nb_products": 5,
+	}).Expect(http.StatusCreated).Data(&product)
+	buyJetons(t, s, parent, 10, 5)
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/stands/%d/products/%d/purchases", stand.ID, product.ID), parent, gin.H{"quantity": 2}).
+		Expect(http.StatusOK)
+
+	var purchase models.History
+	if err := s.DB.Where("stand_id = ? AND type = ?", stand.ID, models.HistoryTypePurchase).First(&purchase).Error; err != nil {
+		t.Fatal(err)
+	}
+	s.Request(http.MethodPost, reversePath(purchase.ID), teneur, nil).Expect(http.StatusOK)
+
+	s.DB.First(&product, product.ID)
+	if s.Balance(parent) != 10 || product.Nb_Products != 5 || standConso(t, s, stand.ID) != 0 {
+		t.Fatalf("jetons, stock et conso restaurés attendus : %d, %d, %d", s.Balance(parent), product.Nb_Products, standConso(t, s, stand.ID))
+	}
+}
+
+// Le teneur n'annule que pendant le délai, les organisateurs à tout moment, les autres jamais
+func TestReversalPermissions(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	teneur := s.Teneur()
+	organisateur := s.Organisateur()
+	parent := s.Parent()
+	stand := createStand(t, s, teneur, 2)
+	kermesse := createKermesse(t, s, organisateur)
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/kermesses/%d/stands", kermesse.ID), organisateur,
+		gin.H{"stand_ids": []uint{stand.ID}}).Expect(http.StatusOK)
+	buyJetons(t, s, parent, 10, 5)
+
+	var interaction responses.InteractionResponse
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/stands/%d/interactions", stand.ID), parent, nil).
+		Expect(http.StatusOK).
+		Data(&interaction)
+	if err := s.DB.Model(&models.History{}).Where("id = ?", interaction.History.ID).
+		Update("date", time.Now().Add(-services.ReversalWindow-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	path := reversePath(interaction.History.ID)
+	if code := s.Request(http.MethodPost, path, parent, nil).Expect(http.StatusForbidden).Error().Code; code != "reversal_forbidden" {
+		t.Errorf("code %q", code)
+	}
+	if code := s.Request(http.MethodPost, path, teneur, nil).Expect(http.StatusForbidden).Error().Code; code != "reversal_window_expired" {
+		t.Errorf("code %q", code)
+	}
+	s.Request(http.MethodPost, path, organisateur, nil).Expect(http.StatusOK)
+	if s.Balance(parent) != 10 {
+		t.Fatalf("10 jetons attendus, %d en base", s.Balance(parent))
+	}
+}
+
+// Deux annulations simultanées de la même entrée ne rendent les jetons qu'une fois
+func TestConcurrentReversals(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	teneur := s.Teneur()
+	parent := s.Parent()
+	stand := createStand(t, s, teneur, 4)
+	buyJetons(t, s, parent, 10, 5)
+
+	var interaction responses.InteractionResponse
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/stands/%d/interactions", stand.ID), parent, nil).
+		Expect(http.StatusOK).
+		Data(&interaction)
+
+	const attempts = 5
+	codes := make([]int, attempts)
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = s.Request(http.MethodPost, reversePath(interaction.History.ID), teneur, nil).Code
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			succeeded++
+		case http.StatusConflict:
+		default:
+			t.Fatalf("200 ou 409 attendus : %v", codes)
+		}
+	}
+	if succeeded != 1 || s.Balance(parent) != 10 || standConso(t, s, stand.ID) != 0 {
+		t.Fatalf("une seule annulation attendue : %v, solde %d", codes, s.Balance(parent))
+	}
+}
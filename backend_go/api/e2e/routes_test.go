@@ -0,0 +1,108 @@
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"project/internal/models"
+	"project/internal/testserver"
+)
+
+func TestNestedResources(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	admin := s.Admin()
+	organisateur := s.Organisateur()
+	teneur := s.Teneur()
+	stranger := s.Parent()
+
+	kermesse := createKermesse(t, s, organisateur)
+	attached := createStand(t, s, teneur, 2)
+	createStand(t, s, teneur, 4)
+	standsPath := fmt.Sprintf("/api/v1/kermesses/%d/stands", kermesse.ID)
+	s.Request(http.MethodPost, standsPath, organisateur, gin.H{"stand_ids": []uint{attached.ID}}).Expect(http.StatusOK)
+
+	stands, meta := listPage[models.Stand](t, s, standsPath, organisateur)
+	if meta.Total != 1 || stands[0].ID != attached.ID {
+		t.Fatalf("seul le stand rattaché doit être listé : %+v", stands)
+	}
+	s.Request(http.MethodGet, standsPath, stranger, nil).Expect(http.StatusForbidden)
+	s.Request(http.MethodGet, "/api/v1/kermesses/999999/stands", admin, nil).Expect(http.StatusNotFound)
+
+	productsPath := fmt.Sprintf("/api/v1/stands/%d/products", attached.ID)
+	var product models.Product
+	s.Request(http.MethodPost, productsPath, admin, gin.H{"name": "Gaufre", "type": "nourriture", "stand_id": 999999}).
+		Expect(http.StatusCreated).
+		Data(&product)
+	if product.StandID != uint64(attached.ID) {
+		t.Fatalf("le produit doit appartenir au stand du chemin : %+v", product)
+	}
+
+	products, meta := listPage[models.Product](t, s, productsPath, stranger)
+	if meta.Total != 1 || products[0].ID != product.ID {
+		t.Fatalf("le stock du stand doit être visible de tout utilisateur connecté : %+v", products)
+	}
+	s.Request(http.MethodGet, "/api/v1/stands/999999/products", stranger, nil).Expect(http.StatusNotFound)
+}
+
+func TestLegacyRoutesAreDeprecatedAliases(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	teneur := s.Teneur()
+	stand := createStand(t, s, teneur, 2)
+
+	current := s.Request(http.MethodGet, "/api/v1/me", teneur, nil).Expect(http.StatusOK)
+	if current.Header().Get("Deprecation") != "" {
+		t.Fatal("une route /api/v1 ne doit pas être dépréciée")
+	}
+
+	legacy := s.Request(http.MethodGet, "/profile", teneur, nil).Expect(http.StatusOK)
+	if legacy.Header().Get("Deprecation") != "true" || legacy.Header().Get("Link") != `</api/v1/me>; rel="successor-version"` {
+		t.Fatalf("l'ancienne route doit être signalée dépréciée : %v", legacy.Header())
+	}
+
+	var updated struct {
+		Stand models.Stand `json:"stand"`
+	}
+	renamed := s.Request(http.MethodPut, fmt.Sprintf("/stands/%d/update", stand.ID), teneur, gin.H{"name": "Chamboule-tout"}).
+		Expect(http.StatusOK).
+		JSON(&updated)
+	if want := fmt.Sprintf(`</api/v1/stands/%d>; rel="successor-version"`, stand.ID); renamed.Header().Get("Link") != want {
+		t.Fatalf("lien %q attendu, %q reçu", want, renamed.Header().Get("Link"))
+	}
+	if updated.Stand.Name != "Chamboule-tout" {
+		t.Fatalf("l'alias doit servir le même handler : %s", renamed.Body.String())
+	}
+
+	// Les routes apparues avec /api/v1 n'ont pas d'alias
+	s.Request(http.MethodPost, "/till-sessions", teneur, gin.H{"opening_float": 0}).Expect(http.StatusNotFound)
+	s.Request(http.MethodPost, "/payment/webhook", nil, gin.H{}).Expect(http.StatusNotFound)
+}
+
+// Les anciennes routes gardent la forme de réponse attendue par les clients d'avant /api/v1
+func TestLegacyRoutesKeepTheirResponseShape(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	parent := s.Parent()
+
+	var login map[string]any
+	s.Request(http.MethodPost, "/login", nil, gin.H{"email": parent.Email, "password": testserver.Password}).
+		Expect(http.StatusOK).
+		JSON(&login)
+	if token, ok := login["token"].(string); !ok || token == "" || len(login) != 1 {
+		t.Fatalf(`{"token": ...} attendu : %v`, login)
+	}
+
+	var profile map[string]map[string]any
+	s.Request(http.MethodGet, "/profile", parent, nil).Expect(http.StatusOK).JSON(&profile)
+	if profile["user"]["email"] != parent.Email {
+		t.Fatalf(`{"user": ...} attendu : %v`, profile)
+	}
+
+	// Les erreurs gardent la forme commune
+	if code := s.Request(http.MethodPost, "/login", nil, gin.H{"email": parent.Email, "password": "faux"}).Error().Code; code == "" {
+		t.Fatal("une erreur doit rester une erreur de l'API")
+	}
+}
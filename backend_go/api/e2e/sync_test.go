@@ -0,0 +1,214 @@
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"project/api/responses"
+	"project/internal/models"
+	"project/internal/testserver"
+	"project/services"
+)
+
+// registerDevice enregistre un appareil pour le stand du teneur et retourne son identifiant et son secret
+func registerDevice(t *testing.T, s *testserver.Server, teneur *testserver.User, stand models.Stand) (uint, string) {
+	t.Helper()
+	var registered responses.RegisterDeviceResponse
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/stands/%d/devices", stand.ID), teneur, gin.H{"name": "Tablette"}).
+		Expect(http.StatusCreated).
+		Data(&registered)
+	return registered.Device.ID, registered.Secret
+}
+
+// pointsOperation prépare une attribution de points signée avec le secret donné
+func pointsOperation(secret, clientID string, userID, points uint) services.OfflineOperation {
+	operation := services.OfflineOperation{
+		ClientID:   clientID,
+		Type:       models.SyncOperationPoints,
+		RecordedAt: time.Now().UTC().Truncate(time.Millisecond),
+		UserID:     userID,
+		Points:     points,
+	}
+	operation.Signature = services.SignOperation(secret, operation)
+	return operation
+}
+
+// upload envoie les opérations de l'appareil et retourne leurs résultats
+func upload(t *testing.T, s *testserver.Server, teneur *testserver.User, deviceID uint, operations ...services.OfflineOperation) []models.SyncOperation {
+	t.Helper()
+	body := make([]gin.H, 0, len(operations))
+	for _, operation := range operations {
+		body = append(body, gin.H{
+			"client_id":   operation.ClientID,
+			"type":        operation.Type,
+			"recorded_at": operation.RecordedAt,
+			"user_id":     operation.UserID,
+			"card_code":   operation.CardCode,
+			"product_id":  operation.ProductID,
+			"quantity":    operation.Quantity,
+			"points":      operation.Points,
+			"signature":   operation.Signature,
+		})
+	}
+	var results []models.SyncOperation
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/sync/devices/%d/operations", deviceID), teneur, gin.H{"operations": body}).
+		Expect(http.StatusOK).
+		Data(&results)
+	if len(results) != len(operations) {
+		t.Fatalf("%d résultats attendus : %+v", len(operations), results)
+	}
+	return results
+}
+
+func points(t *testing.T, s *testserver.Server, user *testserver.User) uint {
+	t.Helper()
+	var stored models.User
+	if err := s.DB.First(&stored, user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return stored.PtsAttribues
+}
+
+func storedOperations(s *testserver.Server, deviceID uint) int64 {
+	var count int64
+	s.DB.Model(&models.SyncOperation{}).Where("device_id = ?", deviceID).Count(&count)
+	return count
+}
+
+// Une opération mal signée est rejetée sans être enregistrée : renvoyée avec la bonne signature, elle est appliquée
+func TestSyncRejectsInvalidSignatureWithoutReservingTheOperation(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	teneur := s.Teneur()
+	eleve := s.Eleve()
+	deviceID, secret := registerDevice(t, s, teneur, createStand(t, s, teneur, 1))
+
+	forged := pointsOperation("un-autre-secret", "op-1", eleve.ID, 5)
+	results := upload(t, s, teneur, deviceID, forged)
+	if results[0].Status != models.SyncStatusRejected || results[0].Reason != "invalid signature" || results[0].ID != 0 {
+		t.Fatalf("rejet non enregistré attendu : %+v", results[0])
+	}
+	if storedOperations(s, deviceID) != 0 || points(t, s, eleve) != 0 {
+		t.Fatal("une opération mal signée ne doit rien enregistrer ni attribuer")
+	}
+
+	signed := pointsOperation(secret, "op-1", eleve.ID, 5)
+	results = upload(t, s, teneur, deviceID, signed)
+	if results[0].Status != models.SyncStatusApplied || points(t, s, eleve) != 5 {
+		t.Fatalf("l'opération correctement signée doit être appliquée : %+v", results[0])
+	}
+}
+
+// Une opération renvoyée retourne le résultat enregistré sans être appliquée deux fois, y compris
+// lorsqu'une copie mal signée arrive ensuite avec le même client_id
+func TestSyncReplayIsAppliedOnce(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	teneur := s.Teneur()
+	eleve := s.Eleve()
+	deviceID, secret := registerDevice(t, s, teneur, createStand(t, s, teneur, 1))
+
+	operation := pointsOperation(secret, "op-1", eleve.ID, 3)
+	first := upload(t, s, teneur, deviceID, operation, operation)
+	replayed := upload(t, s, teneur, deviceID, operation)
+	if first[0].ID == 0 || first[1].ID != first[0].ID || replayed[0].ID != first[0].ID || replayed[0].Status != models.SyncStatusApplied {
+		t.Fatalf("le même enregistrement doit être retourné : %+v %+v", first, replayed)
+	}
+
+	tampered := operation
+	tampered.Points = 30
+	if results := upload(t, s, teneur, deviceID, tampered); results[0].Status != models.SyncStatusRejected {
+		t.Fatalf("une copie modifiée doit être rejetée : %+v", results[0])
+	}
+	if storedOperations(s, deviceID) != 1 || points(t, s, eleve) != 3 {
+		t.Fatalf("une seule opération appliquée attendue, %d points", points(t, s, eleve))
+	}
+}
+
+// Une opération rejetée sur l'état du serveur (carte désactivée) est retentée à son renvoi
+func TestSyncRetriesRejectedOperation(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	admin := s.Admin()
+	teneur := s.Teneur()
+	organisateur := s.Organisateur()
+	stand := createStand(t, s, teneur, 1)
+	kermesse := createKermesse(t, s, organisateur)
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/kermesses/%d/stands", kermesse.ID), organisateur,
+		gin.H{"stand_ids": []uint{stand.ID}}).Expect(http.StatusOK)
+	var product models.Product
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/stands/%d/products", stand.ID), admin, gin.H{
+		"name": "Crêpe", "type": "nourriture", "jetons_requis": 2, "nb_products": 5,
+	}).Expect(http.StatusCreated).Data(&product)
+	card := models.PrepaidCard{Code: "SYNC0001", Jetons: 10, Disabled: true, KermesseID: kermesse.ID}
+	if err := s.DB.Create(&card).Error; err != nil {
+		t.Fatal(err)
+	}
+	deviceID, secret := registerDevice(t, s, teneur, stand)
+
+	operation := services.OfflineOperation{
+		ClientID:   "achat-1",
+		Type:       models.SyncOperationPurchase,
+		RecordedAt: time.Now().UTC().Truncate(time.Millisecond),
+		CardCode:   card.Code,
+		ProductID:  product.ID,
+		Quantity:   1,
+	}
+	operation.Signature = services.SignOperation(secret, operation)
+
+	if results := upload(t, s, teneur, deviceID, operation); results[0].Status != models.SyncStatusRejected {
+		t.Fatalf("achat sur carte désactivée rejeté attendu : %+v", results[0])
+	}
+	if err := s.DB.Model(&card).Update("disabled", false).Error; err != nil {
+		t.Fatal(err)
+	}
+	results := upload(t, s, teneur, deviceID, operation)
+	if results[0].Status != models.SyncStatusApplied || results[0].HistoryID == nil {
+		t.Fatalf("le renvoi doit être appliqué : %+v", results[0])
+	}
+	if err := s.DB.First(&card, card.ID).Error; err != nil || card.Jetons != 8 {
+		t.Fatalf("8 jetons attendus sur la carte : %+v, %v", card, err)
+	}
+
+	// Appliquée, elle n'est plus retentée
+	upload(t, s, teneur, deviceID, operation)
+	if s.DB.First(&card, card.ID); card.Jetons != 8 || storedOperations(s, deviceID) != 1 {
+		t.Fatalf("l'achat ne doit être débité qu'une fois : %d jetons", card.Jetons)
+	}
+}
+
+// Un client_id plus long que la colonne est refusé à la lecture de la requête, sans rien enregistrer
+func TestSyncRejectsOverlongClientID(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	teneur := s.Teneur()
+	eleve := s.Eleve()
+	deviceID, secret := registerDevice(t, s, teneur, createStand(t, s, teneur, 1))
+
+	operation := pointsOperation(secret, strings.Repeat("x", 65), eleve.ID, 5)
+	body := s.Request(http.MethodPost, fmt.Sprintf("/api/v1/sync/devices/%d/operations", deviceID), teneur, gin.H{
+		"operations": []gin.H{{
+			"client_id":   operation.ClientID,
+			"type":        operation.Type,
+			"recorded_at": operation.RecordedAt,
+			"user_id":     operation.UserID,
+			"points":      operation.Points,
+			"signature":   operation.Signature,
+		}},
+	}).Expect(http.StatusBadRequest).Error()
+	if len(body.Fields) != 1 || body.Fields[0].Field != "operations[0].client_id" || body.Fields[0].Code != "max" {
+		t.Fatalf("client_id trop long attendu : %+v", body)
+	}
+	if storedOperations(s, deviceID) != 0 || points(t, s, eleve) != 0 {
+		t.Fatal("une requête refusée ne doit rien enregistrer ni attribuer")
+	}
+
+	// 64 caractères restent acceptés
+	if results := upload(t, s, teneur, deviceID, pointsOperation(secret, strings.Repeat("x", 64), eleve.ID, 5)); results[0].Status != models.SyncStatusApplied {
+		t.Fatalf("opération appliquée attendue : %+v", results[0])
+	}
+}
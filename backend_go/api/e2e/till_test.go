@@ -0,0 +1,124 @@
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"project/internal/models"
+	"project/internal/testserver"
+	"project/services"
+)
+
+// La caisse est réservée aux caissiers et aux admins, et ses montants ne peuvent être négatifs
+func TestTillRolesAndAmounts(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	kermesse := createKermesse(t, s, s.Organisateur())
+
+	for _, user := range []*testserver.User{s.Teneur(), s.Parent()} {
+		if code := s.Request(http.MethodPost, "/api/v1/till-sessions", user, gin.H{"kermesse_id": kermesse.ID}).
+			Expect(http.StatusForbidden).Error().Code; code != "till_forbidden" {
+			t.Errorf("code %q", code)
+		}
+	}
+	s.Request(http.MethodPost, "/api/v1/till-sessions", s.Admin(), gin.H{"kermesse_id": kermesse.ID}).
+		Expect(http.StatusCreated)
+
+	caissier := s.Caissier()
+	if code := s.Request(http.MethodPost, "/api/v1/till-sessions", caissier, gin.H{"kermesse_id": kermesse.ID, "opening_float": -10}).
+		Expect(http.StatusBadRequest).Error().Code; code != "negative_till_amount" {
+		t.Errorf("code %q", code)
+	}
+	s.Request(http.MethodPost, "/api/v1/till-sessions", caissier, gin.H{"kermesse_id": kermesse.ID, "opening_float": 50}).
+		Expect(http.StatusCreated)
+	if code := s.Request(http.MethodPost, "/api/v1/till-sessions/current/close", caissier, gin.H{"closing_count": -1}).
+		Expect(http.StatusBadRequest).Error().Code; code != "negative_till_amount" {
+		t.Errorf("code %q", code)
+	}
+
+	// Le refus laisse la session ouverte, et le comptage donne l'écart avec le fond de caisse
+	var report services.TillReport
+	s.Request(http.MethodPost, "/api/v1/till-sessions/current/close", caissier, gin.H{"closing_count": 48.5}).
+		Expect(http.StatusOK).
+		Data(&report)
+	if report.Session.Status != models.TillSessionClosed || report.Discrepancy == nil || *report.Discrepancy != -1.5 {
+		t.Fatalf("session fermée avec 1,50 € d'écart attendue : %+v", report)
+	}
+}
+
+// Deux ouvertures simultanées d'un même caissier n'ouvrent qu'une session
+func TestTillConcurrentOpen(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	kermesse := createKermesse(t, s, s.Organisateur())
+	caissier := s.Caissier()
+
+	const attempts = 5
+	codes := make([]int, attempts)
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = s.Request(http.MethodPost, "/api/v1/till-sessions", caissier, gin.H{"kermesse_id": kermesse.ID}).Code
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+		default:
+			t.Fatalf("201 ou 409 attendus : %v", codes)
+		}
+	}
+	var open int64
+	s.DB.Model(&models.TillSession{}).Where("cashier_id = ? AND status = ?", caissier.ID, models.TillSessionOpen).Count(&open)
+	if created != 1 || open != 1 {
+		t.Fatalf("une seule session ouverte attendue : %v, %d en base", codes, open)
+	}
+}
+
+// Les ventes en espèces entrent dans le montant attendu, pas celles au terminal de paiement
+func TestTillSalesReport(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	organisateur := s.Organisateur()
+	kermesse := createKermesse(t, s, organisateur)
+	caissier := s.Caissier()
+	parent := s.Parent()
+	pack := models.Jetons{NbJetons: 10, Price: 5}
+	if err := s.DB.Create(&pack).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	s.Request(http.MethodPost, "/api/v1/till-sessions", caissier, gin.H{"kermesse_id": kermesse.ID, "opening_float": 20}).
+		Expect(http.StatusCreated)
+	for _, method := range []string{models.PaymentMethodCash, models.PaymentMethodCardTerminal} {
+		s.Request(http.MethodPost, "/api/v1/till-sessions/current/sales", caissier, gin.H{
+			"user_id": parent.ID, "jetons_id": pack.ID, "packs": 2, "payment_method": method,
+		}).Expect(http.StatusCreated)
+	}
+	if s.Balance(parent) != 40 {
+		t.Fatalf("40 jetons attendus, %d en base", s.Balance(parent))
+	}
+
+	var report services.TillReport
+	s.Request(http.MethodPost, "/api/v1/till-sessions/current/close", caissier, gin.H{"closing_count": 30}).
+		Expect(http.StatusOK).
+		Data(&report)
+	if report.SalesCount != 2 || report.CashSales != 10 || report.CardTerminalSales != 10 || report.ExpectedCash != 30 || *report.Discrepancy != 0 {
+		t.Fatalf("rapport inattendu : %+v", report)
+	}
+
+	// Le rapport est visible des organisateurs de la kermesse, pas des autres comptes
+	path := fmt.Sprintf("/api/v1/till-sessions/%d/report", report.Session.ID)
+	s.Request(http.MethodGet, path, organisateur, nil).Expect(http.StatusOK)
+	s.Request(http.MethodGet, path, parent, nil).Expect(http.StatusForbidden)
+}
@@ -2,30 +2,39 @@ package middlewares
 
 import (
 	"fmt"
-	"net/http"
-	"os"
-	"project/internal/initializers"
+	"log/slog"
+	"project/internal/logging"
 	"project/internal/models"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
-func CheckAuth(c *gin.Context) {
+// Middlewares porte la connexion, le secret et le logger dont les middlewares ont besoin
+type Middlewares struct {
+	db     *gorm.DB
+	secret string
+	logger *slog.Logger
+}
+
+func New(db *gorm.DB, secret string, logger *slog.Logger) *Middlewares {
+	return &Middlewares{db: db, secret: secret, logger: logger}
+}
+
+func (m *Middlewares) CheckAuth(c *gin.Context) {
 	authHeader := c.Request.Header.Get("Authorization")
 
 	if authHeader == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "No Authorization header found"})
-		c.AbortWithStatus(http.StatusUnauthorized)
+		abort(c, ErrMissingToken)
 		return
 	}
 
 	authToken := strings.Split(authHeader, " ")
 	if len(authToken) != 2 || authToken[0] != "Bearer" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Authorization header"})
-		c.AbortWithStatus(http.StatusUnauthorized)
+		abort(c, ErrInvalidAuthHeader)
 		return
 	}
 
@@ -34,36 +43,34 @@ func CheckAuth(c *gin.Context) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(os.Getenv("SECRET")), nil
+		return []byte(m.secret), nil
 	})
 	if err != nil || !token.Valid {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-		c.AbortWithStatus(http.StatusUnauthorized)
+		abort(c, ErrInvalidToken)
 		return
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-		c.Abort()
+		abort(c, ErrInvalidToken)
 		return
 	}
 
-	if float64(time.Now().Unix()) > claims["exp"].(float64) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "token expired"})
-		c.AbortWithStatus(http.StatusUnauthorized)
+	if exp, ok := claims["exp"].(float64); !ok || float64(time.Now().Unix()) > exp {
+		abort(c, ErrInvalidToken)
 		return
 	}
 
 	var user models.User
-	initializers.DB.Where("ID=?", claims["id"]).Find(&user)
+	m.db.Where("ID=?", claims["id"]).Find(&user)
 
 	if user.ID == 0 {
-		c.AbortWithStatus(http.StatusUnauthorized)
+		abort(c, ErrInvalidToken)
 		return
 	}
 
 	c.Set("currentUser", user)
+	c.Request = c.Request.WithContext(logging.With(c.Request.Context(), "user_id", user.ID))
 
 	c.Next()
 
@@ -0,0 +1,79 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecated signale une ancienne route, conservée comme alias le temps que les clients passent
+// à /api/v1 : la réponse porte l'en-tête Deprecation et un lien vers la route qui la remplace.
+// Les paramètres du chemin de successor (":id") prennent les valeurs de la requête.
+func (m *Middlewares) Deprecated(successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Link", "<"+expandPath(successor, c.Params)+`>; rel="successor-version"`)
+		c.Next()
+	}
+}
+
+func expandPath(path string, params gin.Params) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = params.ByName(name)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// bufferedWriter retient le corps de la réponse pour qu'il soit réécrit avant l'envoi
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// LegacyBody rend aux anciennes routes la forme de réponse d'avant /api/v1 : la ressource de
+// l'enveloppe {"data": ...} est renvoyée sous la clé key, ou telle quelle si key est vide.
+// Les erreurs et les réponses sans corps ne sont pas modifiées.
+func (m *Middlewares) LegacyBody(key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writer := c.Writer
+		buffered := &bufferedWriter{ResponseWriter: writer}
+		c.Writer = buffered
+		defer func() {
+			c.Writer = writer
+			if buffered.body.Len() > 0 {
+				writer.Write(legacyBody(key, writer.Status(), buffered.body.Bytes()))
+			}
+		}()
+		c.Next()
+	}
+}
+
+func legacyBody(key string, status int, body []byte) []byte {
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if status >= 300 || json.Unmarshal(body, &envelope) != nil || envelope.Data == nil {
+		return body
+	}
+	if key == "" {
+		return envelope.Data
+	}
+	legacy, err := json.Marshal(map[string]json.RawMessage{key: envelope.Data})
+	if err != nil {
+		return body
+	}
+	return legacy
+}
@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"project/internal/apperror"
+	"project/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrMissingToken      = apperror.Unauthorized("missing_token", "no Authorization header found")
+	ErrInvalidAuthHeader = apperror.Unauthorized("invalid_authorization_header", "the Authorization header must be: Bearer <token>")
+	ErrInvalidToken      = apperror.Unauthorized("invalid_token", "invalid or expired token")
+	ErrNotAuthenticated  = apperror.Unauthorized("not_authenticated", "user not logged in")
+	ErrRouteNotFound     = apperror.NotFound("route_not_found", "no route matches this path")
+)
+
+// Errors écrit la réponse d'erreur de la requête : handlers et middlewares signalent l'erreur
+// avec c.Error et s'interrompent sans écrire de corps. Le statut découle de la catégorie de
+// l'erreur ; une erreur non typée devient une erreur interne dont seul le log garde le détail.
+func (m *Middlewares) Errors(c *gin.Context) {
+	c.Next()
+	writeError(c)
+}
+
+// writeError écrit la réponse de la dernière erreur signalée, si aucune réponse n'a encore été écrite
+func writeError(c *gin.Context) {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+	err := apperror.From(c.Errors.Last().Err)
+	c.JSON(err.Status(), err.Response(logging.RequestID(c.Request.Context())))
+}
+
+// NoRoute répond 404 dans le format d'erreur de l'API
+func (m *Middlewares) NoRoute(c *gin.Context) {
+	abort(c, ErrRouteNotFound)
+}
+
+// abort interrompt la requête sur une erreur, mise en forme par Errors
+func abort(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
@@ -0,0 +1,171 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"project/internal/apperror"
+	"project/internal/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	IdempotencyHeader = "Idempotency-Key"
+	ReplayedHeader    = "Idempotent-Replayed"
+)
+
+// Durée pendant laquelle une clé est conservée ; passé ce délai elle peut être réutilisée
+const IdempotencyTTL = 24 * time.Hour
+
+// Taille maximale du corps d'une requête portant une Idempotency-Key, lu en entier pour son empreinte
+const IdempotencyMaxBody = 1 << 20
+
+var (
+	ErrIdempotencyKeyTooLong = apperror.Validation("idempotency_key_too_long", "Idempotency-Key is too long")
+	ErrUnreadableBody        = apperror.Validation("unreadable_body", "cannot read body")
+	ErrBodyTooLarge          = apperror.TooLarge("body_too_large", "request body is too large")
+	ErrIdempotencyInProgress = apperror.Conflict("idempotency_in_progress", "a request with this Idempotency-Key is being processed")
+	ErrIdempotencyKeyReused  = apperror.Unprocessable("idempotency_key_reused", "Idempotency-Key was already used for a different request")
+)
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency rejoue la réponse d'origine quand une requête est renvoyée avec le même Idempotency-Key.
+// Doit être placé après CheckAuth : les clés sont propres à chaque utilisateur.
+// Sans en-tête, la requête est traitée normalement.
+func (m *Middlewares) Idempotency(c *gin.Context) {
+	key := c.GetHeader(IdempotencyHeader)
+	if key == "" {
+		c.Next()
+		return
+	}
+	if len(key) > 255 {
+		abort(c, ErrIdempotencyKeyTooLong)
+		return
+	}
+
+	user, exists := c.Get("currentUser")
+	if !exists {
+		abort(c, ErrNotAuthenticated)
+		return
+	}
+	currentUser := user.(models.User)
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, IdempotencyMaxBody))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		abort(c, ErrBodyTooLarge.Wrap(err))
+		return
+	}
+	if err != nil {
+		abort(c, ErrUnreadableBody.Wrap(err))
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	sum := sha256.Sum256([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n" + string(body)))
+	fingerprint := hex.EncodeToString(sum[:])
+
+	record := models.IdempotencyKey{
+		Key:         key,
+		UserID:      currentUser.ID,
+		Method:      c.Request.Method,
+		Path:        c.Request.URL.Path,
+		Fingerprint: fingerprint,
+		Status:      models.IdempotencyInProgress,
+	}
+
+	// Les clés expirées sont libérées avant la réservation
+	m.db.Where("user_id = ? AND key = ? AND created_at < ?", currentUser.ID, key, time.Now().Add(-IdempotencyTTL)).
+		Delete(&models.IdempotencyKey{})
+
+	// La réservation de la clé est atomique : une seule des requêtes concurrentes l'obtient
+	res := m.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+	if res.Error != nil {
+		abort(c, res.Error)
+		return
+	}
+	if res.RowsAffected == 0 {
+		m.replay(c, currentUser.ID, key, fingerprint)
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	// La clé est toujours soldée, même si le handler panique : sinon elle resterait « en cours »
+	// et toute nouvelle tentative serait refusée jusqu'à son expiration
+	defer func() {
+		if r := recover(); r != nil {
+			m.db.Delete(&models.IdempotencyKey{}, record.ID)
+			panic(r)
+		}
+		m.complete(c, record.ID, recorder)
+	}()
+	c.Next()
+}
+
+// complete mémorise la réponse avec la clé, ou libère la clé après une erreur serveur
+func (m *Middlewares) complete(c *gin.Context, id uint, recorder *responseRecorder) {
+	// Une erreur est mise en forme ici plutôt que par Errors, pour être mémorisée avec la clé
+	writeError(c)
+
+	// Une erreur serveur n'est pas mémorisée : la requête pourra être retentée avec la même clé
+	if recorder.Status() >= http.StatusInternalServerError {
+		m.db.Delete(&models.IdempotencyKey{}, id)
+		return
+	}
+	m.db.Model(&models.IdempotencyKey{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":                models.IdempotencyCompleted,
+		"response_status":       recorder.Status(),
+		"response_content_type": recorder.Header().Get("Content-Type"),
+		"response_body":         recorder.body.Bytes(),
+	})
+}
+
+func (m *Middlewares) replay(c *gin.Context, userID uint, key string, fingerprint string) {
+	var existing models.IdempotencyKey
+	if err := m.db.Where("user_id = ? AND key = ?", userID, key).First(&existing).Error; err != nil {
+		abort(c, ErrIdempotencyInProgress)
+		return
+	}
+
+	if existing.Fingerprint != fingerprint {
+		abort(c, ErrIdempotencyKeyReused)
+		return
+	}
+	if existing.Status != models.IdempotencyCompleted {
+		abort(c, ErrIdempotencyInProgress)
+		return
+	}
+
+	c.Header(ReplayedHeader, "true")
+	c.Data(existing.ResponseStatus, existing.ResponseContentType, existing.ResponseBody)
+	c.Abort()
+}
+
+// PurgeIdempotencyKeys supprime les clés expirées que plus aucune requête ne viendra libérer
+func PurgeIdempotencyKeys(ctx context.Context, db *gorm.DB) (int64, error) {
+	res := db.WithContext(ctx).Where("created_at < ?", time.Now().Add(-IdempotencyTTL)).Delete(&models.IdempotencyKey{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,56 @@
+package middlewares_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"project/api/middlewares"
+	"project/internal/models"
+	"project/internal/testserver"
+)
+
+// Une panique du handler libère la clé : la requête peut être retentée avec la même clé
+func TestIdempotencyReleasesKeyOnPanic(t *testing.T) {
+	s := testserver.New(t)
+	user := s.Parent()
+	m := middlewares.New(s.DB, testserver.Secret, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	calls := 0
+	router := gin.New()
+	router.Use(m.Errors, m.Recovery())
+	router.POST("/panic", func(c *gin.Context) {
+		c.Set("currentUser", user.User)
+	}, m.Idempotency, func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("échec inattendu")
+		}
+		c.JSON(http.StatusOK, gin.H{"calls": calls})
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/panic", strings.NewReader(`{}`))
+		req.Header.Set(middlewares.IdempotencyHeader, "panique")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := send(); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("500 attendu, %d reçu : %s", rec.Code, rec.Body.String())
+	}
+	var count int64
+	s.DB.Model(&models.IdempotencyKey{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("la clé doit être libérée après la panique, %d en base", count)
+	}
+
+	if rec := send(); rec.Code != http.StatusOK || calls != 2 {
+		t.Fatalf("la nouvelle tentative doit être traitée : %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,87 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"project/internal/audit"
+	"project/internal/logging"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// Un identifiant fourni par le client (proxy, application) est repris s'il reste raisonnable
+const maxRequestIDLength = 128
+
+// RequestLogger attribue un identifiant à chaque requête, le renvoie dans l'en-tête X-Request-ID
+// et le rattache au logger du contexte, puis journalise la requête une fois traitée avec sa route,
+// son statut, l'utilisateur connecté et les erreurs internes signalées par c.Error.
+// L'IP du client est aussi rattachée au contexte pour le journal d'audit.
+// Doit être le premier middleware.
+func (m *Middlewares) RequestLogger(c *gin.Context) {
+	start := time.Now()
+	id := c.GetHeader(RequestIDHeader)
+	if !validRequestID(id) {
+		id = newRequestID()
+	}
+	c.Header(RequestIDHeader, id)
+
+	ctx := logging.WithLogger(c.Request.Context(), m.logger)
+	ctx = logging.WithRequestID(ctx, id)
+	ctx = logging.With(ctx, "route", c.FullPath())
+	ctx = audit.WithClientIP(ctx, c.ClientIP())
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+
+	attrs := []any{
+		"method", c.Request.Method,
+		"path", c.Request.URL.Path,
+		"status", c.Writer.Status(),
+		"duration_ms", time.Since(start).Milliseconds(),
+		"client_ip", c.ClientIP(),
+	}
+	if len(c.Errors) > 0 {
+		attrs = append(attrs, "error", c.Errors.String())
+	}
+	// Le logger du contexte porte user_id quand CheckAuth a authentifié la requête
+	logger := logging.FromContext(c.Request.Context())
+	if c.Writer.Status() >= http.StatusInternalServerError {
+		logger.Error("request", attrs...)
+		return
+	}
+	logger.Info("request", attrs...)
+}
+
+// Recovery transforme une panique en erreur interne, journalisée avec le détail
+func (m *Middlewares) Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		abort(c, fmt.Errorf("panic: %v", recovered))
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
@@ -13,3 +13,13 @@ type LoginRequest struct {
 	Email    string `json:"email" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
+
+// UpdateUserRequest ne modifie que les champs renseignés
+type UpdateUserRequest struct {
+	Firstname string `json:"first_name"`
+	Lastname  string `json:"last_name"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	Picture   string `json:"picture"`
+	Role      uint   `json:"role"`
+}
@@ -4,4 +4,6 @@ type PaymentRequest struct {
 	Type     string  `json:"type" binding:"required"`
 	Quantity uint    `json:"quantity" binding:"required"`
 	Price    float32 `json:"price" binding:"required"`
+	// Kermesse pour laquelle les jetons sont achetés, pour le bilan financier
+	KermesseID uint `json:"kermesse_id"`
 }
@@ -0,0 +1,5 @@
+package requests
+
+type LinkCardRequest struct {
+	MergeBalance bool `json:"merge_balance"`
+}
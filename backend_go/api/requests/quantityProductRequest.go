@@ -1,5 +1,6 @@
 package requests
 
 type QuantityProductRequest struct {
-	Quantity uint `json:"quantity"`
+	Quantity uint   `json:"quantity"`
+	CardCode string `json:"card_code"` // Achat payé avec une carte prépayée, débitée par le teneur du stand
 }
@@ -0,0 +1,5 @@
+package requests
+
+type SettleTokensRequest struct {
+	Donate bool `json:"donate"`
+}
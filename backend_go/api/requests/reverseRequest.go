@@ -0,0 +1,5 @@
+package requests
+
+type ReverseRequest struct {
+	Reason string `json:"reason"`
+}
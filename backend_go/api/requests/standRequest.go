@@ -2,6 +2,13 @@ package requests
 
 type StandRequest struct {
 	Name         string `json:"name" binding:"required"`
-	Type         string `son:"type" binding:"required"`
+	Type         string `json:"type" binding:"required"`
 	JetonsRequis uint   `json:"jetons_requis" binding:"required"`
 }
+
+// UpdateStandRequest ne modifie que les champs renseignés
+type UpdateStandRequest struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	JetonsRequis uint   `json:"jetons_requis"`
+}
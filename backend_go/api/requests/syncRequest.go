@@ -0,0 +1,24 @@
+package requests
+
+import "time"
+
+type RegisterDeviceRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// Opération enregistrée hors ligne ; signature = HMAC-SHA256 avec le secret de l'appareil (voir services.SignOperation)
+type SyncOperationRequest struct {
+	ClientID   string    `json:"client_id" binding:"required,max=64"`
+	Type       string    `json:"type" binding:"required"`
+	RecordedAt time.Time `json:"recorded_at" binding:"required"`
+	UserID     uint      `json:"user_id"`
+	CardCode   string    `json:"card_code"`
+	ProductID  uint      `json:"product_id"`
+	Quantity   uint      `json:"quantity"`
+	Points     uint      `json:"points"`
+	Signature  string    `json:"signature" binding:"required"`
+}
+
+type SyncUploadRequest struct {
+	Operations []SyncOperationRequest `json:"operations" binding:"required,dive"`
+}
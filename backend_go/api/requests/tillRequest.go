@@ -0,0 +1,26 @@
+package requests
+
+type OpenTillRequest struct {
+	KermesseID   uint    `json:"kermesse_id" binding:"required"`
+	OpeningFloat float64 `json:"opening_float"`
+}
+
+// Une vente crédite soit le compte user_id, soit la carte prépayée card_code
+type TillSaleRequest struct {
+	UserID        uint   `json:"user_id"`
+	CardCode      string `json:"card_code"`
+	JetonsID      uint   `json:"jetons_id" binding:"required"`
+	Packs         uint   `json:"packs"`
+	PaymentMethod string `json:"payment_method" binding:"required"`
+}
+
+// La carte peut être émise vide ou chargée immédiatement avec un pack de jetons
+type IssueCardRequest struct {
+	JetonsID      uint   `json:"jetons_id"`
+	Packs         uint   `json:"packs"`
+	PaymentMethod string `json:"payment_method"`
+}
+
+type CloseTillRequest struct {
+	ClosingCount *float64 `json:"closing_count" binding:"required"`
+}
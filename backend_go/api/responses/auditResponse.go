@@ -0,0 +1,42 @@
+package responses
+
+import (
+	"encoding/json"
+	"time"
+
+	"project/internal/audit"
+	"project/internal/models"
+)
+
+// AuditEntryResponse est une entrée du journal d'audit, avec ses changements décodés
+type AuditEntryResponse struct {
+	ID         uint                    `json:"id"`
+	CreatedAt  time.Time               `json:"created_at"`
+	ActorID    *uint                   `json:"actor_id" extensions:"x-nullable"`
+	Actor      string                  `json:"actor"`
+	Action     string                  `json:"action"`
+	EntityType string                  `json:"entity_type"`
+	EntityID   uint                    `json:"entity_id"`
+	Changes    map[string]audit.Change `json:"changes"`
+	IP         string                  `json:"ip"`
+	RequestID  string                  `json:"request_id"`
+}
+
+// NewAuditEntry construit la réponse d'une entrée du journal
+func NewAuditEntry(entry models.AuditEntry) AuditEntryResponse {
+	changes := map[string]audit.Change{}
+	// Les changements sont écrits par audit.Record : un objet JSON valide
+	json.Unmarshal([]byte(entry.Changes), &changes)
+	return AuditEntryResponse{
+		ID:         entry.ID,
+		CreatedAt:  entry.CreatedAt,
+		ActorID:    entry.ActorID,
+		Actor:      entry.Actor,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Changes:    changes,
+		IP:         entry.IP,
+		RequestID:  entry.RequestID,
+	}
+}
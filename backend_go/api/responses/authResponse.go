@@ -0,0 +1,5 @@
+package responses
+
+type LoginResponse struct {
+	Token string `json:"token"`
+}
@@ -0,0 +1,7 @@
+package responses
+
+// GiveCoinResponse donne les soldes du parent et de l'enfant après le transfert
+type GiveCoinResponse struct {
+	ParentCoins uint `json:"parent_coins"`
+	ChildCoins  uint `json:"child_coins"`
+}
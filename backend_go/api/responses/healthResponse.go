@@ -0,0 +1,7 @@
+package responses
+
+// HealthResponse est l'état renvoyé par les sondes ; RequestID n'accompagne que l'indisponibilité
+type HealthResponse struct {
+	Status    string `json:"status" enums:"ok,unavailable"`
+	RequestID string `json:"request_id,omitempty"`
+}
@@ -0,0 +1,63 @@
+package responses
+
+import (
+	"time"
+
+	"project/internal/models"
+)
+
+// KermesseResponse est une kermesse avec ses stands et ses membres, réduits à leur vue publique
+type KermesseResponse struct {
+	ID            uint           `json:"id"`
+	Name          string         `json:"name"`
+	Picture       string         `json:"picture"`
+	Thumbnail     string         `json:"thumbnail"`
+	Status        string         `json:"status" enums:"open,closed"`
+	ClosedAt      *time.Time     `json:"closed_at" extensions:"x-nullable"`
+	Stands        []models.Stand `json:"stands"`
+	Organisateurs []PublicUser   `json:"organisateurs"`
+	Participants  []PublicUser   `json:"participants"`
+	UserID        uint           `json:"user_id"`
+}
+
+// KermesseSummary identifie une kermesse dans le profil de son créateur
+type KermesseSummary struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Picture   string `json:"picture"`
+	Thumbnail string `json:"thumbnail"`
+	Status    string `json:"status" enums:"open,closed"`
+}
+
+// NewKermesse construit la réponse d'une kermesse
+func NewKermesse(kermesse models.Kermesse, pictures Pictures) KermesseResponse {
+	picture, thumbnail := pictures.URLs(kermesse.Picture)
+	return KermesseResponse{
+		ID:            kermesse.ID,
+		Name:          kermesse.Name,
+		Picture:       picture,
+		Thumbnail:     thumbnail,
+		Status:        kermesse.Status,
+		ClosedAt:      kermesse.ClosedAt,
+		Stands:        nonNil(kermesse.Stands),
+		Organisateurs: PublicUsers(kermesse.Organisateurs, pictures),
+		Participants:  PublicUsers(kermesse.Participants, pictures),
+		UserID:        kermesse.UserID,
+	}
+}
+
+// KermesseSummaries résume chaque kermesse ; la liste n'est jamais nulle
+func KermesseSummaries(kermesses []models.Kermesse, pictures Pictures) []KermesseSummary {
+	summaries := make([]KermesseSummary, 0, len(kermesses))
+	for _, kermesse := range kermesses {
+		picture, thumbnail := pictures.URLs(kermesse.Picture)
+		summaries = append(summaries, KermesseSummary{
+			ID:        kermesse.ID,
+			Name:      kermesse.Name,
+			Picture:   picture,
+			Thumbnail: thumbnail,
+			Status:    kermesse.Status,
+		})
+	}
+	return summaries
+}
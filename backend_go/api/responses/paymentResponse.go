@@ -0,0 +1,16 @@
+package responses
+
+import (
+	"project/internal/models"
+	"project/internal/payment"
+)
+
+// PaymentResponse porte l'intention à confirmer côté client et la transaction en attente
+type PaymentResponse struct {
+	PaymentIntent payment.Intent     `json:"paymentIntent"`
+	Transaction   models.Transaction `json:"transaction"`
+}
+
+type WebhookResponse struct {
+	Received bool `json:"received"`
+}
@@ -0,0 +1,10 @@
+package responses
+
+import "time"
+
+// PictureResponse donne les liens signés de la photo envoyée et de sa miniature, valables jusqu'à ExpiresAt
+type PictureResponse struct {
+	Picture   string    `json:"picture"`
+	Thumbnail string    `json:"thumbnail"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
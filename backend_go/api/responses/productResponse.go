@@ -0,0 +1,36 @@
+package responses
+
+import (
+	"time"
+
+	"project/internal/models"
+)
+
+// ProductResponse est un produit avec les liens de sa photo
+type ProductResponse struct {
+	ID           uint      `json:"id"`
+	Name         string    `json:"name"`
+	Picture      string    `json:"picture"`
+	Thumbnail    string    `json:"thumbnail"`
+	Type         string    `json:"type"`
+	JetonsRequis uint      `json:"jetons_requis"`
+	NbProducts   uint64    `json:"nb_products"`
+	StandID      uint64    `json:"stand_id"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// NewProduct construit la réponse d'un produit
+func NewProduct(product models.Product, pictures Pictures) ProductResponse {
+	picture, thumbnail := pictures.URLs(product.Picture)
+	return ProductResponse{
+		ID:           product.ID,
+		Name:         product.Name,
+		Picture:      picture,
+		Thumbnail:    thumbnail,
+		Type:         product.Type,
+		JetonsRequis: product.JetonsRequis,
+		NbProducts:   product.Nb_Products,
+		StandID:      product.StandID,
+		UpdatedAt:    product.UpdatedAt,
+	}
+}
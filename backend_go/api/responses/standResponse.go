@@ -0,0 +1,16 @@
+package responses
+
+import "project/internal/models"
+
+// InteractionResponse est le résultat d'une participation : l'entrée d'historique, la conso
+// du stand et le solde de l'utilisateur après débit
+type InteractionResponse struct {
+	History models.History `json:"history"`
+	Stand   uint           `json:"stand"`
+	Jetons  uint           `json:"jetons"`
+}
+
+type GivePointsResponse struct {
+	UserID      uint `json:"user_id"`
+	PointsGiven uint `json:"points_given"`
+}
@@ -0,0 +1,9 @@
+package responses
+
+import "project/internal/models"
+
+// RegisterDeviceResponse porte le secret de signature de l'appareil, renvoyé une seule fois
+type RegisterDeviceResponse struct {
+	Device models.StandDevice `json:"device"`
+	Secret string             `json:"secret"`
+}
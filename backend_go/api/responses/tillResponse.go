@@ -0,0 +1,9 @@
+package responses
+
+import "project/internal/models"
+
+// IssueCardResponse porte la carte émise et la vente de la première recharge, absente sans pack
+type IssueCardResponse struct {
+	Card        models.PrepaidCard  `json:"card"`
+	Transaction *models.Transaction `json:"transaction" extensions:"x-nullable"`
+}
@@ -0,0 +1,106 @@
+package responses
+
+import (
+	"time"
+
+	"project/internal/models"
+)
+
+// Les comptes ne sont jamais renvoyés tels quels : chaque public a sa vue, construite champ par
+// champ, pour qu'un ajout au modèle (ou le hash du mot de passe) ne sorte pas par accident.
+
+// Pictures transforme la valeur enregistrée d'une photo en liens à servir, signés pour les photos envoyées
+type Pictures interface {
+	URLs(picture string) (url, thumbnail string)
+}
+
+// PublicUser est ce que les autres membres voient d'un compte : ni email ni solde
+type PublicUser struct {
+	ID        uint   `json:"id"`
+	Firstname string `json:"firstname"`
+	Lastname  string `json:"lastname"`
+	Picture   string `json:"picture"`
+	Thumbnail string `json:"thumbnail"`
+	Role      uint   `json:"role"`
+}
+
+// Profile est le compte vu par son titulaire, avec ses liens familiaux et ce qu'il gère
+type Profile struct {
+	ID           uint                 `json:"id"`
+	Firstname    string               `json:"firstname"`
+	Lastname     string               `json:"lastname"`
+	Email        string               `json:"email"`
+	Picture      string               `json:"picture"`
+	Thumbnail    string               `json:"thumbnail"`
+	Role         uint                 `json:"role"`
+	Jetons       uint                 `json:"jetons"`
+	PtsAttribues uint                 `json:"pts_attribues"`
+	Parents      []PublicUser         `json:"parents"`
+	Enfants      []PublicUser         `json:"enfants"`
+	Kermesses    []KermesseSummary    `json:"kermesses"`
+	Stands       []models.Stand       `json:"stands"`
+	Transactions []models.Transaction `json:"transactions"`
+	Historique   []models.History     `json:"historique"`
+}
+
+// AdminUser est le compte vu par un administrateur : le profil complet et sa date de mise à jour
+type AdminUser struct {
+	Profile
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewPublicUser construit la vue publique d'un compte
+func NewPublicUser(user models.User, pictures Pictures) PublicUser {
+	picture, thumbnail := pictures.URLs(user.Picture)
+	return PublicUser{
+		ID:        user.ID,
+		Firstname: user.Firstname,
+		Lastname:  user.Lastname,
+		Picture:   picture,
+		Thumbnail: thumbnail,
+		Role:      user.Role,
+	}
+}
+
+// PublicUsers construit la vue publique de chaque compte ; la liste n'est jamais nulle
+func PublicUsers(users []models.User, pictures Pictures) []PublicUser {
+	public := make([]PublicUser, 0, len(users))
+	for _, user := range users {
+		public = append(public, NewPublicUser(user, pictures))
+	}
+	return public
+}
+
+// NewProfile construit le profil d'un compte pour son titulaire
+func NewProfile(user models.User, pictures Pictures) Profile {
+	picture, thumbnail := pictures.URLs(user.Picture)
+	return Profile{
+		ID:           user.ID,
+		Firstname:    user.Firstname,
+		Lastname:     user.Lastname,
+		Email:        user.Email,
+		Picture:      picture,
+		Thumbnail:    thumbnail,
+		Role:         user.Role,
+		Jetons:       user.Jetons,
+		PtsAttribues: user.PtsAttribues,
+		Parents:      PublicUsers(user.Parents, pictures),
+		Enfants:      PublicUsers(user.Enfants, pictures),
+		Kermesses:    KermesseSummaries(user.Kermesses, pictures),
+		Stands:       nonNil(user.Stands),
+		Transactions: nonNil(user.Transactions),
+		Historique:   nonNil(user.Historique),
+	}
+}
+
+// NewAdminUser construit la vue administrateur d'un compte
+func NewAdminUser(user models.User, pictures Pictures) AdminUser {
+	return AdminUser{Profile: NewProfile(user, pictures), UpdatedAt: user.UpdatedAt}
+}
+
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -1,76 +1,223 @@
 package routes
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"project/api/controllers"
 	"project/api/middlewares"
+	"project/internal/app"
 )
 
-// Authentifications
-func AuthRoutes(r *gin.Engine) {
-	r.POST("/signup", controllers.Signup)
-	r.POST("/login", controllers.Login)
-	r.POST("/logout", controllers.Logout)
-	r.GET("/profile", middlewares.CheckAuth, controllers.UserProfile)
-	r.PUT("/profile/update", middlewares.CheckAuth, controllers.UpdateProfile)
+// Préfixe de la version courante de l'API
+const V1 = "/api/v1"
+
+// Sondes de l'orchestrateur, sans authentification
+func HealthRoutes(r *gin.Engine, h *controllers.Controller) {
+	r.GET("/healthz", h.Healthz)
+	r.GET("/readyz", h.Readyz)
+}
+
+// Authentifications et compte de l'utilisateur connecté
+func AuthRoutes(r gin.IRouter, h *controllers.Controller, m *middlewares.Middlewares) {
+	r.POST("/auth/signup", h.Signup)
+	r.POST("/auth/login", h.Login)
+	r.POST("/auth/logout", h.Logout)
+	r.GET("/me", m.CheckAuth, h.UserProfile)
+	r.PUT("/me", m.CheckAuth, h.UpdateProfile)
+	r.POST("/me/children", m.CheckAuth, h.AddChildren)
+	r.PUT("/me/picture", m.CheckAuth, h.SetMyPicture)
 }
 
-func UserRoutes(r *gin.Engine) {
-	r.POST("/api/users", middlewares.CheckAuth, controllers.CreateUser)
-	r.GET("/api/users", middlewares.CheckAuth, controllers.GetAllUsers)
-	r.GET("/api/users/:id", middlewares.CheckAuth, controllers.GetUser)
-	r.PUT("/api/users/:id", middlewares.CheckAuth, controllers.UpdateUser)
-	r.DELETE("/api/users/:id", middlewares.CheckAuth, controllers.DeleteUser)
+func UserRoutes(r gin.IRouter, h *controllers.Controller, m *middlewares.Middlewares) {
+	r.POST("/users", m.CheckAuth, h.CreateUser)
+	r.GET("/users", m.CheckAuth, h.GetAllUsers)
+	r.GET("/users/:id", m.CheckAuth, h.GetUser)
+	r.PUT("/users/:id", m.CheckAuth, h.UpdateUser)
+	r.DELETE("/users/:id", m.CheckAuth, h.DeleteUser)
+	r.POST("/users/:id/coins", m.CheckAuth, m.Idempotency, h.GiveCoins)
+	r.GET("/students", m.CheckAuth, h.GetStudents)
 }
 
-func KermesseRoutes(r *gin.Engine) {
-	r.POST("/create-kermesse", middlewares.CheckAuth, controllers.CreateKermesse)
-	r.GET("/kermesses", middlewares.CheckAuth, controllers.GetAllKermesses)
-	r.GET("/kermesses/:id", middlewares.CheckAuth, controllers.GetKermesseById)
-	r.PUT("/kermesses/:id/update", middlewares.CheckAuth, controllers.UpdateKermesse)
-	r.DELETE("/kermesses/:id/delete", middlewares.CheckAuth, controllers.DeleteKermesse)
-	r.POST("/kermesses/:id/add-stands", middlewares.CheckAuth, controllers.AddStand)
-	r.POST("/kermesses/:id/add-users", middlewares.CheckAuth, controllers.AddParticipantAndOrga)
+func KermesseRoutes(r gin.IRouter, h *controllers.Controller, m *middlewares.Middlewares) {
+	r.POST("/kermesses", m.CheckAuth, h.CreateKermesse)
+	r.GET("/kermesses", m.CheckAuth, h.GetAllKermesses)
+	r.GET("/kermesses/:id", m.CheckAuth, h.GetKermesseById)
+	r.PUT("/kermesses/:id", m.CheckAuth, h.UpdateKermesse)
+	r.DELETE("/kermesses/:id", m.CheckAuth, h.DeleteKermesse)
+	r.GET("/kermesses/:id/stands", m.CheckAuth, h.GetKermesseStands)
+	r.POST("/kermesses/:id/stands", m.CheckAuth, h.AddStand)
+	r.POST("/kermesses/:id/members", m.CheckAuth, h.AddParticipantAndOrga)
+	r.POST("/kermesses/:id/close", m.CheckAuth, h.CloseKermesse)
+	r.PUT("/kermesses/:id/picture", m.CheckAuth, h.SetKermessePicture)
+	r.GET("/kermesses/:id/refunds", m.CheckAuth, h.GetKermesseRefunds)
+	r.POST("/kermesses/:id/refunds", m.CheckAuth, m.Idempotency, h.RefundKermesse)
+	r.POST("/kermesses/:id/refunds/me", m.CheckAuth, m.Idempotency, h.SettleMyTokens)
+	r.GET("/kermesses/:id/till-sessions", m.CheckAuth, h.GetKermesseTills)
+	r.GET("/kermesses/:id/finances", m.CheckAuth, h.GetKermesseFinances)
 }
 
-func StandRoutes(r *gin.Engine) {
-	r.POST("/create-stand", middlewares.CheckAuth, controllers.CreateStand)
-	r.POST("/stands/:id/interact", middlewares.CheckAuth, controllers.InteractWithStand)
-	r.GET("/stands", middlewares.CheckAuth, controllers.GetAllStands)
-	r.GET("/stands/:id", middlewares.CheckAuth, controllers.GetStandById)
-	r.PUT("/stands/:id/update", middlewares.CheckAuth, controllers.UpdateStand)
-	r.DELETE("/stands/:id/delete", middlewares.CheckAuth, controllers.DeleteStand)
-	r.POST("/stands/:id/products/products/:product_id/buy", middlewares.CheckAuth, controllers.BuyProduct)
-	r.POST("/stands/:id/users/:user_id/points", middlewares.CheckAuth, controllers.GivePoints)
+func StandRoutes(r gin.IRouter, h *controllers.Controller, m *middlewares.Middlewares) {
+	r.POST("/stands", m.CheckAuth, h.CreateStand)
+	r.GET("/stands", m.CheckAuth, h.GetAllStands)
+	r.GET("/stands/:id", m.CheckAuth, h.GetStandById)
+	r.PUT("/stands/:id", m.CheckAuth, h.UpdateStand)
+	r.DELETE("/stands/:id", m.CheckAuth, h.DeleteStand)
+	r.POST("/stands/:id/interactions", m.CheckAuth, m.Idempotency, h.InteractWithStand)
+	r.GET("/stands/:id/products", m.CheckAuth, h.GetStandProducts)
+	r.POST("/stands/:id/products", m.CheckAuth, h.CreateStandProduct)
+	r.POST("/stands/:id/products/:product_id/purchases", m.CheckAuth, m.Idempotency, h.BuyProduct)
+	r.POST("/stands/:id/users/:user_id/points", m.CheckAuth, h.GivePoints)
+	r.GET("/stands/:id/history", m.CheckAuth, h.GetStandHistory)
+	r.POST("/stands/:id/devices", m.CheckAuth, h.RegisterStandDevice)
+	r.GET("/stands/:id/sync-conflicts", m.CheckAuth, h.GetSyncConflicts)
 }
 
-func ProductRoutes(r *gin.Engine) {
-	r.POST("/create-product", middlewares.CheckAuth, controllers.CreateProduct)
-	r.GET("/products", middlewares.CheckAuth, controllers.GetProducts)
-	r.PUT("/products/:id/update", middlewares.CheckAuth, controllers.UpdateProduct)
-	r.DELETE("/products/:id/delete", middlewares.CheckAuth, controllers.DeleteProduct)
+func HistoryRoutes(r gin.IRouter, h *controllers.Controller, m *middlewares.Middlewares) {
+	r.POST("/history/:id/reverse", m.CheckAuth, m.Idempotency, h.ReverseHistory)
 }
 
-func JetonsRoutes(r *gin.Engine) {
-	r.POST("/create-jeton", middlewares.CheckAuth, controllers.CreateJetons)
-	r.GET("/jetons", controllers.GetJetons)
-	r.PUT("/jetons/:id/update", middlewares.CheckAuth, controllers.UpdateJeton)
-	r.DELETE("/jetons/:id/delete", middlewares.CheckAuth, controllers.DeleteJeton)
+func ProductRoutes(r gin.IRouter, h *controllers.Controller, m *middlewares.Middlewares) {
+	r.POST("/products", m.CheckAuth, h.CreateProduct)
+	r.GET("/products", m.CheckAuth, h.GetProducts)
+	r.PUT("/products/:id", m.CheckAuth, h.UpdateProduct)
+	r.DELETE("/products/:id", m.CheckAuth, h.DeleteProduct)
+	r.PUT("/products/:id/picture", m.CheckAuth, h.SetProductPicture)
 }
 
-func PaymentRoutes(r *gin.Engine) {
-	r.POST("/payment", middlewares.CheckAuth, controllers.Payment)
+// Journal d'audit, réservé aux administrateurs
+func AuditRoutes(r gin.IRouter, h *controllers.Controller, m *middlewares.Middlewares) {
+	r.GET("/audit-log", m.CheckAuth, h.GetAuditLog)
+	r.GET("/audit-log/export", m.CheckAuth, h.ExportAuditLog)
 }
 
-func TransactionsRoutes(r *gin.Engine) {
-	r.GET("/transactions", middlewares.CheckAuth, controllers.GetTransactions)
+// Fichiers du stockage local, servis sans authentification à qui présente un lien signé
+func FileRoutes(r gin.IRouter, h *controllers.Controller) {
+	r.GET("/files/*key", h.ServeFile)
 }
 
-func ParentRoutes(r *gin.Engine) {
-	r.POST("/add-children", middlewares.CheckAuth, controllers.AddChildren)
-	r.POST("/api/users/:id/give-coins", middlewares.CheckAuth, controllers.GiveCoins)
+func JetonsRoutes(r gin.IRouter, h *controllers.Controller, m *middlewares.Middlewares) {
+	r.POST("/jetons", m.CheckAuth, h.CreateJetons)
+	r.GET("/jetons", h.GetJetons)
+	r.PUT("/jetons/:id", m.CheckAuth, h.UpdateJeton)
+	r.DELETE("/jetons/:id", m.CheckAuth, h.DeleteJeton)
 }
 
-func ElevesRoutes(r *gin.Engine) {
-	r.GET("/students", middlewares.CheckAuth, controllers.GetStudents)
+func PaymentRoutes(r gin.IRouter, h *controllers.Controller, m *middlewares.Middlewares) {
+	r.POST("/payments", m.CheckAuth, m.Idempotency, h.Payment)
+	r.POST("/payments/:id/confirm", m.CheckAuth, m.Idempotency, h.ConfirmPayment)
+	r.POST("/payments/webhook", h.PaymentWebhook)
+	r.GET("/transactions", m.CheckAuth, h.GetTransactions)
+}
+
+func CashDeskRoutes(r gin.IRouter, h *controllers.Controller, m *middlewares.Middlewares) {
+	r.POST("/till-sessions", m.CheckAuth, h.OpenTill)
+	r.GET("/till-sessions/current", m.CheckAuth, h.GetCurrentTill)
+	r.POST("/till-sessions/current/sales", m.CheckAuth, m.Idempotency, h.SellJetonsAtTill)
+	r.POST("/till-sessions/current/cards", m.CheckAuth, m.Idempotency, h.IssuePrepaidCard)
+	r.POST("/till-sessions/current/close", m.CheckAuth, h.CloseTill)
+	r.GET("/till-sessions/:id/report", m.CheckAuth, h.GetTillReport)
+}
+
+func PrepaidCardRoutes(r gin.IRouter, h *controllers.Controller, m *middlewares.Middlewares) {
+	r.GET("/prepaid-cards/:code", h.GetPrepaidCard)
+	r.POST("/prepaid-cards/:code/link", m.CheckAuth, m.Idempotency, h.LinkPrepaidCard)
+}
+
+func SyncRoutes(r gin.IRouter, h *controllers.Controller, m *middlewares.Middlewares) {
+	r.POST("/sync/devices/:id/operations", m.CheckAuth, h.UploadSyncOperations)
+	r.GET("/sync/devices/:id/changes", m.CheckAuth, h.GetSyncChanges)
+}
+
+// legacyRoute est une route d'avant /api/v1, la route versionnée qui la remplace et la clé sous
+// laquelle elle renvoyait la ressource (vide : la ressource elle-même, voir middlewares.LegacyBody)
+type legacyRoute struct {
+	method, path, successor, key string
+	handlers                     []gin.HandlerFunc
+}
+
+// LegacyRoutes déclare les routes d'avant /api/v1 comme alias dépréciés, le temps que les clients
+// migrent. Elles servent les mêmes handlers avec leur ancienne forme de réponse et signalent la route
+// qui les remplace. Les routes apparues avec /api/v1 n'ont pas d'alias.
+func LegacyRoutes(r *gin.Engine, h *controllers.Controller, m *middlewares.Middlewares) {
+	routes := []legacyRoute{
+		{http.MethodPost, "/signup", "/auth/signup", "user", []gin.HandlerFunc{h.Signup}},
+		{http.MethodPost, "/login", "/auth/login", "", []gin.HandlerFunc{h.Login}},
+		{http.MethodPost, "/logout", "/auth/logout", "", []gin.HandlerFunc{h.Logout}},
+		{http.MethodGet, "/profile", "/me", "user", []gin.HandlerFunc{m.CheckAuth, h.UserProfile}},
+		{http.MethodPut, "/profile/update", "/me", "user", []gin.HandlerFunc{m.CheckAuth, h.UpdateProfile}},
+		{http.MethodPost, "/add-children", "/me/children", "children", []gin.HandlerFunc{m.CheckAuth, h.AddChildren}},
+
+		{http.MethodPost, "/api/users", "/users", "user", []gin.HandlerFunc{m.CheckAuth, h.CreateUser}},
+		{http.MethodGet, "/api/users", "/users", "", []gin.HandlerFunc{m.CheckAuth, h.GetAllUsers}},
+		{http.MethodGet, "/api/users/:id", "/users/:id", "", []gin.HandlerFunc{m.CheckAuth, h.GetUser}},
+		{http.MethodPut, "/api/users/:id", "/users/:id", "", []gin.HandlerFunc{m.CheckAuth, h.UpdateUser}},
+		{http.MethodDelete, "/api/users/:id", "/users/:id", "", []gin.HandlerFunc{m.CheckAuth, h.DeleteUser}},
+		{http.MethodPost, "/api/users/:id/give-coins", "/users/:id/coins", "", []gin.HandlerFunc{m.CheckAuth, m.Idempotency, h.GiveCoins}},
+		{http.MethodGet, "/students", "/students", "students", []gin.HandlerFunc{m.CheckAuth, h.GetStudents}},
+
+		{http.MethodPost, "/create-kermesse", "/kermesses", "kermesse", []gin.HandlerFunc{m.CheckAuth, h.CreateKermesse}},
+		{http.MethodGet, "/kermesses", "/kermesses", "kermesses", []gin.HandlerFunc{m.CheckAuth, h.GetAllKermesses}},
+		{http.MethodGet, "/kermesses/:id", "/kermesses/:id", "kermesse", []gin.HandlerFunc{m.CheckAuth, h.GetKermesseById}},
+		{http.MethodPut, "/kermesses/:id/update", "/kermesses/:id", "kermesse", []gin.HandlerFunc{m.CheckAuth, h.UpdateKermesse}},
+		{http.MethodDelete, "/kermesses/:id/delete", "/kermesses/:id", "", []gin.HandlerFunc{m.CheckAuth, h.DeleteKermesse}},
+		{http.MethodPost, "/kermesses/:id/add-stands", "/kermesses/:id/stands", "stand", []gin.HandlerFunc{m.CheckAuth, h.AddStand}},
+		{http.MethodPost, "/kermesses/:id/add-users", "/kermesses/:id/members", "", []gin.HandlerFunc{m.CheckAuth, h.AddParticipantAndOrga}},
+
+		{http.MethodPost, "/create-stand", "/stands", "stand", []gin.HandlerFunc{m.CheckAuth, h.CreateStand}},
+		{http.MethodGet, "/stands", "/stands", "stands", []gin.HandlerFunc{m.CheckAuth, h.GetAllStands}},
+		{http.MethodGet, "/stands/:id", "/stands/:id", "stand", []gin.HandlerFunc{m.CheckAuth, h.GetStandById}},
+		{http.MethodPut, "/stands/:id/update", "/stands/:id", "stand", []gin.HandlerFunc{m.CheckAuth, h.UpdateStand}},
+		{http.MethodDelete, "/stands/:id/delete", "/stands/:id", "", []gin.HandlerFunc{m.CheckAuth, h.DeleteStand}},
+		{http.MethodPost, "/stands/:id/interact", "/stands/:id/interactions", "", []gin.HandlerFunc{m.CheckAuth, m.Idempotency, h.InteractWithStand}},
+		{http.MethodPost, "/stands/:id/products/products/:product_id/buy", "/stands/:id/products/:product_id/purchases", "", []gin.HandlerFunc{m.CheckAuth, m.Idempotency, h.BuyProduct}},
+		{http.MethodPost, "/stands/:id/users/:user_id/points", "/stands/:id/users/:user_id/points", "", []gin.HandlerFunc{m.CheckAuth, h.GivePoints}},
+
+		{http.MethodPost, "/create-product", "/products", "product", []gin.HandlerFunc{m.CheckAuth, h.CreateProduct}},
+		{http.MethodGet, "/products", "/products", "products", []gin.HandlerFunc{m.CheckAuth, h.GetProducts}},
+		{http.MethodPut, "/products/:id/update", "/products/:id", "product", []gin.HandlerFunc{m.CheckAuth, h.UpdateProduct}},
+		{http.MethodDelete, "/products/:id/delete", "/products/:id", "", []gin.HandlerFunc{m.CheckAuth, h.DeleteProduct}},
+
+		{http.MethodPost, "/create-jeton", "/jetons", "data", []gin.HandlerFunc{m.CheckAuth, h.CreateJetons}},
+		{http.MethodGet, "/jetons", "/jetons", "jetons", []gin.HandlerFunc{h.GetJetons}},
+		{http.MethodPut, "/jetons/:id/update", "/jetons/:id", "data", []gin.HandlerFunc{m.CheckAuth, h.UpdateJeton}},
+		{http.MethodDelete, "/jetons/:id/delete", "/jetons/:id", "", []gin.HandlerFunc{m.CheckAuth, h.DeleteJeton}},
+
+		{http.MethodPost, "/payment", "/payments", "", []gin.HandlerFunc{m.CheckAuth, m.Idempotency, h.Payment}},
+		{http.MethodGet, "/transactions", "/transactions", "transactions", []gin.HandlerFunc{m.CheckAuth, h.GetTransactions}},
+	}
+	for _, route := range routes {
+		r.Handle(route.method, route.path, append([]gin.HandlerFunc{m.Deprecated(V1 + route.successor), m.LegacyBody(route.key)}, route.handlers...)...)
+	}
+}
+
+// Register déclare toutes les routes de l'API sur le serveur, servies par les dépendances de l'application
+func Register(r *gin.Engine, a *app.App) {
+	h := controllers.New(a.Services)
+	m := middlewares.New(a.DB, a.Config.Secret, a.Logger)
+
+	// Errors met en forme les erreurs signalées par les handlers ; Recovery, placé après, y ajoute les paniques
+	r.Use(m.RequestLogger, a.Metrics.Instrument, m.Errors, m.Recovery())
+	r.NoRoute(m.NoRoute)
+	r.GET("/metrics", gin.WrapH(a.Metrics.Handler()))
+	HealthRoutes(r, h)
+
+	v1 := r.Group(V1)
+	AuthRoutes(v1, h, m)
+	UserRoutes(v1, h, m)
+	KermesseRoutes(v1, h, m)
+	StandRoutes(v1, h, m)
+	ProductRoutes(v1, h, m)
+	PaymentRoutes(v1, h, m)
+	JetonsRoutes(v1, h, m)
+	HistoryRoutes(v1, h, m)
+	CashDeskRoutes(v1, h, m)
+	PrepaidCardRoutes(v1, h, m)
+	SyncRoutes(v1, h, m)
+	AuditRoutes(v1, h, m)
+	FileRoutes(v1, h)
+
+	if a.Config.LegacyRoutes {
+		LegacyRoutes(r, h, m)
+	}
 }
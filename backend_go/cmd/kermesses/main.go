@@ -0,0 +1,7 @@
+package main
+
+import "project/internal/cli"
+
+func main() {
+	cli.Execute()
+}
@@ -15,24 +15,21 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/add-children": {
-            "post": {
+        "/api/v1/audit-log": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Permet de créer une relation entre les parents et les enfant",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Actions privilégiées et financières : modifications de comptes et de rôles, ajustements de solde, paiements, remboursements, annulations et suppressions. Réservé aux administrateurs.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Parent"
+                    "Audit"
                 ],
-                "summary": "Créer une relation parents/enfants",
+                "summary": "Journal d'audit",
                 "parameters": [
                     {
                         "type": "string",
@@ -43,46 +40,169 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Ajouter un ou plusieurs enfants",
-                        "name": "parent",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/requests.AddChildrenRequest"
-                        }
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Taille de la page (1 à 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Nombre de lignes à sauter",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-date,-id",
+                        "description": "Tri, séparé par des virgules, - pour décroissant : id, date",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recherche insensible à la casse sur l'auteur et l'IP",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "user.create",
+                            "user.update",
+                            "user.role_change",
+                            "user.password_reset",
+                            "user.delete",
+                            "balance.adjust",
+                            "payment.succeeded",
+                            "refund.create",
+                            "refund.donate",
+                            "history.reverse",
+                            "kermesse.delete",
+                            "kermesse.stand_add",
+                            "stand.delete",
+                            "product.delete",
+                            "jetons_pack.delete"
+                        ],
+                        "type": "string",
+                        "description": "Filtre sur l'action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "user",
+                            "prepaid_card",
+                            "transaction",
+                            "history",
+                            "kermesse",
+                            "stand",
+                            "product",
+                            "jetons_pack"
+                        ],
+                        "type": "string",
+                        "description": "Filtre sur le type d'enregistrement visé",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filtre sur l'enregistrement visé",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filtre sur le compte auteur",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtre sur l'identifiant de requête",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date de début incluse (2006-01-02 ou RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date de fin incluse (2006-01-02 ou RFC 3339)",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/responses.AuditEntryResponse"
+                                            }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/controllers.Meta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètres de liste invalides",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Réservé aux administrateurs",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Erreur serveur interne",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/api/users": {
+        "/api/v1/audit-log/export": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Récupère la liste de tous les utilisateurs",
+                "description": "Toutes les entrées correspondant aux filtres, sans pagination, dans l'ordre des identifiants. Réservé aux administrateurs.",
                 "produces": [
-                    "application/json"
+                    "text/csv"
                 ],
                 "tags": [
-                    "User"
+                    "Audit"
                 ],
-                "summary": "Récupère tous les utilisateurs",
+                "summary": "Exporte le journal d'audit en CSV",
                 "parameters": [
                     {
                         "type": "string",
@@ -91,33 +211,120 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recherche insensible à la casse sur l'auteur et l'IP",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "user.create",
+                            "user.update",
+                            "user.role_change",
+                            "user.password_reset",
+                            "user.delete",
+                            "balance.adjust",
+                            "payment.succeeded",
+                            "refund.create",
+                            "refund.donate",
+                            "history.reverse",
+                            "kermesse.delete",
+                            "kermesse.stand_add",
+                            "stand.delete",
+                            "product.delete",
+                            "jetons_pack.delete"
+                        ],
+                        "type": "string",
+                        "description": "Filtre sur l'action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "user",
+                            "prepaid_card",
+                            "transaction",
+                            "history",
+                            "kermesse",
+                            "stand",
+                            "product",
+                            "jetons_pack"
+                        ],
+                        "type": "string",
+                        "description": "Filtre sur le type d'enregistrement visé",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filtre sur l'enregistrement visé",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filtre sur le compte auteur",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtre sur l'identifiant de requête",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date de début incluse (2006-01-02 ou RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date de fin incluse (2006-01-02 ou RFC 3339)",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Fichier CSV",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètres de liste invalides",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Réservé aux administrateurs",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.User"
-                            }
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Erreur serveur interne",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/auth/login": {
             "post": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "Crée un nouvel utilisateur avec les informations fournies",
+                "description": "login to the app",
                 "consumes": [
                     "application/json"
                 ],
@@ -125,175 +332,233 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Auth"
                 ],
-                "summary": "Crée un nouvel utilisateur",
+                "summary": "Allow you to log and have an JWT Token",
                 "parameters": [
                     {
-                        "type": "string",
-                        "default": "Bearer Add access token here",
-                        "description": "Insert your access token",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
-                    },
-                    {
-                        "description": "Utilisateur à créer",
+                        "description": "User data",
                         "name": "user",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/requests.SignupRequest"
+                            "$ref": "#/definitions/requests.LoginRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "Connexion réussie",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.LoginResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid email or password",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
-                        "description": "Erreur serveur interne",
+                        "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/api/users/{id}": {
-            "get": {
+        "/api/v1/auth/logout": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Récupère les informations d'un utilisateur spécifique",
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Inform the client to delete the token",
                 "tags": [
-                    "User"
+                    "Auth"
                 ],
-                "summary": "Récupère un utilisateur par ID",
+                "summary": "Logout",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "Bearer Add access token here",
+                        "default": "Bearer \u003cAdd access token here\u003e",
                         "description": "Insert your access token",
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Déconnexion réussie"
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/signup": {
+            "post": {
+                "description": "Create a new user with the provided information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Allow you to register as a new User",
+                "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID de l'utilisateur",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "User data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.SignupRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "User created",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.Profile"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "404": {
-                        "description": "Utilisateur non trouvé",
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already used",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
-                        "description": "Erreur serveur interne",
+                        "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
-            },
-            "put": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "Met à jour les informations d'un utilisateur spécifique",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/api/v1/files/{key}": {
+            "get": {
+                "description": "Cible des liens signés renvoyés par l'API quand les photos sont stockées localement. Sans authentification : la signature et l'expiration du lien font foi.",
                 "produces": [
-                    "application/json"
+                    "image/jpeg",
+                    "image/png"
                 ],
                 "tags": [
-                    "User"
+                    "File"
                 ],
-                "summary": "Met à jour un utilisateur par ID",
+                "summary": "Sert un fichier du stockage local",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "Bearer Add access token here",
-                        "description": "Insert your access token",
-                        "name": "Authorization",
-                        "in": "header",
+                        "description": "Clé du fichier",
+                        "name": "key",
+                        "in": "path",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "ID de l'utilisateur",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Expiration du lien, en secondes Unix",
+                        "name": "expires",
+                        "in": "query",
                         "required": true
                     },
                     {
-                        "description": "Utilisateur à mettre à jour",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.User"
-                        }
+                        "type": "string",
+                        "description": "Signature du lien",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Le fichier",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "Lien invalide ou expiré",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "404": {
-                        "description": "Utilisateur non trouvé",
+                        "description": "Fichier non trouvé",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Erreur serveur interne",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/v1/history/{id}/reverse": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Supprime un utilisateur spécifique",
+                "description": "Rend les jetons à l'utilisateur, restaure le stock et la conso du stand et crée une entrée d'annulation liée. Le teneur du stand peut annuler pendant 15 minutes, les organisateurs et admins à tout moment.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "History"
                 ],
-                "summary": "Supprime un utilisateur par ID",
+                "summary": "Annule un achat ou une interaction",
                 "parameters": [
                     {
                         "type": "string",
@@ -303,41 +568,145 @@ const docTemplate = `{
                         "in": "header",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "type": "integer",
-                        "description": "ID de l'utilisateur",
+                        "description": "ID de l'entrée d'historique",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    }
+                    },
+                    {
+                        "description": "Motif de l'annulation",
+                        "name": "reversal",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/requests.ReverseRequest"
+                        }
+                    }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.History"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Entrée non annulable",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
                     },
                     "404": {
-                        "description": "Utilisateur non trouvé",
+                        "description": "Entrée non trouvée",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Déjà annulée",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Corps de requête trop grand",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key déjà utilisée pour une autre requête",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Erreur serveur interne",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/api/users/{id}/give-coins": {
+        "/api/v1/jetons": {
+            "get": {
+                "description": "Récupère la liste de tous les jetons",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jeton"
+                ],
+                "summary": "Récupère tous les jetons",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Jetons"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
             "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Permet à un parent de transférer des jetons à ses enfants",
+                "description": "Crée un nouveau jeton",
                 "consumes": [
                     "application/json"
                 ],
@@ -345,9 +714,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Parent"
+                    "Jeton"
                 ],
-                "summary": "Transférer des jetons aux enfants",
+                "summary": "Crée un nouveau jeton",
                 "parameters": [
                     {
                         "type": "string",
@@ -358,64 +727,69 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "ID de l'enfant",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Détails du transfert de jetons (seulement la quantité de jetons)",
-                        "name": "transaction",
+                        "description": "Jeton à créer",
+                        "name": "user",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/requests.GiveCoinRequest"
+                            "$ref": "#/definitions/models.Jetons"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Jetons"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Mauvaise requête",
+                        "description": "Données invalides",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "401": {
-                        "description": "Non autorisé",
+                        "description": "Non connecté",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "404": {
-                        "description": "Enfant non trouvé",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Erreur serveur interne",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/create-jeton": {
-            "post": {
+        "/api/v1/jetons/{id}": {
+            "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Crée un nouveau jeton",
+                "description": "Met à jour les informations d'un jeton spécifique",
                 "consumes": [
                     "application/json"
                 ],
@@ -425,7 +799,7 @@ const docTemplate = `{
                 "tags": [
                     "Jeton"
                 ],
-                "summary": "Crée un nouveau jeton",
+                "summary": "Met à jour un jeton par ID",
                 "parameters": [
                     {
                         "type": "string",
@@ -436,7 +810,14 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Jeton à créer",
+                        "type": "integer",
+                        "description": "ID de l'utilisateur",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Utilisateur à mettre à jour",
                         "name": "user",
                         "in": "body",
                         "required": true,
@@ -446,39 +827,70 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Jetons"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Jetons"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètre invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Jeton non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Erreur serveur interne",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
-            }
-        },
-        "/create-kermesse": {
-            "post": {
+            },
+            "delete": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Permet aux user de créé un groupe de groupeVoyage",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Supprime un jeton spécifique",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Kermesse"
+                    "Jeton"
                 ],
-                "summary": "Créé une kermesse",
+                "summary": "Supprime un jeton par ID",
                 "parameters": [
                     {
                         "type": "string",
@@ -489,63 +901,58 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Données de la kermesse",
-                        "name": "kermesse",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/requests.KermeseRequest"
-                        }
+                        "type": "integer",
+                        "description": "ID du jeton",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Groupe créé",
-                        "schema": {
-                            "$ref": "#/definitions/gin.H"
-                        }
+                    "204": {
+                        "description": "Jeton supprimé"
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Paramètre invalide",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Non connecté",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "404": {
-                        "description": "Voyage non trouvé",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "404": {
+                        "description": "Jeton non trouvé",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Erreur serveur interne",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/create-product": {
-            "post": {
+        "/api/v1/kermesses": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Crée un nouvel produit avec les informations fournies",
+                "description": "Fetches kermesses for admin, organizers, and participants",
                 "consumes": [
                     "application/json"
                 ],
@@ -553,10 +960,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "Kermesse"
                 ],
-                "summary": "Crée un nouveau produit",
+                "summary": "Get all Kermesses based on user role",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Taille de la page (1 à 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Nombre de lignes à sauter",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Tri, séparé par des virgules, - pour décroissant : id, name, status, closed_at",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recherche insensible à la casse sur le nom",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "open",
+                            "closed"
+                        ],
+                        "type": "string",
+                        "description": "Filtre sur le statut",
+                        "name": "status",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "default": "Bearer Add access token here",
@@ -564,41 +1008,60 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "description": "Produit à créer",
-                        "name": "product",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Product"
-                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "List of kermesses",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/responses.KermesseResponse"
+                                            }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/controllers.Meta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètres de liste invalides",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "User not logged",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
-                        "description": "Erreur serveur interne",
+                        "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
-            }
-        },
-        "/create-stand": {
+            },
             "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Crée un nouveau stand avec les informations fournies",
+                "description": "Permet aux user de créé un groupe de groupeVoyage",
                 "consumes": [
                     "application/json"
                 ],
@@ -606,9 +1069,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Stand"
+                    "Kermesse"
                 ],
-                "summary": "Crée un nouveau stand",
+                "summary": "Créé une kermesse",
                 "parameters": [
                     {
                         "type": "string",
@@ -619,75 +1082,79 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Stand à créer",
-                        "name": "stand",
+                        "description": "Données de la kermesse",
+                        "name": "kermesse",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/requests.StandRequest"
+                            "$ref": "#/definitions/requests.KermeseRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Created",
+                        "description": "Kermesse créée",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.KermesseResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/models.Stand"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
-                        "description": "Erreur serveur interne",
+                        "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/jetons": {
+        "/api/v1/kermesses/{id}": {
             "get": {
-                "description": "Récupère la liste de tous les jetons",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Fetch a kermesse by its ID if the user has permission",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Jeton"
+                    "Kermesse"
                 ],
-                "summary": "Récupère tous les jetons",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Jetons"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Erreur serveur interne",
-                        "schema": {
-                            "$ref": "#/definitions/gin.H"
-                        }
-                    }
-                }
-            }
-        },
-        "/jetons/{id}/delete": {
-            "delete": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "Supprime un jeton spécifique",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Jeton"
-                ],
-                "summary": "Supprime un jeton par ID",
+                "summary": "Get a Kermesse by its ID",
                 "parameters": [
                     {
                         "type": "string",
@@ -699,39 +1166,64 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "ID du jeton",
+                        "description": "Kermesse ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "Kermesse found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.KermesseResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     },
-                    "404": {
-                        "description": "Jeton non trouvé",
+                    "400": {
+                        "description": "Paramètre invalide",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "500": {
-                        "description": "Erreur serveur interne",
+                    "401": {
+                        "description": "User not logged",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Kermesse not found",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
-            }
-        },
-        "/jetons/{id}/update": {
+            },
             "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Met à jour les informations d'un jeton spécifique",
+                "description": "Allows an admin or the creator to update a Kermesse",
                 "consumes": [
                     "application/json"
                 ],
@@ -739,9 +1231,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Jeton"
+                    "Kermesse"
                 ],
-                "summary": "Met à jour un jeton par ID",
+                "summary": "Update a Kermesse",
                 "parameters": [
                     {
                         "type": "string",
@@ -753,51 +1245,79 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "ID de l'utilisateur",
+                        "description": "Kermesse ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Utilisateur à mettre à jour",
-                        "name": "user",
+                        "description": "Kermesse data",
+                        "name": "kermesse",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Jetons"
+                            "$ref": "#/definitions/requests.KermeseRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Kermesse updated",
                         "schema": {
-                            "$ref": "#/definitions/models.Jetons"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.KermesseResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètre invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "User not logged",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "404": {
-                        "description": "Jeton non trouvé",
+                        "description": "Kermesse not found",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
-                        "description": "Erreur serveur interne",
+                        "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
-            }
-        },
-        "/kermesses": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Fetches kermesses for admin, organizers, and participants",
+                "description": "Allows an admin or the creator to update a Kermesse",
                 "consumes": [
                     "application/json"
                 ],
@@ -807,7 +1327,7 @@ const docTemplate = `{
                 "tags": [
                     "Kermesse"
                 ],
-                "summary": "Get all Kermesses based on user role",
+                "summary": "Delete a Kermesse",
                 "parameters": [
                     {
                         "type": "string",
@@ -816,51 +1336,67 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Kermesse ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "List of kermesses",
+                    "204": {
+                        "description": "Kermesse supprimée"
+                    },
+                    "400": {
+                        "description": "Paramètre invalide",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Kermesse"
-                            }
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "401": {
                         "description": "User not logged",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Kermesse not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/kermesses/{id}": {
-            "get": {
+        "/api/v1/kermesses/{id}/close": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Fetch a kermesse by its ID if the user has permission",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Marque la kermesse comme terminée : les jetons non dépensés peuvent ensuite être remboursés",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Kermesse"
                 ],
-                "summary": "Get a Kermesse by its ID",
+                "summary": "Clôture une kermesse",
                 "parameters": [
                     {
                         "type": "string",
@@ -880,50 +1416,77 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Kermesse found",
+                        "description": "Kermesse clôturée",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.KermesseResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid kermesse ID",
                         "schema": {
-                            "$ref": "#/definitions/models.Kermesse"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "401": {
                         "description": "User not logged",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "404": {
                         "description": "Kermesse not found",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Kermesse déjà clôturée",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/kermesses/{id}/add-stands": {
-            "post": {
+        "/api/v1/kermesses/{id}/finances": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Permet d'ajouter des users (partcicpants ou organisateurs)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Ventes en ligne, en caisse et sur cartes prépayées, remboursements, dons, dépenses sur les stands et soldes des cartes prépayées",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Kermesse"
                 ],
-                "summary": "Ajouter des stands à la kermesse",
+                "summary": "Bilan financier d'une kermesse",
                 "parameters": [
                     {
                         "type": "string",
@@ -939,58 +1502,55 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Données du groupe",
-                        "name": "kermesse",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/requests.AddStandRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Stand ajouté",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.KermesseFinances"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Paramètre invalide",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/gin.H"
-                        }
-                    },
-                    "404": {
-                        "description": "kermesse non trouvé",
+                        "description": "Non connecté",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "404": {
+                        "description": "Kermesse not found",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/kermesses/{id}/add-users": {
+        "/api/v1/kermesses/{id}/members": {
             "post": {
                 "security": [
                     {
@@ -1038,52 +1598,67 @@ const docTemplate = `{
                     "200": {
                         "description": "User ajouté(s)",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/responses.PublicUser"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
                         "description": "Bad request",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "404": {
-                        "description": "kermesse non trouvé",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "404": {
+                        "description": "kermesse non trouvé",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/kermesses/{id}/delete": {
-            "delete": {
+        "/api/v1/kermesses/{id}/picture": {
+            "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Allows an admin or the creator to update a Kermesse",
+                "description": "Envoi multipart d'une image, réservé aux organisateurs de la kermesse",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
@@ -1091,7 +1666,7 @@ const docTemplate = `{
                 "tags": [
                     "Kermesse"
                 ],
-                "summary": "Delete a Kermesse",
+                "summary": "Change la photo d'une kermesse",
                 "parameters": [
                     {
                         "type": "string",
@@ -1103,64 +1678,92 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Kermesse ID",
+                        "description": "ID de la kermesse",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image JPEG, PNG ou GIF",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Kermesse delete",
+                        "description": "Liens signés de la photo",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.PictureResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Fichier absent, illisible ou d'un type non accepté",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "401": {
-                        "description": "User not logged",
+                        "description": "Non connecté",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "403": {
-                        "description": "Forbidden",
+                        "description": "Réservé aux organisateurs",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "404": {
-                        "description": "Kermesse not found",
+                        "description": "Kermesse non trouvée",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Fichier ou image trop grand",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Erreur serveur interne",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/kermesses/{id}/update": {
-            "put": {
+        "/api/v1/kermesses/{id}/refunds": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Allows an admin or the creator to update a Kermesse",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Calcule pour chaque famille la valeur des jetons non dépensés achetés pour la kermesse, au prix payé lors des achats",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Kermesse"
+                    "Refund"
                 ],
-                "summary": "Update a Kermesse",
+                "summary": "Aperçu des remboursements de fin de kermesse",
                 "parameters": [
                     {
                         "type": "string",
@@ -1176,117 +1779,180 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Kermesse data",
-                        "name": "kermesse",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Kermesse"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Kermesse updated",
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/services.FamilyRefund"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètre invalide",
                         "schema": {
-                            "$ref": "#/definitions/models.Kermesse"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "401": {
-                        "description": "User not logged",
+                        "description": "Non connecté",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "404": {
                         "description": "Kermesse not found",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
-            }
-        },
-        "/login": {
+            },
             "post": {
-                "description": "login to the app",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "Bearer": []
+                    }
                 ],
+                "description": "Émet, pour chaque famille de la kermesse clôturée, un remboursement carte sur les paiements faits pour cette kermesse et débite les jetons remboursés. Les autres jetons restent sur les comptes.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Refund"
                 ],
-                "summary": "Allow you to log and have an JWT Token",
+                "summary": "Rembourse les jetons non dépensés de toutes les familles",
                 "parameters": [
                     {
-                        "description": "User data",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/requests.LoginRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Connexion réussie",
-                        "schema": {
-                            "$ref": "#/definitions/gin.H"
-                        }
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Kermesse ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/services.FamilyRefund"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Paramètre invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "404": {
-                        "description": "Bad request",
+                        "description": "Kermesse not found",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "409": {
-                        "description": "Conflict",
+                        "description": "Kermesse non clôturée",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Corps de requête trop grand",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key déjà utilisée pour une autre requête",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/logout": {
+        "/api/v1/kermesses/{id}/refunds/me": {
             "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Inform the client to delete the token",
+                "description": "Le parent choisit entre un remboursement carte des jetons non dépensés ou un don à l'école",
                 "consumes": [
                     "application/json"
                 ],
@@ -1294,121 +1960,126 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Refund"
                 ],
-                "summary": "Logout",
+                "summary": "Rembourse ou donne le solde de jetons de sa famille",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "default": "Bearer Add access token here",
                         "description": "Insert your access token",
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Kermesse ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Don à l'école plutôt que remboursement",
+                        "name": "settle",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.SettleTokensRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Déconnexion réussie",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.FamilyRefund"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
                         "description": "Bad request",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "404": {
-                        "description": "Bad request",
+                    "401": {
+                        "description": "Non connecté",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "404": {
+                        "description": "Kermesse not found",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
-                    }
-                }
-            }
-        },
-        "/payment": {
-            "post": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "Crée une intention de paiement pour acheter des jetons ou des tickets de tombola",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Payment"
-                ],
-                "summary": "Crée une intention de paiement pour les jetons ou les tickets de tombola",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "default": "Bearer Add access token here",
-                        "description": "Insert your access token",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
                     },
-                    {
-                        "description": "Paiement des jetons ou tombola",
-                        "name": "payment",
-                        "in": "body",
-                        "required": true,
+                    "409": {
+                        "description": "Kermesse non clôturée",
                         "schema": {
-                            "$ref": "#/definitions/requests.PaymentRequest"
+                            "$ref": "#/definitions/apperror.Response"
                         }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
+                    },
+                    "413": {
+                        "description": "Corps de requête trop grand",
                         "schema": {
-                            "$ref": "#/definitions/models.Transaction"
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key déjà utilisée pour une autre requête",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
-                        "description": "Erreur serveur interne",
+                        "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/products": {
+        "/api/v1/kermesses/{id}/stands": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Récupère la liste de tous les produits",
+                "description": "Récupère les stands rattachés à la kermesse, pour ceux qui peuvent voir la kermesse",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "Kermesse"
                 ],
-                "summary": "Récupère tous les produits",
+                "summary": "Liste les stands d'une kermesse",
                 "parameters": [
                     {
                         "type": "string",
@@ -1417,42 +2088,122 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Kermesse ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Taille de la page (1 à 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Nombre de lignes à sauter",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Tri, séparé par des virgules, - pour décroissant : id, name, type, jetons_requis, conso",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recherche insensible à la casse sur le nom",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtre sur le type de stand",
+                        "name": "type",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Product"
-                            }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Stand"
+                                            }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/controllers.Meta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètres de liste invalides",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "kermesse non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
-                        "description": "Erreur serveur interne",
+                        "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
-            }
-        },
-        "/products/{id}/delete": {
-            "delete": {
+            },
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Supprime un utilisateur spécifique",
+                "description": "Permet d'ajouter des users (partcicpants ou organisateurs)",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "Kermesse"
                 ],
-                "summary": "Supprime un produit par ID",
+                "summary": "Ajouter des stands à la kermesse",
                 "parameters": [
                     {
                         "type": "string",
@@ -1464,49 +2215,91 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "ID de du produit",
+                        "description": "Kermesse ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Données du groupe",
+                        "name": "kermesse",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.AddStandRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "Stands ajoutés",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Stand"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
                     },
                     "404": {
-                        "description": "produit non trouvé",
+                        "description": "kermesse non trouvé",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
-                        "description": "Erreur serveur interne",
+                        "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/products/{id}/update": {
-            "put": {
+        "/api/v1/kermesses/{id}/till-sessions": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Met à jour les informations d'un produit spécifique",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Liste les sessions de caisse d'une kermesse avec leurs écarts (organisateurs et admins)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "Caisse"
                 ],
-                "summary": "Met à jour un produit par son ID",
+                "summary": "Rapports de caisse d'une kermesse",
                 "parameters": [
                     {
                         "type": "string",
@@ -1518,44 +2311,62 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "ID de l'utilisateur",
+                        "description": "Kermesse ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Produit à mettre à jour",
-                        "name": "product",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Product"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/services.TillReport"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "404": {
-                        "description": "Prodduit non trouvé",
+                    "400": {
+                        "description": "Paramètre invalide",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "500": {
-                        "description": "Erreur serveur interne",
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Kermesse not found",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/profile": {
+        "/api/v1/me": {
             "get": {
                 "security": [
                     {
@@ -1587,19 +2398,29 @@ const docTemplate = `{
                     "200": {
                         "description": "Success",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.Profile"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
-            }
-        },
-        "/profile/update": {
+            },
             "put": {
                 "security": [
                     {
@@ -1640,103 +2461,152 @@ const docTemplate = `{
                     "200": {
                         "description": "Profil mis à jour avec succès",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.Profile"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
                         "description": "Erreur de validation",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "401": {
                         "description": "Non autorisé",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Email déjà utilisé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Erreur du serveur",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/signup": {
+        "/api/v1/me/children": {
             "post": {
-                "description": "Create a new user with the provided information",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Permet de créer une relation entre les parents et les enfant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Parent"
                 ],
-                "summary": "Allow you to register as a new User",
+                "summary": "Créer une relation parents/enfants",
                 "parameters": [
                     {
-                        "description": "User data",
-                        "name": "user",
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Ajouter un ou plusieurs enfants",
+                        "name": "parent",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/requests.SignupRequest"
+                            "$ref": "#/definitions/requests.AddChildrenRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "User created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/requests.SignupRequest"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/responses.PublicUser"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Aucun enfant trouvé",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "404": {
-                        "description": "Bad request",
+                    "401": {
+                        "description": "Non connecté",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "403": {
+                        "description": "Réservé aux parents",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Erreur serveur interne",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/stands": {
-            "get": {
+        "/api/v1/me/picture": {
+            "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Récupère la liste de tous les stands",
+                "description": "Envoi multipart d'une image JPEG, PNG ou GIF. Elle est réduite, une miniature est produite et les métadonnées EXIF sont retirées.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Stand"
+                    "Auth"
                 ],
-                "summary": "Récupère tous les produits",
+                "summary": "Change la photo du compte connecté",
                 "parameters": [
                     {
                         "type": "string",
@@ -1745,42 +2615,79 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image JPEG, PNG ou GIF",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Liens signés de la photo",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.PictureResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Fichier absent, illisible ou d'un type non accepté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Fichier ou image trop grand",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Stand"
-                            }
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Erreur serveur interne",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/stands/{id}": {
-            "get": {
+        "/api/v1/payments": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Récupère les informations d'un utilisateur spécifique",
+                "description": "Crée une intention de paiement et une transaction en attente. Les jetons sont crédités à la confirmation du paiement (POST /payment/{id}/confirm ou webhook).",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Stand"
+                    "Payment"
                 ],
-                "summary": "Récupère un stand par ID",
+                "summary": "Crée une intention de paiement pour les jetons ou les tickets de tombola",
                 "parameters": [
                     {
                         "type": "string",
@@ -1791,112 +2698,151 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "ID de l'utilisateur",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Paiement des jetons ou tombola",
+                        "name": "payment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.PaymentRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Stand"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.PaymentResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "404": {
-                        "description": "Stand non trouvé",
+                    "400": {
+                        "description": "Type de paiement invalide",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Requête de même Idempotency-Key en cours",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Corps de requête trop grand",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key déjà utilisée pour une autre requête",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Erreur serveur interne",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Fournisseur de paiement indisponible",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/stands/{id}/delete": {
-            "delete": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
+        "/api/v1/payments/webhook": {
+            "post": {
+                "description": "Reçoit les événements signés du fournisseur (paiement réussi ou échoué)",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Supprime un stand spécifique",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Stand"
+                    "Payment"
                 ],
-                "summary": "Supprime un stand par ID",
+                "summary": "Webhook du fournisseur de paiement",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "Bearer Add access token here",
-                        "description": "Insert your access token",
-                        "name": "Authorization",
+                        "description": "Signature de l'événement",
+                        "name": "Stripe-Signature",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "ID de du stand",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "404": {
-                        "description": "stand non trouvé",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.WebhookResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "500": {
-                        "description": "Erreur serveur interne",
+                    "400": {
+                        "description": "Signature invalide",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/stands/{id}/interact": {
+        "/api/v1/payments/{id}/confirm": {
             "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Permet d'interagir avec un stand en échange de jetons",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Vérifie auprès du fournisseur que le paiement a abouti et crédite les jetons",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Stand"
+                    "Payment"
                 ],
-                "summary": "Interagir avec un stand",
+                "summary": "Confirme un paiement",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID du stand",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "string",
                         "default": "Bearer Add access token here",
@@ -1904,93 +2850,158 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de la transaction",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Stand"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Transaction"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètre invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Transaction non trouvée",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Requête de même Idempotency-Key en cours",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Corps de requête trop grand",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key déjà utilisée pour une autre requête",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Erreur serveur interne",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Fournisseur de paiement indisponible",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/stands/{id}/update": {
-            "put": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "Met à jour les informations d'un stand spécifique",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/v1/prepaid-cards/{code}": {
+            "get": {
+                "description": "Retourne le solde de la carte à partir du code imprimé (sans compte : le code fait office de justificatif)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Stand"
+                    "PrepaidCard"
                 ],
-                "summary": "Met à jour un stand par ID",
+                "summary": "Solde d'une carte prépayée",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "Bearer Add access token here",
-                        "description": "Insert your access token",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "ID de l'utilisateur",
-                        "name": "id",
+                        "description": "Code de la carte",
+                        "name": "code",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Stand à mettre à jours",
-                        "name": "stand",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Stand"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Stand"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.PrepaidCard"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "404": {
-                        "description": "Stand non trouvé",
+                        "description": "Carte non trouvée",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
-                    "500": {
-                        "description": "Erreur serveur interne",
+                    "422": {
+                        "description": "Carte désactivée",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/stands/{stand_id}/products/{product_id}/buy": {
+        "/api/v1/prepaid-cards/{code}/link": {
             "post": {
-                "description": "Permet à un utilisateur d'acheter un produit sur un stand avec ses jetons",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Lie la carte au compte connecté ; avec merge_balance, le solde de la carte est transféré sur le compte",
                 "consumes": [
                     "application/json"
                 ],
@@ -1998,81 +3009,117 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Stand"
+                    "PrepaidCard"
                 ],
-                "summary": "Achat d'un produit sur un stand",
+                "summary": "Rattache une carte prépayée à son compte",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "default": "Bearer Add access token here",
                         "description": "Insert your access token",
                         "name": "Authorization",
                         "in": "header",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "ID du stand",
-                        "name": "stand_id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     },
                     {
-                        "type": "integer",
-                        "description": "ID du produit",
-                        "name": "product_id",
+                        "type": "string",
+                        "description": "Code de la carte",
+                        "name": "code",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Quantité de produit à acheter",
-                        "name": "quantity",
+                        "description": "Transfert du solde",
+                        "name": "link",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/requests.QuantityProductRequest"
+                            "$ref": "#/definitions/requests.LinkCardRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.PrepaidCard"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Requête invalide",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Carte non trouvée",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Carte déjà rattachée à un autre compte, ou utilisée pendant le rattachement",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Corps de requête trop grand",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Carte désactivée",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/stands/{stand_id}/users/{user_id}/points": {
-            "post": {
-                "description": "Un stand peut attribuer des points à un utilisateur en fonction du type de stand",
-                "consumes": [
-                    "application/json"
+        "/api/v1/products": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
                 ],
+                "description": "Récupère la liste de tous les produits",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Stand"
+                    "Product"
                 ],
-                "summary": "Attribue des points à un utilisateur depuis un stand",
+                "summary": "Récupère tous les produits",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "default": "Bearer Add access token here",
                         "description": "Insert your access token",
                         "name": "Authorization",
                         "in": "header",
@@ -2080,65 +3127,201 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "ID du stand",
-                        "name": "stand_id",
-                        "in": "path",
-                        "required": true
+                        "default": 50,
+                        "description": "Taille de la page (1 à 200)",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "ID de l'utilisateur",
-                        "name": "user_id",
-                        "in": "path",
+                        "default": 0,
+                        "description": "Nombre de lignes à sauter",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Tri, séparé par des virgules, - pour décroissant : id, name, type, jetons_requis, nb_products, updated_at",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recherche insensible à la casse sur le nom",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Produits du stand",
+                        "name": "stand_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtre sur le type de produit",
+                        "name": "type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/responses.ProductResponse"
+                                            }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/controllers.Meta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètres de liste invalides",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Crée un nouvel produit avec les informations fournies",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Crée un nouveau produit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
                         "required": true
                     },
                     {
-                        "description": "Nombre de points à attribuer",
-                        "name": "points",
+                        "description": "Produit à créer",
+                        "name": "product",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/requests.GivePointsRequest"
+                            "$ref": "#/definitions/models.Product"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Success",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.ProductResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Données invalides",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Stand non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
         },
-        "/students": {
-            "get": {
+        "/api/v1/products/{id}": {
+            "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Récupère la liste de tous les utilisateurs",
+                "description": "Met à jour les informations d'un produit spécifique",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Student"
+                    "Product"
                 ],
-                "summary": "Récupère tous les utilisateurs avec le rôle d'élève",
+                "summary": "Met à jour un produit par son ID",
                 "parameters": [
                     {
                         "type": "string",
@@ -2147,42 +3330,89 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de l'utilisateur",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Produit à mettre à jour",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.User"
-                            }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.ProductResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètre invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Prodduit non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Erreur serveur interne",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
-            }
-        },
-        "/transactions": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Récupère la liste de toutes les transactions",
+                "description": "Supprime un utilisateur spécifique",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Transactions"
+                    "Product"
                 ],
-                "summary": "Récupère toutes les transactions faites par le user",
+                "summary": "Supprime un produit par ID",
                 "parameters": [
                     {
                         "type": "string",
@@ -2191,87 +3421,4057 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de du produit",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "Produit supprimé"
+                    },
+                    "400": {
+                        "description": "Paramètre invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "produit non trouvé",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Transaction"
-                            }
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     },
                     "500": {
                         "description": "Erreur serveur interne",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/apperror.Response"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "gin.H": {
-            "type": "object",
-            "additionalProperties": {}
-        },
-        "models.History": {
-            "type": "object",
-            "properties": {
-                "date": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "nb_jetons": {
-                    "type": "integer"
-                },
-                "stand_name": {
-                    "type": "string"
-                },
-                "user_id": {
-                    "type": "integer"
-                }
-            }
-        },
-        "models.Jetons": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "integer"
-                },
-                "nb_jetons": {
-                    "type": "integer"
-                },
-                "price": {
-                    "type": "number"
-                }
-            }
         },
-        "models.Kermesse": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "integer"
-                },
+        "/api/v1/products/{id}/picture": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Envoi multipart d'une image, réservé aux administrateurs",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Change la photo d'un produit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID du produit",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image JPEG, PNG ou GIF",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Liens signés de la photo",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.PictureResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Fichier absent, illisible ou d'un type non accepté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Réservé aux administrateurs",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Produit non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Fichier ou image trop grand",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stands": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Récupère la liste de tous les stands",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stand"
+                ],
+                "summary": "Récupère tous les produits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Taille de la page (1 à 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Nombre de lignes à sauter",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Tri, séparé par des virgules, - pour décroissant : id, name, type, jetons_requis, conso",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recherche insensible à la casse sur le nom",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtre sur le type de stand",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Stands de la kermesse",
+                        "name": "kermesse_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Stand"
+                                            }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/controllers.Meta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètres de liste invalides",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Crée un nouveau stand avec les informations fournies",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stand"
+                ],
+                "summary": "Crée un nouveau stand",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Stand à créer",
+                        "name": "stand",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.StandRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Stand"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Données invalides",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stands/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Récupère les informations d'un utilisateur spécifique",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stand"
+                ],
+                "summary": "Récupère un stand par ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de l'utilisateur",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Stand"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètre invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Stand non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Met à jour les informations d'un stand spécifique",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stand"
+                ],
+                "summary": "Met à jour un stand par ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de l'utilisateur",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Stand à mettre à jour",
+                        "name": "stand",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.UpdateStandRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Stand"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètre invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Stand non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Supprime un stand spécifique",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stand"
+                ],
+                "summary": "Supprime un stand par ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de du stand",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Stand supprimé"
+                    },
+                    "400": {
+                        "description": "Paramètre invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "stand non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stands/{id}/devices": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Déclare une tablette ou un téléphone du stand pour la vente hors ligne. Le secret de signature n'est renvoyé qu'à cette occasion.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sync"
+                ],
+                "summary": "Enregistre un appareil de stand",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID du stand",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Nom de l'appareil",
+                        "name": "device",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.RegisterDeviceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.RegisterDeviceResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètre invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Stand non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stands/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Liste les achats, interactions et annulations d'un stand (teneur, organisateurs et admins)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "History"
+                ],
+                "summary": "Historique des opérations d'un stand",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID du stand",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.History"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètre invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Stand non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stands/{id}/interactions": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Permet d'interagir avec un stand en échange de jetons",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stand"
+                ],
+                "summary": "Interagir avec un stand",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID du stand",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.InteractionResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètre invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Stand non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Requête de même Idempotency-Key en cours",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Corps de requête trop grand",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Pas assez de jetons",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stands/{id}/products": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Récupère le stock du stand, visible de tout utilisateur connecté",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Liste les produits d'un stand",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID du stand",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Taille de la page (1 à 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Nombre de lignes à sauter",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Tri, séparé par des virgules, - pour décroissant : id, name, type, jetons_requis, nb_products, updated_at",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recherche insensible à la casse sur le nom",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtre sur le type de produit",
+                        "name": "type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/responses.ProductResponse"
+                                            }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/controllers.Meta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètres de liste invalides",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Stand non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Crée un produit dans le stock du stand désigné par le chemin ; le stand_id du corps est ignoré",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Ajoute un produit à un stand",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID du stand",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Produit à créer",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.ProductResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Données invalides",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Stand non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stands/{id}/products/{product_id}/purchases": {
+            "post": {
+                "description": "Permet à un utilisateur d'acheter un produit sur un stand avec ses jetons. Avec card_code, le teneur du stand débite la carte prépayée d'un visiteur.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stand"
+                ],
+                "summary": "Achat d'un produit sur un stand",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID du stand",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID du produit",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Quantité de produit à acheter",
+                        "name": "quantity",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.QuantityProductRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.History"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Stand, produit ou carte non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Requête de même Idempotency-Key en cours",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Corps de requête trop grand",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Stock ou jetons insuffisants, carte inutilisable",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stands/{id}/sync-conflicts": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Liste les opérations hors ligne valides qui n'ont pas pu être appliquées (solde ou stock insuffisant)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sync"
+                ],
+                "summary": "Conflits de synchronisation d'un stand",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID du stand",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.SyncOperation"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètre invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Stand non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stands/{id}/users/{user_id}/points": {
+            "post": {
+                "description": "Un stand peut attribuer des points à un utilisateur en fonction du type de stand",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stand"
+                ],
+                "summary": "Attribue des points à un utilisateur depuis un stand",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID du stand",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de l'utilisateur",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Nombre de points à attribuer",
+                        "name": "points",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.GivePointsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.GivePointsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Stand ou utilisateur non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/students": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Récupère la liste de tous les utilisateurs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Student"
+                ],
+                "summary": "Récupère tous les utilisateurs avec le rôle d'élève",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Taille de la page (1 à 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Nombre de lignes à sauter",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "lastname,firstname",
+                        "description": "Tri, séparé par des virgules, - pour décroissant : id, firstname, lastname, email, jetons",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Élèves participant à la kermesse",
+                        "name": "kermesse_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/responses.PublicUser"
+                                            }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/controllers.Meta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètres de liste invalides",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sync/devices/{id}/changes": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Retourne les produits du stand et les soldes des comptes et cartes prépayées modifiés depuis le curseur, ainsi que le nouveau curseur",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sync"
+                ],
+                "summary": "Télécharge les mises à jour du catalogue et des soldes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de l'appareil",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Curseur renvoyé par la synchronisation précédente",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.SyncChanges"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Curseur invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Appareil non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sync/devices/{id}/operations": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Applique un lot d'achats et d'attributions de points signés par l'appareil. Chaque opération est appliquée, rejetée ou signalée en conflit ; un renvoi de la même opération (même client_id) retourne le résultat déjà enregistré. Une opération invalide ou mal signée n'est pas enregistrée (id 0) et une opération rejetée peut être renvoyée.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sync"
+                ],
+                "summary": "Envoie les opérations enregistrées hors ligne",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de l'appareil",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Opérations hors ligne",
+                        "name": "operations",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.SyncUploadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.SyncOperation"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Appareil non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/till-sessions": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Le caissier ouvre sa caisse pour une kermesse avec un fond de caisse",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Caisse"
+                ],
+                "summary": "Ouvre une session de caisse",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Kermesse et fond de caisse",
+                        "name": "till",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.OpenTillRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.TillSession"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Caisse déjà ouverte",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/till-sessions/current": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Retourne la session ouverte du caissier avec ses ventes et le montant attendu dans le tiroir",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Caisse"
+                ],
+                "summary": "Session de caisse en cours",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.TillReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Aucune caisse ouverte",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/till-sessions/current/cards": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Crée une carte prépayée anonyme pour la kermesse de la caisse, chargée immédiatement si un pack de jetons est indiqué",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Caisse"
+                ],
+                "summary": "Émet une carte prépayée",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Pack de jetons et moyen de paiement pour la première recharge",
+                        "name": "card",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/requests.IssueCardRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.IssueCardResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Pack ou caisse non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Requête de même Idempotency-Key en cours",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Corps de requête trop grand",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key déjà utilisée pour une autre requête",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/till-sessions/current/close": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Enregistre le comptage du tiroir et calcule l'écart avec le montant attendu",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Caisse"
+                ],
+                "summary": "Ferme la session de caisse",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Comptage du tiroir",
+                        "name": "till",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.CloseTillRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.TillReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Aucune caisse ouverte",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/till-sessions/current/sales": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Vend un ou plusieurs packs de jetons payés en espèces ou au terminal carte et crédite le compte de la famille (user_id) ou une carte prépayée (card_code)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Caisse"
+                ],
+                "summary": "Vend des jetons en caisse",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Client, pack de jetons et moyen de paiement",
+                        "name": "sale",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.TillSaleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Transaction"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Client, carte, pack ou caisse non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Requête de même Idempotency-Key en cours",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Corps de requête trop grand",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key déjà utilisée pour une autre requête",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/till-sessions/{id}/report": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Ventes, montant attendu, comptage et écart d'une session (caissier, organisateurs et admins)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Caisse"
+                ],
+                "summary": "Rapport d'une session de caisse",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de la session",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.TillReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètre invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Session non trouvée",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/transactions": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Récupère la liste de toutes les transactions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Récupère toutes les transactions faites par le user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Taille de la page (1 à 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Nombre de lignes à sauter",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-date,-id",
+                        "description": "Tri, séparé par des virgules, - pour décroissant : id, date, price, quantity",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "jetons",
+                            "tombola",
+                            "refund",
+                            "donation"
+                        ],
+                        "type": "string",
+                        "description": "Filtre sur le type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "succeeded",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Filtre sur le statut",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Transactions de la kermesse",
+                        "name": "kermesse_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Début de la période (2006-01-02 ou RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fin de la période, incluse",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Transaction"
+                                            }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/controllers.Meta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètres de liste invalides",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Récupère la liste de tous les utilisateurs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Récupère tous les utilisateurs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Taille de la page (1 à 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Nombre de lignes à sauter",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Tri, séparé par des virgules, - pour décroissant : id, firstname, lastname, email, role, jetons",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recherche insensible à la casse sur le prénom, le nom et l'email",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filtre sur le rôle",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Membres de la kermesse",
+                        "name": "kermesse_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/responses.AdminUser"
+                                            }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/controllers.Meta"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètres de liste invalides",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Réservé aux administrateurs",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Crée un nouvel utilisateur avec les informations fournies",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Crée un nouvel utilisateur",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Utilisateur à créer",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.SignupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.AdminUser"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Requête invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Réservé aux administrateurs",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Email déjà utilisé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Récupère les informations d'un utilisateur spécifique",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Récupère un utilisateur par ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de l'utilisateur",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.AdminUser"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Paramètre invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Réservé aux administrateurs",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Utilisateur non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Met à jour les informations d'un utilisateur spécifique",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Met à jour un utilisateur par ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de l'utilisateur",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Champs à mettre à jour",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.AdminUser"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Requête invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Réservé aux administrateurs",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Utilisateur non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Email déjà utilisé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Supprime un utilisateur spécifique",
+                "tags": [
+                    "User"
+                ],
+                "summary": "Supprime un utilisateur par ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de l'utilisateur",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Utilisateur supprimé"
+                    },
+                    "400": {
+                        "description": "Paramètre invalide",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Réservé aux administrateurs",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Utilisateur non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{id}/coins": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Permet à un parent de transférer des jetons à ses enfants",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Parent"
+                ],
+                "summary": "Transférer des jetons aux enfants",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer Add access token here",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clé unique par tentative : une requête renvoyée avec la même clé rejoue la réponse d'origine",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de l'enfant",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Détails du transfert de jetons (seulement la quantité de jetons)",
+                        "name": "transaction",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.GiveCoinRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.GiveCoinResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Mauvaise requête",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Non connecté",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Non autorisé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Enfant non trouvé",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Requête de même Idempotency-Key en cours",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Corps de requête trop grand",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Pas assez de jetons",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Erreur serveur interne",
+                        "schema": {
+                            "$ref": "#/definitions/apperror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Vérifie que le processus répond",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/responses.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "La base de données doit répondre et toutes les migrations doivent y être appliquées",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Vérifie que l'API peut servir des requêtes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/responses.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Base injoignable ou migrations en attente",
+                        "schema": {
+                            "$ref": "#/definitions/responses.HealthResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "apperror.Body": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "stand_not_found"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apperror.FieldError"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "stand not found"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/apperror.Kind"
+                        }
+                    ],
+                    "example": "not_found"
+                }
+            }
+        },
+        "apperror.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "param": {
+                    "type": "string"
+                }
+            }
+        },
+        "apperror.Kind": {
+            "type": "string",
+            "enum": [
+                "validation",
+                "unauthorized",
+                "forbidden",
+                "not_found",
+                "conflict",
+                "unprocessable",
+                "insufficient_funds",
+                "out_of_stock",
+                "too_large",
+                "unavailable",
+                "internal"
+            ],
+            "x-enum-varnames": [
+                "KindValidation",
+                "KindUnauthorized",
+                "KindForbidden",
+                "KindNotFound",
+                "KindConflict",
+                "KindUnprocessable",
+                "KindInsufficientFunds",
+                "KindOutOfStock",
+                "KindTooLarge",
+                "KindUnavailable",
+                "KindInternal"
+            ]
+        },
+        "apperror.Response": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/apperror.Body"
+                }
+            }
+        },
+        "audit.Change": {
+            "type": "object",
+            "properties": {
+                "from": {},
+                "to": {}
+            }
+        },
+        "controllers.Envelope": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "meta": {
+                    "$ref": "#/definitions/controllers.Meta"
+                }
+            }
+        },
+        "controllers.Meta": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.History": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "nb_jetons": {
+                    "type": "integer"
+                },
+                "operator_id": {
+                    "type": "integer",
+                    "x-nullable": true
+                },
+                "prepaid_card_id": {
+                    "description": "Achat payé avec une carte prépayée (UserID est alors le compte lié à la carte, s'il existe)",
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer",
+                    "x-nullable": true
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reversal_of_id": {
+                    "type": "integer",
+                    "x-nullable": true
+                },
+                "reversed_at": {
+                    "description": "Annulation : l'entrée d'origine est marquée, l'entrée \"reversal\" pointe vers elle",
+                    "type": "string",
+                    "x-nullable": true
+                },
+                "stand_id": {
+                    "type": "integer"
+                },
+                "stand_name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "x-nullable": true
+                }
+            }
+        },
+        "models.Jetons": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "nb_jetons": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Kermesse": {
+            "type": "object",
+            "properties": {
+                "closed_at": {
+                    "type": "string",
+                    "x-nullable": true
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "organisateurs": {
+                    "description": "Relations Many-to-Many : Organisateurs et participants de la kermesse",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.User"
+                    },
+                    "x-nullable": true
+                },
+                "participants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.User"
+                    },
+                    "x-nullable": true
+                },
+                "picture": {
+                    "type": "string"
+                },
+                "stands": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Stand"
+                    },
+                    "x-nullable": true
+                },
+                "status": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "Relation Many-to-One : L'utilisateur qui crée la kermesse",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PrepaidCard": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "jetons": {
+                    "type": "integer"
+                },
+                "kermesse_id": {
+                    "type": "integer"
+                },
+                "linked_at": {
+                    "type": "string",
+                    "x-nullable": true
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "Compte auquel la carte a été rattachée après coup, le cas échéant",
+                    "type": "integer",
+                    "x-nullable": true
+                }
+            }
+        },
+        "models.Product": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "jetons_requis": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nb_products": {
+                    "type": "integer"
+                },
+                "picture": {
+                    "type": "string"
+                },
+                "stand_id": {
+                    "description": "Clé étrangère vers le stand",
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Stand": {
+            "type": "object",
+            "properties": {
+                "conso": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "jetons_requis": {
+                    "type": "integer"
+                },
+                "kermesses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Kermesse"
+                    },
+                    "x-nullable": true
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pts_donnees": {
+                    "type": "integer"
+                },
+                "stocks": {
+                    "description": "Clé étrangère vers Product",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Product"
+                    },
+                    "x-nullable": true
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.StandDevice": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "cursor": {
+                    "description": "Dernier curseur de synchronisation envoyé à l'appareil",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_sync_at": {
+                    "type": "string",
+                    "x-nullable": true
+                },
+                "name": {
+                    "type": "string"
+                },
+                "stand_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SyncOperation": {
+            "type": "object",
+            "properties": {
+                "card_code": {
+                    "type": "string"
+                },
+                "client_id": {
+                    "type": "string"
+                },
+                "device_id": {
+                    "type": "integer"
+                },
+                "history_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "received_at": {
+                    "type": "string"
+                },
+                "recorded_at": {
+                    "type": "string"
+                },
+                "stand_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "x-nullable": true
+                }
+            }
+        },
+        "models.TillSession": {
+            "type": "object",
+            "properties": {
+                "cashier_id": {
+                    "type": "integer"
+                },
+                "closed_at": {
+                    "type": "string",
+                    "x-nullable": true
+                },
+                "closing_count": {
+                    "type": "number",
+                    "x-nullable": true
+                },
+                "discrepancy": {
+                    "type": "number",
+                    "x-nullable": true
+                },
+                "expected_cash": {
+                    "type": "number",
+                    "x-nullable": true
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kermesse_id": {
+                    "type": "integer"
+                },
+                "opened_at": {
+                    "type": "string"
+                },
+                "opening_float": {
+                    "type": "number"
+                },
+                "sales": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transaction"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Transaction": {
+            "type": "object",
+            "properties": {
+                "Quantity": {
+                    "type": "integer"
+                },
+                "date_transaction": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kermesse_id": {
+                    "type": "integer"
+                },
+                "payment_intent_id": {
+                    "type": "string"
+                },
+                "payment_method": {
+                    "type": "string"
+                },
+                "prepaid_card_id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "refund_id": {
+                    "type": "string"
+                },
+                "refund_of_id": {
+                    "type": "integer"
+                },
+                "refunded_quantity": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Paiement : fournisseur, référence de l'intention et suivi des remboursements. RefundedQuantity\ncompte les jetons de l'achat soldés en fin de kermesse, remboursés ou donnés à l'école.",
+                    "type": "string"
+                },
+                "till_session_id": {
+                    "description": "Vente en caisse : session du caissier et moyen de paiement (espèces ou terminal carte)",
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "Relations avec l'utilisateur (vide pour la recharge d'une carte prépayée anonyme)",
+                    "type": "integer",
+                    "x-nullable": true
+                }
+            }
+        },
+        "models.User": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "enfants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.User"
+                    },
+                    "x-nullable": true
+                },
+                "firstname": {
+                    "type": "string"
+                },
+                "historique": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.History"
+                    },
+                    "x-nullable": true
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "jetons": {
+                    "type": "integer"
+                },
+                "kermesses": {
+                    "description": "Relations One-to-Many pour les Kermesses et Stands gérés par l'utilisateur",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Kermesse"
+                    },
+                    "x-nullable": true
+                },
+                "lastname": {
+                    "type": "string"
+                },
+                "parents": {
+                    "description": "Relations Many-to-Many pour Parents/Enfants",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.User"
+                    },
+                    "x-nullable": true
+                },
+                "picture": {
+                    "type": "string"
+                },
+                "pts_attribues": {
+                    "type": "integer"
+                },
+                "role": {
+                    "description": "1 = ADMIN / 2 = ORGANISATEUR / 3 = TENEUR DE STAND / 4 = PARENT / 5 ELEVE / 6 = CAISSIER",
+                    "type": "integer"
+                },
+                "stands": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Stand"
+                    },
+                    "x-nullable": true
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transaction"
+                    },
+                    "x-nullable": true
+                },
+                "updated_at": {
+                    "description": "Sert de curseur à la synchronisation des stands hors ligne",
+                    "type": "string"
+                }
+            }
+        },
+        "payment.Intent": {
+            "type": "object",
+            "properties": {
+                "amount_cents": {
+                    "type": "integer"
+                },
+                "client_secret": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "requests.AddChildrenRequest": {
+            "type": "object",
+            "properties": {
+                "children_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "requests.AddStandRequest": {
+            "type": "object",
+            "required": [
+                "stand_ids"
+            ],
+            "properties": {
+                "stand_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "requests.AddUserRequest": {
+            "type": "object",
+            "properties": {
+                "type": {
+                    "type": "string"
+                },
+                "user_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "requests.CloseTillRequest": {
+            "type": "object",
+            "required": [
+                "closing_count"
+            ],
+            "properties": {
+                "closing_count": {
+                    "type": "number"
+                }
+            }
+        },
+        "requests.GiveCoinRequest": {
+            "type": "object",
+            "properties": {
+                "nb_jetons": {
+                    "type": "integer"
+                }
+            }
+        },
+        "requests.GivePointsRequest": {
+            "type": "object",
+            "properties": {
+                "points": {
+                    "type": "integer"
+                }
+            }
+        },
+        "requests.IssueCardRequest": {
+            "type": "object",
+            "properties": {
+                "jetons_id": {
+                    "type": "integer"
+                },
+                "packs": {
+                    "type": "integer"
+                },
+                "payment_method": {
+                    "type": "string"
+                }
+            }
+        },
+        "requests.KermeseRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "picture": {
+                    "type": "string"
+                }
+            }
+        },
+        "requests.LinkCardRequest": {
+            "type": "object",
+            "properties": {
+                "merge_balance": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "requests.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "requests.OpenTillRequest": {
+            "type": "object",
+            "required": [
+                "kermesse_id"
+            ],
+            "properties": {
+                "kermesse_id": {
+                    "type": "integer"
+                },
+                "opening_float": {
+                    "type": "number"
+                }
+            }
+        },
+        "requests.PaymentRequest": {
+            "type": "object",
+            "required": [
+                "price",
+                "quantity",
+                "type"
+            ],
+            "properties": {
+                "kermesse_id": {
+                    "description": "Kermesse pour laquelle les jetons sont achetés, pour le bilan financier",
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "requests.QuantityProductRequest": {
+            "type": "object",
+            "properties": {
+                "card_code": {
+                    "description": "Achat payé avec une carte prépayée, débitée par le teneur du stand",
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "requests.RegisterDeviceRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "requests.ReverseRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "requests.SettleTokensRequest": {
+            "type": "object",
+            "properties": {
+                "donate": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "requests.SignupRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "first_name",
+                "last_name",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "picture": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "integer"
+                }
+            }
+        },
+        "requests.StandRequest": {
+            "type": "object",
+            "required": [
+                "jetons_requis",
+                "name",
+                "type"
+            ],
+            "properties": {
+                "jetons_requis": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "requests.SyncOperationRequest": {
+            "type": "object",
+            "required": [
+                "client_id",
+                "recorded_at",
+                "signature",
+                "type"
+            ],
+            "properties": {
+                "card_code": {
+                    "type": "string"
+                },
+                "client_id": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "recorded_at": {
+                    "type": "string"
+                },
+                "signature": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "requests.SyncUploadRequest": {
+            "type": "object",
+            "required": [
+                "operations"
+            ],
+            "properties": {
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/requests.SyncOperationRequest"
+                    }
+                }
+            }
+        },
+        "requests.TillSaleRequest": {
+            "type": "object",
+            "required": [
+                "jetons_id",
+                "payment_method"
+            ],
+            "properties": {
+                "card_code": {
+                    "type": "string"
+                },
+                "jetons_id": {
+                    "type": "integer"
+                },
+                "packs": {
+                    "type": "integer"
+                },
+                "payment_method": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "requests.UpdateStandRequest": {
+            "type": "object",
+            "properties": {
+                "jetons_requis": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "requests.UpdateUserRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "picture": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "integer"
+                }
+            }
+        },
+        "responses.AdminUser": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "enfants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/responses.PublicUser"
+                    }
+                },
+                "firstname": {
+                    "type": "string"
+                },
+                "historique": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.History"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "jetons": {
+                    "type": "integer"
+                },
+                "kermesses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/responses.KermesseSummary"
+                    }
+                },
+                "lastname": {
+                    "type": "string"
+                },
+                "parents": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/responses.PublicUser"
+                    }
+                },
+                "picture": {
+                    "type": "string"
+                },
+                "pts_attribues": {
+                    "type": "integer"
+                },
+                "role": {
+                    "type": "integer"
+                },
+                "stands": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Stand"
+                    }
+                },
+                "thumbnail": {
+                    "type": "string"
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transaction"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "responses.AuditEntryResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "integer",
+                    "x-nullable": true
+                },
+                "changes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/audit.Change"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "integer"
+                },
+                "entity_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "responses.GiveCoinResponse": {
+            "type": "object",
+            "properties": {
+                "child_coins": {
+                    "type": "integer"
+                },
+                "parent_coins": {
+                    "type": "integer"
+                }
+            }
+        },
+        "responses.GivePointsResponse": {
+            "type": "object",
+            "properties": {
+                "points_given": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "responses.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "request_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "ok",
+                        "unavailable"
+                    ]
+                }
+            }
+        },
+        "responses.InteractionResponse": {
+            "type": "object",
+            "properties": {
+                "history": {
+                    "$ref": "#/definitions/models.History"
+                },
+                "jetons": {
+                    "type": "integer"
+                },
+                "stand": {
+                    "type": "integer"
+                }
+            }
+        },
+        "responses.IssueCardResponse": {
+            "type": "object",
+            "properties": {
+                "card": {
+                    "$ref": "#/definitions/models.PrepaidCard"
+                },
+                "transaction": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    ],
+                    "x-nullable": true
+                }
+            }
+        },
+        "responses.KermesseResponse": {
+            "type": "object",
+            "properties": {
+                "closed_at": {
+                    "type": "string",
+                    "x-nullable": true
+                },
+                "id": {
+                    "type": "integer"
+                },
                 "name": {
                     "type": "string"
                 },
                 "organisateurs": {
-                    "description": "Relations Many-to-Many : Organisateurs et participants de la kermesse",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.User"
+                        "$ref": "#/definitions/responses.PublicUser"
                     }
                 },
                 "participants": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.User"
+                        "$ref": "#/definitions/responses.PublicUser"
                     }
                 },
                 "picture": {
@@ -2283,103 +7483,111 @@ const docTemplate = `{
                         "$ref": "#/definitions/models.Stand"
                     }
                 },
-                "user_id": {
-                    "description": "Relation Many-to-One : L'utilisateur qui crée la kermesse",
-                    "type": "integer"
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "open",
+                        "closed"
+                    ]
+                },
+                "thumbnail": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "responses.KermesseSummary": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "picture": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "open",
+                        "closed"
+                    ]
+                },
+                "thumbnail": {
+                    "type": "string"
                 }
             }
         },
-        "models.Product": {
+        "responses.LoginResponse": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "integer"
-                },
-                "jetons_requis": {
-                    "type": "integer"
-                },
-                "name": {
+                "token": {
                     "type": "string"
+                }
+            }
+        },
+        "responses.PaymentResponse": {
+            "type": "object",
+            "properties": {
+                "paymentIntent": {
+                    "$ref": "#/definitions/payment.Intent"
                 },
-                "nb_products": {
-                    "type": "integer"
+                "transaction": {
+                    "$ref": "#/definitions/models.Transaction"
+                }
+            }
+        },
+        "responses.PictureResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
                 },
                 "picture": {
                     "type": "string"
                 },
-                "stand_id": {
-                    "description": "Clé étrangère vers le stand",
-                    "type": "integer"
-                },
-                "type": {
+                "thumbnail": {
                     "type": "string"
                 }
             }
         },
-        "models.Stand": {
+        "responses.ProductResponse": {
             "type": "object",
             "properties": {
-                "conso": {
-                    "type": "integer"
-                },
                 "id": {
                     "type": "integer"
                 },
                 "jetons_requis": {
                     "type": "integer"
                 },
-                "kermesses": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Kermesse"
-                    }
-                },
                 "name": {
                     "type": "string"
                 },
-                "pts_donnees": {
+                "nb_products": {
                     "type": "integer"
                 },
-                "stocks": {
-                    "description": "Clé étrangère vers Product",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Product"
-                    }
-                },
-                "type": {
+                "picture": {
                     "type": "string"
                 },
-                "user_id": {
-                    "type": "integer"
-                }
-            }
-        },
-        "models.Transaction": {
-            "type": "object",
-            "properties": {
-                "Quantity": {
+                "stand_id": {
                     "type": "integer"
                 },
-                "date_transaction": {
+                "thumbnail": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "price": {
-                    "type": "number"
-                },
                 "type": {
                     "type": "string"
                 },
-                "user_id": {
-                    "description": "Relations avec l'utilisateur",
-                    "type": "integer"
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "models.User": {
+        "responses.Profile": {
             "type": "object",
             "properties": {
                 "email": {
@@ -2388,7 +7596,7 @@ const docTemplate = `{
                 "enfants": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.User"
+                        "$ref": "#/definitions/responses.PublicUser"
                     }
                 },
                 "firstname": {
@@ -2407,25 +7615,20 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "kermesses": {
-                    "description": "Relations One-to-Many pour les Kermesses et Stands gérés par l'utilisateur",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Kermesse"
+                        "$ref": "#/definitions/responses.KermesseSummary"
                     }
                 },
                 "lastname": {
                     "type": "string"
                 },
                 "parents": {
-                    "description": "Relations Many-to-Many pour Parents/Enfants",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.User"
+                        "$ref": "#/definitions/responses.PublicUser"
                     }
                 },
-                "password": {
-                    "type": "string"
-                },
                 "picture": {
                     "type": "string"
                 },
@@ -2433,7 +7636,6 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "role": {
-                    "description": "1 = ADMIN / 2 = ORGANISATEUR / 3 = TENEUR DE STAND / 4 = PARENT / 5 ELEVE",
                     "type": "integer"
                 },
                 "stands": {
@@ -2442,6 +7644,9 @@ const docTemplate = `{
                         "$ref": "#/definitions/models.Stand"
                     }
                 },
+                "thumbnail": {
+                    "type": "string"
+                },
                 "transactions": {
                     "type": "array",
                     "items": {
@@ -2450,159 +7655,207 @@ const docTemplate = `{
                 }
             }
         },
-        "requests.AddChildrenRequest": {
-            "type": "object",
-            "properties": {
-                "children_ids": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
-                }
-            }
-        },
-        "requests.AddStandRequest": {
-            "type": "object",
-            "required": [
-                "stand_ids"
-            ],
-            "properties": {
-                "stand_ids": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
-                }
-            }
-        },
-        "requests.AddUserRequest": {
+        "responses.PublicUser": {
             "type": "object",
             "properties": {
-                "type": {
+                "firstname": {
                     "type": "string"
                 },
-                "user_ids": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
+                "id": {
+                    "type": "integer"
+                },
+                "lastname": {
+                    "type": "string"
+                },
+                "picture": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "integer"
+                },
+                "thumbnail": {
+                    "type": "string"
                 }
             }
         },
-        "requests.GiveCoinRequest": {
+        "responses.RegisterDeviceResponse": {
             "type": "object",
             "properties": {
-                "nb_jetons": {
-                    "type": "integer"
+                "device": {
+                    "$ref": "#/definitions/models.StandDevice"
+                },
+                "secret": {
+                    "type": "string"
                 }
             }
         },
-        "requests.GivePointsRequest": {
+        "responses.WebhookResponse": {
             "type": "object",
             "properties": {
-                "points": {
-                    "type": "integer"
+                "received": {
+                    "type": "boolean"
                 }
             }
         },
-        "requests.KermeseRequest": {
+        "services.AccountBalance": {
             "type": "object",
             "properties": {
-                "name": {
+                "firstname": {
                     "type": "string"
                 },
-                "picture": {
+                "id": {
+                    "type": "integer"
+                },
+                "jetons": {
+                    "type": "integer"
+                },
+                "lastname": {
                     "type": "string"
                 }
             }
         },
-        "requests.LoginRequest": {
+        "services.FamilyRefund": {
             "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
             "properties": {
-                "email": {
+                "donated": {
+                    "type": "boolean"
+                },
+                "error": {
                     "type": "string"
                 },
-                "password": {
+                "firstname": {
                     "type": "string"
+                },
+                "lastname": {
+                    "type": "string"
+                },
+                "member_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
+                "refundable_amount": {
+                    "type": "number"
+                },
+                "refundable_jetons": {
+                    "type": "integer"
+                },
+                "settled": {
+                    "type": "boolean"
+                },
+                "unrefundable_jetons": {
+                    "type": "integer"
+                },
+                "unspent_jetons": {
+                    "type": "integer"
                 }
             }
         },
-        "requests.PaymentRequest": {
+        "services.KermesseFinances": {
             "type": "object",
-            "required": [
-                "price",
-                "quantity",
-                "type"
-            ],
             "properties": {
-                "price": {
-                    "type": "number"
+                "account_spending": {
+                    "type": "integer"
                 },
-                "quantity": {
+                "cash_desk_sales": {
+                    "$ref": "#/definitions/services.SalesSummary"
+                },
+                "donations": {
+                    "$ref": "#/definitions/services.SalesSummary"
+                },
+                "kermesse_id": {
                     "type": "integer"
                 },
-                "type": {
-                    "type": "string"
+                "online_sales": {
+                    "$ref": "#/definitions/services.SalesSummary"
+                },
+                "prepaid_card_sales": {
+                    "$ref": "#/definitions/services.SalesSummary"
+                },
+                "prepaid_card_spending": {
+                    "type": "integer"
+                },
+                "prepaid_cards_issued": {
+                    "type": "integer"
+                },
+                "prepaid_cards_linked": {
+                    "type": "integer"
+                },
+                "prepaid_cards_outstanding": {
+                    "type": "integer"
+                },
+                "refunds": {
+                    "$ref": "#/definitions/services.SalesSummary"
                 }
             }
         },
-        "requests.QuantityProductRequest": {
+        "services.SalesSummary": {
             "type": "object",
             "properties": {
-                "quantity": {
+                "amount": {
+                    "type": "number"
+                },
+                "jetons": {
                     "type": "integer"
                 }
             }
         },
-        "requests.SignupRequest": {
+        "services.SyncChanges": {
             "type": "object",
-            "required": [
-                "email",
-                "first_name",
-                "last_name",
-                "password"
-            ],
             "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "first_name": {
-                    "type": "string"
-                },
-                "last_name": {
-                    "type": "string"
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.AccountBalance"
+                    }
                 },
-                "password": {
-                    "type": "string"
+                "cards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PrepaidCard"
+                    }
                 },
-                "picture": {
+                "cursor": {
                     "type": "string"
                 },
-                "role": {
-                    "type": "integer"
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Product"
+                    }
                 }
             }
         },
-        "requests.StandRequest": {
+        "services.TillReport": {
             "type": "object",
-            "required": [
-                "jetons_requis",
-                "name",
-                "type"
-            ],
             "properties": {
-                "jetons_requis": {
+                "card_terminal_sales": {
+                    "type": "number"
+                },
+                "cash_sales": {
+                    "type": "number"
+                },
+                "closing_count": {
+                    "type": "number"
+                },
+                "discrepancy": {
+                    "type": "number"
+                },
+                "expected_cash": {
+                    "type": "number"
+                },
+                "jetons_sold": {
                     "type": "integer"
                 },
-                "name": {
-                    "type": "string"
+                "sales_count": {
+                    "type": "integer"
                 },
-                "type": {
-                    "type": "string"
+                "session": {
+                    "$ref": "#/definitions/models.TillSession"
                 }
             }
         }
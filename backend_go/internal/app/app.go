@@ -0,0 +1,71 @@
+// Package app construit l'application : configuration, connexion à la base, fournisseurs
+// de paiement, stockage des fichiers et services. Rien n'est global : plusieurs instances peuvent cohabiter,
+// par exemple dans les tests.
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"project/internal/config"
+	"project/internal/jobs"
+	"project/internal/logging"
+	"project/internal/metrics"
+	"project/internal/payment"
+	"project/internal/storage"
+	"project/services"
+)
+
+// App porte les dépendances partagées par le serveur HTTP et la ligne de commande
+type App struct {
+	Config   config.Config
+	Logger   *slog.Logger
+	DB       *gorm.DB
+	Payments *payment.Registry
+	Storage  storage.Store
+	Services *services.Services
+	Metrics  *metrics.Metrics
+	Jobs     *jobs.Runner
+}
+
+// New ouvre la base décrite par la configuration, validée au préalable, et construit l'application
+func New(cfg config.Config) (*App, error) {
+	logger := logging.New(os.Stdout, cfg.LogLevel)
+	db, err := gorm.Open(postgres.Open(cfg.DatabaseURL), &gorm.Config{Logger: logging.Gorm(logger)})
+	if err != nil {
+		return nil, fmt.Errorf("connexion à la base de données : %w", err)
+	}
+	store, err := NewStorage(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("stockage des photos : %w", err)
+	}
+	return NewWithDB(cfg, logger, db, NewPayments(cfg), store), nil
+}
+
+// NewWithDB construit l'application sur un logger, une connexion, des fournisseurs de paiement
+// et un stockage déjà prêts
+func NewWithDB(cfg config.Config, logger *slog.Logger, db *gorm.DB, payments *payment.Registry, store storage.Store) *App {
+	m := metrics.New(db)
+	return &App{
+		Config:   cfg,
+		Logger:   logger,
+		DB:       db,
+		Payments: payments,
+		Storage:  store,
+		Services: services.New(db, payments, cfg.Secret, m, store, PictureSettings(cfg)),
+		Metrics:  m,
+		Jobs:     jobs.New(logger),
+	}
+}
+
+// Close ferme la connexion à la base
+func (a *App) Close() error {
+	sqlDB, err := a.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,18 @@
+package app
+
+import (
+	"project/internal/config"
+	"project/internal/payment"
+)
+
+// NewPayments construit les fournisseurs de paiement, la carte étant servie par
+// Stripe sauf si le fake est demandé explicitement
+func NewPayments(cfg config.Config) *payment.Registry {
+	var card payment.Provider
+	if cfg.PaymentProvider == payment.ProviderFake {
+		card = payment.NewFakeProvider(cfg.PaymentWebhookSecret)
+	} else {
+		card = payment.NewStripeProvider(cfg.StripeSecretKey, cfg.StripeWebhookSecret)
+	}
+	return payment.NewRegistry(card, payment.NewCashProvider())
+}
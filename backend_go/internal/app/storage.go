@@ -0,0 +1,37 @@
+package app
+
+import (
+	"strings"
+
+	"project/internal/config"
+	"project/internal/imaging"
+	"project/internal/storage"
+	"project/services"
+)
+
+// FilesPath est la route qui sert les fichiers du stockage local, déclarée par routes.FileRoutes
+const FilesPath = "/api/v1/files"
+
+// NewStorage construit le stockage des photos choisi par la configuration. Les liens du stockage
+// local sont signés avec le secret de l'application et pointent vers l'API.
+func NewStorage(cfg config.Config) (storage.Store, error) {
+	if cfg.StorageBackend == storage.BackendS3 {
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		}, nil)
+	}
+	return storage.NewLocalStore(cfg.StorageDir, strings.TrimSuffix(cfg.PublicURL, "/")+FilesPath, cfg.Secret)
+}
+
+// PictureSettings applique la taille maximale des envois et la durée des liens de la configuration
+func PictureSettings(cfg config.Config) services.PictureSettings {
+	limits := imaging.DefaultLimits
+	if cfg.UploadMaxBytes > 0 {
+		limits.MaxBytes = int64(cfg.UploadMaxBytes)
+	}
+	return services.PictureSettings{Limits: limits, URLTTL: cfg.StorageURLTTL}
+}
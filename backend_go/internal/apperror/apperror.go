@@ -0,0 +1,170 @@
+// Package apperror définit les erreurs métier de l'application : chacune porte une catégorie,
+// qui décide du statut HTTP, et un code stable que les clients peuvent traduire.
+// Les erreurs sentinelles des services sont des *Error : errors.Is continue de fonctionner,
+// et une erreur qui n'en est pas une est traitée comme une erreur interne.
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Kind est la catégorie d'une erreur
+type Kind string
+
+const (
+	KindValidation        Kind = "validation"
+	KindUnauthorized      Kind = "unauthorized"
+	KindForbidden         Kind = "forbidden"
+	KindNotFound          Kind = "not_found"
+	KindConflict          Kind = "conflict"
+	KindUnprocessable     Kind = "unprocessable"
+	KindInsufficientFunds Kind = "insufficient_funds"
+	KindOutOfStock        Kind = "out_of_stock"
+	KindTooLarge          Kind = "too_large"
+	KindUnavailable       Kind = "unavailable"
+	KindInternal          Kind = "internal"
+)
+
+var statuses = map[Kind]int{
+	KindValidation:        http.StatusBadRequest,
+	KindUnauthorized:      http.StatusUnauthorized,
+	KindForbidden:         http.StatusForbidden,
+	KindNotFound:          http.StatusNotFound,
+	KindConflict:          http.StatusConflict,
+	KindUnprocessable:     http.StatusUnprocessableEntity,
+	KindInsufficientFunds: http.StatusUnprocessableEntity,
+	KindOutOfStock:        http.StatusUnprocessableEntity,
+	KindTooLarge:          http.StatusRequestEntityTooLarge,
+	KindUnavailable:       http.StatusServiceUnavailable,
+	KindInternal:          http.StatusInternalServerError,
+}
+
+// Internal est l'erreur présentée au client pour toute erreur non prévue ; le détail n'est que journalisé
+var Internal = New(KindInternal, "internal_error", "internal server error")
+
+// FieldError décrit une erreur sur un champ de la requête
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Param   string `json:"param,omitempty"`
+}
+
+// Error est une erreur métier. Deux erreurs de même catégorie et de même code sont égales pour errors.Is.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+	cause   error
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func Validation(code, message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message, Fields: fields}
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func Unprocessable(code, message string) *Error {
+	return New(KindUnprocessable, code, message)
+}
+
+func InsufficientFunds(code, message string) *Error {
+	return New(KindInsufficientFunds, code, message)
+}
+
+func OutOfStock(code, message string) *Error {
+	return New(KindOutOfStock, code, message)
+}
+
+func TooLarge(code, message string) *Error {
+	return New(KindTooLarge, code, message)
+}
+
+func Unavailable(code, message string) *Error {
+	return New(KindUnavailable, code, message)
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// Wrap retourne une copie de l'erreur avec sa cause, journalisée mais jamais renvoyée au client
+func (e *Error) Wrap(cause error) *Error {
+	wrapped := *e
+	wrapped.cause = cause
+	return &wrapped
+}
+
+// Status retourne le statut HTTP de l'erreur
+func (e *Error) Status() int {
+	if status, ok := statuses[e.Kind]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// From retourne l'erreur métier contenue dans err, ou Internal enveloppant err
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal.Wrap(err)
+}
+
+// Body est le corps d'une réponse d'erreur
+type Body struct {
+	Type      Kind         `json:"type" example:"not_found"`
+	Code      string       `json:"code" example:"stand_not_found"`
+	Message   string       `json:"message" example:"stand not found"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// Response est l'enveloppe de toutes les réponses d'erreur de l'API
+type Response struct {
+	Error Body `json:"error"`
+}
+
+// Response construit la réponse renvoyée au client pour cette erreur
+func (e *Error) Response(requestID string) Response {
+	return Response{Error: Body{
+		Type:      e.Kind,
+		Code:      e.Code,
+		Message:   e.Message,
+		Fields:    e.Fields,
+		RequestID: requestID,
+	}}
+}
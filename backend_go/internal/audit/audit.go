@@ -0,0 +1,107 @@
+// Package audit tient le journal des actions privilégiées et financières : qui a fait quoi, sur quel
+// enregistrement, avec les valeurs avant et après, depuis quelle IP et dans quelle requête.
+//
+// Le journal ne fait que grandir : la base refuse la modification et la suppression de ses lignes
+// (voir la migration 0005_audit_entries), y compris lors d'un seed --clean.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"gorm.io/gorm"
+	"project/internal/logging"
+	"project/internal/models"
+)
+
+// Actions journalisées
+const (
+	ActionUserCreate        = "user.create"
+	ActionUserUpdate        = "user.update"
+	ActionUserRoleChange    = "user.role_change"
+	ActionUserPasswordReset = "user.password_reset"
+	ActionUserDelete        = "user.delete"
+	ActionBalanceAdjust     = "balance.adjust"
+	ActionPaymentSucceeded  = "payment.succeeded"
+	ActionRefund            = "refund.create"
+	ActionDonation          = "refund.donate"
+	ActionHistoryReverse    = "history.reverse"
+	ActionKermesseDelete    = "kermesse.delete"
+	ActionKermesseStandAdd  = "kermesse.stand_add"
+	ActionStandDelete       = "stand.delete"
+	ActionProductDelete     = "product.delete"
+	ActionJetonsPackDelete  = "jetons_pack.delete"
+)
+
+// Actions liste toutes les actions, pour valider le filtre de la liste
+var Actions = []string{
+	ActionUserCreate, ActionUserUpdate, ActionUserRoleChange, ActionUserPasswordReset, ActionUserDelete,
+	ActionBalanceAdjust, ActionPaymentSucceeded, ActionRefund, ActionDonation, ActionHistoryReverse,
+	ActionKermesseDelete, ActionKermesseStandAdd, ActionStandDelete, ActionProductDelete, ActionJetonsPackDelete,
+}
+
+// Types des enregistrements visés
+const (
+	EntityUser        = "user"
+	EntityPrepaidCard = "prepaid_card"
+	EntityTransaction = "transaction"
+	EntityHistory     = "history"
+	EntityKermesse    = "kermesse"
+	EntityStand       = "stand"
+	EntityProduct     = "product"
+	EntityJetonsPack  = "jetons_pack"
+)
+
+// EntityTypes liste tous les types d'enregistrements, pour valider le filtre de la liste
+var EntityTypes = []string{
+	EntityUser, EntityPrepaidCard, EntityTransaction, EntityHistory,
+	EntityKermesse, EntityStand, EntityProduct, EntityJetonsPack,
+}
+
+// Entry décrit une action à journaliser. Before et After sont l'enregistrement avant et après
+// l'action, nil pour une création ou une suppression ; seuls leurs champs modifiés sont gardés.
+type Entry struct {
+	// Operator est l'auteur de l'action. La ligne de commande et les webhooks n'ont pas de compte :
+	// leur ID est nul et leur Email les décrit (cli:<login>, webhook:<fournisseur>).
+	Operator   models.User
+	Action     string
+	EntityType string
+	EntityID   uint
+	Before     any
+	After      any
+}
+
+type clientIPKey struct{}
+
+// WithClientIP rattache au contexte l'adresse IP du client de la requête
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// Record ajoute l'entrée au journal, avec l'IP et l'identifiant de la requête de ctx.
+// db est de préférence la transaction de l'action : l'entrée n'est écrite que si l'action l'est.
+func Record(ctx context.Context, db *gorm.DB, entry Entry) error {
+	changes, err := Diff(entry.Before, entry.After)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	row := models.AuditEntry{
+		Actor:      entry.Operator.Email,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Changes:    string(encoded),
+		IP:         ip,
+		RequestID:  logging.RequestID(ctx),
+	}
+	if entry.Operator.ID != 0 {
+		row.ActorID = &entry.Operator.ID
+	}
+	return db.WithContext(ctx).Create(&row).Error
+}
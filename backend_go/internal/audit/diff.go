@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// Champs jamais comparés : ils changent à chaque écriture
+var ignoredFields = map[string]bool{"updated_at": true}
+
+// Change est la valeur d'un champ avant et après l'action, null quand l'enregistrement n'existait pas
+type Change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// Diff retourne les champs qui diffèrent entre les représentations JSON de before et after.
+// Un nil compte pour un enregistrement absent : tous ses champs apparaissent alors.
+// Seuls les champs simples sont comparés ; les relations chargées avec l'enregistrement sont
+// ignorées, leurs modifications ont leurs propres entrées.
+func Diff(before, after any) (map[string]Change, error) {
+	from, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]Change{}
+	for name, value := range from {
+		if other, ok := to[name]; !ok || !reflect.DeepEqual(value, other) {
+			changes[name] = Change{From: value, To: to[name]}
+		}
+	}
+	for name, value := range to {
+		if _, ok := from[name]; !ok {
+			changes[name] = Change{To: value}
+		}
+	}
+	return changes, nil
+}
+
+// fields retourne les champs simples de la représentation JSON de v
+func fields(v any) (map[string]any, error) {
+	values := map[string]any{}
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil() {
+		return values, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// Les identifiants et les montants restent exacts
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, err
+	}
+
+	for name, value := range values {
+		switch value.(type) {
+		case map[string]any, []any:
+			delete(values, name)
+			continue
+		}
+		if ignoredFields[name] {
+			delete(values, name)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"fmt"
+	"project/services"
+
+	"github.com/spf13/cobra"
+)
+
+func newAdjustBalanceCommand(d *deps) *cobra.Command {
+	var target services.BalanceTarget
+	var delta int
+	var reason string
+	cmd := &cobra.Command{
+		Use:     "adjust-balance",
+		Short:   "Corrige le solde de jetons d'un compte ou d'une carte prépayée",
+		Long:    "Ajoute (delta positif) ou retire (delta négatif) des jetons. Chaque correction est tracée avec son motif et l'opérateur.",
+		Args:    cobra.NoArgs,
+		PreRunE: d.requireCurrentSchema,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			adjustment, err := d.app.Services.Balances.Adjust(cmd.Context(), target, delta, reason, operator())
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "adjustment %d: %+d jetons, new balance %d\n", adjustment.ID, adjustment.Delta, adjustment.BalanceAfter)
+			return nil
+		},
+	}
+	cmd.Flags().UintVar(&target.UserID, "user", 0, "ID du compte à corriger")
+	cmd.Flags().StringVar(&target.CardCode, "card", "", "code de la carte prépayée à corriger")
+	cmd.Flags().IntVar(&delta, "delta", 0, "nombre de jetons à ajouter, négatif pour en retirer")
+	cmd.Flags().StringVar(&reason, "reason", "", "motif de la correction")
+	cmd.MarkFlagsOneRequired("user", "card")
+	cmd.MarkFlagsMutuallyExclusive("user", "card")
+	cmd.MarkFlagRequired("delta")
+	cmd.MarkFlagRequired("reason")
+	return cmd
+}
+
+func newRecomputeCardsAndStandsCommand(d *deps) *cobra.Command {
+	apply := false
+	cmd := &cobra.Command{
+		Use:   "recompute-cards-and-stands",
+		Short: "Recalcule les soldes des cartes prépayées et la conso des stands depuis l'historique",
+		Long: "Compare les soldes enregistrés à ceux recalculés depuis les recharges, les achats non annulés " +
+			"et les ajustements. Sans --apply, les écarts sont seulement affichés. " +
+			"Les soldes des comptes ne sont pas recalculés : utiliser adjust-balance.",
+		Args:    cobra.NoArgs,
+		PreRunE: d.requireCurrentSchema,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			drifts, err := d.app.Services.Balances.Recompute(apply)
+			if err != nil {
+				return err
+			}
+			for _, drift := range drifts {
+				status := "drift"
+				if drift.Fixed {
+					status = "fixed"
+				} else if drift.Computed < 0 {
+					status = "negative, not fixed"
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%-12s %-6d %-20s stored %-6d computed %-6d %s\n",
+					drift.Kind, drift.ID, drift.Label, drift.Stored, drift.Computed, status)
+			}
+			if len(drifts) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "all balances match the ledger")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&apply, "apply", false, "remplace les valeurs enregistrées par les valeurs recalculées")
+	return cmd
+}
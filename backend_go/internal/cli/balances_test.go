@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"project/internal/config"
+	"project/internal/models"
+	"project/internal/testserver"
+	"project/services"
+)
+
+// execute lance la commande sur la base du serveur de test et retourne sa sortie
+func execute(s *testserver.Server, newCommand func(*deps) *cobra.Command, args ...string) (string, error) {
+	d := &deps{config: config.Config{Env: config.EnvTest}, app: s.App}
+	cmd := newCommand(d)
+	var out bytes.Buffer
+	cmd.SetArgs(args)
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+// Une correction est appliquée et tracée au nom de l'opérateur ; un solde ne devient jamais négatif
+func TestAdjustBalance(t *testing.T) {
+	s := testserver.New(t)
+	parent := s.Parent()
+	user := strconv.FormatUint(uint64(parent.ID), 10)
+
+	out, err := execute(s, newAdjustBalanceCommand, "--user", user, "--delta", "5", "--reason", "geste commercial")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "+5 jetons, new balance 5") || s.Balance(parent) != 5 {
+		t.Fatalf("5 jetons attendus : %q, solde %d", out, s.Balance(parent))
+	}
+	var adjustment models.BalanceAdjustment
+	if err := s.DB.First(&adjustment).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(adjustment.Operator, "cli:") || adjustment.Reason != "geste commercial" || *adjustment.UserID != parent.ID {
+		t.Errorf("correction tracée au nom de la ligne de commande attendue : %+v", adjustment)
+	}
+
+	if _, err := execute(s, newAdjustBalanceCommand, "--user", user, "--delta", "-10", "--reason", "erreur"); !errors.Is(err, services.ErrNotEnoughJetons) {
+		t.Fatalf("ErrNotEnoughJetons attendue, reçu %v", err)
+	}
+	if _, err := execute(s, newAdjustBalanceCommand, "--user", "999999", "--delta", "1", "--reason", "erreur"); !errors.Is(err, services.ErrUserNotFound) {
+		t.Fatalf("ErrUserNotFound attendue, reçu %v", err)
+	}
+	if _, err := execute(s, newAdjustBalanceCommand, "--user", user, "--card", "AAAA-BBBB-CCCC", "--delta", "1", "--reason", "erreur"); err == nil {
+		t.Fatal("--user et --card ensemble doivent être refusés")
+	}
+	if _, err := execute(s, newAdjustBalanceCommand, "--user", user, "--delta", "1"); err == nil {
+		t.Fatal("le motif doit être obligatoire")
+	}
+	if s.Balance(parent) != 5 {
+		t.Errorf("les corrections refusées ne doivent rien changer : %d", s.Balance(parent))
+	}
+}
+
+// Les écarts sont seulement affichés sans --apply, puis corrigés avec
+func TestRecomputeCardsAndStands(t *testing.T) {
+	s := testserver.New(t)
+	var kermesse models.Kermesse
+	s.Request(http.MethodPost, "/api/v1/kermesses", s.Organisateur(), map[string]any{"name": "Kermesse"}).
+		Expect(http.StatusCreated).
+		Data(&kermesse)
+	var stand models.Stand
+	s.Request(http.MethodPost, "/api/v1/stands", s.Teneur(), map[string]any{"name": "Chamboule-tout", "type": "activite", "jetons_requis": 1}).
+		Expect(http.StatusCreated).
+		Data(&stand)
+	card := models.PrepaidCard{Code: "AAAA-BBBB-CCCC", Jetons: 7, KermesseID: kermesse.ID}
+	if err := s.DB.Create(&card).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DB.Model(&models.Stand{}).Where("id = ?", stand.ID).Update("conso", 4).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := execute(s, newRecomputeCardsAndStandsCommand)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(out, " drift") != 2 || !strings.Contains(out, card.Code) {
+		t.Fatalf("deux écarts attendus : %q", out)
+	}
+	s.DB.First(&card, card.ID)
+	s.DB.First(&stand, stand.ID)
+	if card.Jetons != 7 || stand.Conso != 4 {
+		t.Fatalf("rien ne doit changer sans --apply : carte %d, conso %d", card.Jetons, stand.Conso)
+	}
+
+	out, err = execute(s, newRecomputeCardsAndStandsCommand, "--apply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(out, " fixed") != 2 {
+		t.Fatalf("deux corrections attendues : %q", out)
+	}
+	s.DB.First(&card, card.ID)
+	s.DB.First(&stand, stand.ID)
+	if card.Jetons != 0 || stand.Conso != 0 {
+		t.Fatalf("valeurs recalculées attendues : carte %d, conso %d", card.Jetons, stand.Conso)
+	}
+
+	if out, err := execute(s, newRecomputeCardsAndStandsCommand); err != nil || !strings.Contains(out, "all balances match the ledger") {
+		t.Fatalf("plus aucun écart attendu : %q, %v", out, err)
+	}
+}
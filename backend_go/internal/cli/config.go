@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func newConfigCommand(d *deps) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspection de la configuration",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:         "check",
+		Short:       "Affiche la configuration effective, secrets masqués, et la valide",
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{configOnly: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CLÉ\tVALEUR\tORIGINE")
+			for _, setting := range d.config.Settings() {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, setting.Value, setting.Source)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			if err := d.config.Validate(); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Configuration valide")
+			return nil
+		},
+	})
+	return cmd
+}
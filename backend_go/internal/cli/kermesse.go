@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func newKermesseCommand(d *deps) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "kermesse",
+		Short: "Exporte ou clôture une kermesse",
+	}
+
+	output := ""
+	export := &cobra.Command{
+		Use:     "export <id>",
+		Short:   "Exporte une kermesse en JSON (stands, produits, ventes, historique, cartes, caisses, bilan)",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: d.requireCurrentSchema,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			data, err := d.app.Services.Kermesses.Export(operator(), id)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if output != "" {
+				file, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				out = file
+			}
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(data)
+		},
+	}
+	export.Flags().StringVarP(&output, "output", "o", "", "fichier de sortie (sortie standard par défaut)")
+
+	closeCmd := &cobra.Command{
+		Use:     "close <id>",
+		Short:   "Clôture une kermesse pour permettre le remboursement des familles",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: d.requireCurrentSchema,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			kermesse, err := d.app.Services.Kermesses.Close(operator(), id)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "kermesse %d (%s) closed at %s\n", kermesse.ID, kermesse.Name, kermesse.ClosedAt.Format("2006-01-02 15:04:05"))
+			return nil
+		},
+	}
+
+	cmd.AddCommand(export, closeCmd)
+	return cmd
+}
+
+func parseID(arg string) (uint, error) {
+	id, err := strconv.ParseUint(arg, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("identifiant invalide : %s", arg)
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"project/internal/models"
+	"project/internal/testserver"
+	"project/services"
+)
+
+// L'export s'écrit en JSON sur la sortie standard ou dans le fichier donné
+func TestExportKermesse(t *testing.T) {
+	s := testserver.New(t)
+	var kermesse models.Kermesse
+	s.Request(http.MethodPost, "/api/v1/kermesses", s.Organisateur(), map[string]any{"name": "Kermesse d'été"}).
+		Expect(http.StatusCreated).
+		Data(&kermesse)
+	id := strconv.FormatUint(uint64(kermesse.ID), 10)
+
+	out, err := execute(s, newKermesseCommand, "export", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var export services.KermesseExport
+	if err := json.Unmarshal([]byte(out), &export); err != nil {
+		t.Fatalf("JSON attendu : %v\n%s", err, out)
+	}
+	if export.Kermesse.ID != kermesse.ID || export.Finances == nil {
+		t.Fatalf("export de la kermesse %d attendu : %+v", kermesse.ID, export)
+	}
+
+	file := filepath.Join(t.TempDir(), "export.json")
+	if out, err := execute(s, newKermesseCommand, "export", id, "--output", file); err != nil || out != "" {
+		t.Fatalf("rien sur la sortie standard attendu : %q, %v", out, err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	export = services.KermesseExport{}
+	if err := json.Unmarshal(data, &export); err != nil || export.Kermesse.Name != "Kermesse d'été" {
+		t.Fatalf("export dans le fichier attendu : %+v, %v", export, err)
+	}
+
+	if _, err := execute(s, newKermesseCommand, "export", "999999"); !errors.Is(err, services.ErrKermesseNotFound) {
+		t.Fatalf("ErrKermesseNotFound attendue, reçu %v", err)
+	}
+	if _, err := execute(s, newKermesseCommand, "export", "abc"); err == nil {
+		t.Fatal("un identifiant invalide doit être refusé")
+	}
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"project/internal/migrate"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCommand(d *deps) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Applique, annule ou liste les migrations du schéma",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "up",
+		Short: "Applique les migrations en attente",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			applied, err := migrate.Up(d.app.DB)
+			for _, migration := range applied {
+				fmt.Printf("applied  %04d_%s\n", migration.Version, migration.Name)
+			}
+			if err != nil {
+				return fmt.Errorf("migration de la base de données : %w", err)
+			}
+			if len(applied) == 0 {
+				fmt.Println("schema is up to date")
+			}
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "down [n]",
+		Short: "Annule les n dernières migrations (1 par défaut)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			steps := 1
+			if len(args) == 1 {
+				n, err := strconv.Atoi(args[0])
+				if err != nil || n < 1 {
+					return fmt.Errorf("nombre de migrations à annuler invalide : %s", args[0])
+				}
+				steps = n
+			}
+			reverted, err := migrate.Down(d.app.DB, steps)
+			for _, migration := range reverted {
+				fmt.Printf("reverted %04d_%s\n", migration.Version, migration.Name)
+			}
+			if err != nil {
+				return fmt.Errorf("annulation de la migration : %w", err)
+			}
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "status",
+		Short: "Liste les migrations et leur date d'application",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			statuses, err := migrate.Statuses(d.app.DB)
+			if err != nil {
+				return fmt.Errorf("lecture des migrations : %w", err)
+			}
+			for _, status := range statuses {
+				if status.AppliedAt == nil {
+					fmt.Printf("pending  %04d_%s\n", status.Version, status.Name)
+					continue
+				}
+				fmt.Printf("applied  %04d_%s  %s\n", status.Version, status.Name, status.AppliedAt.Format("2006-01-02 15:04:05"))
+			}
+			return nil
+		},
+	})
+	return cmd
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// Annotation des commandes qui n'ont besoin que de la configuration, sans base de données
+const configOnly = "config-only"
+
+// deps porte la configuration et l'application construites avant l'exécution de chaque sous-commande
+type deps struct {
+	configFile string
+	config     config.Config
+	app        *app.App
+}
+
+// NewRootCommand construit la commande `kermesses` et toutes ses sous-commandes.
+// Sans sous-commande, le serveur HTTP démarre comme avec `kermesses serve`.
+func NewRootCommand() *cobra.Command {
+	d := &deps{}
+	root := &cobra.Command{
+		Use:           "kermesses",
+		Short:         "Serveur et outils d'administration des kermesses",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(config.Options{File: d.configFile, Flags: cmd.Flags()})
+			if err != nil {
+				return err
+			}
+			d.config = cfg
+			if cmd.Annotations[configOnly] != "" {
+				return nil
+			}
+			// Une configuration incomplète est refusée dès le démarrage plutôt qu'à la première requête
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+			d.app, err = app.New(cfg)
+			return err
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			if d.app == nil {
+				return nil
+			}
+			return d.app.Close()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return d.serve()
+		},
+	}
+	root.PersistentFlags().StringVar(&d.configFile, "config", "", "fichier de configuration (par défaut "+config.DefaultFile+" s'il existe)")
+	config.BindFlags(root.PersistentFlags())
+
+	root.AddCommand(
+		newServeCommand(d),
+		newMigrateCommand(d),
+		newSeedCommand(d),
+		newCreateAdminCommand(d),
+		newResetPasswordCommand(d),
+		newAdjustBalanceCommand(d),
+		newRecomputeCardsAndStandsCommand(d),
+		newKermesseCommand(d),
+		newConfigCommand(d),
+	)
+	return root
+}
+
+// Execute lance la commande correspondant aux arguments du programme
+func Execute() {
+	if err := NewRootCommand().Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "Erreur :", err)
+		os.Exit(1)
+	}
+}
+
+// operator est l'identité sous laquelle la ligne de commande agit : un admin,
+// tracé avec le login système de la personne qui lance la commande.
+func operator() models.User {
+	login := os.Getenv("USER")
+	if current, err := user.Current(); err == nil {
+		login = current.Username
+	}
+	return models.User{Role: models.RoleAdmin, Email: "cli:" + login}
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"project/internal/config"
+	"project/internal/migrate"
+	"project/internal/seed"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	errDisposableProduction   = errors.New("a production database can't be flagged as disposable")
+	errDisposableConfirmation = errors.New("flagging the database as disposable lets seed --clean wipe it: confirm with --yes")
+)
+
+// newSeedCommand insère les données d'un environnement. Avec --clean, la base est d'abord vidée,
+// ce qui n'est possible que sur une base marquée jetable (seed mark-disposable).
+func newSeedCommand(d *deps) *cobra.Command {
+	clean := false
+	cmd := &cobra.Command{
+		Use:       "seed demo|test|empty",
+		Short:     "Insère les données d'un environnement",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{seed.EnvDemo, seed.EnvTest, seed.EnvEmpty},
+		PreRunE:   d.requireCurrentSchema,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if clean {
+				if err := seed.Clean(d.app.DB); err != nil {
+					return fmt.Errorf("nettoyage de la base : %w", err)
+				}
+			}
+			if err := seed.Run(d.app.DB, args[0]); err != nil {
+				return fmt.Errorf("insertion des données : %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&clean, "clean", false, "vide la base avant l'insertion (base marquée jetable uniquement)")
+
+	cmd.AddCommand(newMarkDisposableCommand(d))
+	return cmd
+}
+
+// newMarkDisposableCommand marque la base comme jetable. Refusé en production, et seulement
+// sur confirmation explicite : la base pourra ensuite être vidée par seed --clean.
+func newMarkDisposableCommand(d *deps) *cobra.Command {
+	yes := false
+	cmd := &cobra.Command{
+		Use:   "mark-disposable",
+		Short: "Marque la base comme jetable pour autoriser seed --clean",
+		Args:  cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if d.config.Env == config.EnvProduction {
+				return errDisposableProduction
+			}
+			if !yes {
+				return errDisposableConfirmation
+			}
+			return d.requireCurrentSchema(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := seed.MarkDisposable(d.app.DB); err != nil {
+				return fmt.Errorf("marquage de la base : %w", err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "database flagged as disposable: seed --clean can now wipe it")
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&yes, "yes", false, "confirme que la base pourra être vidée")
+	return cmd
+}
+
+// requireCurrentSchema refuse d'agir sur une base dont le schéma n'est pas à jour
+func (d *deps) requireCurrentSchema(cmd *cobra.Command, args []string) error {
+	if err := migrate.CheckCurrent(d.app.DB); err != nil {
+		return fmt.Errorf("schéma de base de données non à jour : %w", err)
+	}
+	return nil
+}
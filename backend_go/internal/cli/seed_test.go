@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"project/internal/config"
+	"project/internal/seed"
+	"project/internal/testserver"
+)
+
+// Le marquage jetable est refusé en production et sans --yes ; confirmé ailleurs, il autorise seed --clean
+func TestMarkDisposable(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        string
+		args       []string
+		err        error
+		disposable bool
+	}{
+		{"production", config.EnvProduction, []string{"--yes"}, errDisposableProduction, false},
+		{"sans confirmation", config.EnvDevelopment, nil, errDisposableConfirmation, false},
+		{"confirmé en développement", config.EnvDevelopment, []string{"--yes"}, nil, true},
+		{"confirmé en test", config.EnvTest, []string{"--yes"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testserver.New(t)
+			d := &deps{config: config.Config{Env: tt.env}, app: s.App}
+			cmd := newMarkDisposableCommand(d)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+
+			if err := cmd.Execute(); !errors.Is(err, tt.err) {
+				t.Fatalf("erreur %v attendue, reçu %v", tt.err, err)
+			}
+			disposable, err := seed.IsDisposable(s.DB)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if disposable != tt.disposable {
+				t.Errorf("base jetable : %v, %v attendu", disposable, tt.disposable)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"project/api/middlewares"
+	"project/api/routes"
+	_ "project/docs" // spécification servie par /swagger
+	"project/internal/migrate"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cobra"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+// Fréquence de la purge des clés d'idempotence expirées
+const idempotencyPurgeInterval = time.Hour
+
+func newServeCommand(d *deps) *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Démarre l'API HTTP",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return d.serve()
+		},
+	}
+}
+
+// serve démarre l'API jusqu'à SIGINT ou SIGTERM, puis laisse aux requêtes et tâches de fond
+// en cours SHUTDOWN_TIMEOUT pour se terminer : un achat commencé n'est pas coupé par un déploiement.
+func (d *deps) serve() error {
+	// Le schéma est migré explicitement avec `migrate up` : on refuse de démarrer sur une base en retard
+	if err := migrate.CheckCurrent(d.app.DB); err != nil {
+		return fmt.Errorf("schéma de base de données non à jour : %w", err)
+	}
+
+	// Initialisation du serveur : les logs d'accès et la reprise sur panique sont déclarés avec les routes
+	router := gin.New()
+	routes.Register(router, d.app)
+
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	cfg, logger := d.app.Config, d.app.Logger
+	server := &http.Server{
+		Addr:         cfg.Addr,
+		Handler:      router,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+
+	d.app.Jobs.Every("idempotency-purge", idempotencyPurgeInterval, func(ctx context.Context) error {
+		_, err := middlewares.PurgeIdempotencyKeys(ctx, d.app.DB)
+		return err
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	logger.Info("server starting", "addr", cfg.Addr, "payment_provider", d.app.Payments.Card().Name())
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		d.app.Jobs.Shutdown(context.Background())
+		return fmt.Errorf("démarrage du serveur : %w", err)
+	case <-ctx.Done():
+	}
+	// Un second signal interrompt l'arrêt progressif
+	stop()
+
+	logger.Info("server shutting down", "timeout", cfg.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(shutdownCtx)
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("server stopped", "error", err)
+	}
+	if err := errors.Join(err, d.app.Jobs.Shutdown(shutdownCtx)); err != nil {
+		return fmt.Errorf("arrêt du serveur : %w", err)
+	}
+	logger.Info("server stopped")
+	return nil
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newCreateAdminCommand(d *deps) *cobra.Command {
+	var email, firstname, lastname, password string
+	cmd := &cobra.Command{
+		Use:     "create-admin",
+		Short:   "Crée un compte administrateur",
+		Args:    cobra.NoArgs,
+		PreRunE: d.requireCurrentSchema,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			generated := password == ""
+			if generated {
+				password = randomPassword()
+			}
+			user, err := d.app.Services.Users.CreateAdmin(cmd.Context(), operator(), firstname, lastname, email, password)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "admin %d created: %s\n", user.ID, user.Email)
+			if generated {
+				fmt.Fprintf(cmd.OutOrStdout(), "password: %s\n", password)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&email, "email", "", "email de connexion")
+	cmd.Flags().StringVar(&firstname, "firstname", "Admin", "prénom")
+	cmd.Flags().StringVar(&lastname, "lastname", "Kermesse", "nom")
+	cmd.Flags().StringVar(&password, "password", "", "mot de passe (généré et affiché s'il est omis)")
+	cmd.MarkFlagRequired("email")
+	return cmd
+}
+
+func newResetPasswordCommand(d *deps) *cobra.Command {
+	var email, password string
+	cmd := &cobra.Command{
+		Use:     "reset-password",
+		Short:   "Remplace le mot de passe d'un compte",
+		Args:    cobra.NoArgs,
+		PreRunE: d.requireCurrentSchema,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			generated := password == ""
+			if generated {
+				password = randomPassword()
+			}
+			user, err := d.app.Services.Users.ResetPassword(cmd.Context(), operator(), email, password)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "password reset for user %d (%s)\n", user.ID, user.Email)
+			if generated {
+				fmt.Fprintf(cmd.OutOrStdout(), "password: %s\n", password)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&email, "email", "", "email du compte")
+	cmd.Flags().StringVar(&password, "password", "", "nouveau mot de passe (généré et affiché s'il est omis)")
+	cmd.MarkFlagRequired("email")
+	return cmd
+}
+
+func randomPassword() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,295 @@
+// Package config charge la configuration typée de l'application. Par ordre de priorité croissante :
+// les valeurs par défaut, le fichier .env, les variables d'environnement puis les drapeaux
+// de la ligne de commande.
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/pflag"
+	"project/internal/payment"
+	"project/internal/storage"
+)
+
+// Fichier lu par défaut ; son absence n'est pas une erreur
+const DefaultFile = ".env"
+
+// Origines possibles d'une valeur
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Environnements d'exécution : le fournisseur de paiement fake n'est accepté qu'en développement et en test
+const (
+	EnvDevelopment = "development"
+	EnvTest        = "test"
+	EnvProduction  = "production"
+)
+
+var ErrInvalid = errors.New("invalid configuration")
+
+// Config décrit chaque réglage par des tags : env (variable et clé du fichier), flag (drapeau
+// de la ligne de commande), default, required et secret (valeur masquée à l'affichage).
+// Les secrets n'ont pas de drapeau : ils apparaîtraient dans la liste des processus.
+type Config struct {
+	Env                  string        `env:"APP_ENV" flag:"env" default:"production" usage:"environnement d'exécution : development, test ou production"`
+	Addr                 string        `env:"ADDR" flag:"addr" default:":8080" usage:"adresse d'écoute du serveur HTTP"`
+	ReadTimeout          time.Duration `env:"READ_TIMEOUT" default:"15s" usage:"durée maximale de lecture d'une requête"`
+	WriteTimeout         time.Duration `env:"WRITE_TIMEOUT" default:"30s" usage:"durée maximale d'écriture d'une réponse"`
+	IdleTimeout          time.Duration `env:"IDLE_TIMEOUT" default:"60s" usage:"durée de vie d'une connexion inactive"`
+	LogLevel             slog.Level    `env:"LOG_LEVEL" default:"info" usage:"niveau minimal des logs : debug, info, warn ou error"`
+	ShutdownTimeout      time.Duration `env:"SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" default:"20s" usage:"délai laissé aux requêtes et tâches en cours à l'arrêt"`
+	DatabaseURL          string        `env:"DB_URL" required:"true" secret:"true" usage:"chaîne de connexion PostgreSQL"`
+	Secret               string        `env:"SECRET" required:"true" secret:"true" usage:"clé de signature des jetons de connexion"`
+	PaymentProvider      string        `env:"PAYMENT_PROVIDER" flag:"payment-provider" default:"stripe" usage:"fournisseur de paiement carte : stripe, ou fake en développement et en test"`
+	StripeSecretKey      string        `env:"STRIPE_SECRET_KEY" secret:"true" usage:"clé secrète de l'API Stripe"`
+	StripeWebhookSecret  string        `env:"STRIPE_WEBHOOK_SECRET" secret:"true" usage:"secret de signature des webhooks Stripe"`
+	PaymentWebhookSecret string        `env:"PAYMENT_WEBHOOK_SECRET" secret:"true" usage:"secret de signature des webhooks du fournisseur fake"`
+	LegacyRoutes         bool          `env:"LEGACY_ROUTES" default:"true" usage:"sert aussi les anciennes routes, dépréciées au profit de /api/v1"`
+	PublicURL            string        `env:"PUBLIC_URL" usage:"adresse publique de l'API (https://api.exemple.fr), préfixe des liens signés vers les fichiers locaux"`
+	StorageBackend       string        `env:"STORAGE_BACKEND" flag:"storage-backend" default:"local" usage:"stockage des photos : local ou s3"`
+	StorageDir           string        `env:"STORAGE_DIR" default:"uploads" usage:"répertoire du stockage local des photos"`
+	StorageURLTTL        time.Duration `env:"STORAGE_URL_TTL" default:"15m" usage:"durée de validité des liens signés vers les photos"`
+	UploadMaxBytes       int           `env:"UPLOAD_MAX_BYTES" default:"5242880" usage:"taille maximale d'une photo envoyée, en octets"`
+	S3Endpoint           string        `env:"S3_ENDPOINT" usage:"adresse du stockage compatible S3 (https://s3.eu-west-3.amazonaws.com, http://localhost:9000)"`
+	S3Region             string        `env:"S3_REGION" default:"us-east-1" usage:"région du stockage S3"`
+	S3Bucket             string        `env:"S3_BUCKET" usage:"bucket des photos"`
+	S3AccessKey          string        `env:"S3_ACCESS_KEY" secret:"true" usage:"identifiant de la clé d'accès S3"`
+	S3SecretKey          string        `env:"S3_SECRET_KEY" secret:"true" usage:"clé d'accès secrète S3"`
+
+	sources map[string]string
+}
+
+// Options indique où chercher la configuration
+type Options struct {
+	// Fichier à lire. Vide : DefaultFile, ignoré s'il n'existe pas. Un fichier nommé explicitement doit exister.
+	File string
+	// Drapeaux déclarés par BindFlags ; seuls ceux passés sur la ligne de commande comptent
+	Flags *pflag.FlagSet
+	// Lecture de l'environnement, os.LookupEnv par défaut
+	LookupEnv func(string) (string, bool)
+}
+
+// Setting est un réglage tel qu'affiché par `config check`
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// BindFlags déclare un drapeau pour chaque réglage qui en a un
+func BindFlags(flags *pflag.FlagSet) {
+	forEachField(func(field reflect.StructField, _ reflect.Value) {
+		if name := field.Tag.Get("flag"); name != "" {
+			flags.String(name, field.Tag.Get("default"), field.Tag.Get("usage")+" ("+field.Tag.Get("env")+")")
+		}
+	})
+}
+
+// Load construit la configuration à partir de toutes les sources, sans la valider
+func Load(opts Options) (Config, error) {
+	lookup := opts.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	file, err := readFile(opts.File)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg := Config{sources: map[string]string{}}
+	var errs []error
+	forEachField(func(field reflect.StructField, value reflect.Value) {
+		key := field.Tag.Get("env")
+		raw, source := field.Tag.Get("default"), SourceDefault
+		if v, ok := file[key]; ok {
+			raw, source = v, SourceFile
+		}
+		if v, ok := lookup(key); ok {
+			raw, source = v, SourceEnv
+		}
+		if name := field.Tag.Get("flag"); name != "" && opts.Flags != nil && opts.Flags.Changed(name) {
+			raw, _ = opts.Flags.GetString(name)
+			source = SourceFlag
+		}
+		if raw == "" {
+			return
+		}
+		if err := set(value, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			return
+		}
+		cfg.sources[key] = source
+	}, &cfg)
+	if len(errs) > 0 {
+		return Config{}, fmt.Errorf("%w: %w", ErrInvalid, errors.Join(errs...))
+	}
+	return cfg, nil
+}
+
+// Validate vérifie les réglages obligatoires et la cohérence du paiement et du stockage
+func (c Config) Validate() error {
+	var errs []error
+	forEachField(func(field reflect.StructField, value reflect.Value) {
+		if field.Tag.Get("required") == "true" && value.IsZero() {
+			errs = append(errs, fmt.Errorf("%s is required", field.Tag.Get("env")))
+		}
+	}, &c)
+
+	forEachField(func(field reflect.StructField, value reflect.Value) {
+		if d, ok := value.Interface().(time.Duration); ok && d < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative", field.Tag.Get("env")))
+		}
+	}, &c)
+
+	switch c.Env {
+	case EnvDevelopment, EnvTest, EnvProduction:
+	default:
+		errs = append(errs, fmt.Errorf("APP_ENV must be %s, %s or %s, got %q", EnvDevelopment, EnvTest, EnvProduction, c.Env))
+	}
+
+	// Le fake crédite des jetons sans paiement : il doit être demandé explicitement, hors production
+	switch c.PaymentProvider {
+	case payment.ProviderFake:
+		if !c.Development() {
+			errs = append(errs, fmt.Errorf("PAYMENT_PROVIDER=%s is only allowed when APP_ENV is %s or %s", payment.ProviderFake, EnvDevelopment, EnvTest))
+		}
+	case payment.ProviderStripe:
+		if c.StripeSecretKey == "" {
+			errs = append(errs, errors.New("STRIPE_SECRET_KEY is required with the stripe payment provider"))
+		}
+		if c.StripeWebhookSecret == "" {
+			errs = append(errs, errors.New("STRIPE_WEBHOOK_SECRET is required with the stripe payment provider"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("PAYMENT_PROVIDER must be %s or %s, got %q", payment.ProviderStripe, payment.ProviderFake, c.PaymentProvider))
+	}
+
+	switch c.StorageBackend {
+	case storage.BackendLocal:
+	case storage.BackendS3:
+		for _, setting := range []struct{ key, value string }{
+			{"S3_ENDPOINT", c.S3Endpoint}, {"S3_BUCKET", c.S3Bucket}, {"S3_ACCESS_KEY", c.S3AccessKey}, {"S3_SECRET_KEY", c.S3SecretKey},
+		} {
+			if setting.value == "" {
+				errs = append(errs, fmt.Errorf("%s is required with the s3 storage backend", setting.key))
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf("STORAGE_BACKEND must be %s or %s, got %q", storage.BackendLocal, storage.BackendS3, c.StorageBackend))
+	}
+	if c.UploadMaxBytes <= 0 {
+		errs = append(errs, errors.New("UPLOAD_MAX_BYTES must be positive"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalid, errors.Join(errs...))
+	}
+	return nil
+}
+
+// Development indique un environnement de développement ou de test
+func (c Config) Development() bool {
+	return c.Env == EnvDevelopment || c.Env == EnvTest
+}
+
+// Settings liste les réglages avec leur origine ; les secrets renseignés sont masqués
+func (c Config) Settings() []Setting {
+	var settings []Setting
+	forEachField(func(field reflect.StructField, value reflect.Value) {
+		key := field.Tag.Get("env")
+		setting := Setting{Key: key, Value: fmt.Sprint(value.Interface()), Source: c.sources[key]}
+		if setting.Source == "" {
+			setting.Source = "unset"
+		}
+		if field.Tag.Get("secret") == "true" && !value.IsZero() {
+			setting.Value = "********"
+		}
+		settings = append(settings, setting)
+	}, &c)
+	return settings
+}
+
+// String affiche la configuration sans ses secrets
+func (c Config) String() string {
+	var b strings.Builder
+	for _, setting := range c.Settings() {
+		fmt.Fprintf(&b, "%s=%s (%s)\n", setting.Key, setting.Value, setting.Source)
+	}
+	return b.String()
+}
+
+func readFile(path string) (map[string]string, error) {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultFile
+	}
+	values, err := godotenv.Read(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("lecture du fichier de configuration %s : %w", path, err)
+	}
+	return values, nil
+}
+
+// forEachField parcourt les réglages de cfg (ou d'une configuration vide)
+func forEachField(fn func(reflect.StructField, reflect.Value), cfg ...*Config) {
+	target := &Config{}
+	if len(cfg) > 0 {
+		target = cfg[0]
+	}
+	v := reflect.ValueOf(target).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Tag.Get("env") == "" {
+			continue
+		}
+		fn(field, v.Field(i))
+	}
+}
+
+func set(value reflect.Value, raw string) error {
+	if unmarshaler, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(raw))
+	}
+	switch {
+	case value.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+	case value.Kind() == reflect.String:
+		value.SetString(raw)
+	case value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(n))
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"project/internal/payment"
+	"project/internal/storage"
+)
+
+// env simule l'environnement du processus
+func env(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := values[key]
+		return v, ok
+	}
+}
+
+// load charge la configuration depuis les seules variables données ; le dossier du paquet n'a pas de .env
+func load(t *testing.T, values map[string]string) Config {
+	t.Helper()
+	cfg, err := Load(Options{LookupEnv: env(values)})
+	if err != nil {
+		t.Fatalf("chargement : %v", err)
+	}
+	return cfg
+}
+
+// Variables minimales d'une configuration valide, complétées par extra
+func withBase(extra map[string]string) map[string]string {
+	values := map[string]string{"DB_URL": "postgres://localhost/app", "SECRET": "s3cr3t"}
+	for k, v := range extra {
+		values[k] = v
+	}
+	return values
+}
+
+// Le fournisseur fake n'est jamais choisi implicitement et reste interdit en production
+func TestValidatePaymentProvider(t *testing.T) {
+	stripe := map[string]string{"STRIPE_SECRET_KEY": "sk_test", "STRIPE_WEBHOOK_SECRET": "whsec"}
+	tests := []struct {
+		name   string
+		values map[string]string
+		errors []string // Extraits attendus dans l'erreur ; vide : configuration valide
+	}{
+		{"rien de configuré", nil, []string{"STRIPE_SECRET_KEY is required", "STRIPE_WEBHOOK_SECRET is required"}},
+		{"rien de configuré en développement", map[string]string{"APP_ENV": "development"}, []string{"STRIPE_SECRET_KEY is required"}},
+		{"stripe complet", stripe, nil},
+		{"stripe sans secret de webhook", map[string]string{"STRIPE_SECRET_KEY": "sk_test"}, []string{"STRIPE_WEBHOOK_SECRET is required"}},
+		{"fake en production", map[string]string{"PAYMENT_PROVIDER": "fake"}, []string{"PAYMENT_PROVIDER=fake is only allowed"}},
+		{"fake en développement", map[string]string{"APP_ENV": "development", "PAYMENT_PROVIDER": "fake"}, nil},
+		{"fake en test", map[string]string{"APP_ENV": "test", "PAYMENT_PROVIDER": "fake"}, nil},
+		{"fournisseur inconnu", map[string]string{"PAYMENT_PROVIDER": "paypal"}, []string{`PAYMENT_PROVIDER must be stripe or fake, got "paypal"`}},
+		{"environnement inconnu", map[string]string{"APP_ENV": "staging", "PAYMENT_PROVIDER": "fake"}, []string{`APP_ENV must be`, "PAYMENT_PROVIDER=fake is only allowed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := load(t, withBase(tt.values)).Validate()
+			if len(tt.errors) == 0 {
+				if err != nil {
+					t.Fatalf("configuration valide attendue : %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalid) {
+				t.Fatalf("ErrInvalid attendue, reçu %v", err)
+			}
+			for _, want := range tt.errors {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("%q attendu dans %q", want, err)
+				}
+			}
+		})
+	}
+}
+
+// Chaque source l'emporte sur la précédente : défaut, fichier, environnement puis drapeau
+func TestLoadPrecedence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.env")
+	if err := os.WriteFile(file, []byte("ADDR=:7000\nSTORAGE_DIR=/srv/fichier\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		file   string
+		env    map[string]string
+		args   []string
+		addr   string
+		source string
+	}{
+		{"défaut", "", nil, nil, ":8080", SourceDefault},
+		{"fichier", file, nil, nil, ":7000", SourceFile},
+		{"environnement sur fichier", file, map[string]string{"ADDR": ":7500"}, nil, ":7500", SourceEnv},
+		{"drapeau sur environnement", file, map[string]string{"ADDR": ":7500"}, []string{"--addr=:9000"}, ":9000", SourceFlag},
+		{"drapeau sur défaut", "", nil, []string{"--addr", ":9100"}, ":9100", SourceFlag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			BindFlags(flags)
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := Load(Options{File: tt.file, Flags: flags, LookupEnv: env(tt.env)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Addr != tt.addr {
+				t.Errorf("ADDR %q, %q attendu", cfg.Addr, tt.addr)
+			}
+			if source := setting(t, cfg, "ADDR").Source; source != tt.source {
+				t.Errorf("origine %q, %q attendue", source, tt.source)
+			}
+		})
+	}
+}
+
+// Sans aucune source, chaque réglage prend sa valeur par défaut
+func TestLoadDefaults(t *testing.T) {
+	cfg := load(t, nil)
+	if cfg.Env != EnvProduction || cfg.Development() {
+		t.Errorf("production attendue par défaut : %q", cfg.Env)
+	}
+	if cfg.PaymentProvider != payment.ProviderStripe || cfg.StorageBackend != storage.BackendLocal {
+		t.Errorf("stripe et stockage local attendus : %q, %q", cfg.PaymentProvider, cfg.StorageBackend)
+	}
+	if cfg.ReadTimeout != 15*time.Second || cfg.ShutdownTimeout != 20*time.Second || cfg.LogLevel != slog.LevelInfo {
+		t.Errorf("délais et niveau de log par défaut inattendus : %v, %v, %v", cfg.ReadTimeout, cfg.ShutdownTimeout, cfg.LogLevel)
+	}
+	if !cfg.LegacyRoutes || cfg.UploadMaxBytes != 5<<20 || cfg.S3Region != "us-east-1" {
+		t.Errorf("valeurs par défaut inattendues : %+v", cfg)
+	}
+	if source := setting(t, cfg, "DB_URL").Source; source != "unset" {
+		t.Errorf("DB_URL ne doit pas avoir d'origine, %q", source)
+	}
+}
+
+// Une valeur illisible fait échouer le chargement en nommant la variable
+func TestLoadInvalidValues(t *testing.T) {
+	tests := map[string]string{
+		"READ_TIMEOUT":     "quinze",
+		"LOG_LEVEL":        "bavard",
+		"LEGACY_ROUTES":    "peut-être",
+		"UPLOAD_MAX_BYTES": "5Mo",
+	}
+	for key, value := range tests {
+		t.Run(key, func(t *testing.T) {
+			_, err := Load(Options{LookupEnv: env(map[string]string{key: value})})
+			if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), key) {
+				t.Fatalf("ErrInvalid sur %s attendue, reçu %v", key, err)
+			}
+		})
+	}
+
+	if _, err := Load(Options{File: filepath.Join(t.TempDir(), "absent.env"), LookupEnv: env(nil)}); err == nil {
+		t.Error("un fichier nommé explicitement doit exister")
+	}
+}
+
+// Validate relève toutes les incohérences à la fois
+func TestValidateCombinations(t *testing.T) {
+	valid := map[string]string{
+		"DB_URL": "postgres://localhost/app", "SECRET": "s3cr3t",
+		"STRIPE_SECRET_KEY": "sk_test", "STRIPE_WEBHOOK_SECRET": "whsec",
+	}
+	s3 := map[string]string{
+		"STORAGE_BACKEND": "s3", "S3_ENDPOINT": "http://localhost:9000", "S3_BUCKET": "photos",
+		"S3_ACCESS_KEY": "access", "S3_SECRET_KEY": "secret",
+	}
+	tests := []struct {
+		name   string
+		values map[string]string
+		unset  []string
+		errors []string
+	}{
+		{"configuration complète", nil, nil, nil},
+		{"stockage s3 complet", s3, nil, nil},
+		{"réglages obligatoires absents", nil, []string{"DB_URL", "SECRET"}, []string{"DB_URL is required", "SECRET is required"}},
+		{"délai négatif", map[string]string{"WRITE_TIMEOUT": "-1s"}, nil, []string{"WRITE_TIMEOUT must not be negative"}},
+		{"s3 incomplet", map[string]string{"STORAGE_BACKEND": "s3", "S3_BUCKET": "photos"}, nil,
+			[]string{"S3_ENDPOINT is required", "S3_ACCESS_KEY is required", "S3_SECRET_KEY is required"}},
+		{"stockage inconnu", map[string]string{"STORAGE_BACKEND": "ftp"}, nil, []string{`STORAGE_BACKEND must be local or s3, got "ftp"`}},
+		{"taille de photo nulle", map[string]string{"UPLOAD_MAX_BYTES": "0"}, nil, []string{"UPLOAD_MAX_BYTES must be positive"}},
+		{"fake et s3 incomplet en production", map[string]string{"PAYMENT_PROVIDER": "fake", "STORAGE_BACKEND": "s3"}, nil,
+			[]string{"PAYMENT_PROVIDER=fake is only allowed", "S3_BUCKET is required"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := map[string]string{}
+			for k, v := range valid {
+				values[k] = v
+			}
+			for k, v := range tt.values {
+				values[k] = v
+			}
+			for _, k := range tt.unset {
+				delete(values, k)
+			}
+			err := load(t, values).Validate()
+			if len(tt.errors) == 0 {
+				if err != nil {
+					t.Fatalf("configuration valide attendue : %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalid) {
+				t.Fatalf("ErrInvalid attendue, reçu %v", err)
+			}
+			for _, want := range tt.errors {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("%q attendu dans %q", want, err)
+				}
+			}
+		})
+	}
+}
+
+// L'affichage de la configuration masque les secrets renseignés
+func TestSettingsMaskSecrets(t *testing.T) {
+	cfg := load(t, withBase(map[string]string{"STRIPE_SECRET_KEY": "sk_live_123"}))
+	if value := setting(t, cfg, "STRIPE_SECRET_KEY").Value; value != "********" {
+		t.Errorf("secret affiché : %q", value)
+	}
+	if strings.Contains(cfg.String(), "sk_live_123") || strings.Contains(cfg.String(), "s3cr3t") {
+		t.Errorf("la configuration affichée contient un secret :\n%s", cfg)
+	}
+	if value := setting(t, cfg, "STRIPE_WEBHOOK_SECRET").Value; value != "" {
+		t.Errorf("un secret vide reste vide, %q", value)
+	}
+}
+
+func setting(t *testing.T, cfg Config, key string) Setting {
+	t.Helper()
+	for _, setting := range cfg.Settings() {
+		if setting.Key == key {
+			return setting
+		}
+	}
+	t.Fatalf("réglage %s inconnu", key)
+	return Setting{}
+}
@@ -0,0 +1,167 @@
+// Package imaging prépare les images envoyées par les clients : le format est reconnu au contenu,
+// la taille et le nombre de pixels sont bornés, l'orientation EXIF est appliquée puis l'image est
+// réduite et réencodée. Le réencodage ne recopie aucune métadonnée : EXIF, GPS et profils
+// disparaissent des fichiers stockés.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+)
+
+// Types acceptés, reconnus aux premiers octets et non à l'extension ou à l'en-tête du client
+const (
+	TypeJPEG = "image/jpeg"
+	TypePNG  = "image/png"
+	TypeGIF  = "image/gif"
+)
+
+var (
+	ErrTooLarge        = errors.New("image file too large")
+	ErrTooManyPixels   = errors.New("image dimensions too large")
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrInvalidImage    = errors.New("invalid image")
+)
+
+// Limits borne ce qui est accepté et fixe la taille des variantes produites
+type Limits struct {
+	// Taille maximale du fichier envoyé, en octets
+	MaxBytes int64
+	// Nombre maximal de pixels de l'image d'origine, vérifié avant de la décoder
+	MaxPixels int
+	// Plus grand côté de l'image stockée et de sa miniature
+	MaxSide       int
+	ThumbnailSide int
+}
+
+// DefaultLimits convient aux photos de téléphone : 5 Mo, 40 mégapixels, 1024 px et miniature de 256 px
+var DefaultLimits = Limits{MaxBytes: 5 << 20, MaxPixels: 40_000_000, MaxSide: 1024, ThumbnailSide: 256}
+
+// Variant est une version encodée de l'image
+type Variant struct {
+	Data   []byte
+	Width  int
+	Height int
+}
+
+// Processed est le résultat de Process : l'image réduite et sa miniature, dans le même format
+type Processed struct {
+	ContentType string
+	Ext         string
+	Full        Variant
+	Thumbnail   Variant
+}
+
+// Process lit une image depuis r et produit ses variantes selon limits.
+// Les JPEG restent des JPEG ; les PNG et les GIF (première image) deviennent des PNG.
+func Process(r io.Reader, limits Limits) (*Processed, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limits.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limits.MaxBytes {
+		return nil, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case TypeJPEG, TypePNG, TypeGIF:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImage, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > limits.MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImage, err)
+	}
+	if contentType == TypeJPEG {
+		src = orient(src, exifOrientation(data))
+	}
+
+	processed := &Processed{ContentType: TypePNG, Ext: ".png"}
+	encode := func(w io.Writer, img image.Image) error { return png.Encode(w, img) }
+	if contentType == TypeJPEG {
+		processed.ContentType, processed.Ext = TypeJPEG, ".jpg"
+		encode = func(w io.Writer, img image.Image) error { return jpeg.Encode(w, img, &jpeg.Options{Quality: 85}) }
+	}
+
+	if processed.Full, err = variant(src, limits.MaxSide, encode); err != nil {
+		return nil, err
+	}
+	if processed.Thumbnail, err = variant(src, limits.ThumbnailSide, encode); err != nil {
+		return nil, err
+	}
+	return processed, nil
+}
+
+func variant(src image.Image, maxSide int, encode func(io.Writer, image.Image) error) (Variant, error) {
+	img := Fit(src, maxSide)
+	var buf bytes.Buffer
+	if err := encode(&buf, img); err != nil {
+		return Variant{}, err
+	}
+	return Variant{Data: buf.Bytes(), Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}, nil
+}
+
+// Fit réduit src pour que son plus grand côté ne dépasse pas maxSide, en moyennant les pixels
+// recouverts par chaque pixel de destination. Une image déjà assez petite est seulement recopiée.
+func Fit(src image.Image, maxSide int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dw, dh := sw, sh
+	if sw > maxSide || sh > maxSide {
+		if sw >= sh {
+			dw, dh = maxSide, max(1, sh*maxSide/sw)
+		} else {
+			dw, dh = max(1, sw*maxSide/sh), maxSide
+		}
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, sw, sh))
+	draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
+	if dw == sw && dh == sh {
+		return rgba
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+			var r, g, bl, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := rgba.Pix[sy*rgba.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += int(p[0])
+					g += int(p[1])
+					bl += int(p[2])
+					a += int(p[3])
+					n++
+				}
+			}
+			o := dst.PixOffset(x, y)
+			dst.Pix[o+0] = uint8(r / n)
+			dst.Pix[o+1] = uint8(g / n)
+			dst.Pix[o+2] = uint8(bl / n)
+			dst.Pix[o+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
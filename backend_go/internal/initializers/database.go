@@ -1,25 +0,0 @@
-package initializers
-
-import (
-	"fmt"
-	"log"
-	"os"
-
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
-)
-
-var DB *gorm.DB
-
-func ConnectToDatabase() {
-	var err error
-	dsn := os.Getenv("DB_URL")
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		DisableForeignKeyConstraintWhenMigrating: true,
-	})
-
-	if err != nil {
-		log.Fatal("Failed to connect to database ", err)
-	}
-	fmt.Println("database is connect")
-}
@@ -0,0 +1,60 @@
+// Package jobs exécute les tâches de fond du serveur et attend leur fin à l'arrêt,
+// comme http.Server.Shutdown le fait pour les requêtes.
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Runner suit les tâches lancées ; après Shutdown, plus aucune tâche ne démarre
+type Runner struct {
+	logger *slog.Logger
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(logger *slog.Logger) *Runner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runner{logger: logger, ctx: ctx, cancel: cancel}
+}
+
+// Every exécute fn toutes les interval jusqu'à l'arrêt. Le contexte passé à fn est annulé
+// à l'arrêt : une exécution en cours doit s'interrompre proprement.
+func (r *Runner) Every(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := fn(r.ctx); err != nil && r.ctx.Err() == nil {
+					r.logger.Error("job failed", "job", name, "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Shutdown arrête les tâches et attend qu'elles se terminent, au plus jusqu'à l'échéance de ctx
+func (r *Runner) Shutdown(ctx context.Context) error {
+	r.cancel()
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,84 @@
+package listing
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Find applique les filtres, la recherche et la plage de dates de q à db, compte les lignes
+// correspondantes puis charge la page demandée, triée, avec les relations preloads.
+// Le tri se termine toujours par l'identifiant pour que la pagination soit stable.
+func Find[T any](db *gorm.DB, q Query, preloads ...string) (Page[T], error) {
+	page := Page[T]{Items: []T{}}
+	db = q.Scope(db.Model(new(T)))
+
+	if err := db.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return page, err
+	}
+	if page.Total == 0 || int64(q.Offset) >= page.Total {
+		return page, nil
+	}
+
+	for _, preload := range preloads {
+		db = db.Preload(preload)
+	}
+	if err := db.Order(q.orderBy()).Limit(q.Limit).Offset(q.Offset).Find(&page.Items).Error; err != nil {
+		return page, err
+	}
+	return page, nil
+}
+
+// Scope applique à db les filtres, la recherche et la plage de dates de q, sans pagination
+func (q Query) Scope(db *gorm.DB) *gorm.DB {
+	for name, value := range q.filters {
+		where := q.spec.Filters[name].Where
+		args := make([]any, strings.Count(where, "?"))
+		for i := range args {
+			args[i] = value
+		}
+		db = db.Where(where, args...)
+	}
+	if q.Search != "" && len(q.spec.Search) > 0 {
+		pattern := "%" + escapeLike(strings.ToLower(q.Search)) + "%"
+		conditions := make([]string, 0, len(q.spec.Search))
+		args := make([]any, 0, len(q.spec.Search))
+		for _, column := range q.spec.Search {
+			conditions = append(conditions, "LOWER("+column+`) LIKE ? ESCAPE '\'`)
+			args = append(args, pattern)
+		}
+		db = db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+	if !q.From.IsZero() {
+		db = db.Where(clause.Gte{Column: clause.Column{Table: clause.CurrentTable, Name: q.spec.Date}, Value: q.From})
+	}
+	if !q.Before.IsZero() {
+		db = db.Where(clause.Lt{Column: clause.Column{Table: clause.CurrentTable, Name: q.spec.Date}, Value: q.Before})
+	}
+	return db
+}
+
+func (q Query) orderBy() clause.OrderBy {
+	var order clause.OrderBy
+	byID := false
+	for _, s := range q.Sort {
+		column := q.spec.Sorts[s.Field]
+		byID = byID || column == "id"
+		order.Columns = append(order.Columns, clause.OrderByColumn{
+			Column: clause.Column{Table: clause.CurrentTable, Name: column},
+			Desc:   s.Desc,
+		})
+	}
+	if !byID {
+		order.Columns = append(order.Columns, clause.OrderByColumn{
+			Column: clause.Column{Table: clause.CurrentTable, Name: "id"},
+		})
+	}
+	return order
+}
+
+// escapeLike neutralise les jokers de LIKE saisis dans la recherche
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
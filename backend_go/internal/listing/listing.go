@@ -0,0 +1,243 @@
+// Package listing lit la pagination, le tri et les filtres des listes de l'API depuis la query string.
+//
+// Chaque liste déclare dans une Spec les champs triables, les filtres, les colonnes de la recherche
+// et la colonne bornée par from et to. Parse valide les paramètres : une valeur mal formée ou un tri
+// non déclaré est refusé avec une erreur de validation plutôt qu'ignoré.
+package listing
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"project/internal/apperror"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// Paramètres de la query string communs à toutes les listes
+const (
+	ParamLimit  = "limit"
+	ParamOffset = "offset"
+	ParamSort   = "sort"
+	ParamSearch = "q"
+	ParamFrom   = "from"
+	ParamTo     = "to"
+)
+
+const dateLayout = "2006-01-02"
+
+// ErrInvalidQuery est retourné quand la pagination, le tri ou un filtre est invalide
+var ErrInvalidQuery = apperror.Validation("invalid_query", "invalid list parameters")
+
+// Filter décrit un filtre de la liste. La valeur demandée est passée à chaque ? de Where.
+type Filter struct {
+	Where string
+	// Numeric impose un identifiant entier positif
+	Numeric bool
+	// Values restreint un filtre texte à ces valeurs
+	Values []string
+}
+
+// Spec décrit ce qu'une liste accepte
+type Spec struct {
+	// Sorts associe les champs triables à leur colonne
+	Sorts map[string]string
+	// DefaultSort est le tri appliqué sans paramètre sort, par exemple "-date"
+	DefaultSort string
+	Filters     map[string]Filter
+	// Search liste les colonnes comparées au paramètre q ; sans colonne, q est refusé
+	Search []string
+	// Date est la colonne bornée par from et to ; sans colonne, from et to sont refusés
+	Date string
+}
+
+// Sort est un champ de tri, décroissant si Desc
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Query est une demande de liste validée
+type Query struct {
+	Limit  int
+	Offset int
+	Sort   []Sort
+	Search string
+	// From et Before bornent la colonne de date, Before exclu ; une borne nulle ne filtre pas
+	From   time.Time
+	Before time.Time
+
+	spec    Spec
+	filters map[string]string
+}
+
+// Page est une page de résultats avec le nombre total de lignes correspondant aux filtres
+type Page[T any] struct {
+	Items []T
+	Total int64
+}
+
+// Map convertit chaque ligne de la page avec fn, sans toucher au total
+func Map[T, U any](page Page[T], fn func(T) U) Page[U] {
+	items := make([]U, 0, len(page.Items))
+	for _, item := range page.Items {
+		items = append(items, fn(item))
+	}
+	return Page[U]{Items: items, Total: page.Total}
+}
+
+// Parse lit la demande de liste dans values selon spec. Les paramètres inconnus sont ignorés.
+func Parse(values url.Values, spec Spec) (Query, error) {
+	q := Query{Limit: DefaultLimit, spec: spec, filters: map[string]string{}}
+	var invalid []apperror.FieldError
+
+	if v := values.Get(ParamLimit); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			invalid = append(invalid, fieldError(ParamLimit, "range", "must be between 1 and "+strconv.Itoa(MaxLimit)))
+		}
+		q.Limit = limit
+	}
+	if v := values.Get(ParamOffset); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			invalid = append(invalid, fieldError(ParamOffset, "min", "must be a positive integer"))
+		}
+		q.Offset = offset
+	}
+
+	sort := values.Get(ParamSort)
+	if sort == "" {
+		sort = spec.DefaultSort
+	}
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		s := Sort{Field: strings.TrimPrefix(field, "-"), Desc: strings.HasPrefix(field, "-")}
+		if _, ok := spec.Sorts[s.Field]; !ok {
+			invalid = append(invalid, fieldError(ParamSort, "oneof", "cannot sort by "+s.Field))
+			continue
+		}
+		q.Sort = append(q.Sort, s)
+	}
+
+	if v := strings.TrimSpace(values.Get(ParamSearch)); v != "" {
+		if len(spec.Search) == 0 {
+			invalid = append(invalid, fieldError(ParamSearch, "unsupported", "this list cannot be searched"))
+		}
+		q.Search = v
+	}
+
+	for _, param := range []string{ParamFrom, ParamTo} {
+		v := values.Get(param)
+		if v == "" {
+			continue
+		}
+		if spec.Date == "" {
+			invalid = append(invalid, fieldError(param, "unsupported", "this list has no date range"))
+			continue
+		}
+		t, dateOnly, err := parseTime(v)
+		if err != nil {
+			invalid = append(invalid, fieldError(param, "datetime", "must be a date (2006-01-02) or an RFC 3339 time"))
+			continue
+		}
+		if param == ParamFrom {
+			q.From = t
+		} else if dateOnly {
+			// Une date seule inclut toute la journée
+			q.Before = t.AddDate(0, 0, 1)
+		} else {
+			q.Before = t.Add(time.Nanosecond)
+		}
+	}
+	if !q.From.IsZero() && !q.Before.IsZero() && !q.From.Before(q.Before) {
+		invalid = append(invalid, fieldError(ParamTo, "gtefield", "must not be before from"))
+	}
+
+	for name, filter := range spec.Filters {
+		v := strings.TrimSpace(values.Get(name))
+		if v == "" {
+			continue
+		}
+		if filter.Numeric {
+			if id, err := strconv.ParseUint(v, 10, 64); err != nil || id == 0 {
+				invalid = append(invalid, fieldError(name, "numeric", "must be a positive integer"))
+				continue
+			}
+		}
+		if len(filter.Values) > 0 && !contains(filter.Values, v) {
+			invalid = append(invalid, apperror.FieldError{
+				Field:   name,
+				Code:    "oneof",
+				Message: "must be one of: " + strings.Join(filter.Values, ", "),
+				Param:   strings.Join(filter.Values, " "),
+			})
+			continue
+		}
+		q.filters[name] = v
+	}
+
+	if len(invalid) > 0 {
+		return Query{}, apperror.Validation(ErrInvalidQuery.Code, ErrInvalidQuery.Message, invalid...)
+	}
+	return q, nil
+}
+
+// Filter retourne la valeur du filtre s'il est demandé
+func (q Query) Filter(name string) (string, bool) {
+	v, ok := q.filters[name]
+	return v, ok
+}
+
+// With retourne une copie de la demande restreinte par le filtre name, par exemple à la ressource
+// parente désignée par le chemin
+func (q Query) With(name, value string) Query {
+	filters := make(map[string]string, len(q.filters)+1)
+	for k, v := range q.filters {
+		filters[k] = v
+	}
+	filters[name] = value
+	q.filters = filters
+	return q
+}
+
+// ID retourne la valeur d'un filtre numérique, 0 s'il n'est pas demandé
+func (q Query) ID(name string) uint {
+	id, _ := strconv.ParseUint(q.filters[name], 10, 64)
+	return uint(id)
+}
+
+// Next retourne l'offset de la page suivante, ou false si la page demandée est la dernière
+func (q Query) Next(total int64) (int, bool) {
+	next := q.Offset + q.Limit
+	return next, int64(next) < total
+}
+
+func parseTime(v string) (time.Time, bool, error) {
+	if t, err := time.Parse(dateLayout, v); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, false, err
+}
+
+func fieldError(field, code, message string) apperror.FieldError {
+	return apperror.FieldError{Field: field, Code: code, Message: message}
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package listing
+
+import "sort"
+
+// Slice trie et découpe des lignes déjà filtrées, pour les dépôts en mémoire.
+// compare associe chaque champ triable de la Spec à sa comparaison ; l'ordre d'origine départage.
+func Slice[T any](rows []T, q Query, compare map[string]func(a, b T) int) Page[T] {
+	sorted := append([]T(nil), rows...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		for _, s := range q.Sort {
+			cmp, ok := compare[s.Field]
+			if !ok {
+				continue
+			}
+			c := cmp(sorted[i], sorted[j])
+			if s.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	page := Page[T]{Items: []T{}, Total: int64(len(sorted))}
+	if q.Offset < len(sorted) {
+		end := min(q.Offset+q.Limit, len(sorted))
+		page.Items = sorted[q.Offset:end]
+	}
+	return page
+}
@@ -0,0 +1,51 @@
+// Package logging construit le logger JSON de l'application et le transporte dans le contexte
+// des requêtes, avec l'identifiant de requête, pour que chaque ligne puisse être corrélée.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+type loggerKey struct{}
+type requestIDKey struct{}
+
+// New retourne un logger JSON écrivant dans w à partir du niveau donné
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// Discard retourne un logger qui n'écrit rien
+func Discard() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(io.Discard, nil))
+}
+
+// WithLogger rattache un logger au contexte
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext retourne le logger du contexte, ou le logger par défaut s'il n'y en a pas
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// With ajoute des attributs au logger du contexte
+func With(ctx context.Context, args ...any) context.Context {
+	return WithLogger(ctx, FromContext(ctx).With(args...))
+}
+
+// WithRequestID rattache l'identifiant de requête au contexte et à son logger
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return With(context.WithValue(ctx, requestIDKey{}, id), "request_id", id)
+}
+
+// RequestID retourne l'identifiant de la requête en cours, vide hors requête
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
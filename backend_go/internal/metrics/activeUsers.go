@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// Un utilisateur est actif s'il a dépensé des jetons sur un stand de la kermesse dans cette fenêtre
+const activeWindow = 15 * time.Minute
+
+// Délai laissé à la requête de comptage pendant une collecte
+const collectTimeout = 5 * time.Second
+
+// activeUsersCollector compte les utilisateurs actifs par kermesse au moment de la collecte.
+// Les cartes prépayées anonymes comptent chacune pour un visiteur.
+type activeUsersCollector struct {
+	db   *gorm.DB
+	desc *prometheus.Desc
+}
+
+func newActiveUsersCollector(db *gorm.DB) *activeUsersCollector {
+	return &activeUsersCollector{
+		db: db,
+		desc: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "active_users"),
+			"Visiteurs ayant dépensé des jetons dans les 15 dernières minutes, par kermesse.",
+			[]string{"kermesse"}, nil),
+	}
+}
+
+func (c *activeUsersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *activeUsersCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	var rows []struct {
+		KermesseID uint
+		Active     int64
+	}
+	err := c.db.WithContext(ctx).Table("histories").
+		Select("kermesse_stands.kermesse_id AS kermesse_id, "+
+			"COUNT(DISTINCT histories.user_id) + COUNT(DISTINCT CASE WHEN histories.user_id IS NULL THEN histories.prepaid_card_id END) AS active").
+		Joins("JOIN kermesse_stands ON kermesse_stands.stand_id = histories.stand_id").
+		Where("histories.date >= ? AND histories.reversed_at IS NULL AND histories.type <> ?", time.Now().Add(-activeWindow), "reversal").
+		Group("kermesse_stands.kermesse_id").
+		Scan(&rows).Error
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for _, row := range rows {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(row.Active), id(row.KermesseID))
+	}
+}
@@ -0,0 +1,146 @@
+// Package metrics expose l'activité HTTP et l'activité de la kermesse au format Prometheus.
+// Chaque application a son propre registre : rien n'est enregistré dans le registre global.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+	"project/internal/models"
+)
+
+const namespace = "kermesse"
+
+// Metrics regroupe les métriques de l'application. Un *Metrics nil est accepté par les méthodes
+// Record* et n'enregistre rien : les services restent utilisables sans métriques.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requestDuration *prometheus.HistogramVec
+	jetonsSold      *prometheus.CounterVec
+	jetonsSpent     *prometheus.CounterVec
+	purchases       *prometheus.CounterVec
+	pointsAwarded   *prometheus.CounterVec
+	paymentsFailed  *prometheus.CounterVec
+}
+
+// New crée le registre et ses métriques ; les utilisateurs actifs sont calculés sur db à chaque collecte
+func New(db *gorm.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Durée de traitement des requêtes HTTP par route et statut.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		jetonsSold: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "jetons_sold_total",
+			Help:      "Jetons crédités après un paiement réussi, par fournisseur et kermesse.",
+		}, []string{"provider", "kermesse"}),
+		jetonsSpent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "jetons_spent_total",
+			Help:      "Jetons dépensés sur chaque stand, en achats et participations.",
+		}, []string{"stand"}),
+		purchases: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "product_purchases_total",
+			Help:      "Quantités achetées de chaque produit.",
+		}, []string{"product"}),
+		pointsAwarded: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "points_awarded_total",
+			Help:      "Points attribués par chaque stand.",
+		}, []string{"stand"}),
+		paymentsFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "payments_failed_total",
+			Help:      "Paiements en échec, par fournisseur et motif.",
+		}, []string{"provider", "reason"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requestDuration,
+		m.jetonsSold,
+		m.jetonsSpent,
+		m.purchases,
+		m.pointsAwarded,
+		m.paymentsFailed,
+		newActiveUsersCollector(db),
+	)
+	return m
+}
+
+// Handler sert les métriques au format d'exposition Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Instrument mesure la durée et le statut de chaque requête. La route est le motif déclaré
+// (/stands/:id) et non le chemin, pour garder un nombre de séries borné.
+func (m *Metrics) Instrument(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	m.requestDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+		Observe(time.Since(start).Seconds())
+}
+
+// RecordJetonsSold compte les jetons d'une transaction qui vient d'être créditée
+func (m *Metrics) RecordJetonsSold(transaction models.Transaction) {
+	if m == nil {
+		return
+	}
+	m.jetonsSold.WithLabelValues(transaction.Provider, optionalID(transaction.KermesseID)).Add(float64(transaction.Quantity))
+}
+
+// RecordSpending compte une entrée d'historique qui débite des jetons : participation ou achat
+func (m *Metrics) RecordSpending(history models.History) {
+	if m == nil {
+		return
+	}
+	m.jetonsSpent.WithLabelValues(id(history.StandID)).Add(float64(history.NbJetons))
+	if history.ProductID != nil {
+		m.purchases.WithLabelValues(id(*history.ProductID)).Add(float64(history.Quantity))
+	}
+}
+
+// RecordPoints compte les points attribués par un stand
+func (m *Metrics) RecordPoints(standID uint, points uint) {
+	if m == nil {
+		return
+	}
+	m.pointsAwarded.WithLabelValues(id(standID)).Add(float64(points))
+}
+
+// RecordPaymentFailure compte un paiement refusé par le fournisseur ou qui n'a pas pu aboutir
+func (m *Metrics) RecordPaymentFailure(provider, reason string) {
+	if m == nil {
+		return
+	}
+	m.paymentsFailed.WithLabelValues(provider, reason).Inc()
+}
+
+func id(value uint) string {
+	return strconv.FormatUint(uint64(value), 10)
+}
+
+func optionalID(value *uint) string {
+	if value == nil {
+		return "none"
+	}
+	return id(*value)
+}
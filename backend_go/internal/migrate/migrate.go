@@ -1,21 +1,229 @@
 package migrate
 
 import (
-	"project/internal/models"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
 
 	"gorm.io/gorm"
 )
 
-// MigrateDB effectue les migrations de la base de données.
+// Les migrations sont des fichiers SQL numérotés, un répertoire par dialecte :
+// sql/<dialecte>/<version>_<nom>.up.sql et sql/<dialecte>/<version>_<nom>.down.sql
+//
+//go:embed sql
+var files embed.FS
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+var ErrPendingMigrations = errors.New("the database schema is not up to date, run `migrate up`")
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status indique si une migration a été appliquée, et quand
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+type schemaMigration struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+// MigrateDB applique toutes les migrations en attente.
 func MigrateDB(DB *gorm.DB) error {
-	// Exécute les migrations pour créer les tables
-	return DB.AutoMigrate(
-		&models.User{},
-		&models.Kermesse{},
-		&models.Stand{},
-		&models.Product{},
-		&models.Transaction{},
-		&models.Jetons{},
-		&models.History{},
-	)
+	_, err := Up(DB)
+	return err
+}
+
+// Up applique les migrations en attente dans l'ordre, chacune dans sa propre transaction,
+// et retourne celles qui ont été appliquées.
+func Up(db *gorm.DB) ([]Migration, error) {
+	migrations, err := Load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureTable(db); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+			return tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+				migration.Version, migration.Name, time.Now()).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down annule les steps dernières migrations appliquées, de la plus récente à la plus ancienne.
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	migrations, err := Load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+			return tx.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Statuses liste toutes les migrations connues avec leur date d'application.
+func Statuses(db *gorm.DB) ([]Status, error) {
+	migrations, err := Load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	for _, migration := range migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// CheckCurrent retourne ErrPendingMigrations si une migration n'a pas été appliquée.
+// Le serveur refuse de démarrer sur un schéma qui n'est pas à jour.
+func CheckCurrent(db *gorm.DB) error {
+	statuses, err := Statuses(db)
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			return fmt.Errorf("%w (first pending: %04d_%s)", ErrPendingMigrations, status.Version, status.Name)
+		}
+	}
+	return nil
+}
+
+// Load lit les migrations embarquées pour un dialecte, triées par version.
+func Load(dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "sql/"+dialect)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for database %q: %w", dialect, err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		content, err := files.ReadFile("sql/" + dialect + "/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %04d has two names: %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// ensureTable crée la table de suivi des migrations, seulement avant d'en appliquer
+func ensureTable(db *gorm.DB) error {
+	return db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name varchar(255) NOT NULL,
+		applied_at timestamp NOT NULL
+	)`).Error
+}
+
+// appliedVersions lit les migrations appliquées sans rien écrire : elle sert aussi à CheckCurrent
+// et à /readyz. Une base sans table de suivi n'a aucune migration appliquée.
+func appliedVersions(db *gorm.DB) (map[int64]schemaMigration, error) {
+	applied := map[int64]schemaMigration{}
+	if !db.Migrator().HasTable("schema_migrations") {
+		return applied, nil
+	}
+
+	var rows []schemaMigration
+	if err := db.Table("schema_migrations").Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
 }
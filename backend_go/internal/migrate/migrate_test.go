@@ -0,0 +1,132 @@
+package migrate
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// open ouvre une base SQLite vide
+func open(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "migrate.db") + "?_pragma=foreign_keys(1)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func latest(t *testing.T) Migration {
+	t.Helper()
+	migrations, err := Load("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrations[len(migrations)-1]
+}
+
+// Vérifier l'état d'une base vide ne crée rien : elle est simplement en version 0
+func TestCheckCurrentIsReadOnly(t *testing.T) {
+	db := open(t)
+
+	if err := CheckCurrent(db); !errors.Is(err, ErrPendingMigrations) {
+		t.Fatalf("ErrPendingMigrations attendue, reçu %v", err)
+	}
+	statuses, err := Statuses(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			t.Errorf("migration %04d marquée appliquée", status.Version)
+		}
+	}
+	if db.Migrator().HasTable("schema_migrations") {
+		t.Error("la lecture de l'état ne doit pas créer la table de suivi")
+	}
+	if reverted, err := Down(db, 1); err != nil || len(reverted) != 0 {
+		t.Fatalf("rien à annuler attendu : %v, %v", reverted, err)
+	}
+}
+
+// Up applique tout une fois, Down annule la dernière migration et Up la réapplique
+func TestUpDownCurrent(t *testing.T) {
+	db := open(t)
+
+	applied, err := Up(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, _ := Load("sqlite")
+	if len(applied) != len(all) {
+		t.Fatalf("%d migrations appliquées, %d attendues", len(applied), len(all))
+	}
+	if err := CheckCurrent(db); err != nil {
+		t.Fatalf("schéma à jour attendu : %v", err)
+	}
+	if again, err := Up(db); err != nil || len(again) != 0 {
+		t.Fatalf("rien à réappliquer attendu : %v, %v", again, err)
+	}
+
+	last := latest(t)
+	reverted, err := Down(db, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reverted) != 1 || reverted[0].Version != last.Version {
+		t.Fatalf("annulation de %04d attendue : %v", last.Version, reverted)
+	}
+	if err := CheckCurrent(db); !errors.Is(err, ErrPendingMigrations) {
+		t.Fatalf("ErrPendingMigrations attendue après Down, reçu %v", err)
+	}
+
+	applied, err = Up(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 1 || applied[0].Version != last.Version {
+		t.Fatalf("seule %04d doit être réappliquée : %v", last.Version, applied)
+	}
+	if err := CheckCurrent(db); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Chaque migration s'annule entièrement puis se réapplique sur le même schéma
+func TestFullRollback(t *testing.T) {
+	db := open(t)
+	if _, err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+	all, _ := Load("sqlite")
+
+	reverted, err := Down(db, len(all))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reverted) != len(all) {
+		t.Fatalf("%d migrations annulées, %d attendues", len(reverted), len(all))
+	}
+	for _, table := range []string{"users", "stands", "kermesses", "transactions"} {
+		if db.Migrator().HasTable(table) {
+			t.Errorf("la table %s doit être supprimée", table)
+		}
+	}
+
+	if _, err := Up(db); err != nil {
+		t.Fatalf("réapplication après annulation complète : %v", err)
+	}
+	if err := CheckCurrent(db); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,194 @@
+-- Schéma initial, identique à celui créé jusqu'ici par AutoMigrate.
+-- Les tables sont créées avec IF NOT EXISTS pour qu'une base existante puisse être reprise telle quelle.
+
+CREATE TABLE IF NOT EXISTS users (
+    id            bigserial PRIMARY KEY,
+    firstname     varchar(64)  NOT NULL,
+    lastname      varchar(64)  NOT NULL,
+    email         varchar(100) NOT NULL,
+    password      varchar(100) NOT NULL,
+    picture       varchar(100),
+    role          bigint       NOT NULL,
+    jetons        bigint       NOT NULL DEFAULT 0,
+    pts_attribues bigint       DEFAULT 0,
+    updated_at    timestamptz,
+    CONSTRAINT uni_users_email UNIQUE (email)
+);
+
+CREATE TABLE IF NOT EXISTS user_parents (
+    user_id   bigint NOT NULL,
+    parent_id bigint NOT NULL,
+    PRIMARY KEY (user_id, parent_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_enfants (
+    user_id   bigint NOT NULL,
+    enfant_id bigint NOT NULL,
+    PRIMARY KEY (user_id, enfant_id)
+);
+
+CREATE TABLE IF NOT EXISTS kermesses (
+    id        bigserial PRIMARY KEY,
+    name      varchar(64) NOT NULL,
+    picture   varchar(64),
+    status    varchar(16) NOT NULL DEFAULT 'open',
+    closed_at timestamptz,
+    user_id   bigint      NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS kermesse_organisateurs (
+    kermesse_id bigint NOT NULL,
+    user_id     bigint NOT NULL,
+    PRIMARY KEY (kermesse_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS kermesse_participants (
+    kermesse_id bigint NOT NULL,
+    user_id     bigint NOT NULL,
+    PRIMARY KEY (kermesse_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS stands (
+    id            bigserial PRIMARY KEY,
+    name          varchar(64) NOT NULL,
+    type          varchar(64) NOT NULL,
+    pts_donnees   bigint      NOT NULL,
+    conso         bigint      NOT NULL,
+    jetons_requis bigint      NOT NULL,
+    user_id       bigint      NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS kermesse_stands (
+    kermesse_id bigint NOT NULL,
+    stand_id    bigint NOT NULL,
+    PRIMARY KEY (kermesse_id, stand_id)
+);
+
+CREATE TABLE IF NOT EXISTS products (
+    id            bigserial PRIMARY KEY,
+    name          varchar(64)  NOT NULL,
+    picture       varchar(100),
+    type          varchar(100) NOT NULL,
+    jetons_requis bigint       NOT NULL DEFAULT 0,
+    nb_products   bigint       NOT NULL DEFAULT 0,
+    stand_id      bigint       NOT NULL,
+    updated_at    timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS jetons (
+    id        bigserial PRIMARY KEY,
+    nb_jetons bigint,
+    price     decimal
+);
+
+CREATE TABLE IF NOT EXISTS tombolas (
+    id    bigserial PRIMARY KEY,
+    price decimal NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS till_sessions (
+    id            bigserial PRIMARY KEY,
+    status        varchar(16) NOT NULL DEFAULT 'open',
+    opened_at     timestamptz NOT NULL,
+    closed_at     timestamptz,
+    opening_float decimal     NOT NULL DEFAULT 0,
+    expected_cash decimal,
+    closing_count decimal,
+    discrepancy   decimal,
+    kermesse_id   bigint      NOT NULL,
+    cashier_id    bigint      NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS prepaid_cards (
+    id          bigserial PRIMARY KEY,
+    code        varchar(32) NOT NULL,
+    jetons      bigint      NOT NULL DEFAULT 0,
+    disabled    boolean     NOT NULL DEFAULT false,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    kermesse_id bigint      NOT NULL,
+    user_id     bigint,
+    linked_at   timestamptz,
+    CONSTRAINT uni_prepaid_cards_code UNIQUE (code)
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id                bigserial PRIMARY KEY,
+    type              text         NOT NULL,
+    date_transaction  timestamptz  NOT NULL,
+    price             decimal      NOT NULL,
+    quantity          bigint       NOT NULL,
+    status            varchar(16)  NOT NULL DEFAULT 'succeeded',
+    provider          varchar(16),
+    payment_intent_id varchar(255),
+    refund_id         varchar(255),
+    refunded_quantity bigint       NOT NULL DEFAULT 0,
+    refund_of_id      bigint,
+    kermesse_id       bigint,
+    till_session_id   bigint,
+    payment_method    varchar(16),
+    prepaid_card_id   bigint,
+    user_id           bigint
+);
+
+CREATE TABLE IF NOT EXISTS histories (
+    id              bigserial PRIMARY KEY,
+    date            timestamptz  NOT NULL,
+    type            varchar(32)  NOT NULL DEFAULT 'interaction',
+    nb_jetons       bigint       NOT NULL,
+    stand_name      text         NOT NULL,
+    stand_id        bigint       DEFAULT 0,
+    product_id      bigint,
+    quantity        bigint       DEFAULT 0,
+    user_id         bigint,
+    prepaid_card_id bigint,
+    reversed_at     timestamptz,
+    reversal_of_id  bigint,
+    operator_id     bigint,
+    reason          varchar(255)
+);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id                    bigserial PRIMARY KEY,
+    key                   varchar(255) NOT NULL,
+    user_id               bigint       NOT NULL,
+    method                varchar(8)   NOT NULL,
+    path                  varchar(255) NOT NULL,
+    fingerprint           varchar(64)  NOT NULL,
+    status                varchar(16)  NOT NULL DEFAULT 'in_progress',
+    created_at            timestamptz,
+    response_status       bigint,
+    response_content_type varchar(100),
+    response_body         bytea
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_idempotency_user_key ON idempotency_keys (key, user_id);
+
+CREATE TABLE IF NOT EXISTS stand_devices (
+    id           bigserial PRIMARY KEY,
+    name         varchar(64) NOT NULL,
+    secret       varchar(64) NOT NULL,
+    cursor       varchar(64),
+    last_sync_at timestamptz,
+    created_at   timestamptz,
+    stand_id     bigint      NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS sync_operations (
+    id          bigserial PRIMARY KEY,
+    client_id   varchar(64) NOT NULL,
+    device_id   bigint      NOT NULL,
+    type        varchar(16) NOT NULL,
+    recorded_at timestamptz NOT NULL,
+    received_at timestamptz NOT NULL,
+    signature   varchar(64) NOT NULL,
+    user_id     bigint,
+    card_code   varchar(32),
+    product_id  bigint,
+    quantity    bigint      DEFAULT 0,
+    points      bigint      DEFAULT 0,
+    status      varchar(16) NOT NULL,
+    reason      varchar(255),
+    history_id  bigint,
+    stand_id    bigint      NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sync_device_client ON sync_operations (client_id, device_id);
@@ -0,0 +1,65 @@
+DROP INDEX IF EXISTS idx_till_sessions_open_cashier;
+DROP INDEX IF EXISTS idx_sync_operations_stand_status;
+DROP INDEX IF EXISTS idx_stand_devices_stand;
+DROP INDEX IF EXISTS idx_histories_prepaid_card;
+DROP INDEX IF EXISTS idx_histories_stand_date;
+DROP INDEX IF EXISTS idx_histories_user;
+DROP INDEX IF EXISTS idx_transactions_prepaid_card;
+DROP INDEX IF EXISTS idx_transactions_till_session;
+DROP INDEX IF EXISTS idx_transactions_payment_intent;
+DROP INDEX IF EXISTS idx_transactions_kermesse_type;
+DROP INDEX IF EXISTS idx_transactions_user;
+DROP INDEX IF EXISTS idx_prepaid_cards_user;
+DROP INDEX IF EXISTS idx_prepaid_cards_kermesse;
+DROP INDEX IF EXISTS idx_till_sessions_kermesse;
+DROP INDEX IF EXISTS idx_products_stand;
+DROP INDEX IF EXISTS idx_kermesse_stands_stand;
+DROP INDEX IF EXISTS idx_stands_user;
+DROP INDEX IF EXISTS idx_kermesse_participants_user;
+DROP INDEX IF EXISTS idx_kermesse_organisateurs_user;
+DROP INDEX IF EXISTS idx_kermesses_user;
+DROP INDEX IF EXISTS idx_user_enfants_enfant;
+DROP INDEX IF EXISTS idx_user_parents_parent;
+
+ALTER TABLE sync_operations
+    DROP CONSTRAINT IF EXISTS fk_sync_operations_history,
+    DROP CONSTRAINT IF EXISTS fk_sync_operations_stand,
+    DROP CONSTRAINT IF EXISTS fk_sync_operations_device;
+ALTER TABLE stand_devices DROP CONSTRAINT IF EXISTS fk_stand_devices_stand;
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS fk_idempotency_keys_user;
+ALTER TABLE histories
+    DROP CONSTRAINT IF EXISTS fk_histories_operator,
+    DROP CONSTRAINT IF EXISTS fk_histories_reversal_of,
+    DROP CONSTRAINT IF EXISTS fk_histories_prepaid_card,
+    DROP CONSTRAINT IF EXISTS fk_histories_product,
+    DROP CONSTRAINT IF EXISTS fk_users_historique;
+ALTER TABLE transactions
+    DROP CONSTRAINT IF EXISTS fk_transactions_prepaid_card,
+    DROP CONSTRAINT IF EXISTS fk_till_sessions_sales,
+    DROP CONSTRAINT IF EXISTS fk_transactions_kermesse,
+    DROP CONSTRAINT IF EXISTS fk_transactions_refund_of,
+    DROP CONSTRAINT IF EXISTS fk_users_transactions;
+ALTER TABLE prepaid_cards
+    DROP CONSTRAINT IF EXISTS fk_prepaid_cards_user,
+    DROP CONSTRAINT IF EXISTS fk_prepaid_cards_kermesse;
+ALTER TABLE till_sessions
+    DROP CONSTRAINT IF EXISTS fk_till_sessions_cashier,
+    DROP CONSTRAINT IF EXISTS fk_till_sessions_kermesse;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS fk_stands_stock;
+ALTER TABLE kermesse_stands
+    DROP CONSTRAINT IF EXISTS fk_kermesse_stands_stand,
+    DROP CONSTRAINT IF EXISTS fk_kermesse_stands_kermesse;
+ALTER TABLE stands DROP CONSTRAINT IF EXISTS fk_users_stands;
+ALTER TABLE kermesse_participants
+    DROP CONSTRAINT IF EXISTS fk_kermesse_participants_user,
+    DROP CONSTRAINT IF EXISTS fk_kermesse_participants_kermesse;
+ALTER TABLE kermesse_organisateurs
+    DROP CONSTRAINT IF EXISTS fk_kermesse_organisateurs_user,
+    DROP CONSTRAINT IF EXISTS fk_kermesse_organisateurs_kermesse;
+ALTER TABLE kermesses DROP CONSTRAINT IF EXISTS fk_users_kermesses;
+ALTER TABLE user_enfants
+    DROP CONSTRAINT IF EXISTS fk_user_enfants_enfants,
+    DROP CONSTRAINT IF EXISTS fk_user_enfants_user;
+ALTER TABLE user_parents
+    DROP CONSTRAINT IF EXISTS fk_user_parents_parents,
+    DROP CONSTRAINT IF EXISTS fk_user_parents_user;
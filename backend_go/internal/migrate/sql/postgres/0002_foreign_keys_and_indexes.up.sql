@@ -0,0 +1,91 @@
+-- Clés étrangères : les liaisons disparaissent avec leurs deux côtés, les historiques financiers
+-- gardent leurs lignes (référence mise à NULL) et on ne peut pas supprimer ce qui porte des ventes.
+-- histories.stand_id n'a pas de clé étrangère : 0 désigne les entrées antérieures au suivi des stands.
+
+ALTER TABLE user_parents
+    ADD CONSTRAINT fk_user_parents_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_user_parents_parents FOREIGN KEY (parent_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE user_enfants
+    ADD CONSTRAINT fk_user_enfants_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_user_enfants_enfants FOREIGN KEY (enfant_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE kermesses
+    ADD CONSTRAINT fk_users_kermesses FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE kermesse_organisateurs
+    ADD CONSTRAINT fk_kermesse_organisateurs_kermesse FOREIGN KEY (kermesse_id) REFERENCES kermesses (id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_kermesse_organisateurs_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE kermesse_participants
+    ADD CONSTRAINT fk_kermesse_participants_kermesse FOREIGN KEY (kermesse_id) REFERENCES kermesses (id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_kermesse_participants_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE stands
+    ADD CONSTRAINT fk_users_stands FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE kermesse_stands
+    ADD CONSTRAINT fk_kermesse_stands_kermesse FOREIGN KEY (kermesse_id) REFERENCES kermesses (id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_kermesse_stands_stand FOREIGN KEY (stand_id) REFERENCES stands (id) ON DELETE CASCADE;
+
+ALTER TABLE products
+    ADD CONSTRAINT fk_stands_stock FOREIGN KEY (stand_id) REFERENCES stands (id) ON DELETE CASCADE;
+
+ALTER TABLE till_sessions
+    ADD CONSTRAINT fk_till_sessions_kermesse FOREIGN KEY (kermesse_id) REFERENCES kermesses (id),
+    ADD CONSTRAINT fk_till_sessions_cashier FOREIGN KEY (cashier_id) REFERENCES users (id);
+
+ALTER TABLE prepaid_cards
+    ADD CONSTRAINT fk_prepaid_cards_kermesse FOREIGN KEY (kermesse_id) REFERENCES kermesses (id),
+    ADD CONSTRAINT fk_prepaid_cards_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL;
+
+ALTER TABLE transactions
+    ADD CONSTRAINT fk_users_transactions FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_transactions_refund_of FOREIGN KEY (refund_of_id) REFERENCES transactions (id),
+    ADD CONSTRAINT fk_transactions_kermesse FOREIGN KEY (kermesse_id) REFERENCES kermesses (id),
+    ADD CONSTRAINT fk_till_sessions_sales FOREIGN KEY (till_session_id) REFERENCES till_sessions (id),
+    ADD CONSTRAINT fk_transactions_prepaid_card FOREIGN KEY (prepaid_card_id) REFERENCES prepaid_cards (id);
+
+ALTER TABLE histories
+    ADD CONSTRAINT fk_users_historique FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_histories_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_histories_prepaid_card FOREIGN KEY (prepaid_card_id) REFERENCES prepaid_cards (id),
+    ADD CONSTRAINT fk_histories_reversal_of FOREIGN KEY (reversal_of_id) REFERENCES histories (id),
+    ADD CONSTRAINT fk_histories_operator FOREIGN KEY (operator_id) REFERENCES users (id) ON DELETE SET NULL;
+
+ALTER TABLE idempotency_keys
+    ADD CONSTRAINT fk_idempotency_keys_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE stand_devices
+    ADD CONSTRAINT fk_stand_devices_stand FOREIGN KEY (stand_id) REFERENCES stands (id) ON DELETE CASCADE;
+
+ALTER TABLE sync_operations
+    ADD CONSTRAINT fk_sync_operations_device FOREIGN KEY (device_id) REFERENCES stand_devices (id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_sync_operations_stand FOREIGN KEY (stand_id) REFERENCES stands (id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_sync_operations_history FOREIGN KEY (history_id) REFERENCES histories (id) ON DELETE SET NULL;
+
+-- Index des clés étrangères et des recherches fréquentes
+CREATE INDEX idx_user_parents_parent ON user_parents (parent_id);
+CREATE INDEX idx_user_enfants_enfant ON user_enfants (enfant_id);
+CREATE INDEX idx_kermesses_user ON kermesses (user_id);
+CREATE INDEX idx_kermesse_organisateurs_user ON kermesse_organisateurs (user_id);
+CREATE INDEX idx_kermesse_participants_user ON kermesse_participants (user_id);
+CREATE INDEX idx_stands_user ON stands (user_id);
+CREATE INDEX idx_kermesse_stands_stand ON kermesse_stands (stand_id);
+CREATE INDEX idx_products_stand ON products (stand_id);
+CREATE INDEX idx_till_sessions_kermesse ON till_sessions (kermesse_id);
+CREATE INDEX idx_prepaid_cards_kermesse ON prepaid_cards (kermesse_id);
+CREATE INDEX idx_prepaid_cards_user ON prepaid_cards (user_id);
+CREATE INDEX idx_transactions_user ON transactions (user_id);
+CREATE INDEX idx_transactions_kermesse_type ON transactions (kermesse_id, type);
+CREATE INDEX idx_transactions_payment_intent ON transactions (payment_intent_id);
+CREATE INDEX idx_transactions_till_session ON transactions (till_session_id);
+CREATE INDEX idx_transactions_prepaid_card ON transactions (prepaid_card_id);
+CREATE INDEX idx_histories_user ON histories (user_id);
+CREATE INDEX idx_histories_stand_date ON histories (stand_id, date);
+CREATE INDEX idx_histories_prepaid_card ON histories (prepaid_card_id);
+CREATE INDEX idx_stand_devices_stand ON stand_devices (stand_id);
+CREATE INDEX idx_sync_operations_stand_status ON sync_operations (stand_id, status);
+
+-- Un caissier n'a qu'une seule caisse ouverte à la fois
+CREATE UNIQUE INDEX idx_till_sessions_open_cashier ON till_sessions (cashier_id) WHERE status = 'open';
@@ -0,0 +1,6 @@
+-- Réglages propres à une base, comme le marqueur "disposable" qui autorise seed --clean
+CREATE TABLE database_settings (
+    name       varchar(64)  PRIMARY KEY,
+    value      varchar(255) NOT NULL,
+    updated_at timestamptz  NOT NULL DEFAULT now()
+);
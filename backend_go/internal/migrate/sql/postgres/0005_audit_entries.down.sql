@@ -0,0 +1,4 @@
+-- Le journal n'est jamais supprimé : seuls les triggers qui le verrouillent sont retirés
+DROP TRIGGER IF EXISTS trg_audit_entries_no_truncate ON audit_entries;
+DROP TRIGGER IF EXISTS trg_audit_entries_append_only ON audit_entries;
+DROP FUNCTION IF EXISTS audit_entries_append_only();
@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS sync_operations;
+DROP TABLE IF EXISTS stand_devices;
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS histories;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS prepaid_cards;
+DROP TABLE IF EXISTS till_sessions;
+DROP TABLE IF EXISTS tombolas;
+DROP TABLE IF EXISTS jetons;
+DROP TABLE IF EXISTS products;
+DROP TABLE IF EXISTS kermesse_stands;
+DROP TABLE IF EXISTS stands;
+DROP TABLE IF EXISTS kermesse_participants;
+DROP TABLE IF EXISTS kermesse_organisateurs;
+DROP TABLE IF EXISTS kermesses;
+DROP TABLE IF EXISTS user_enfants;
+DROP TABLE IF EXISTS user_parents;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,195 @@
+-- Schéma initial pour SQLite, utilisé par les tests de bout en bout.
+-- SQLite ne sait pas ajouter une clé étrangère à une table existante : elles sont déclarées ici
+-- avec les tables, là où PostgreSQL les ajoute en 0002.
+
+CREATE TABLE IF NOT EXISTS users (
+    id            integer PRIMARY KEY AUTOINCREMENT,
+    firstname     varchar(64)  NOT NULL,
+    lastname      varchar(64)  NOT NULL,
+    email         varchar(100) NOT NULL,
+    password      varchar(100) NOT NULL,
+    picture       varchar(100),
+    role          integer      NOT NULL,
+    jetons        integer      NOT NULL DEFAULT 0,
+    pts_attribues integer      DEFAULT 0,
+    updated_at    datetime,
+    CONSTRAINT uni_users_email UNIQUE (email)
+);
+
+CREATE TABLE IF NOT EXISTS user_parents (
+    user_id   integer NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    parent_id integer NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, parent_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_enfants (
+    user_id   integer NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    enfant_id integer NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, enfant_id)
+);
+
+CREATE TABLE IF NOT EXISTS kermesses (
+    id        integer PRIMARY KEY AUTOINCREMENT,
+    name      varchar(64) NOT NULL,
+    picture   varchar(64),
+    status    varchar(16) NOT NULL DEFAULT 'open',
+    closed_at datetime,
+    user_id   integer     NOT NULL REFERENCES users (id)
+);
+
+CREATE TABLE IF NOT EXISTS kermesse_organisateurs (
+    kermesse_id integer NOT NULL REFERENCES kermesses (id) ON DELETE CASCADE,
+    user_id     integer NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (kermesse_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS kermesse_participants (
+    kermesse_id integer NOT NULL REFERENCES kermesses (id) ON DELETE CASCADE,
+    user_id     integer NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (kermesse_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS stands (
+    id            integer PRIMARY KEY AUTOINCREMENT,
+    name          varchar(64) NOT NULL,
+    type          varchar(64) NOT NULL,
+    pts_donnees   integer     NOT NULL,
+    conso         integer     NOT NULL,
+    jetons_requis integer     NOT NULL,
+    user_id       integer     NOT NULL REFERENCES users (id)
+);
+
+CREATE TABLE IF NOT EXISTS kermesse_stands (
+    kermesse_id integer NOT NULL REFERENCES kermesses (id) ON DELETE CASCADE,
+    stand_id    integer NOT NULL REFERENCES stands (id) ON DELETE CASCADE,
+    PRIMARY KEY (kermesse_id, stand_id)
+);
+
+CREATE TABLE IF NOT EXISTS products (
+    id            integer PRIMARY KEY AUTOINCREMENT,
+    name          varchar(64)  NOT NULL,
+    picture       varchar(100),
+    type          varchar(100) NOT NULL,
+    jetons_requis integer      NOT NULL DEFAULT 0,
+    nb_products   integer      NOT NULL DEFAULT 0,
+    stand_id      integer      NOT NULL REFERENCES stands (id) ON DELETE CASCADE,
+    updated_at    datetime
+);
+
+CREATE TABLE IF NOT EXISTS jetons (
+    id        integer PRIMARY KEY AUTOINCREMENT,
+    nb_jetons integer,
+    price     real
+);
+
+CREATE TABLE IF NOT EXISTS tombolas (
+    id    integer PRIMARY KEY AUTOINCREMENT,
+    price real NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS till_sessions (
+    id            integer PRIMARY KEY AUTOINCREMENT,
+    status        varchar(16) NOT NULL DEFAULT 'open',
+    opened_at     datetime    NOT NULL,
+    closed_at     datetime,
+    opening_float real        NOT NULL DEFAULT 0,
+    expected_cash real,
+    closing_count real,
+    discrepancy   real,
+    kermesse_id   integer     NOT NULL REFERENCES kermesses (id),
+    cashier_id    integer     NOT NULL REFERENCES users (id)
+);
+
+CREATE TABLE IF NOT EXISTS prepaid_cards (
+    id          integer PRIMARY KEY AUTOINCREMENT,
+    code        varchar(32) NOT NULL,
+    jetons      integer     NOT NULL DEFAULT 0,
+    disabled    boolean     NOT NULL DEFAULT false,
+    created_at  datetime,
+    updated_at  datetime,
+    kermesse_id integer     NOT NULL REFERENCES kermesses (id),
+    user_id     integer     REFERENCES users (id) ON DELETE SET NULL,
+    linked_at   datetime,
+    CONSTRAINT uni_prepaid_cards_code UNIQUE (code)
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id                integer PRIMARY KEY AUTOINCREMENT,
+    type              text         NOT NULL,
+    date_transaction  datetime     NOT NULL,
+    price             real         NOT NULL,
+    quantity          integer      NOT NULL,
+    status            varchar(16)  NOT NULL DEFAULT 'succeeded',
+    provider          varchar(16),
+    payment_intent_id varchar(255),
+    refund_id         varchar(255),
+    refunded_quantity integer      NOT NULL DEFAULT 0,
+    refund_of_id      integer      REFERENCES transactions (id),
+    kermesse_id       integer      REFERENCES kermesses (id),
+    till_session_id   integer      REFERENCES till_sessions (id),
+    payment_method    varchar(16),
+    prepaid_card_id   integer      REFERENCES prepaid_cards (id),
+    user_id           integer      REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS histories (
+    id              integer PRIMARY KEY AUTOINCREMENT,
+    date            datetime     NOT NULL,
+    type            varchar(32)  NOT NULL DEFAULT 'interaction',
+    nb_jetons       integer      NOT NULL,
+    stand_name      text         NOT NULL,
+    stand_id        integer      DEFAULT 0,
+    product_id      integer      REFERENCES products (id) ON DELETE SET NULL,
+    quantity        integer      DEFAULT 0,
+    user_id         integer      REFERENCES users (id) ON DELETE SET NULL,
+    prepaid_card_id integer      REFERENCES prepaid_cards (id),
+    reversed_at     datetime,
+    reversal_of_id  integer      REFERENCES histories (id),
+    operator_id     integer      REFERENCES users (id) ON DELETE SET NULL,
+    reason          varchar(255)
+);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id                    integer PRIMARY KEY AUTOINCREMENT,
+    key                   varchar(255) NOT NULL,
+    user_id               integer      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    method                varchar(8)   NOT NULL,
+    path                  varchar(255) NOT NULL,
+    fingerprint           varchar(64)  NOT NULL,
+    status                varchar(16)  NOT NULL DEFAULT 'in_progress',
+    created_at            datetime,
+    response_status       integer,
+    response_content_type varchar(100),
+    response_body         blob
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_idempotency_user_key ON idempotency_keys (key, user_id);
+
+CREATE TABLE IF NOT EXISTS stand_devices (
+    id           integer PRIMARY KEY AUTOINCREMENT,
+    name         varchar(64) NOT NULL,
+    secret       varchar(64) NOT NULL,
+    cursor       varchar(64),
+    last_sync_at datetime,
+    created_at   datetime,
+    stand_id     integer     NOT NULL REFERENCES stands (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS sync_operations (
+    id          integer PRIMARY KEY AUTOINCREMENT,
+    client_id   varchar(64) NOT NULL,
+    device_id   integer     NOT NULL REFERENCES stand_devices (id) ON DELETE CASCADE,
+    type        varchar(16) NOT NULL,
+    recorded_at datetime    NOT NULL,
+    received_at datetime    NOT NULL,
+    signature   varchar(64) NOT NULL,
+    user_id     integer,
+    card_code   varchar(32),
+    product_id  integer,
+    quantity    integer     DEFAULT 0,
+    points      integer     DEFAULT 0,
+    status      varchar(16) NOT NULL,
+    reason      varchar(255),
+    history_id  integer     REFERENCES histories (id) ON DELETE SET NULL,
+    stand_id    integer     NOT NULL REFERENCES stands (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sync_device_client ON sync_operations (client_id, device_id);
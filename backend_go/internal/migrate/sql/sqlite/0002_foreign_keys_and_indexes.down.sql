@@ -0,0 +1,22 @@
+DROP INDEX IF EXISTS idx_till_sessions_open_cashier;
+DROP INDEX IF EXISTS idx_sync_operations_stand_status;
+DROP INDEX IF EXISTS idx_stand_devices_stand;
+DROP INDEX IF EXISTS idx_histories_prepaid_card;
+DROP INDEX IF EXISTS idx_histories_stand_date;
+DROP INDEX IF EXISTS idx_histories_user;
+DROP INDEX IF EXISTS idx_transactions_prepaid_card;
+DROP INDEX IF EXISTS idx_transactions_till_session;
+DROP INDEX IF EXISTS idx_transactions_payment_intent;
+DROP INDEX IF EXISTS idx_transactions_kermesse_type;
+DROP INDEX IF EXISTS idx_transactions_user;
+DROP INDEX IF EXISTS idx_prepaid_cards_user;
+DROP INDEX IF EXISTS idx_prepaid_cards_kermesse;
+DROP INDEX IF EXISTS idx_till_sessions_kermesse;
+DROP INDEX IF EXISTS idx_products_stand;
+DROP INDEX IF EXISTS idx_kermesse_stands_stand;
+DROP INDEX IF EXISTS idx_stands_user;
+DROP INDEX IF EXISTS idx_kermesse_participants_user;
+DROP INDEX IF EXISTS idx_kermesse_organisateurs_user;
+DROP INDEX IF EXISTS idx_kermesses_user;
+DROP INDEX IF EXISTS idx_user_enfants_enfant;
+DROP INDEX IF EXISTS idx_user_parents_parent;
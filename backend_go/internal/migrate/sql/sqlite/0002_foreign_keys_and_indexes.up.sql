@@ -0,0 +1,26 @@
+-- Les clés étrangères sont déclarées en 0001 pour SQLite ; seuls les index sont ajoutés ici.
+
+CREATE INDEX idx_user_parents_parent ON user_parents (parent_id);
+CREATE INDEX idx_user_enfants_enfant ON user_enfants (enfant_id);
+CREATE INDEX idx_kermesses_user ON kermesses (user_id);
+CREATE INDEX idx_kermesse_organisateurs_user ON kermesse_organisateurs (user_id);
+CREATE INDEX idx_kermesse_participants_user ON kermesse_participants (user_id);
+CREATE INDEX idx_stands_user ON stands (user_id);
+CREATE INDEX idx_kermesse_stands_stand ON kermesse_stands (stand_id);
+CREATE INDEX idx_products_stand ON products (stand_id);
+CREATE INDEX idx_till_sessions_kermesse ON till_sessions (kermesse_id);
+CREATE INDEX idx_prepaid_cards_kermesse ON prepaid_cards (kermesse_id);
+CREATE INDEX idx_prepaid_cards_user ON prepaid_cards (user_id);
+CREATE INDEX idx_transactions_user ON transactions (user_id);
+CREATE INDEX idx_transactions_kermesse_type ON transactions (kermesse_id, type);
+CREATE INDEX idx_transactions_payment_intent ON transactions (payment_intent_id);
+CREATE INDEX idx_transactions_till_session ON transactions (till_session_id);
+CREATE INDEX idx_transactions_prepaid_card ON transactions (prepaid_card_id);
+CREATE INDEX idx_histories_user ON histories (user_id);
+CREATE INDEX idx_histories_stand_date ON histories (stand_id, date);
+CREATE INDEX idx_histories_prepaid_card ON histories (prepaid_card_id);
+CREATE INDEX idx_stand_devices_stand ON stand_devices (stand_id);
+CREATE INDEX idx_sync_operations_stand_status ON sync_operations (stand_id, status);
+
+-- Un caissier n'a qu'une seule caisse ouverte à la fois
+CREATE UNIQUE INDEX idx_till_sessions_open_cashier ON till_sessions (cashier_id) WHERE status = 'open';
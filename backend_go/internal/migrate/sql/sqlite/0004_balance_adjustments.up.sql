@@ -0,0 +1,15 @@
+-- Corrections manuelles des soldes : chaque ajustement garde son motif et son auteur
+CREATE TABLE balance_adjustments (
+    id              integer      PRIMARY KEY AUTOINCREMENT,
+    delta           integer      NOT NULL,
+    balance_after   integer      NOT NULL,
+    reason          varchar(255) NOT NULL,
+    operator        varchar(100) NOT NULL,
+    created_at      datetime,
+    user_id         integer      REFERENCES users (id) ON DELETE SET NULL,
+    prepaid_card_id integer      REFERENCES prepaid_cards (id) ON DELETE SET NULL,
+    CONSTRAINT chk_balance_adjustments_target CHECK (user_id IS NULL OR prepaid_card_id IS NULL)
+);
+
+CREATE INDEX idx_balance_adjustments_user_id ON balance_adjustments (user_id);
+CREATE INDEX idx_balance_adjustments_prepaid_card_id ON balance_adjustments (prepaid_card_id);
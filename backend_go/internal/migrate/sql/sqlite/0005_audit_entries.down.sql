@@ -0,0 +1,3 @@
+-- Le journal n'est jamais supprimé : seuls les triggers qui le verrouillent sont retirés
+DROP TRIGGER IF EXISTS trg_audit_entries_no_delete;
+DROP TRIGGER IF EXISTS trg_audit_entries_no_update;
@@ -0,0 +1,31 @@
+-- Journal d'audit des actions privilégiées et financières. Sans clé étrangère : une entrée
+-- survit à la suppression de son auteur ou de l'enregistrement visé. Le retour arrière garde la
+-- table : la réappliquer ne fait que reposer les triggers.
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id          integer      PRIMARY KEY AUTOINCREMENT,
+    created_at  datetime,
+    actor_id    integer,
+    actor       varchar(100) NOT NULL,
+    action      varchar(64)  NOT NULL,
+    entity_type varchar(32)  NOT NULL,
+    entity_id   integer      NOT NULL,
+    changes     text         NOT NULL,
+    ip          varchar(64),
+    request_id  varchar(128)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_entries_created_at ON audit_entries (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_actor_id ON audit_entries (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_action ON audit_entries (action);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_entity ON audit_entries (entity_type, entity_id);
+
+-- Le journal ne fait que grandir
+CREATE TRIGGER IF NOT EXISTS trg_audit_entries_no_update BEFORE UPDATE ON audit_entries
+BEGIN
+    SELECT RAISE(ABORT, 'audit_entries is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_audit_entries_no_delete BEFORE DELETE ON audit_entries
+BEGIN
+    SELECT RAISE(ABORT, 'audit_entries is append-only');
+END;
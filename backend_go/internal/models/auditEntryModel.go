@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// AuditEntry est une ligne du journal d'audit (voir le paquet audit). Changes est l'objet JSON des
+// champs modifiés, {"champ": {"from": ..., "to": ...}}. Les lignes ne sont ni modifiées ni supprimées :
+// l'auteur et l'enregistrement visé sont gardés sans clé étrangère pour survivre à leur suppression.
+type AuditEntry struct {
+	ID         uint      `gorm:"primary_key; not null; autoIncrement" json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	ActorID    *uint     `json:"actor_id" extensions:"x-nullable"`
+	Actor      string    `gorm:"size:100; not null" json:"actor"` // Email du compte, ou "cli:<login>", "webhook:<fournisseur>"
+	Action     string    `gorm:"size:64; not null" json:"action"`
+	EntityType string    `gorm:"size:32; not null" json:"entity_type"`
+	EntityID   uint      `gorm:"not null" json:"entity_id"`
+	Changes    string    `gorm:"not null" json:"-"`
+	IP         string    `gorm:"size:64" json:"ip"`
+	RequestID  string    `gorm:"size:128" json:"request_id"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// BalanceAdjustment trace une correction manuelle du solde d'un compte ou d'une carte prépayée,
+// faite en dehors des achats et des ventes (geste commercial, erreur de saisie, fusion d'une carte...).
+type BalanceAdjustment struct {
+	ID           uint      `gorm:"primary_key; not null; autoIncrement" json:"id"`
+	Delta        int       `gorm:"not null" json:"delta"`
+	BalanceAfter uint      `gorm:"not null" json:"balance_after"`
+	Reason       string    `gorm:"size:255; not null" json:"reason"`
+	Operator     string    `gorm:"size:100; not null" json:"operator"` // Email de l'utilisateur, ou "cli:<login>" en ligne de commande
+	CreatedAt    time.Time `json:"created_at"`
+
+	// Un seul des deux est renseigné
+	UserID        *uint `json:"user_id" extensions:"x-nullable"`
+	PrepaidCardID *uint `json:"prepaid_card_id" extensions:"x-nullable"`
+}
@@ -2,10 +2,29 @@ package models
 
 import "time"
 
+const (
+	HistoryTypeInteraction = "interaction"
+	HistoryTypePurchase    = "purchase"
+	HistoryTypeReversal    = "reversal"
+)
+
 type History struct {
 	ID        uint      `gorm:"primary_key; autoIncrement" json:"id"`
 	Date      time.Time `gorm:"not null" json:"date"`
+	Type      string    `gorm:"size:32; not null; default:interaction" json:"type"`
 	NbJetons  uint      `gorm:"not null" json:"nb_jetons"`
 	StandName string    `gorm:"not null" json:"stand_name"`
-	UserID    uint      `gorm:"not null" json:"user_id"`
+	StandID   uint      `gorm:"default:0" json:"stand_id"`
+	ProductID *uint     `json:"product_id" extensions:"x-nullable"`
+	Quantity  uint      `gorm:"default:0" json:"quantity"`
+	UserID    *uint     `json:"user_id" extensions:"x-nullable"`
+
+	// Achat payé avec une carte prépayée (UserID est alors le compte lié à la carte, s'il existe)
+	PrepaidCardID *uint `json:"prepaid_card_id,omitempty"`
+
+	// Annulation : l'entrée d'origine est marquée, l'entrée "reversal" pointe vers elle
+	ReversedAt   *time.Time `json:"reversed_at" extensions:"x-nullable"`
+	ReversalOfID *uint      `json:"reversal_of_id" extensions:"x-nullable"`
+	OperatorID   *uint      `json:"operator_id" extensions:"x-nullable"`
+	Reason       string     `gorm:"size:255" json:"reason"`
 }
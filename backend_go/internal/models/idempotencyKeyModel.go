@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+const (
+	IdempotencyInProgress = "in_progress"
+	IdempotencyCompleted  = "completed"
+)
+
+// IdempotencyKey garde la réponse d'une requête rejouable, identifiée par l'en-tête Idempotency-Key
+// de l'utilisateur, pour la renvoyer telle quelle si l'application retente la requête.
+type IdempotencyKey struct {
+	ID          uint      `gorm:"primary_key; not null; autoIncrement" json:"id"`
+	Key         string    `gorm:"size:255; not null; uniqueIndex:idx_idempotency_user_key" json:"key"`
+	UserID      uint      `gorm:"not null; uniqueIndex:idx_idempotency_user_key" json:"user_id"`
+	Method      string    `gorm:"size:8; not null" json:"method"`
+	Path        string    `gorm:"size:255; not null" json:"path"`
+	Fingerprint string    `gorm:"size:64; not null" json:"fingerprint"`
+	Status      string    `gorm:"size:16; not null; default:in_progress" json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	ResponseStatus      int    `json:"response_status"`
+	ResponseContentType string `gorm:"size:100" json:"response_content_type"`
+	ResponseBody        []byte `json:"-"`
+}
@@ -1,15 +1,24 @@
 package models
 
+import "time"
+
+const (
+	KermesseStatusOpen   = "open"
+	KermesseStatusClosed = "closed"
+)
+
 type Kermesse struct {
-	ID      uint   `gorm:"primary_key; not null; autoIncrement" json:"id"`
-	Name    string `gorm:"size:64; not null" json:"name"`
-	Picture string `gorm:"size:64" json:"picture"`
+	ID       uint       `gorm:"primary_key; not null; autoIncrement" json:"id"`
+	Name     string     `gorm:"size:64; not null" json:"name"`
+	Picture  string     `gorm:"size:64" json:"picture"`
+	Status   string     `gorm:"size:16; not null; default:open" json:"status"`
+	ClosedAt *time.Time `json:"closed_at" extensions:"x-nullable"`
 
-	Stands []Stand `gorm:"many2many:kermesse_stands;" json:"stands"`
+	Stands []Stand `gorm:"many2many:kermesse_stands;" json:"stands" extensions:"x-nullable"`
 
 	// Relations Many-to-Many : Organisateurs et participants de la kermesse
-	Organisateurs []User `gorm:"many2many:kermesse_organisateurs;" json:"organisateurs"`
-	Participants  []User `gorm:"many2many:kermesse_participants;" json:"participants"`
+	Organisateurs []User `gorm:"many2many:kermesse_organisateurs;" json:"organisateurs" extensions:"x-nullable"`
+	Participants  []User `gorm:"many2many:kermesse_participants;" json:"participants" extensions:"x-nullable"`
 
 	// Relation Many-to-One : L'utilisateur qui crée la kermesse
 	UserID uint `gorm:"not null" json:"user_id"`
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// PrepaidCard est une carte de jetons anonyme identifiée par un code imprimé (ou son QR code),
+// rechargée en caisse et utilisable sur les stands comme un compte.
+type PrepaidCard struct {
+	ID        uint      `gorm:"primary_key; not null; autoIncrement" json:"id"`
+	Code      string    `gorm:"size:32; not null; unique" json:"code"`
+	Jetons    uint      `gorm:"default:0; not null" json:"jetons"`
+	Disabled  bool      `gorm:"default:false; not null" json:"disabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	KermesseID uint `gorm:"not null" json:"kermesse_id"`
+
+	// Compte auquel la carte a été rattachée après coup, le cas échéant
+	UserID   *uint      `json:"user_id" extensions:"x-nullable"`
+	LinkedAt *time.Time `json:"linked_at" extensions:"x-nullable"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// StandDevice est une tablette ou un téléphone de stand qui enregistre les ventes hors ligne.
+// Le secret sert à signer les opérations enregistrées sur l'appareil.
+type StandDevice struct {
+	ID         uint       `gorm:"primary_key; not null; autoIncrement" json:"id"`
+	Name       string     `gorm:"size:64; not null" json:"name"`
+	Secret     string     `gorm:"size:64; not null" json:"-"`
+	Cursor     string     `gorm:"size:64" json:"cursor"` // Dernier curseur de synchronisation envoyé à l'appareil
+	LastSyncAt *time.Time `json:"last_sync_at" extensions:"x-nullable"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	StandID uint `gorm:"not null" json:"stand_id"`
+}
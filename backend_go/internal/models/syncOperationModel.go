@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const (
+	SyncOperationPurchase = "purchase"
+	SyncOperationPoints   = "points"
+)
+
+const (
+	SyncStatusApplied  = "applied"
+	SyncStatusRejected = "rejected" // Opération invalide : signature, produit ou client inconnu
+	SyncStatusConflict = "conflict" // Opération valide mais inapplicable (solde ou stock insuffisant), à revoir par le stand
+)
+
+// SyncOperation est une opération enregistrée hors ligne par un appareil de stand et envoyée plus tard.
+// ClientID est généré par l'appareil : un renvoi de la même opération retourne le résultat déjà enregistré.
+type SyncOperation struct {
+	ID         uint      `gorm:"primary_key; not null; autoIncrement" json:"id"`
+	ClientID   string    `gorm:"size:64; not null; uniqueIndex:idx_sync_device_client" json:"client_id"`
+	DeviceID   uint      `gorm:"not null; uniqueIndex:idx_sync_device_client" json:"device_id"`
+	Type       string    `gorm:"size:16; not null" json:"type"`
+	RecordedAt time.Time `gorm:"not null" json:"recorded_at"`
+	ReceivedAt time.Time `gorm:"not null" json:"received_at"`
+	Signature  string    `gorm:"size:64; not null" json:"-"`
+
+	UserID    *uint  `json:"user_id" extensions:"x-nullable"`
+	CardCode  string `gorm:"size:32" json:"card_code,omitempty"`
+	ProductID *uint  `json:"product_id,omitempty"`
+	Quantity  uint   `gorm:"default:0" json:"quantity"`
+	Points    uint   `gorm:"default:0" json:"points"`
+
+	Status    string `gorm:"size:16; not null" json:"status"`
+	Reason    string `gorm:"size:255" json:"reason,omitempty"`
+	HistoryID *uint  `json:"history_id,omitempty"`
+
+	StandID uint `gorm:"not null" json:"stand_id"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	TillSessionOpen   = "open"
+	TillSessionClosed = "closed"
+)
+
+const (
+	PaymentMethodCash         = "cash"
+	PaymentMethodCardTerminal = "card_terminal"
+)
+
+// TillSession est la session de caisse d'un caissier : fond de caisse à l'ouverture,
+// comptage à la fermeture et écart avec le montant attendu.
+type TillSession struct {
+	ID           uint       `gorm:"primary_key; not null; autoIncrement" json:"id"`
+	Status       string     `gorm:"size:16; not null; default:open" json:"status"`
+	OpenedAt     time.Time  `gorm:"not null" json:"opened_at"`
+	ClosedAt     *time.Time `json:"closed_at" extensions:"x-nullable"`
+	OpeningFloat float64    `gorm:"not null; default:0" json:"opening_float"`
+	ExpectedCash *float64   `json:"expected_cash" extensions:"x-nullable"`
+	ClosingCount *float64   `json:"closing_count" extensions:"x-nullable"`
+	Discrepancy  *float64   `json:"discrepancy" extensions:"x-nullable"`
+
+	KermesseID uint `gorm:"not null" json:"kermesse_id"`
+	CashierID  uint `gorm:"not null" json:"cashier_id"`
+
+	Sales []Transaction `gorm:"foreignKey:TillSessionID" json:"sales,omitempty"`
+}
@@ -1,6 +1,6 @@
 package models
 
 type Tombola struct {
-	ID    uint    `gorm:"primary_key; autoIncreme,t; not null" json:"id"`
+	ID    uint    `gorm:"primary_key; autoIncrement; not null" json:"id"`
 	Price float32 `gorm:"not null" json:"price"`
 }
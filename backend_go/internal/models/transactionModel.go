@@ -2,6 +2,19 @@ package models
 
 import "time"
 
+const (
+	TransactionTypeJetons   = "jetons"
+	TransactionTypeTombola  = "tombola"
+	TransactionTypeRefund   = "refund"
+	TransactionTypeDonation = "donation"
+)
+
+const (
+	TransactionStatusPending   = "pending"
+	TransactionStatusSucceeded = "succeeded"
+	TransactionStatusFailed    = "failed"
+)
+
 type Transaction struct {
 	ID              uint      `gorm:"primary_key; not null; autoIncrement" json:"id"`
 	Type            string    `gorm:"not null" json:"type"`
@@ -9,6 +22,21 @@ type Transaction struct {
 	Price           float32   `gorm:"not null;" json:"price"`
 	Quantity        uint      `gorm:"not null" json:"Quantity"`
 
-	// Relations avec l'utilisateur
-	UserID uint `gorm:"not null" json:"user_id"` // Clé étrangère
+	// Paiement : fournisseur, référence de l'intention et suivi des remboursements. RefundedQuantity
+	// compte les jetons de l'achat soldés en fin de kermesse, remboursés ou donnés à l'école.
+	Status           string `gorm:"size:16; not null; default:succeeded" json:"status"`
+	Provider         string `gorm:"size:16" json:"provider"`
+	PaymentIntentID  string `gorm:"size:255" json:"payment_intent_id"`
+	RefundID         string `gorm:"size:255" json:"refund_id,omitempty"`
+	RefundedQuantity uint   `gorm:"default:0; not null" json:"refunded_quantity"`
+	RefundOfID       *uint  `json:"refund_of_id,omitempty"`
+	KermesseID       *uint  `json:"kermesse_id,omitempty"`
+
+	// Vente en caisse : session du caissier et moyen de paiement (espèces ou terminal carte)
+	TillSessionID *uint  `json:"till_session_id,omitempty"`
+	PaymentMethod string `gorm:"size:16" json:"payment_method,omitempty"`
+	PrepaidCardID *uint  `json:"prepaid_card_id,omitempty"`
+
+	// Relations avec l'utilisateur (vide pour la recharge d'une carte prépayée anonyme)
+	UserID *uint `json:"user_id" extensions:"x-nullable"` // Clé étrangère
 }
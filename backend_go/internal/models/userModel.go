@@ -1,23 +1,36 @@
 package models
 
+import "time"
+
+// Rôles des comptes, valeurs de User.Role
+const (
+	RoleAdmin        = 1
+	RoleOrganisateur = 2
+	RoleTeneur       = 3
+	RoleParent       = 4
+	RoleEnfant       = 5
+	RoleCaissier     = 6
+)
+
 type User struct {
-	ID           uint   `gorm:"primary_key; not null; autoIncrement" json:"id"`
-	Firstname    string `gorm:"size:64; not null" json:"firstname"`
-	Lastname     string `gorm:"size:64; not null" json:"lastname"`
-	Email        string `gorm:"size:100; not null; unique" json:"email"`
-	Password     string `gorm:"size:100; not null" json:"password"`
-	Picture      string `gorm:"size:100;" json:"picture"`
-	Role         uint   `gorm:"size: 64; not null" json:"role"` /* 1 = ADMIN / 2 = ORGANISATEUR / 3 = TENEUR DE STAND / 4 = PARENT / 5 ELEVE  */
-	Jetons       uint   `gorm:"size: 64; default:0; not null" json:"jetons"`
-	PtsAttribues uint   `gorm:"size: 64; default: 0" json:"pts_attribues"`
+	ID           uint      `gorm:"primary_key; not null; autoIncrement" json:"id"`
+	Firstname    string    `gorm:"size:64; not null" json:"firstname"`
+	Lastname     string    `gorm:"size:64; not null" json:"lastname"`
+	Email        string    `gorm:"size:100; not null; unique" json:"email"`
+	Password     string    `gorm:"size:100; not null" json:"-"`
+	Picture      string    `gorm:"size:100;" json:"picture"`
+	Role         uint      `gorm:"size: 64; not null" json:"role"` /* 1 = ADMIN / 2 = ORGANISATEUR / 3 = TENEUR DE STAND / 4 = PARENT / 5 ELEVE / 6 = CAISSIER  */
+	Jetons       uint      `gorm:"size: 64; default:0; not null" json:"jetons"`
+	PtsAttribues uint      `gorm:"size: 64; default: 0" json:"pts_attribues"`
+	UpdatedAt    time.Time `json:"updated_at"` // Sert de curseur à la synchronisation des stands hors ligne
 
 	// Relations Many-to-Many pour Parents/Enfants
-	Parents []User `gorm:"many2many:user_parents;" json:"parents"`
-	Enfants []User `gorm:"many2many:user_enfants;" json:"enfants"`
+	Parents []User `gorm:"many2many:user_parents;" json:"parents" extensions:"x-nullable"`
+	Enfants []User `gorm:"many2many:user_enfants;" json:"enfants" extensions:"x-nullable"`
 
 	// Relations One-to-Many pour les Kermesses et Stands gérés par l'utilisateur
-	Kermesses    []Kermesse    `gorm:"foreignKey:UserID" json:"kermesses"`
-	Stands       []Stand       `gorm:"foreignKey:UserID" json:"stands"`
-	Transactions []Transaction `gorm:"foreignKey:UserID" json:"transactions"`
-	Historique   []History     `gorm:"foreignKey:UserID" json:"historique"`
+	Kermesses    []Kermesse    `gorm:"foreignKey:UserID" json:"kermesses" extensions:"x-nullable"`
+	Stands       []Stand       `gorm:"foreignKey:UserID" json:"stands" extensions:"x-nullable"`
+	Transactions []Transaction `gorm:"foreignKey:UserID" json:"transactions" extensions:"x-nullable"`
+	Historique   []History     `gorm:"foreignKey:UserID" json:"historique" extensions:"x-nullable"`
 }
@@ -0,0 +1,48 @@
+package payment
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// CashProvider représente l'encaissement sur place (espèces ou terminal de paiement
+// de la caisse) : l'argent est reçu par le caissier, l'intention est donc confirmée
+// dès qu'il valide la vente et un remboursement se fait de la main à la main.
+type CashProvider struct {
+	seq atomic.Uint64
+}
+
+func NewCashProvider() *CashProvider {
+	return &CashProvider{}
+}
+
+func (p *CashProvider) Name() string {
+	return ProviderCash
+}
+
+func (p *CashProvider) CreateIntent(params IntentParams) (*Intent, error) {
+	return &Intent{
+		ID:          p.nextID("cash"),
+		AmountCents: params.AmountCents,
+		Currency:    params.Currency,
+		Status:      StatusPending,
+		Provider:    ProviderCash,
+	}, nil
+}
+
+func (p *CashProvider) ConfirmIntent(intentID string) (*Intent, error) {
+	return &Intent{ID: intentID, Status: StatusSucceeded, Provider: ProviderCash}, nil
+}
+
+func (p *CashProvider) Refund(intentID string, amountCents int64, idempotencyKey string) (*Refund, error) {
+	return &Refund{ID: "cash_re_" + idempotencyKey, IntentID: intentID, AmountCents: amountCents}, nil
+}
+
+func (p *CashProvider) VerifyWebhook(payload []byte, signature string) (*Event, error) {
+	return nil, ErrWebhookUnsupported
+}
+
+func (p *CashProvider) nextID(prefix string) string {
+	return fmt.Sprintf("%s_%d_%d", prefix, time.Now().UnixNano(), p.seq.Add(1))
+}
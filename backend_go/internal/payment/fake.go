@@ -0,0 +1,155 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// FakeProvider simule Stripe en mémoire pour les tests et le développement local.
+// Les intentions sont payées à la confirmation, sauf si Fail a été appelé avant.
+type FakeProvider struct {
+	mu            sync.Mutex
+	seq           int
+	webhookSecret string
+	intents       map[string]*Intent
+	failing       map[string]bool
+	unavailable   bool
+	refunds       map[string]*Refund
+}
+
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{
+		webhookSecret: webhookSecret,
+		intents:       map[string]*Intent{},
+		failing:       map[string]bool{},
+		refunds:       map[string]*Refund{},
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return ProviderFake
+}
+
+func (p *FakeProvider) CreateIntent(params IntentParams) (*Intent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.seq++
+	id := fmt.Sprintf("pi_fake_%d", p.seq)
+	intent := &Intent{
+		ID:           id,
+		ClientSecret: id + "_secret",
+		AmountCents:  params.AmountCents,
+		Currency:     params.Currency,
+		Status:       StatusPending,
+		Provider:     ProviderFake,
+	}
+	p.intents[id] = intent
+
+	copied := *intent
+	return &copied, nil
+}
+
+func (p *FakeProvider) ConfirmIntent(intentID string) (*Intent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent, ok := p.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	if intent.Status == StatusPending {
+		if p.failing[intentID] {
+			intent.Status = StatusFailed
+		} else {
+			intent.Status = StatusSucceeded
+		}
+	}
+
+	copied := *intent
+	return &copied, nil
+}
+
+func (p *FakeProvider) Refund(intentID string, amountCents int64, idempotencyKey string) (*Refund, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.unavailable {
+		return nil, errFakeUnavailable
+	}
+	if _, ok := p.intents[intentID]; !ok {
+		return nil, ErrIntentNotFound
+	}
+
+	// Même clé d'idempotence, même remboursement, comme chez Stripe
+	if existing, ok := p.refunds[idempotencyKey]; ok {
+		copied := *existing
+		return &copied, nil
+	}
+
+	refund := &Refund{ID: "re_fake_" + idempotencyKey, IntentID: intentID, AmountCents: amountCents}
+	p.refunds[idempotencyKey] = refund
+
+	copied := *refund
+	return &copied, nil
+}
+
+type fakeEvent struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	IntentID string `json:"intent_id"`
+}
+
+// VerifyWebhook refuse tout webhook quand aucun secret n'est configuré : une signature calculée
+// avec une clé vide serait à la portée de n'importe qui
+func (p *FakeProvider) VerifyWebhook(payload []byte, signature string) (*Event, error) {
+	if p.webhookSecret == "" || !hmac.Equal([]byte(signature), []byte(p.Sign(payload))) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event fakeEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	return &Event{ID: event.ID, Type: event.Type, IntentID: event.IntentID}, nil
+}
+
+// Sign calcule la signature attendue par VerifyWebhook pour un payload
+func (p *FakeProvider) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(p.webhookSecret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Fail fait échouer la prochaine confirmation de l'intention
+func (p *FakeProvider) Fail(intentID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failing[intentID] = true
+}
+
+var errFakeUnavailable = errors.New("fake provider is unavailable")
+
+// SetUnavailable fait échouer les remboursements, comme une panne du fournisseur, jusqu'au rappel avec false
+func (p *FakeProvider) SetUnavailable(unavailable bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.unavailable = unavailable
+}
+
+// Refunds retourne les remboursements émis, pour les vérifications des tests
+func (p *FakeProvider) Refunds() []Refund {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	refunds := make([]Refund, 0, len(p.refunds))
+	for _, refund := range p.refunds {
+		refunds = append(refunds, *refund)
+	}
+	return refunds
+}
@@ -0,0 +1,115 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	ProviderStripe = "stripe"
+	ProviderCash   = "cash"
+	ProviderFake   = "fake"
+)
+
+// Statuts d'une intention de paiement, communs à tous les fournisseurs
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCanceled  = "canceled"
+)
+
+// Types d'événements remontés par les webhooks
+const (
+	EventIntentSucceeded = "payment_intent.succeeded"
+	EventIntentFailed    = "payment_intent.payment_failed"
+)
+
+var (
+	ErrIntentNotFound     = errors.New("payment intent not found")
+	ErrInvalidSignature   = errors.New("invalid webhook signature")
+	ErrWebhookUnsupported = errors.New("this payment provider does not send webhooks")
+	ErrUnknownProvider    = errors.New("unknown payment provider")
+)
+
+type IntentParams struct {
+	AmountCents int64
+	Currency    string
+	Metadata    map[string]string
+}
+
+type Intent struct {
+	ID           string `json:"id"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	AmountCents  int64  `json:"amount_cents"`
+	Currency     string `json:"currency"`
+	Status       string `json:"status"`
+	Provider     string `json:"provider"`
+}
+
+type Refund struct {
+	ID          string `json:"id"`
+	IntentID    string `json:"intent_id"`
+	AmountCents int64  `json:"amount_cents"`
+}
+
+type Event struct {
+	ID       string
+	Type     string
+	IntentID string
+}
+
+// Provider est implémenté par chaque moyen de paiement (Stripe, caisse sur place, fake)
+type Provider interface {
+	Name() string
+	CreateIntent(params IntentParams) (*Intent, error)
+	ConfirmIntent(intentID string) (*Intent, error)
+	Refund(intentID string, amountCents int64, idempotencyKey string) (*Refund, error)
+	VerifyWebhook(payload []byte, signature string) (*Event, error)
+}
+
+// Registry donne accès aux fournisseurs par nom, pour rembourser une transaction
+// auprès du fournisseur qui l'a encaissée.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+	card      string
+}
+
+// NewRegistry enregistre les fournisseurs ; card est celui utilisé pour les paiements carte
+func NewRegistry(card Provider, others ...Provider) *Registry {
+	r := &Registry{providers: map[string]Provider{}, card: card.Name()}
+	r.Register(card)
+	for _, provider := range others {
+		r.Register(provider)
+	}
+	return r
+}
+
+func (r *Registry) Register(provider Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[provider.Name()] = provider
+}
+
+func (r *Registry) Get(name string) (Provider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Les transactions antérieures au registre ont toutes été payées via Stripe
+	if name == "" {
+		name = ProviderStripe
+	}
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+	}
+	return provider, nil
+}
+
+// Card retourne le fournisseur utilisé pour les paiements en ligne
+func (r *Registry) Card() Provider {
+	provider, _ := r.Get(r.card)
+	return provider
+}
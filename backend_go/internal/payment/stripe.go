@@ -0,0 +1,118 @@
+package payment
+
+import (
+	"encoding/json"
+
+	"github.com/stripe/stripe-go/v72"
+	"github.com/stripe/stripe-go/v72/paymentintent"
+	"github.com/stripe/stripe-go/v72/refund"
+	"github.com/stripe/stripe-go/v72/webhook"
+)
+
+type StripeProvider struct {
+	intents       paymentintent.Client
+	refunds       refund.Client
+	webhookSecret string
+}
+
+func NewStripeProvider(secretKey string, webhookSecret string) *StripeProvider {
+	backend := stripe.GetBackend(stripe.APIBackend)
+	return &StripeProvider{
+		intents:       paymentintent.Client{B: backend, Key: secretKey},
+		refunds:       refund.Client{B: backend, Key: secretKey},
+		webhookSecret: webhookSecret,
+	}
+}
+
+func (p *StripeProvider) Name() string {
+	return ProviderStripe
+}
+
+func (p *StripeProvider) CreateIntent(params IntentParams) (*Intent, error) {
+	stripeParams := &stripe.PaymentIntentParams{
+		Amount:   stripe.Int64(params.AmountCents),
+		Currency: stripe.String(params.Currency),
+		AutomaticPaymentMethods: &stripe.PaymentIntentAutomaticPaymentMethodsParams{
+			Enabled: stripe.Bool(true),
+		},
+	}
+	for key, value := range params.Metadata {
+		stripeParams.AddMetadata(key, value)
+	}
+
+	pi, err := p.intents.New(stripeParams)
+	if err != nil {
+		return nil, err
+	}
+	return p.toIntent(pi), nil
+}
+
+// ConfirmIntent relit l'intention chez Stripe : le client la confirme normalement
+// avec le SDK mobile, on ne la confirme côté serveur que si elle l'attend encore.
+func (p *StripeProvider) ConfirmIntent(intentID string) (*Intent, error) {
+	pi, err := p.intents.Get(intentID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if pi.Status == stripe.PaymentIntentStatusRequiresConfirmation {
+		pi, err = p.intents.Confirm(intentID, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p.toIntent(pi), nil
+}
+
+func (p *StripeProvider) Refund(intentID string, amountCents int64, idempotencyKey string) (*Refund, error) {
+	params := &stripe.RefundParams{
+		PaymentIntent: stripe.String(intentID),
+		Amount:        stripe.Int64(amountCents),
+		Reason:        stripe.String(string(stripe.RefundReasonRequestedByCustomer)),
+	}
+	params.SetIdempotencyKey(idempotencyKey)
+
+	re, err := p.refunds.New(params)
+	if err != nil {
+		return nil, err
+	}
+	return &Refund{ID: re.ID, IntentID: intentID, AmountCents: re.Amount}, nil
+}
+
+func (p *StripeProvider) VerifyWebhook(payload []byte, signature string) (*Event, error) {
+	event, err := webhook.ConstructEvent(payload, signature, p.webhookSecret)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	result := &Event{ID: event.ID, Type: string(event.Type)}
+	if event.Data != nil {
+		var pi stripe.PaymentIntent
+		if err := json.Unmarshal(event.Data.Raw, &pi); err == nil {
+			result.IntentID = pi.ID
+		}
+	}
+	return result, nil
+}
+
+func (p *StripeProvider) toIntent(pi *stripe.PaymentIntent) *Intent {
+	return &Intent{
+		ID:           pi.ID,
+		ClientSecret: pi.ClientSecret,
+		AmountCents:  pi.Amount,
+		Currency:     string(pi.Currency),
+		Status:       stripeStatus(pi.Status),
+		Provider:     ProviderStripe,
+	}
+}
+
+func stripeStatus(status stripe.PaymentIntentStatus) string {
+	switch status {
+	case stripe.PaymentIntentStatusSucceeded:
+		return StatusSucceeded
+	case stripe.PaymentIntentStatusCanceled:
+		return StatusCanceled
+	default:
+		return StatusPending
+	}
+}
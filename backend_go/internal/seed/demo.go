@@ -0,0 +1,123 @@
+package seed
+
+import (
+	"project/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// seedDemo insère les données de démonstration : packs de jetons, un compte par rôle,
+// des familles, trois kermesses, leurs stands et leurs produits.
+func seedDemo(tx *gorm.DB) error {
+	// Insertion des jetons
+	packs := []models.Jetons{
+		{NbJetons: 15, Price: 10},
+		{NbJetons: 37, Price: 20},
+		{NbJetons: 80, Price: 40},
+		{NbJetons: 100, Price: 50},
+		{NbJetons: 150, Price: 70},
+		{NbJetons: 200, Price: 80},
+	}
+	for _, pack := range packs {
+		if _, err := upsertJetons(tx, pack); err != nil {
+			return err
+		}
+	}
+
+	// Insertion des utilisateurs, indexés par email
+	users := map[string]models.User{}
+	accounts := []struct {
+		user     models.User
+		password string
+	}{
+		{models.User{Firstname: "Admin", Lastname: "User", Email: "admin@example.com", Role: models.RoleAdmin}, "adminpass"},
+		{models.User{Firstname: "Organisateur1", Lastname: "User", Email: "org1@example.com", Role: models.RoleOrganisateur}, "orgpass"},
+		{models.User{Firstname: "Organisateur2", Lastname: "User", Email: "org2@example.com", Role: models.RoleOrganisateur}, "orgpass"},
+		{models.User{Firstname: "Teneur1", Lastname: "Stand", Email: "teneur1@example.com", Role: models.RoleTeneur}, "teneurpass"},
+		{models.User{Firstname: "Teneur2", Lastname: "Stand", Email: "teneur2@example.com", Role: models.RoleTeneur}, "teneurpass"},
+		{models.User{Firstname: "Parent1", Lastname: "User", Email: "parent1@example.com", Role: models.RoleParent}, "parentpass"},
+		{models.User{Firstname: "Parent2", Lastname: "User", Email: "parent2@example.com", Role: models.RoleParent}, "parentpass"},
+		{models.User{Firstname: "Caissier1", Lastname: "User", Email: "caissier1@example.com", Role: models.RoleCaissier}, "caissierpass"},
+		{models.User{Firstname: "Enfant1", Lastname: "Parent1", Email: "enfant1@example.com", Role: models.RoleEnfant}, "enfantpass"},
+		{models.User{Firstname: "Enfant2", Lastname: "Parent1", Email: "enfant2@example.com", Role: models.RoleEnfant}, "enfantpass"},
+		{models.User{Firstname: "Enfant1", Lastname: "Parent2", Email: "enfant3@example.com", Role: models.RoleEnfant}, "enfantpass"},
+	}
+	for _, account := range accounts {
+		user, err := upsertUser(tx, account.user, account.password)
+		if err != nil {
+			return err
+		}
+		users[user.Email] = user
+	}
+
+	// Lier les enfants à leurs parents
+	families := map[string][]string{
+		"parent1@example.com": {"enfant1@example.com", "enfant2@example.com"},
+		"parent2@example.com": {"enfant3@example.com"},
+	}
+	for parentEmail, childEmails := range families {
+		parent := users[parentEmail]
+		for _, childEmail := range childEmails {
+			child := users[childEmail]
+			if err := tx.Model(&parent).Association("Enfants").Append(&child); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Insertion des kermesses
+	kermesses := []models.Kermesse{
+		{Name: "Kermesse de Printemps", Picture: "kermesse1.jpg", UserID: users["admin@example.com"].ID},
+		{Name: "Kermesse d'Été", Picture: "kermesse2.jpg", UserID: users["org1@example.com"].ID},
+		{Name: "Kermesse d'Hiver", Picture: "kermesse3.jpg", UserID: users["org2@example.com"].ID},
+	}
+	for i := range kermesses {
+		kermesse, err := upsertKermesse(tx, kermesses[i])
+		if err != nil {
+			return err
+		}
+		kermesses[i] = kermesse
+	}
+
+	// Insertion des stands
+	stands := []models.Stand{
+		{Name: "Stand de Nourriture", Type: "Nourriture", Pts_Donnees: 10, Conso: 5, JetonsRequis: 2, UserID: users["teneur1@example.com"].ID},
+		{Name: "Stand de Boissons", Type: "Boissons", Pts_Donnees: 5, Conso: 3, JetonsRequis: 1, UserID: users["teneur1@example.com"].ID},
+		{Name: "Stand de Jeux", Type: "Jeux", Pts_Donnees: 15, Conso: 8, JetonsRequis: 3, UserID: users["teneur2@example.com"].ID},
+	}
+	for i := range stands {
+		stand, err := upsertStand(tx, stands[i])
+		if err != nil {
+			return err
+		}
+		stands[i] = stand
+	}
+
+	// Associer les stands aux kermesses : deux stands par kermesse, dans l'ordre
+	for i, kermesse := range kermesses {
+		start := i * 2
+		if start >= len(stands) {
+			break
+		}
+		end := start + 2
+		if end > len(stands) {
+			end = len(stands)
+		}
+		if err := tx.Model(&kermesse).Association("Stands").Append(stands[start:end]); err != nil {
+			return err
+		}
+	}
+
+	// Insertion des produits
+	products := []models.Product{
+		{Name: "Frites", Picture: "frites.jpg", Type: "Nourriture", JetonsRequis: 2, Nb_Products: 100, StandID: uint64(stands[0].ID)},
+		{Name: "Soda", Picture: "soda.jpg", Type: "Boissons", JetonsRequis: 1, Nb_Products: 200, StandID: uint64(stands[1].ID)},
+		{Name: "Jeu de société", Picture: "jeu.jpg", Type: "Jeux", JetonsRequis: 3, Nb_Products: 50, StandID: uint64(stands[2].ID)},
+	}
+	for _, product := range products {
+		if _, err := upsertProduct(tx, product); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package seed
+
+import (
+	"project/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Comptes du jeu de données de test, tous avec le mot de passe FixturePassword
+const (
+	FixturePassword     = "password"
+	FixtureAdmin        = "admin@test.local"
+	FixtureOrganisateur = "organisateur@test.local"
+	FixtureTeneur       = "teneur@test.local"
+	FixtureCaissier     = "caissier@test.local"
+	FixtureParent       = "parent@test.local"
+	FixtureEnfant       = "enfant@test.local"
+
+	FixtureKermesse = "Kermesse de test"
+	FixtureStand    = "Stand de test"
+	FixtureProduct  = "Crêpe"
+)
+
+// seedTest insère un jeu de données minimal et stable : un compte par rôle, une famille
+// participant à une kermesse, un stand avec un produit et un pack de jetons.
+// Le solde du parent est remis à 50 jetons à chaque exécution.
+func seedTest(tx *gorm.DB) error {
+	if _, err := upsertJetons(tx, models.Jetons{NbJetons: 10, Price: 5}); err != nil {
+		return err
+	}
+
+	users := map[string]models.User{}
+	for _, user := range []models.User{
+		{Firstname: "Admin", Lastname: "Test", Email: FixtureAdmin, Role: models.RoleAdmin},
+		{Firstname: "Organisateur", Lastname: "Test", Email: FixtureOrganisateur, Role: models.RoleOrganisateur},
+		{Firstname: "Teneur", Lastname: "Test", Email: FixtureTeneur, Role: models.RoleTeneur},
+		{Firstname: "Caissier", Lastname: "Test", Email: FixtureCaissier, Role: models.RoleCaissier},
+		{Firstname: "Parent", Lastname: "Test", Email: FixtureParent, Role: models.RoleParent},
+		{Firstname: "Enfant", Lastname: "Test", Email: FixtureEnfant, Role: models.RoleEnfant},
+	} {
+		created, err := upsertUser(tx, user, FixturePassword)
+		if err != nil {
+			return err
+		}
+		users[created.Email] = created
+	}
+
+	parent := users[FixtureParent]
+	child := users[FixtureEnfant]
+	if err := tx.Model(&models.User{}).Where("id = ?", parent.ID).Update("jetons", 50).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&parent).Association("Enfants").Append(&child); err != nil {
+		return err
+	}
+
+	kermesse, err := upsertKermesse(tx, models.Kermesse{Name: FixtureKermesse, UserID: users[FixtureAdmin].ID})
+	if err != nil {
+		return err
+	}
+	organisateur := users[FixtureOrganisateur]
+	if err := tx.Model(&kermesse).Association("Organisateurs").Append(&organisateur); err != nil {
+		return err
+	}
+	if err := tx.Model(&kermesse).Association("Participants").Append(&parent); err != nil {
+		return err
+	}
+
+	stand, err := upsertStand(tx, models.Stand{Name: FixtureStand, Type: "Nourriture", Pts_Donnees: 5, JetonsRequis: 2, UserID: users[FixtureTeneur].ID})
+	if err != nil {
+		return err
+	}
+	if err := tx.Model(&kermesse).Association("Stands").Append(&stand); err != nil {
+		return err
+	}
+
+	_, err = upsertProduct(tx, models.Product{Name: FixtureProduct, Type: "Nourriture", JetonsRequis: 3, Nb_Products: 20, StandID: uint64(stand.ID)})
+	return err
+}
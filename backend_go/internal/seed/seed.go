@@ -1,160 +1,107 @@
 package seed
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"project/internal/models"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Environnements de seed
 const (
-	RoleAdmin        = 1
-	RoleOrganisateur = 2
-	RoleTeneur       = 3
-	RoleParent       = 4
-	RoleEnfant       = 5
+	EnvDemo  = "demo"  // Données de démonstration de l'application
+	EnvTest  = "test"  // Jeu de données minimal et stable pour les tests de bout en bout
+	EnvEmpty = "empty" // Aucune donnée, utile avec Clean pour repartir d'une base vide
 )
 
-func CleanDatabase(DB *gorm.DB) {
-	// Supprimer les entrées dans les tables sans générer d'erreur si elles n'existent pas
-	_ = DB.Exec("DELETE FROM kermesses")
-	_ = DB.Exec("DELETE FROM stands")
-	_ = DB.Exec("DELETE FROM products")
-	_ = DB.Exec("DELETE FROM user_parents")
-	_ = DB.Exec("DELETE FROM user_enfants")
-	_ = DB.Exec("DELETE FROM users")
-	_ = DB.Exec("DELETE FROM jetons")
+const disposableSetting = "disposable"
 
-	fmt.Println("Base de données nettoyée.")
-}
-
-func SeedData(DB *gorm.DB) {
-	// Nettoyer la base de données avant d'insérer les données
-	CleanDatabase(DB)
-
-	// Insertion des jetons
-	jetons := []models.Jetons{
-		{NbJetons: 15, Price: 10},
-		{NbJetons: 37, Price: 20},
-		{NbJetons: 80, Price: 40},
-		{NbJetons: 100, Price: 50},
-		{NbJetons: 150, Price: 70},
-		{NbJetons: 200, Price: 80},
-	}
-
-	for _, jeton := range jetons {
-		if err := DB.Create(&jeton).Error; err != nil {
-			log.Fatalf("Erreur lors de l'insertion du jeton %v : %v", jeton, err)
-		}
-	}
-
-	// Insertion des utilisateurs
-	users := []models.User{
-		{Firstname: "Admin", Lastname: "User", Email: "admin@example.com", Password: hashPassword("adminpass"), Role: RoleAdmin},
-		{Firstname: "Organisateur1", Lastname: "User", Email: "org1@example.com", Password: hashPassword("orgpass"), Role: RoleOrganisateur},
-		{Firstname: "Organisateur2", Lastname: "User", Email: "org2@example.com", Password: hashPassword("orgpass"), Role: RoleOrganisateur},
-		{Firstname: "Teneur1", Lastname: "Stand", Email: "teneur1@example.com", Password: hashPassword("teneurpass"), Role: RoleTeneur},
-		{Firstname: "Teneur2", Lastname: "Stand", Email: "teneur2@example.com", Password: hashPassword("teneurpass"), Role: RoleTeneur},
-		{Firstname: "Parent1", Lastname: "User", Email: "parent1@example.com", Password: hashPassword("parentpass"), Role: RoleParent},
-		{Firstname: "Parent2", Lastname: "User", Email: "parent2@example.com", Password: hashPassword("parentpass"), Role: RoleParent},
-	}
-
-	// Enregistrer les utilisateurs dans la base de données
-	for _, user := range users {
-		if err := DB.Create(&user).Error; err != nil {
-			log.Fatalf("Erreur lors de l'insertion de l'utilisateur %v : %v", user, err)
-		}
-	}
+var (
+	ErrUnknownEnvironment = errors.New("unknown seed environment, expected demo, test or empty")
+	ErrNotDisposable      = errors.New("this database is not flagged as disposable, refusing to clean it")
+)
 
-	// Création des enfants pour chaque parent
-	enfants := []models.User{
-		{Firstname: "Enfant1", Lastname: "Parent1", Email: "enfant1@example.com", Password: hashPassword("enfantpass"), Role: RoleEnfant},
-		{Firstname: "Enfant2", Lastname: "Parent1", Email: "enfant2@example.com", Password: hashPassword("enfantpass"), Role: RoleEnfant},
-		{Firstname: "Enfant1", Lastname: "Parent2", Email: "enfant3@example.com", Password: hashPassword("enfantpass"), Role: RoleEnfant},
+// Run insère les données de l'environnement. Les insertions sont des upserts sur des clés
+// naturelles (email, nom...) : relancer le seed ne crée pas de doublons et ne supprime rien.
+func Run(DB *gorm.DB, env string) error {
+	var err error
+	switch env {
+	case EnvDemo:
+		err = DB.Transaction(seedDemo)
+	case EnvTest:
+		err = DB.Transaction(seedTest)
+	case EnvEmpty:
+	default:
+		return ErrUnknownEnvironment
 	}
-
-	for _, enfant := range enfants {
-		if err := DB.Create(&enfant).Error; err != nil {
-			log.Fatalf("Erreur lors de l'insertion de l'enfant %v : %v", enfant, err)
-		}
-
-		// Lier l'enfant au parent
-		var parent models.User
-		if enfant.Lastname == "Parent1" {
-			DB.Where("email = ?", "parent1@example.com").First(&parent)
-		} else {
-			DB.Where("email = ?", "parent2@example.com").First(&parent)
-		}
-
-		// Associer l'enfant au parent
-		if err := DB.Model(&parent).Association("Enfants").Append(&enfant); err != nil {
-			log.Fatalf("Erreur lors de l'association de l'enfant %v avec le parent %v : %v", enfant, parent, err)
-		}
+	if err != nil {
+		return err
 	}
 
-	// Insertion des kermesses
-	kermesses := []models.Kermesse{
-		{Name: "Kermesse de Printemps", Picture: "kermesse1.jpg", UserID: 1}, // Organisateur : Admin
-		{Name: "Kermesse d'Été", Picture: "kermesse2.jpg", UserID: 2},        // Organisateur : Organisateur1
-		{Name: "Kermesse d'Hiver", Picture: "kermesse3.jpg", UserID: 3},      // Organisateur : Organisateur2
-	}
+	fmt.Printf("Données %q insérées avec succès.\n", env)
+	return nil
+}
 
-	for _, kermesse := range kermesses {
-		if err := DB.Create(&kermesse).Error; err != nil {
-			log.Fatalf("Erreur lors de l'insertion de la kermesse %v : %v", kermesse, err)
-		}
+// Clean vide toutes les tables de données. Refuse de le faire si la base n'a pas été
+// marquée comme jetable avec MarkDisposable.
+func Clean(DB *gorm.DB) error {
+	disposable, err := IsDisposable(DB)
+	if err != nil {
+		return err
 	}
-
-	// Insertion des stands
-	stands := []models.Stand{
-		{Name: "Stand de Nourriture", Type: "Nourriture", Pts_Donnees: 10, Conso: 5, JetonsRequis: 2, UserID: 4}, // Teneur1
-		{Name: "Stand de Boissons", Type: "Boissons", Pts_Donnees: 5, Conso: 3, JetonsRequis: 1, UserID: 4},      // Teneur1
-		{Name: "Stand de Jeux", Type: "Jeux", Pts_Donnees: 15, Conso: 8, JetonsRequis: 3, UserID: 5},             // Teneur2
+	if !disposable {
+		return ErrNotDisposable
 	}
 
-	for _, stand := range stands {
-		if err := DB.Create(&stand).Error; err != nil {
-			log.Fatalf("Erreur lors de l'insertion du stand %v : %v", stand, err)
-		}
+	// Ordre inverse des dépendances : les clés étrangères sont vérifiées à chaque suppression.
+	// Le journal d'audit n'est jamais vidé, la base le refuse.
+	tables := []string{
+		"balance_adjustments", "sync_operations", "stand_devices", "idempotency_keys",
+		"histories", "transactions", "prepaid_cards", "till_sessions",
+		"products", "kermesse_stands", "kermesse_participants", "kermesse_organisateurs",
+		"stands", "kermesses", "user_parents", "user_enfants", "users",
+		"jetons", "tombolas",
 	}
-
-	// Associer les stands aux kermesses
-	for i := 0; i < len(kermesses); i++ {
-		start := i * 2
-		end := start + 2
-
-		// Si nous avons moins de stands que prévu, ajuster la fin
-		if end > len(stands) {
-			end = len(stands)
-		}
-
-		// Si le début dépasse la longueur, nous avons fini d'associer des stands
-		if start >= len(stands) {
-			break
-		}
-		standSlice := stands[start:end] // Assurez-vous que cette partie ne dépasse pas la longueur du tableau
-
-		if err := DB.Model(&kermesses[i]).Association("Stands").Append(standSlice); err != nil {
-			log.Fatalf("Erreur lors de l'association des stands %v avec la kermesse %v : %v", standSlice, kermesses[i], err)
+	err = DB.Transaction(func(tx *gorm.DB) error {
+		for _, table := range tables {
+			if err := tx.Exec("DELETE FROM " + table).Error; err != nil {
+				return fmt.Errorf("cleaning %s: %w", table, err)
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	// Insertion des produits
-	products := []models.Product{
-		{Name: "Frites", Picture: "frites.jpg", Type: "Nourriture", JetonsRequis: 2, Nb_Products: 100, StandID: 1},
-		{Name: "Soda", Picture: "soda.jpg", Type: "Boissons", JetonsRequis: 1, Nb_Products: 200, StandID: 2},
-		{Name: "Jeu de société", Picture: "jeu.jpg", Type: "Jeux", JetonsRequis: 3, Nb_Products: 50, StandID: 3},
-	}
+	fmt.Println("Base de données nettoyée.")
+	return nil
+}
 
-	for _, product := range products {
-		if err := DB.Create(&product).Error; err != nil {
-			log.Fatalf("Erreur lors de l'insertion du produit %v : %v", product, err)
-		}
+// IsDisposable indique si la base a été marquée comme jetable (développement, tests)
+func IsDisposable(DB *gorm.DB) (bool, error) {
+	var values []string
+	if err := DB.Table("database_settings").Where("name = ?", disposableSetting).
+		Pluck("value", &values).Error; err != nil {
+		return false, err
 	}
+	return len(values) == 1 && values[0] == "true", nil
+}
 
-	fmt.Println("Données insérées avec succès.")
+// MarkDisposable marque la base comme jetable : Clean pourra alors la vider
+func MarkDisposable(DB *gorm.DB) error {
+	return DB.Table("database_settings").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(map[string]interface{}{
+		"name":       disposableSetting,
+		"value":      "true",
+		"updated_at": time.Now(),
+	}).Error
 }
 
 func hashPassword(password string) string {
@@ -0,0 +1,59 @@
+package seed
+
+import (
+	"project/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// upsertUser crée l'utilisateur s'il n'existe pas (clé : email) ou met à jour son identité et son rôle.
+// Le mot de passe et le solde ne sont fixés qu'à la création.
+func upsertUser(tx *gorm.DB, user models.User, password string) (models.User, error) {
+	var existing models.User
+	if err := tx.Where("email = ?", user.Email).Limit(1).Find(&existing).Error; err != nil {
+		return existing, err
+	}
+	if existing.ID == 0 {
+		user.Password = hashPassword(password)
+		err := tx.Create(&user).Error
+		return user, err
+	}
+
+	err := tx.Model(&existing).Updates(map[string]interface{}{
+		"firstname": user.Firstname,
+		"lastname":  user.Lastname,
+		"role":      user.Role,
+	}).Error
+	return existing, err
+}
+
+func upsertJetons(tx *gorm.DB, pack models.Jetons) (models.Jetons, error) {
+	err := tx.Where("nb_jetons = ? AND price = ?", pack.NbJetons, pack.Price).FirstOrCreate(&pack).Error
+	return pack, err
+}
+
+// upsertKermesse utilise le nom comme clé
+func upsertKermesse(tx *gorm.DB, kermesse models.Kermesse) (models.Kermesse, error) {
+	err := tx.Where("name = ?", kermesse.Name).
+		Assign(models.Kermesse{Picture: kermesse.Picture, UserID: kermesse.UserID}).
+		FirstOrCreate(&kermesse).Error
+	return kermesse, err
+}
+
+// upsertStand utilise le nom comme clé ; la conso n'est fixée qu'à la création
+func upsertStand(tx *gorm.DB, stand models.Stand) (models.Stand, error) {
+	err := tx.Where("name = ?", stand.Name).
+		Attrs(models.Stand{Conso: stand.Conso}).
+		Assign(models.Stand{Type: stand.Type, Pts_Donnees: stand.Pts_Donnees, JetonsRequis: stand.JetonsRequis, UserID: stand.UserID}).
+		FirstOrCreate(&stand).Error
+	return stand, err
+}
+
+// upsertProduct utilise le nom et le stand comme clé ; le stock n'est fixé qu'à la création
+func upsertProduct(tx *gorm.DB, product models.Product) (models.Product, error) {
+	err := tx.Where("name = ? AND stand_id = ?", product.Name, product.StandID).
+		Attrs(models.Product{Nb_Products: product.Nb_Products}).
+		Assign(models.Product{Picture: product.Picture, Type: product.Type, JetonsRequis: product.JetonsRequis}).
+		FirstOrCreate(&product).Error
+	return product, err
+}
@@ -0,0 +1,111 @@
+// Package s3test fournit un stockage compatible S3 en mémoire, à la manière de MinIO, pour tester
+// storage.S3Store sans service externe : il accepte PUT, GET, HEAD et DELETE sur /bucket/clé
+// et refuse toute requête dont la signature V4, par en-tête ou dans l'URL, est invalide ou expirée.
+package s3test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"project/internal/storage"
+)
+
+// Clés d'accès acceptées par le serveur
+const (
+	AccessKey = "test-access-key"
+	SecretKey = "test-secret-key"
+	Region    = "us-east-1"
+)
+
+type object struct {
+	data        []byte
+	contentType string
+}
+
+// Server est un stockage S3 servi par httptest
+type Server struct {
+	*httptest.Server
+	Bucket string
+
+	mu      sync.Mutex
+	objects map[string]object
+	signer  storage.SigV4
+}
+
+// NewServer démarre le serveur avec un seul bucket ; Close l'arrête
+func NewServer(bucket string) *Server {
+	s := &Server{
+		Bucket:  bucket,
+		objects: map[string]object{},
+		signer:  storage.SigV4{AccessKey: AccessKey, SecretKey: SecretKey, Region: Region, Service: "s3"},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Config retourne la configuration de storage.S3Store pointant vers ce serveur
+func (s *Server) Config() storage.S3Config {
+	return storage.S3Config{Endpoint: s.URL, Region: Region, Bucket: s.Bucket, AccessKey: AccessKey, SecretKey: SecretKey}
+}
+
+// Object retourne le contenu stocké sous key
+func (s *Server) Object(key string) ([]byte, string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[key]
+	return obj.data, obj.contentType, ok
+}
+
+// Keys retourne les clés stockées
+func (s *Server) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	if err := s.signer.Verify(r, time.Now()); err != nil {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != s.Bucket {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.objects[key] = object{data: data, contentType: r.Header.Get("Content-Type")}
+	case http.MethodGet, http.MethodHead:
+		obj, ok := s.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		if r.Method == http.MethodGet {
+			w.Write(obj.data)
+		}
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signature AWS Version 4, acceptée par S3 et par les stockages compatibles
+const (
+	sigAlgorithm      = "AWS4-HMAC-SHA256"
+	sigTimeFormat     = "20060102T150405Z"
+	sigDateFormat     = "20060102"
+	UnsignedPayload   = "UNSIGNED-PAYLOAD"
+	headerContentHash = "X-Amz-Content-Sha256"
+	headerDate        = "X-Amz-Date"
+)
+
+// SigV4 signe (et vérifie) des requêtes avec une paire de clés d'accès
+type SigV4 struct {
+	AccessKey string
+	SecretKey string
+	Region    string
+	Service   string
+}
+
+// Sign ajoute à req les en-têtes de date, d'empreinte du corps et d'autorisation.
+// Tous les en-têtes déjà présents, et l'hôte, sont signés.
+func (s SigV4) Sign(req *http.Request, payloadHash string, t time.Time) {
+	t = t.UTC()
+	req.Header.Set(headerDate, t.Format(sigTimeFormat))
+	req.Header.Set(headerContentHash, payloadHash)
+
+	names := []string{"host"}
+	for name := range req.Header {
+		if name = strings.ToLower(name); name != "authorization" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	signed := strings.Join(names, ";")
+	signature := s.signature(t, canonicalRequest(req.Method, req.URL, req.URL.Query(), headerValues(req, names), signed, payloadHash))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigAlgorithm, s.AccessKey, s.scope(t), signed, signature))
+}
+
+// Presign retourne u signé dans sa requête, valable ttl : seul l'hôte est signé
+func (s SigV4) Presign(method string, u *url.URL, ttl time.Duration, t time.Time) string {
+	t = t.UTC()
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", sigAlgorithm)
+	query.Set("X-Amz-Credential", s.AccessKey+"/"+s.scope(t))
+	query.Set("X-Amz-Date", t.Format(sigTimeFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(ttl.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	canonical := canonicalRequest(method, u, query, []string{"host:" + u.Host}, "host", UnsignedPayload)
+	query.Set("X-Amz-Signature", s.signature(t, canonical))
+
+	signed := *u
+	signed.RawQuery = encodeQuery(query)
+	return signed.String()
+}
+
+// Verify contrôle la signature d'une requête reçue, par en-tête ou dans l'URL, et son expiration.
+// Le service de test compatible S3 s'en sert pour refuser les requêtes mal signées.
+func (s SigV4) Verify(req *http.Request, now time.Time) error {
+	query := req.URL.Query()
+	if sig := query.Get("X-Amz-Signature"); sig != "" {
+		t, err := time.Parse(sigTimeFormat, query.Get("X-Amz-Date"))
+		if err != nil {
+			return ErrInvalidSignature
+		}
+		expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+		if err != nil || now.After(t.Add(time.Duration(expires)*time.Second)) {
+			return ErrInvalidSignature
+		}
+		if query.Get("X-Amz-Credential") != s.AccessKey+"/"+s.scope(t) {
+			return ErrInvalidSignature
+		}
+		query.Del("X-Amz-Signature")
+		names := strings.Split(query.Get("X-Amz-SignedHeaders"), ";")
+		canonical := canonicalRequest(req.Method, req.URL, query, headerValues(req, names), strings.Join(names, ";"), UnsignedPayload)
+		if !hmac.Equal([]byte(sig), []byte(s.signature(t, canonical))) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+
+	var credential, signedHeaders, sig string
+	auth, ok := strings.CutPrefix(req.Header.Get("Authorization"), sigAlgorithm+" ")
+	if !ok {
+		return ErrInvalidSignature
+	}
+	for _, part := range strings.Split(auth, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "Credential":
+			credential = value
+		case "SignedHeaders":
+			signedHeaders = value
+		case "Signature":
+			sig = value
+		}
+	}
+	t, err := time.Parse(sigTimeFormat, req.Header.Get(headerDate))
+	if err != nil || credential != s.AccessKey+"/"+s.scope(t) {
+		return ErrInvalidSignature
+	}
+	// Comme S3, une signature par en-tête vaut un quart d'heure autour de sa date
+	if d := now.Sub(t); d > 15*time.Minute || d < -15*time.Minute {
+		return ErrInvalidSignature
+	}
+	names := strings.Split(signedHeaders, ";")
+	canonical := canonicalRequest(req.Method, req.URL, query, headerValues(req, names), signedHeaders, req.Header.Get(headerContentHash))
+	if !hmac.Equal([]byte(sig), []byte(s.signature(t, canonical))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (s SigV4) scope(t time.Time) string {
+	return t.Format(sigDateFormat) + "/" + s.Region + "/" + s.Service + "/aws4_request"
+}
+
+func (s SigV4) signature(t time.Time, canonical string) string {
+	stringToSign := sigAlgorithm + "\n" + t.Format(sigTimeFormat) + "\n" + s.scope(t) + "\n" + hashHex([]byte(canonical))
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), t.Format(sigDateFormat))
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, s.Service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func canonicalRequest(method string, u *url.URL, query url.Values, headers []string, signedHeaders, payloadHash string) string {
+	return strings.Join([]string{
+		method,
+		canonicalPath(u.EscapedPath()),
+		encodeQuery(query),
+		strings.Join(headers, "\n") + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+}
+
+// headerValues retourne les lignes « nom:valeur » des en-têtes names, déjà triés et en minuscules
+func headerValues(req *http.Request, names []string) []string {
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.Host
+			if value == "" {
+				value = req.URL.Host
+			}
+		}
+		lines = append(lines, name+":"+strings.Join(strings.Fields(value), " "))
+	}
+	return lines
+}
+
+// canonicalPath réencode chaque segment du chemin selon la RFC 3986, comme l'attend S3
+func canonicalPath(escaped string) string {
+	if escaped == "" {
+		return "/"
+	}
+	segments := strings.Split(escaped, "/")
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segments[i] = uriEncode(unescaped)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func encodeQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(key)+"="+uriEncode(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode n'épargne que les caractères non réservés de la RFC 3986
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
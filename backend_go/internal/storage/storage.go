@@ -0,0 +1,50 @@
+// Package storage range les fichiers envoyés par les clients (les photos) derrière une interface
+// commune à deux implémentations : le système de fichiers local et un stockage objet compatible S3
+// (AWS, MinIO, Garage…). Les fichiers ne sont jamais servis par une URL permanente : les clients
+// reçoivent des URL signées qui expirent.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+var (
+	ErrNotFound         = errors.New("stored object not found")
+	ErrInvalidKey       = errors.New("invalid storage key")
+	ErrInvalidSignature = errors.New("invalid or expired signature")
+)
+
+// Store est implémenté par chaque stockage
+type Store interface {
+	// Put enregistre data sous key, en remplaçant l'objet existant
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Delete supprime l'objet ; un objet absent n'est pas une erreur
+	Delete(ctx context.Context, key string) error
+	// SignedURL retourne une URL de lecture de l'objet valable ttl
+	SignedURL(key string, ttl time.Duration) (string, error)
+}
+
+// Object est un fichier ouvert en lecture
+type Object struct {
+	Body        io.ReadSeekCloser
+	ContentType string
+	ModTime     time.Time
+}
+
+// ValidKey vérifie qu'une clé est un chemin relatif propre : pas de « .. », pas de / en tête
+func ValidKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return ErrInvalidKey
+	}
+	return nil
+}
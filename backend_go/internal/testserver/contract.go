@@ -0,0 +1,305 @@
+package testserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"project/docs"
+)
+
+// Nom sous lequel la spécification est chargée dans le compilateur de schémas
+const specURL = "openapi.json"
+
+// Undocumented liste les routes servies hors de la spécification de l'API REST, avec leur raison
+var Undocumented = map[string]string{
+	"GET /metrics": "exposition Prometheus en texte, hors API REST",
+}
+
+// Spec est la spécification OpenAPI générée par swag (package docs), chargée pour les tests
+type Spec struct {
+	Paths       map[string]map[string]Operation `json:"paths"`
+	Definitions map[string]json.RawMessage      `json:"definitions"`
+
+	mu       sync.Mutex
+	compiler *jsonschema.Compiler
+	schemas  map[string]*jsonschema.Schema
+}
+
+// Operation est une opération de la spécification, réduite à ce que les tests vérifient
+type Operation struct {
+	Parameters []Parameter             `json:"parameters"`
+	Responses  map[string]ResponseSpec `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string          `json:"name"`
+	In       string          `json:"in"`
+	Required bool            `json:"required"`
+	Schema   json.RawMessage `json:"schema"`
+}
+
+// ResponseSpec est une réponse documentée d'une opération
+type ResponseSpec struct {
+	Schema json.RawMessage `json:"schema"`
+}
+
+var (
+	specOnce sync.Once
+	spec     *Spec
+	specErr  error
+)
+
+// LoadSpec charge une fois la spécification compilée dans le binaire de test
+func LoadSpec() (*Spec, error) {
+	specOnce.Do(func() {
+		doc := docs.SwaggerInfo.ReadDoc()
+		spec = &Spec{schemas: map[string]*jsonschema.Schema{}}
+		if specErr = json.Unmarshal([]byte(doc), spec); specErr != nil {
+			return
+		}
+		var raw any
+		if specErr = json.Unmarshal([]byte(doc), &raw); specErr != nil {
+			return
+		}
+		spec.compiler = jsonschema.NewCompiler()
+		spec.compiler.Draft = jsonschema.Draft4
+		specErr = spec.compiler.AddResource(specURL, strings.NewReader(mustJSON(nullable(raw))))
+	})
+	return spec, specErr
+}
+
+// nullable traduit l'extension x-nullable de Swagger 2 en JSON Schema : la valeur peut aussi être null
+func nullable(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			v[k] = nullable(child)
+		}
+		if v["x-nullable"] != true {
+			return v
+		}
+		if t, ok := v["type"].(string); ok {
+			v["type"] = []any{t, "null"}
+			return v
+		}
+		return map[string]any{"anyOf": []any{v, map[string]any{"type": "null"}}}
+	case []any:
+		for i, child := range v {
+			v[i] = nullable(child)
+		}
+	}
+	return v
+}
+
+func mustJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+// Operation retourne l'opération documentée pour la méthode et le chemin Gin (/stands/:id)
+func (s *Spec) Operation(method, route string) (Operation, bool) {
+	op, ok := s.Paths[SpecPath(route)][strings.ToLower(method)]
+	return op, ok
+}
+
+// SpecPath convertit un chemin Gin en chemin OpenAPI : /stands/:id devient /stands/{id}
+func SpecPath(route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// PathParams retourne les paramètres d'un chemin OpenAPI, dans l'ordre
+func PathParams(path string) []string {
+	var names []string
+	for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// validate vérifie un corps JSON contre le schéma situé à pointer dans la spécification
+func (s *Spec) validate(pointer string, body []byte) error {
+	schema, err := s.schema(pointer)
+	if err != nil {
+		return err
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return fmt.Errorf("corps JSON invalide : %w", err)
+	}
+	return schema.Validate(v)
+}
+
+func (s *Spec) schema(pointer string) (*jsonschema.Schema, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if schema, ok := s.schemas[pointer]; ok {
+		return schema, nil
+	}
+	schema, err := s.compiler.Compile(specURL + "#" + pointer)
+	if err != nil {
+		return nil, err
+	}
+	s.schemas[pointer] = schema
+	return schema, nil
+}
+
+// jsonPointer construit un pointeur JSON (RFC 6901) vers les segments donnés
+func jsonPointer(segments ...string) string {
+	var b strings.Builder
+	for _, segment := range segments {
+		segment = strings.ReplaceAll(segment, "~", "~0")
+		segment = strings.ReplaceAll(segment, "/", "~1")
+		b.WriteString("/" + url.PathEscape(segment))
+	}
+	return b.String()
+}
+
+type routeKey struct{}
+
+// recordRoute note la route Gin qui a servi la requête, pour retrouver son opération dans la spécification
+func recordRoute(c *gin.Context) {
+	if route, ok := c.Request.Context().Value(routeKey{}).(*string); ok {
+		*route = c.FullPath()
+	}
+	c.Next()
+}
+
+func withRoute(req *http.Request, route *string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), routeKey{}, route))
+}
+
+// ErrUndocumented est retourné pour une route ou un statut absent de la spécification
+var ErrUndocumented = errors.New("absent de la spécification")
+
+// ValidateRequest vérifie un corps de requête contre le paramètre body de l'opération, s'il en a un
+func (s *Spec) ValidateRequest(method, route string, body []byte) error {
+	op, ok := s.Operation(method, route)
+	if !ok {
+		return fmt.Errorf("%s %s : %w", method, SpecPath(route), ErrUndocumented)
+	}
+	for i, param := range op.Parameters {
+		if param.In != "body" || len(body) == 0 {
+			continue
+		}
+		if err := s.validate(jsonPointer("paths", SpecPath(route), strings.ToLower(method), "parameters", strconv.Itoa(i), "schema"), body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateResponse vérifie que le statut est documenté pour l'opération et que le corps suit son schéma
+func (s *Spec) ValidateResponse(method, route string, status int, body []byte) error {
+	op, ok := s.Operation(method, route)
+	if !ok {
+		return fmt.Errorf("%s %s : %w", method, SpecPath(route), ErrUndocumented)
+	}
+	code := strconv.Itoa(status)
+	documented, ok := op.Responses[code]
+	if !ok {
+		code = "default"
+		if documented, ok = op.Responses[code]; !ok {
+			return fmt.Errorf("statut %d de %s %s : %w", status, method, SpecPath(route), ErrUndocumented)
+		}
+	}
+	if fileSchema(documented.Schema) {
+		// Fichier binaire : seul le statut est vérifié
+		return nil
+	}
+	if len(documented.Schema) == 0 {
+		if len(body) > 0 {
+			return fmt.Errorf("statut %d de %s %s : corps non documenté", status, method, SpecPath(route))
+		}
+		return nil
+	}
+	return s.validate(jsonPointer("paths", SpecPath(route), strings.ToLower(method), "responses", code, "schema"), body)
+}
+
+func fileSchema(schema json.RawMessage) bool {
+	var s struct {
+		Type string `json:"type"`
+	}
+	return json.Unmarshal(schema, &s) == nil && s.Type == "file"
+}
+
+// checkContract vérifie qu'un échange de test respecte la spécification : la route et le statut
+// sont documentés, un corps de requête accepté et le corps de la réponse suivent leurs schémas.
+// Les alias dépréciés sont ignorés, leurs successeurs sont vérifiés par les tests qui les appellent.
+func checkContract(t testing.TB, method, route string, reqBody []byte, res *Response) {
+	t.Helper()
+	if route == "" || res.Header().Get("Deprecation") != "" {
+		return
+	}
+	if _, ok := Undocumented[method+" "+route]; ok {
+		return
+	}
+	s, err := LoadSpec()
+	if err != nil {
+		t.Fatalf("chargement de la spécification OpenAPI : %v", err)
+	}
+	if res.Code < 300 {
+		if err := s.ValidateRequest(method, route, reqBody); err != nil {
+			t.Errorf("contrat : requête %s %s acceptée mais hors spécification : %v\n%s", method, route, err, reqBody)
+		}
+	}
+	if err := s.ValidateResponse(method, route, res.Code, res.Body.Bytes()); err != nil {
+		t.Errorf("contrat : réponse %d de %s %s hors spécification : %v\n%s", res.Code, method, route, err, res.Body.String())
+	}
+}
+
+// checkNoPassword fait échouer le test si le corps JSON de la réponse contient, à n'importe quelle
+// profondeur, une clé évoquant un mot de passe : le hash ne doit sortir sur aucune route.
+func checkNoPassword(t testing.TB, method, path string, res *Response) {
+	t.Helper()
+	var v any
+	if json.Unmarshal(res.Body.Bytes(), &v) != nil {
+		return
+	}
+	if field, ok := PasswordField(v, ""); ok {
+		t.Errorf("réponse %d de %s %s : champ %s exposé\n%s", res.Code, method, path, field, res.Body.String())
+	}
+}
+
+// PasswordField cherche dans un document JSON décodé une clé contenant « password » et retourne son chemin
+func PasswordField(v any, at string) (string, bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if strings.Contains(strings.ToLower(key), "password") {
+				return at + "." + key, true
+			}
+			if field, ok := PasswordField(child, at+"."+key); ok {
+				return field, true
+			}
+		}
+	case []any:
+		for i, child := range v {
+			if field, ok := PasswordField(child, at+"["+strconv.Itoa(i)+"]"); ok {
+				return field, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,273 @@
+// Package testserver démarre l'API complète sur une base SQLite jetable, pour les tests de bout en bout.
+// Le schéma est créé par les migrations SQLite, le paiement carte passe par le fournisseur fake.
+package testserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"project/api/routes"
+	"project/internal/app"
+	"project/internal/apperror"
+	"project/internal/config"
+	"project/internal/logging"
+	"project/internal/migrate"
+	"project/internal/models"
+	"project/internal/payment"
+	"project/internal/storage"
+)
+
+const (
+	// Secret de signature des jetons de connexion pendant les tests
+	Secret = "test-secret"
+	// Secret de signature des webhooks du fournisseur fake
+	WebhookSecret = "test-webhook-secret"
+	// Mot de passe de tous les comptes créés par le serveur de test
+	Password = "password"
+)
+
+var userSeq atomic.Uint64
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// Server est une instance de l'API servie en mémoire par httptest
+type Server struct {
+	t        testing.TB
+	App      *app.App
+	DB       *gorm.DB
+	Router   *gin.Engine
+	Payments *payment.FakeProvider
+	// Logs reçoit les lignes JSON écrites par le logger de l'application
+	Logs *bytes.Buffer
+}
+
+// User est un compte de test connecté via /api/v1/auth/login
+type User struct {
+	models.User
+	Token string
+}
+
+// Response est la réponse enregistrée d'une requête
+type Response struct {
+	*httptest.ResponseRecorder
+	t testing.TB
+}
+
+// New migre une base SQLite neuve dans le répertoire temporaire du test et déclare toutes les routes.
+// Chaque serveur a sa propre application : les tests peuvent tourner en parallèle.
+// Les photos sont rangées dans un stockage local, lui aussi dans le répertoire temporaire.
+func New(t testing.TB) *Server {
+	t.Helper()
+	store, err := storage.NewLocalStore(filepath.Join(t.TempDir(), "uploads"), app.FilesPath, Secret)
+	if err != nil {
+		t.Fatalf("stockage de test : %v", err)
+	}
+	return NewWithStorage(t, store)
+}
+
+// NewWithStorage démarre le serveur comme New, avec store pour stockage des photos
+func NewWithStorage(t testing.TB, store storage.Store) *Server {
+	t.Helper()
+
+	dsn := filepath.Join(t.TempDir(), "kermesse.db") + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("ouverture de la base de test : %v", err)
+	}
+	if _, err := migrate.Up(db); err != nil {
+		t.Fatalf("migration de la base de test : %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	fake := payment.NewFakeProvider(WebhookSecret)
+	cfg := config.Config{
+		Env:                  config.EnvTest,
+		Secret:               Secret,
+		PaymentProvider:      payment.ProviderFake,
+		PaymentWebhookSecret: WebhookSecret,
+		LegacyRoutes:         true,
+		StorageURLTTL:        15 * time.Minute,
+	}
+	logs := &bytes.Buffer{}
+	application := app.NewWithDB(cfg, logging.New(logs, slog.LevelInfo), db, payment.NewRegistry(fake, payment.NewCashProvider()), store)
+
+	router := gin.New()
+	router.Use(recordRoute)
+	routes.Register(router, application)
+
+	return &Server{t: t, App: application, DB: db, Router: router, Payments: fake, Logs: logs}
+}
+
+// NewUser crée un compte du rôle donné, avec Password comme mot de passe, et le connecte
+func (s *Server) NewUser(role uint) *User {
+	s.t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(Password), bcrypt.MinCost)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	n := userSeq.Add(1)
+	user := models.User{
+		Firstname: fmt.Sprintf("User%d", n),
+		Lastname:  "Test",
+		Email:     fmt.Sprintf("user%d@test.local", n),
+		Password:  string(hash),
+		Role:      role,
+	}
+	if err := s.DB.Create(&user).Error; err != nil {
+		s.t.Fatalf("création du compte de test : %v", err)
+	}
+	return &User{User: user, Token: s.Login(user.Email, Password)}
+}
+
+func (s *Server) Admin() *User        { return s.NewUser(models.RoleAdmin) }
+func (s *Server) Organisateur() *User { return s.NewUser(models.RoleOrganisateur) }
+func (s *Server) Teneur() *User       { return s.NewUser(models.RoleTeneur) }
+func (s *Server) Parent() *User       { return s.NewUser(models.RoleParent) }
+func (s *Server) Eleve() *User        { return s.NewUser(models.RoleEnfant) }
+func (s *Server) Caissier() *User     { return s.NewUser(models.RoleCaissier) }
+
+// Login se connecte par /api/v1/auth/login et retourne le jeton
+func (s *Server) Login(email, password string) string {
+	s.t.Helper()
+	var body struct {
+		Token string `json:"token"`
+	}
+	s.Request(http.MethodPost, "/api/v1/auth/login", nil, gin.H{"email": email, "password": password}).
+		Expect(http.StatusOK).
+		Data(&body)
+	return body.Token
+}
+
+// Request envoie une requête JSON au routeur, authentifiée par le jeton de user s'il est donné.
+// L'échange est vérifié contre la spécification OpenAPI : un écart fait échouer le test,
+// comme une réponse qui contient un champ de mot de passe, quelle que soit la route.
+func (s *Server) Request(method, path string, user *User, body any) *Response {
+	s.t.Helper()
+	return s.RequestWithHeader(method, path, user, body, nil)
+}
+
+// RequestWithHeader envoie la requête comme Request, avec les en-têtes donnés en plus
+func (s *Server) RequestWithHeader(method, path string, user *User, body any, header http.Header) *Response {
+	s.t.Helper()
+	var reader io.Reader
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			s.t.Fatal(err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	return s.serve(req, user, payload)
+}
+
+// Upload envoie data comme fichier field d'un formulaire multipart, vérifié comme Request
+func (s *Server) Upload(method, path string, user *User, field, filename string, data []byte) *Response {
+	s.t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile(field, filename)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	part.Write(data)
+	form.Close()
+
+	req := httptest.NewRequest(method, path, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return s.serve(req, user, nil)
+}
+
+// serve passe req au routeur, authentifiée par le jeton de user, et vérifie l'échange
+func (s *Server) serve(req *http.Request, user *User, payload []byte) *Response {
+	s.t.Helper()
+	var route string
+	req = withRoute(req, &route)
+	if user != nil {
+		req.Header.Set("Authorization", "Bearer "+user.Token)
+	}
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, req)
+	res := &Response{ResponseRecorder: rec, t: s.t}
+	checkContract(s.t, req.Method, route, payload, res)
+	checkNoPassword(s.t, req.Method, req.URL.RequestURI(), res)
+	return res
+}
+
+// Balance relit le solde de jetons d'un compte en base
+func (s *Server) Balance(user *User) uint {
+	s.t.Helper()
+	var jetons uint
+	if err := s.DB.Model(&models.User{}).Where("id = ?", user.ID).Select("jetons").Scan(&jetons).Error; err != nil {
+		s.t.Fatal(err)
+	}
+	return jetons
+}
+
+// Expect fait échouer le test si le statut n'est pas celui attendu
+func (r *Response) Expect(status int) *Response {
+	r.t.Helper()
+	if r.Code != status {
+		r.t.Fatalf("statut %d attendu, %d reçu : %s", status, r.Code, r.Body.String())
+	}
+	return r
+}
+
+// Data décode le champ data de l'enveloppe de la réponse dans v
+func (r *Response) Data(v any) *Response {
+	r.t.Helper()
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	r.JSON(&envelope)
+	if err := json.Unmarshal(envelope.Data, v); err != nil {
+		r.t.Fatalf("champ data invalide : %v : %s", err, r.Body.String())
+	}
+	return r
+}
+
+// Error décode l'erreur de la réponse
+func (r *Response) Error() apperror.Body {
+	r.t.Helper()
+	var body apperror.Response
+	r.JSON(&body)
+	return body.Error
+}
+
+// JSON décode le corps de la réponse dans v
+func (r *Response) JSON(v any) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body.Bytes(), v); err != nil {
+		r.t.Fatalf("réponse JSON invalide : %v : %s", err, r.Body.String())
+	}
+	return r
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"project/internal/audit"
+)
+
+// AuditRepository ajoute les entrées du journal d'audit. Appelé sur les dépôts d'une
+// transaction, l'entrée n'est écrite que si l'action l'est.
+type AuditRepository interface {
+	Record(ctx context.Context, entry audit.Entry) error
+}
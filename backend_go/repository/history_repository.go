@@ -0,0 +1,12 @@
+package repository
+
+import "project/internal/models"
+
+// HistoryRepository donne accès à l'historique des stands. Une entrée n'est jamais modifiée
+// ni supprimée : une annulation ajoute une entrée "reversal".
+type HistoryRepository interface {
+	Create(history *models.History) error
+	FindById(id uint) (*models.History, error)
+	ListByStand(standID uint) ([]models.History, error)
+	ListByUser(userID uint) ([]models.History, error)
+}
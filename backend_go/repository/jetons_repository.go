@@ -0,0 +1,13 @@
+package repository
+
+import "project/internal/models"
+
+// JetonsRepository donne accès aux packs de jetons proposés à la vente.
+// Update n'applique que les champs non nuls de changes.
+type JetonsRepository interface {
+	Create(pack *models.Jetons) error
+	FindById(id uint) (*models.Jetons, error)
+	List() ([]models.Jetons, error)
+	Update(id uint, changes models.Jetons) error
+	Delete(id uint) error
+}
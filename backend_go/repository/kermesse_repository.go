@@ -0,0 +1,13 @@
+package repository
+
+import "project/internal/models"
+
+// KermesseRepository donne accès aux kermesses.
+// Update n'applique que les champs non nuls de changes.
+type KermesseRepository interface {
+	Create(kermesse *models.Kermesse) error
+	FindById(id uint) (*models.Kermesse, error)
+	List() ([]models.Kermesse, error)
+	Update(id uint, changes models.Kermesse) error
+	Delete(id uint) error
+}
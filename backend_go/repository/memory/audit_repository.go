@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"project/internal/audit"
+)
+
+// AuditRepository garde les entrées telles qu'elles ont été journalisées, pour que les tests
+// vérifient ce qu'un service a tracé
+type AuditRepository struct {
+	mu      sync.Mutex
+	entries []audit.Entry
+}
+
+func (r *AuditRepository) Record(ctx context.Context, entry audit.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+// Entries retourne les entrées journalisées, dans l'ordre
+func (r *AuditRepository) Entries() []audit.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.entries)
+}
+
+func (r *AuditRepository) snapshot() (restore func()) {
+	r.mu.Lock()
+	n := len(r.entries)
+	r.mu.Unlock()
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.entries = r.entries[:n]
+	}
+}
@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"sort"
+
+	"project/internal/models"
+)
+
+type HistoryRepository struct {
+	rows *table[models.History]
+}
+
+func (r *HistoryRepository) Create(history *models.History) error {
+	r.rows.insert(history)
+	return nil
+}
+
+func (r *HistoryRepository) FindById(id uint) (*models.History, error) {
+	return r.rows.get(id)
+}
+
+// ListByStand retourne l'historique du stand, les entrées les plus récentes d'abord
+func (r *HistoryRepository) ListByStand(standID uint) ([]models.History, error) {
+	return newestFirst(r.rows.find(func(h models.History) bool { return h.StandID == standID })), nil
+}
+
+// ListByUser retourne l'historique du compte, les entrées les plus récentes d'abord
+func (r *HistoryRepository) ListByUser(userID uint) ([]models.History, error) {
+	return newestFirst(r.rows.find(func(h models.History) bool { return h.UserID != nil && *h.UserID == userID })), nil
+}
+
+func newestFirst(history []models.History) []models.History {
+	sort.SliceStable(history, func(i, j int) bool {
+		if !history[i].Date.Equal(history[j].Date) {
+			return history[i].Date.After(history[j].Date)
+		}
+		return history[i].ID > history[j].ID
+	})
+	return history
+}
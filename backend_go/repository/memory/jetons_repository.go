@@ -0,0 +1,28 @@
+package memory
+
+import "project/internal/models"
+
+type JetonsRepository struct {
+	rows *table[models.Jetons]
+}
+
+func (r *JetonsRepository) Create(pack *models.Jetons) error {
+	r.rows.insert(pack)
+	return nil
+}
+
+func (r *JetonsRepository) FindById(id uint) (*models.Jetons, error) {
+	return r.rows.get(id)
+}
+
+func (r *JetonsRepository) List() ([]models.Jetons, error) {
+	return r.rows.find(nil), nil
+}
+
+func (r *JetonsRepository) Update(id uint, changes models.Jetons) error {
+	return r.rows.update(id, changes)
+}
+
+func (r *JetonsRepository) Delete(id uint) error {
+	return r.rows.delete(id)
+}
@@ -0,0 +1,28 @@
+package memory
+
+import "project/internal/models"
+
+type KermesseRepository struct {
+	rows *table[models.Kermesse]
+}
+
+func (r *KermesseRepository) Create(kermesse *models.Kermesse) error {
+	r.rows.insert(kermesse)
+	return nil
+}
+
+func (r *KermesseRepository) FindById(id uint) (*models.Kermesse, error) {
+	return r.rows.get(id)
+}
+
+func (r *KermesseRepository) List() ([]models.Kermesse, error) {
+	return r.rows.find(nil), nil
+}
+
+func (r *KermesseRepository) Update(id uint, changes models.Kermesse) error {
+	return r.rows.update(id, changes)
+}
+
+func (r *KermesseRepository) Delete(id uint) error {
+	return r.rows.delete(id)
+}
@@ -0,0 +1,221 @@
+// Package memory implémente les dépôts en mémoire, pour tester les services et les
+// contrôleurs sans base de données. Les dépôts construits par New partagent les mêmes tables,
+// ce qui permet de reconstituer les relations d'un profil.
+package memory
+
+import (
+	"maps"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"project/internal/models"
+	"project/repository"
+)
+
+// store regroupe les tables partagées par les dépôts
+type store struct {
+	tx           sync.Mutex
+	users        *table[models.User]
+	kermesses    *table[models.Kermesse]
+	stands       *table[models.Stand]
+	products     *table[models.Product]
+	transactions *table[models.Transaction]
+	histories    *table[models.History]
+	jetons       *table[models.Jetons]
+	audit        *AuditRepository
+}
+
+// New construit des dépôts vides qui partagent les mêmes tables
+func New() *repository.Repositories {
+	s := &store{
+		users:        newTable(func(u *models.User) *uint { return &u.ID }),
+		kermesses:    newTable(func(k *models.Kermesse) *uint { return &k.ID }),
+		stands:       newTable(func(s *models.Stand) *uint { return &s.ID }),
+		products:     newTable(func(p *models.Product) *uint { return &p.ID }),
+		transactions: newTable(func(t *models.Transaction) *uint { return &t.ID }),
+		histories:    newTable(func(h *models.History) *uint { return &h.ID }),
+		jetons:       newTable(func(j *models.Jetons) *uint { return &j.ID }),
+		audit:        &AuditRepository{},
+	}
+	return s.repositories(transactor{store: s})
+}
+
+func (s *store) repositories(tx repository.Transactor) *repository.Repositories {
+	return &repository.Repositories{
+		Transactor:   tx,
+		Users:        &UserRepository{store: s},
+		Kermesses:    &KermesseRepository{rows: s.kermesses},
+		Stands:       &StandRepository{store: s},
+		Products:     &ProductRepository{rows: s.products},
+		Transactions: &TransactionRepository{rows: s.transactions},
+		Histories:    &HistoryRepository{rows: s.histories},
+		Jetons:       &JetonsRepository{rows: s.jetons},
+		Audit:        s.audit,
+	}
+}
+
+// snapshot fige le contenu de toutes les tables et retourne de quoi le rétablir
+func (s *store) snapshot() (restore func()) {
+	restores := []func(){
+		s.users.snapshot(), s.kermesses.snapshot(), s.stands.snapshot(), s.products.snapshot(),
+		s.transactions.snapshot(), s.histories.snapshot(), s.jetons.snapshot(), s.audit.snapshot(),
+	}
+	return func() {
+		for _, restore := range restores {
+			restore()
+		}
+	}
+}
+
+// transactor rétablit les tables quand fn échoue. Les transactions passent l'une après l'autre ;
+// les écritures faites hors transaction pendant ce temps ne sont pas isolées.
+// Une transaction ouverte dans fn fait partie de celle qui l'englobe.
+type transactor struct {
+	store  *store
+	nested bool
+}
+
+func (t transactor) Transaction(fn func(tx *repository.Repositories) error) error {
+	if t.nested {
+		return fn(t.store.repositories(t))
+	}
+	t.store.tx.Lock()
+	defer t.store.tx.Unlock()
+	restore := t.store.snapshot()
+	if err := fn(t.store.repositories(transactor{store: t.store, nested: true})); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+// table est une table en mémoire indexée par identifiant, qui attribue les identifiants
+// à l'insertion comme le ferait la base
+type table[T any] struct {
+	mu   sync.RWMutex
+	rows map[uint]T
+	next uint
+	id   func(*T) *uint
+}
+
+func newTable[T any](id func(*T) *uint) *table[T] {
+	return &table[T]{rows: map[uint]T{}, id: id}
+}
+
+func (t *table[T]) insert(row *T) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	id := t.id(row)
+	if *id == 0 {
+		t.next++
+		*id = t.next
+	} else if *id > t.next {
+		t.next = *id
+	}
+	touch(row)
+	t.rows[*id] = *row
+}
+
+func (t *table[T]) snapshot() (restore func()) {
+	t.mu.RLock()
+	rows, next := maps.Clone(t.rows), t.next
+	t.mu.RUnlock()
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.rows, t.next = rows, next
+	}
+}
+
+func (t *table[T]) get(id uint) (*T, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	row, ok := t.rows[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &row, nil
+}
+
+// find retourne les lignes acceptées par keep, triées par identifiant
+func (t *table[T]) find(keep func(T) bool) []T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	rows := []T{}
+	for _, row := range t.rows {
+		if keep == nil || keep(row) {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return *t.id(&rows[i]) < *t.id(&rows[j]) })
+	return rows
+}
+
+// update recopie les champs non nuls de changes, sauf les relations et les champs protégés,
+// comme GORM le fait avec Updates
+func (t *table[T]) update(id uint, changes T, protected ...string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	row, ok := t.rows[id]
+	if !ok {
+		return nil
+	}
+	dst := reflect.ValueOf(&row).Elem()
+	src := reflect.ValueOf(changes)
+	for i := 0; i < src.NumField(); i++ {
+		field := src.Type().Field(i)
+		if field.Name == "ID" || field.Type.Kind() == reflect.Slice || contains(protected, field.Name) {
+			continue
+		}
+		if !src.Field(i).IsZero() {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+	touch(&row)
+	t.rows[id] = row
+	return nil
+}
+
+func (t *table[T]) delete(id uint) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.rows[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(t.rows, id)
+	return nil
+}
+
+// matches imite la recherche des listes : search est contenu dans l'une des valeurs, sans tenir compte de la casse
+func matches(search string, values ...string) bool {
+	if search == "" {
+		return true
+	}
+	search = strings.ToLower(search)
+	for _, value := range values {
+		if strings.Contains(strings.ToLower(value), search) {
+			return true
+		}
+	}
+	return false
+}
+
+// touch renseigne UpdatedAt quand le modèle en a un
+func touch(row any) {
+	field := reflect.ValueOf(row).Elem().FieldByName("UpdatedAt")
+	if field.IsValid() && field.Type() == reflect.TypeOf(time.Time{}) {
+		field.Set(reflect.ValueOf(time.Now()))
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
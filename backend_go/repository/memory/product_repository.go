@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"cmp"
+	"strings"
+
+	"project/internal/listing"
+	"project/internal/models"
+)
+
+// productSorts reprend les champs triables de services.ProductListing
+var productSorts = map[string]func(a, b models.Product) int{
+	"id":            func(a, b models.Product) int { return cmp.Compare(a.ID, b.ID) },
+	"name":          func(a, b models.Product) int { return strings.Compare(a.Name, b.Name) },
+	"type":          func(a, b models.Product) int { return strings.Compare(a.Type, b.Type) },
+	"jetons_requis": func(a, b models.Product) int { return cmp.Compare(a.JetonsRequis, b.JetonsRequis) },
+	"nb_products":   func(a, b models.Product) int { return cmp.Compare(a.Nb_Products, b.Nb_Products) },
+	"updated_at":    func(a, b models.Product) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+}
+
+type ProductRepository struct {
+	rows *table[models.Product]
+}
+
+func (r *ProductRepository) Create(product *models.Product) error {
+	r.rows.insert(product)
+	return nil
+}
+
+func (r *ProductRepository) FindById(id uint) (*models.Product, error) {
+	return r.rows.get(id)
+}
+
+// List applique les filtres et la recherche de services.ProductListing
+func (r *ProductRepository) List(q listing.Query) (listing.Page[models.Product], error) {
+	standID := q.ID("stand_id")
+	productType, byType := q.Filter("type")
+	products := r.rows.find(func(p models.Product) bool {
+		return (standID == 0 || p.StandID == uint64(standID)) &&
+			(!byType || p.Type == productType) &&
+			matches(q.Search, p.Name)
+	})
+	return listing.Slice(products, q, productSorts), nil
+}
+
+func (r *ProductRepository) ListByStand(standID uint) ([]models.Product, error) {
+	return r.rows.find(func(p models.Product) bool { return p.StandID == uint64(standID) }), nil
+}
+
+func (r *ProductRepository) Update(id uint, changes models.Product) error {
+	return r.rows.update(id, changes)
+}
+
+func (r *ProductRepository) Delete(id uint) error {
+	return r.rows.delete(id)
+}
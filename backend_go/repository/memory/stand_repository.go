@@ -0,0 +1,38 @@
+package memory
+
+import "project/internal/models"
+
+type StandRepository struct {
+	store *store
+}
+
+func (r *StandRepository) Create(stand *models.Stand) error {
+	r.store.stands.insert(stand)
+	return nil
+}
+
+// FindById reconstitue le stock du stand depuis les produits
+func (r *StandRepository) FindById(id uint) (*models.Stand, error) {
+	stand, err := r.store.stands.get(id)
+	if err != nil {
+		return nil, err
+	}
+	stand.Stock = r.store.products.find(func(p models.Product) bool { return p.StandID == uint64(id) })
+	return stand, nil
+}
+
+func (r *StandRepository) List() ([]models.Stand, error) {
+	return r.store.stands.find(nil), nil
+}
+
+func (r *StandRepository) ListByOwner(userID uint) ([]models.Stand, error) {
+	return r.store.stands.find(func(s models.Stand) bool { return s.UserID == userID }), nil
+}
+
+func (r *StandRepository) Update(id uint, changes models.Stand) error {
+	return r.store.stands.update(id, changes, "Conso", "Pts_Donnees")
+}
+
+func (r *StandRepository) Delete(id uint) error {
+	return r.store.stands.delete(id)
+}
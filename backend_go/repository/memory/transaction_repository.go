@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"sort"
+
+	"project/internal/models"
+)
+
+type TransactionRepository struct {
+	rows *table[models.Transaction]
+}
+
+func (r *TransactionRepository) Create(transaction *models.Transaction) error {
+	r.rows.insert(transaction)
+	return nil
+}
+
+func (r *TransactionRepository) FindById(id uint) (*models.Transaction, error) {
+	return r.rows.get(id)
+}
+
+// ListByUser retourne les transactions du compte, les plus récentes d'abord
+func (r *TransactionRepository) ListByUser(userID uint) ([]models.Transaction, error) {
+	transactions := r.rows.find(func(t models.Transaction) bool { return t.UserID != nil && *t.UserID == userID })
+	sort.SliceStable(transactions, func(i, j int) bool {
+		if !transactions[i].DateTransaction.Equal(transactions[j].DateTransaction) {
+			return transactions[i].DateTransaction.After(transactions[j].DateTransaction)
+		}
+		return transactions[i].ID > transactions[j].ID
+	})
+	return transactions, nil
+}
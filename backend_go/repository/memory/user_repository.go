@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"cmp"
+	"strings"
+
+	"project/internal/listing"
+	"project/internal/models"
+	"project/repository"
+)
+
+// userSorts reprend les champs triables de services.UserListing
+var userSorts = map[string]func(a, b models.User) int{
+	"id":        func(a, b models.User) int { return cmp.Compare(a.ID, b.ID) },
+	"firstname": func(a, b models.User) int { return strings.Compare(a.Firstname, b.Firstname) },
+	"lastname":  func(a, b models.User) int { return strings.Compare(a.Lastname, b.Lastname) },
+	"email":     func(a, b models.User) int { return strings.Compare(a.Email, b.Email) },
+	"role":      func(a, b models.User) int { return cmp.Compare(a.Role, b.Role) },
+	"jetons":    func(a, b models.User) int { return cmp.Compare(a.Jetons, b.Jetons) },
+}
+
+type UserRepository struct {
+	store *store
+}
+
+func (r *UserRepository) Create(user *models.User) error {
+	r.store.users.insert(user)
+	return nil
+}
+
+func (r *UserRepository) FindById(id uint) (*models.User, error) {
+	return r.store.users.get(id)
+}
+
+func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
+	for _, user := range r.store.users.find(func(u models.User) bool { return u.Email == email }) {
+		return &user, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+// FindProfile reconstitue les kermesses, stands, transactions et historique du compte.
+// La famille n'est pas gérée par ces dépôts : Parents et Enfants restent ceux enregistrés.
+func (r *UserRepository) FindProfile(id uint) (*models.User, error) {
+	user, err := r.store.users.get(id)
+	if err != nil {
+		return nil, err
+	}
+	user.Kermesses = r.store.kermesses.find(func(k models.Kermesse) bool { return k.UserID == id })
+	user.Stands = r.store.stands.find(func(s models.Stand) bool { return s.UserID == id })
+	user.Transactions = r.store.transactions.find(func(t models.Transaction) bool {
+		return t.UserID != nil && *t.UserID == id
+	})
+	user.Historique = r.store.histories.find(func(h models.History) bool {
+		return h.UserID != nil && *h.UserID == id
+	})
+	return user, nil
+}
+
+func (r *UserRepository) List(q listing.Query) (listing.Page[models.User], error) {
+	return r.list(q.ID("role"), q), nil
+}
+
+func (r *UserRepository) ListByRole(role uint, q listing.Query) (listing.Page[models.User], error) {
+	return r.list(role, q), nil
+}
+
+// list applique les filtres et la recherche de services.UserListing ; l'appartenance à une
+// kermesse est lue dans ses participants et organisateurs enregistrés
+func (r *UserRepository) list(role uint, q listing.Query) listing.Page[models.User] {
+	var members map[uint]bool
+	if kermesseID := q.ID("kermesse_id"); kermesseID != 0 {
+		members = map[uint]bool{}
+		if kermesse, err := r.store.kermesses.get(kermesseID); err == nil {
+			for _, user := range append(kermesse.Participants, kermesse.Organisateurs...) {
+				members[user.ID] = true
+			}
+		}
+	}
+	users := r.store.users.find(func(u models.User) bool {
+		return (role == 0 || u.Role == role) &&
+			(members == nil || members[u.ID]) &&
+			matches(q.Search, u.Firstname, u.Lastname, u.Email)
+	})
+	return listing.Slice(users, q, userSorts)
+}
+
+func (r *UserRepository) Update(id uint, changes models.User) error {
+	return r.store.users.update(id, changes, "Jetons", "PtsAttribues")
+}
+
+func (r *UserRepository) Delete(id uint) error {
+	return r.store.users.delete(id)
+}
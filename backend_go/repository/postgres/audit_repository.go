@@ -0,0 +1,20 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"project/internal/audit"
+)
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) Record(ctx context.Context, entry audit.Entry) error {
+	return audit.Record(ctx, r.db, entry)
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"gorm.io/gorm"
+	"project/internal/models"
+)
+
+type HistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewHistoryRepository(db *gorm.DB) *HistoryRepository {
+	return &HistoryRepository{db: db}
+}
+
+func (r *HistoryRepository) Create(history *models.History) error {
+	return r.db.Create(history).Error
+}
+
+func (r *HistoryRepository) FindById(id uint) (*models.History, error) {
+	var history models.History
+	if err := r.db.First(&history, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &history, nil
+}
+
+// ListByStand retourne l'historique du stand, les entrées les plus récentes d'abord
+func (r *HistoryRepository) ListByStand(standID uint) ([]models.History, error) {
+	var history []models.History
+	if err := r.db.Where("stand_id = ?", standID).Order("date desc, id desc").Find(&history).Error; err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// ListByUser retourne l'historique du compte, les entrées les plus récentes d'abord
+func (r *HistoryRepository) ListByUser(userID uint) ([]models.History, error) {
+	var history []models.History
+	if err := r.db.Where("user_id = ?", userID).Order("date desc, id desc").Find(&history).Error; err != nil {
+		return nil, err
+	}
+	return history, nil
+}
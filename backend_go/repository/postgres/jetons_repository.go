@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"gorm.io/gorm"
+	"project/internal/models"
+)
+
+type JetonsRepository struct {
+	db *gorm.DB
+}
+
+func NewJetonsRepository(db *gorm.DB) *JetonsRepository {
+	return &JetonsRepository{db: db}
+}
+
+func (r *JetonsRepository) Create(pack *models.Jetons) error {
+	return r.db.Create(pack).Error
+}
+
+func (r *JetonsRepository) FindById(id uint) (*models.Jetons, error) {
+	var pack models.Jetons
+	if err := r.db.First(&pack, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &pack, nil
+}
+
+func (r *JetonsRepository) List() ([]models.Jetons, error) {
+	var packs []models.Jetons
+	if err := r.db.Order("id").Find(&packs).Error; err != nil {
+		return nil, err
+	}
+	return packs, nil
+}
+
+func (r *JetonsRepository) Update(id uint, changes models.Jetons) error {
+	changes.ID = 0
+	return r.db.Model(&models.Jetons{ID: id}).Updates(changes).Error
+}
+
+func (r *JetonsRepository) Delete(id uint) error {
+	return deleted(r.db.Delete(&models.Jetons{}, id))
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/internal/models"
+)
+
+type KermesseRepository struct {
+	db *gorm.DB
+}
+
+func NewKermesseRepository(db *gorm.DB) *KermesseRepository {
+	return &KermesseRepository{db: db}
+}
+
+func (r *KermesseRepository) Create(kermesse *models.Kermesse) error {
+	return r.db.Omit(clause.Associations).Create(kermesse).Error
+}
+
+// FindById charge la kermesse avec ses stands, ses organisateurs et ses participants
+func (r *KermesseRepository) FindById(id uint) (*models.Kermesse, error) {
+	var kermesse models.Kermesse
+	if err := r.db.Preload("Stands").
+		Preload("Organisateurs").
+		Preload("Participants").
+		First(&kermesse, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &kermesse, nil
+}
+
+func (r *KermesseRepository) List() ([]models.Kermesse, error) {
+	var kermesses []models.Kermesse
+	if err := r.db.Order("id").Find(&kermesses).Error; err != nil {
+		return nil, err
+	}
+	return kermesses, nil
+}
+
+func (r *KermesseRepository) Update(id uint, changes models.Kermesse) error {
+	changes.ID = 0
+	return r.db.Model(&models.Kermesse{ID: id}).Omit(clause.Associations).Updates(changes).Error
+}
+
+func (r *KermesseRepository) Delete(id uint) error {
+	return deleted(r.db.Delete(&models.Kermesse{}, id))
+}
@@ -0,0 +1,54 @@
+// Package postgres implémente les dépôts avec GORM sur la base de l'application
+package postgres
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"project/repository"
+)
+
+// New construit tous les dépôts sur la même connexion
+func New(db *gorm.DB) *repository.Repositories {
+	return &repository.Repositories{
+		Transactor:   transactor{db: db},
+		Users:        NewUserRepository(db),
+		Kermesses:    NewKermesseRepository(db),
+		Stands:       NewStandRepository(db),
+		Products:     NewProductRepository(db),
+		Transactions: NewTransactionRepository(db),
+		Histories:    NewHistoryRepository(db),
+		Jetons:       NewJetonsRepository(db),
+		Audit:        NewAuditRepository(db),
+	}
+}
+
+// transactor ouvre une transaction GORM et construit les dépôts sur celle-ci
+type transactor struct {
+	db *gorm.DB
+}
+
+func (t transactor) Transaction(fn func(tx *repository.Repositories) error) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		return fn(New(tx))
+	})
+}
+
+// notFound traduit l'absence d'enregistrement de GORM en repository.ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return repository.ErrNotFound
+	}
+	return err
+}
+
+// deleted vérifie qu'une suppression a bien porté sur un enregistrement
+func deleted(res *gorm.DB) error {
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
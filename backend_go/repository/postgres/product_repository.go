@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"gorm.io/gorm"
+	"project/internal/listing"
+	"project/internal/models"
+)
+
+type ProductRepository struct {
+	db *gorm.DB
+}
+
+func NewProductRepository(db *gorm.DB) *ProductRepository {
+	return &ProductRepository{db: db}
+}
+
+func (r *ProductRepository) Create(product *models.Product) error {
+	return r.db.Create(product).Error
+}
+
+func (r *ProductRepository) FindById(id uint) (*models.Product, error) {
+	var product models.Product
+	if err := r.db.First(&product, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &product, nil
+}
+
+func (r *ProductRepository) List(q listing.Query) (listing.Page[models.Product], error) {
+	return listing.Find[models.Product](r.db, q)
+}
+
+func (r *ProductRepository) ListByStand(standID uint) ([]models.Product, error) {
+	var products []models.Product
+	if err := r.db.Where("stand_id = ?", standID).Order("id").Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *ProductRepository) Update(id uint, changes models.Product) error {
+	changes.ID = 0
+	return r.db.Model(&models.Product{ID: id}).Updates(changes).Error
+}
+
+func (r *ProductRepository) Delete(id uint) error {
+	return deleted(r.db.Delete(&models.Product{}, id))
+}
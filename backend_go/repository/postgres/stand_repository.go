@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/internal/models"
+)
+
+type StandRepository struct {
+	db *gorm.DB
+}
+
+func NewStandRepository(db *gorm.DB) *StandRepository {
+	return &StandRepository{db: db}
+}
+
+func (r *StandRepository) Create(stand *models.Stand) error {
+	return r.db.Omit(clause.Associations).Create(stand).Error
+}
+
+// FindById charge le stand avec son stock
+func (r *StandRepository) FindById(id uint) (*models.Stand, error) {
+	var stand models.Stand
+	if err := r.db.Preload("Stock").First(&stand, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &stand, nil
+}
+
+func (r *StandRepository) List() ([]models.Stand, error) {
+	var stands []models.Stand
+	if err := r.db.Order("id").Find(&stands).Error; err != nil {
+		return nil, err
+	}
+	return stands, nil
+}
+
+func (r *StandRepository) ListByOwner(userID uint) ([]models.Stand, error) {
+	var stands []models.Stand
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&stands).Error; err != nil {
+		return nil, err
+	}
+	return stands, nil
+}
+
+func (r *StandRepository) Update(id uint, changes models.Stand) error {
+	changes.ID = 0
+	return r.db.Model(&models.Stand{ID: id}).
+		Omit(clause.Associations, "conso", "pts_donnees").
+		Updates(changes).Error
+}
+
+func (r *StandRepository) Delete(id uint) error {
+	return deleted(r.db.Delete(&models.Stand{}, id))
+}
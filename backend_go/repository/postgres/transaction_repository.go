@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"gorm.io/gorm"
+	"project/internal/models"
+)
+
+type TransactionRepository struct {
+	db *gorm.DB
+}
+
+func NewTransactionRepository(db *gorm.DB) *TransactionRepository {
+	return &TransactionRepository{db: db}
+}
+
+func (r *TransactionRepository) Create(transaction *models.Transaction) error {
+	return r.db.Create(transaction).Error
+}
+
+func (r *TransactionRepository) FindById(id uint) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := r.db.First(&transaction, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &transaction, nil
+}
+
+// ListByUser retourne les transactions du compte, les plus récentes d'abord
+func (r *TransactionRepository) ListByUser(userID uint) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	if err := r.db.Where("user_id = ?", userID).Order("date_transaction desc, id desc").
+		Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
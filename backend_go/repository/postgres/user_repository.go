@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/internal/listing"
+	"project/internal/models"
+)
+
+type UserRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) Create(user *models.User) error {
+	return r.db.Omit(clause.Associations).Create(user).Error
+}
+
+func (r *UserRepository) FindById(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+// FindProfile charge le compte avec sa famille, ses kermesses, ses stands et son historique
+func (r *UserRepository) FindProfile(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.Preload("Parents").
+		Preload("Enfants").
+		Preload("Kermesses").
+		Preload("Stands").
+		Preload("Transactions").
+		Preload("Historique").
+		First(&user, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) List(q listing.Query) (listing.Page[models.User], error) {
+	return listing.Find[models.User](r.db, q)
+}
+
+func (r *UserRepository) ListByRole(role uint, q listing.Query) (listing.Page[models.User], error) {
+	return listing.Find[models.User](r.db.Where("role = ?", role), q)
+}
+
+func (r *UserRepository) Update(id uint, changes models.User) error {
+	changes.ID = 0
+	return r.db.Model(&models.User{ID: id}).
+		Omit(clause.Associations, "jetons", "pts_attribues").
+		Updates(changes).Error
+}
+
+func (r *UserRepository) Delete(id uint) error {
+	return deleted(r.db.Delete(&models.User{}, id))
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"project/internal/listing"
+	"project/internal/models"
+)
+
+// ProductRepository donne accès aux produits des stands.
+// Update n'applique que les champs non nuls de changes.
+// List retourne la page demandée, avec les filtres de services.ProductListing.
+type ProductRepository interface {
+	Create(product *models.Product) error
+	FindById(id uint) (*models.Product, error)
+	List(q listing.Query) (listing.Page[models.Product], error)
+	ListByStand(standID uint) ([]models.Product, error)
+	Update(id uint, changes models.Product) error
+	Delete(id uint) error
+}
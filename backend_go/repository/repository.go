@@ -0,0 +1,30 @@
+// Package repository déclare l'accès aux données de chaque agrégat, indépendamment de la base.
+// Le sous-paquet postgres l'implémente avec GORM, le sous-paquet memory en mémoire pour les tests.
+//
+// Les opérations qui modifient plusieurs agrégats dans une même transaction (achats, transferts
+// de jetons, remboursements, caisse) restent écrites directement avec GORM dans les services.
+package repository
+
+import "errors"
+
+// ErrNotFound est retourné quand l'enregistrement demandé n'existe pas
+var ErrNotFound = errors.New("record not found")
+
+// Transactor exécute fn avec des dépôts qui écrivent dans une même transaction : si fn retourne
+// une erreur, aucune de leurs écritures n'est conservée
+type Transactor interface {
+	Transaction(fn func(tx *Repositories) error) error
+}
+
+// Repositories regroupe les dépôts de tous les agrégats
+type Repositories struct {
+	Transactor
+	Users        UserRepository
+	Kermesses    KermesseRepository
+	Stands       StandRepository
+	Products     ProductRepository
+	Transactions TransactionRepository
+	Histories    HistoryRepository
+	Jetons       JetonsRepository
+	Audit        AuditRepository
+}
@@ -0,0 +1,15 @@
+package repository
+
+import "project/internal/models"
+
+// StandRepository donne accès aux stands.
+// Update n'applique que les champs non nuls de changes ; la conso et les points se modifient
+// dans les transactions des services.
+type StandRepository interface {
+	Create(stand *models.Stand) error
+	FindById(id uint) (*models.Stand, error)
+	List() ([]models.Stand, error)
+	ListByOwner(userID uint) ([]models.Stand, error)
+	Update(id uint, changes models.Stand) error
+	Delete(id uint) error
+}
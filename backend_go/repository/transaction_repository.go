@@ -0,0 +1,11 @@
+package repository
+
+import "project/internal/models"
+
+// TransactionRepository donne accès aux transactions (achats de jetons, remboursements, dons).
+// Une transaction n'est jamais supprimée.
+type TransactionRepository interface {
+	Create(transaction *models.Transaction) error
+	FindById(id uint) (*models.Transaction, error)
+	ListByUser(userID uint) ([]models.Transaction, error)
+}
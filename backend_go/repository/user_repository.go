@@ -1,10 +1,20 @@
 package repository
 
-import "project/internal/models"
+import (
+	"project/internal/listing"
+	"project/internal/models"
+)
 
+// UserRepository donne accès aux comptes.
+// Update n'applique que les champs non nuls de changes et ne touche jamais au solde de jetons.
+// List et ListByRole retournent la page demandée, avec les filtres de services.UserListing.
 type UserRepository interface {
 	Create(user *models.User) error
-	FindById(id int64) (*models.User, error)
-	Update(user *models.User) error
-	Delete(id int64) error
+	FindById(id uint) (*models.User, error)
+	FindByEmail(email string) (*models.User, error)
+	FindProfile(id uint) (*models.User, error)
+	List(q listing.Query) (listing.Page[models.User], error)
+	ListByRole(role uint, q listing.Query) (listing.Page[models.User], error)
+	Update(id uint, changes models.User) error
+	Delete(id uint) error
 }
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"gorm.io/gorm"
+	"project/internal/audit"
+	"project/internal/listing"
+	"project/internal/models"
+)
+
+// Nombre d'entrées lues à la fois pendant un export
+const auditExportBatch = 500
+
+// AuditListing décrit la pagination, le tri et les filtres du journal d'audit
+var AuditListing = listing.Spec{
+	Sorts:       map[string]string{"id": "id", "date": "created_at"},
+	DefaultSort: "-date,-id",
+	Filters: map[string]listing.Filter{
+		"action":      {Where: "action = ?", Values: audit.Actions},
+		"entity_type": {Where: "entity_type = ?", Values: audit.EntityTypes},
+		"entity_id":   {Where: "entity_id = ?", Numeric: true},
+		"actor_id":    {Where: "actor_id = ?", Numeric: true},
+		"request_id":  {Where: "request_id = ?"},
+	},
+	Search: []string{"actor", "ip"},
+	Date:   "created_at",
+}
+
+// AuditService consulte le journal d'audit ; les entrées sont écrites par les services journalisés
+type AuditService struct {
+	db *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// List retourne une page du journal, réservé aux admins
+func (s *AuditService) List(operator models.User, q listing.Query) (listing.Page[models.AuditEntry], error) {
+	if operator.Role != models.RoleAdmin {
+		return listing.Page[models.AuditEntry]{}, ErrAdminOnly
+	}
+	return listing.Find[models.AuditEntry](s.db, q)
+}
+
+// Export passe à fn, par lots et dans l'ordre des identifiants, toutes les entrées correspondant
+// aux filtres de q, sans pagination. Réservé aux admins.
+func (s *AuditService) Export(operator models.User, q listing.Query, fn func([]models.AuditEntry) error) error {
+	if operator.Role != models.RoleAdmin {
+		return ErrAdminOnly
+	}
+	var batch []models.AuditEntry
+	return q.Scope(s.db.Model(&models.AuditEntry{})).
+		FindInBatches(&batch, auditExportBatch, func(*gorm.DB, int) error {
+			return fn(batch)
+		}).Error
+}
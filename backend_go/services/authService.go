@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+	"project/internal/apperror"
+	"project/internal/models"
+	"project/repository"
+)
+
+// Durée de validité d'un jeton de connexion
+const TokenLifetime = 24 * time.Hour
+
+// ErrInvalidCredentials ne distingue pas un email inconnu d'un mauvais mot de passe
+var ErrInvalidCredentials = apperror.Unauthorized("invalid_credentials", "invalid email or password")
+
+// SignupInput porte les champs d'un compte saisis à l'inscription ou à la mise à jour du profil
+type SignupInput struct {
+	Firstname string
+	Lastname  string
+	Email     string
+	Password  string
+	Picture   string
+	Role      uint
+}
+
+type AuthService struct {
+	users  repository.UserRepository
+	secret string
+}
+
+func NewAuthService(users repository.UserRepository, secret string) *AuthService {
+	return &AuthService{users: users, secret: secret}
+}
+
+// Signup crée un compte sans rôle particulier ; le mot de passe est haché
+func (s *AuthService) Signup(input SignupInput) (*models.User, error) {
+	input.Role = 0
+	return createUser(s.users, input)
+}
+
+// Login vérifie les identifiants et retourne un jeton JWT signé
+func (s *AuthService) Login(email, password string) (string, error) {
+	user, err := s.users.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", ErrInvalidCredentials
+		}
+		return "", err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return "", ErrInvalidCredentials
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"id":  user.ID,
+		"exp": time.Now().Add(TokenLifetime).Unix(),
+	})
+	return token.SignedString([]byte(s.secret))
+}
+
+// Profile retourne le compte avec sa famille, ses kermesses, ses stands et son historique
+func (s *AuthService) Profile(user models.User) (*models.User, error) {
+	return s.users.FindProfile(user.ID)
+}
+
+// UpdateProfile modifie les champs renseignés du compte ; le rôle ne peut pas être changé ici
+func (s *AuthService) UpdateProfile(user models.User, input SignupInput) (*models.User, error) {
+	input.Role = 0
+	return updateUser(s.users, user, input)
+}
+
+// createUser crée un compte après avoir vérifié que l'email est libre
+func createUser(users repository.UserRepository, input SignupInput) (*models.User, error) {
+	if err := emailAvailable(users, input.Email, 0); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	user := models.User{
+		Firstname: input.Firstname,
+		Lastname:  input.Lastname,
+		Email:     input.Email,
+		Password:  string(hash),
+		Picture:   input.Picture,
+		Role:      input.Role,
+	}
+	if err := users.Create(&user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// updateUser applique les champs non vides de input au compte
+func updateUser(users repository.UserRepository, user models.User, input SignupInput) (*models.User, error) {
+	changes := models.User{
+		Firstname: input.Firstname,
+		Lastname:  input.Lastname,
+		Picture:   input.Picture,
+		Role:      input.Role,
+	}
+	if input.Email != "" && input.Email != user.Email {
+		if err := emailAvailable(users, input.Email, user.ID); err != nil {
+			return nil, err
+		}
+		changes.Email = input.Email
+	}
+	if input.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		changes.Password = string(hash)
+	}
+
+	if err := users.Update(user.ID, changes); err != nil {
+		return nil, err
+	}
+	return users.FindById(user.ID)
+}
+
+// emailAvailable retourne ErrEmailTaken si un autre compte que userID utilise déjà l'email
+func emailAvailable(users repository.UserRepository, email string, userID uint) error {
+	existing, err := users.FindByEmail(email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != userID {
+		return ErrEmailTaken
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"project/internal/apperror"
+	"project/internal/audit"
+	"project/internal/models"
+)
+
+var (
+	ErrReasonRequired  = apperror.Validation("reason_required", "a reason is required to adjust a balance")
+	ErrAdjustmentEmpty = apperror.Validation("adjustment_empty", "the adjustment must change the balance")
+	ErrUserNotFound    = apperror.NotFound("user_not_found", "user not found")
+	ErrBalanceTarget   = apperror.Validation("balance_target", "give either a user or a prepaid card code")
+)
+
+// Catégories de soldes recalculables depuis l'historique
+const (
+	BalanceKindPrepaidCard = "prepaid_card"
+	BalanceKindStandConso  = "stand_conso"
+)
+
+// BalanceTarget désigne le solde à corriger : un compte ou une carte prépayée
+type BalanceTarget struct {
+	UserID   uint
+	CardCode string
+}
+
+// BalanceDrift est un écart entre un solde enregistré et celui recalculé depuis l'historique
+type BalanceDrift struct {
+	Kind     string `json:"kind"`
+	ID       uint   `json:"id"`
+	Label    string `json:"label"`
+	Stored   uint   `json:"stored"`
+	Computed int64  `json:"computed"`
+	Fixed    bool   `json:"fixed"`
+}
+
+type BalanceService struct {
+	db *gorm.DB
+}
+
+func NewBalanceService(db *gorm.DB) *BalanceService {
+	return &BalanceService{db: db}
+}
+
+// Adjust corrige le solde d'un compte ou d'une carte de delta jetons et trace la correction.
+// Un solde ne peut pas devenir négatif.
+func (s *BalanceService) Adjust(ctx context.Context, target BalanceTarget, delta int, reason string, operator models.User) (*models.BalanceAdjustment, error) {
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+	if delta == 0 {
+		return nil, ErrAdjustmentEmpty
+	}
+	if (target.UserID == 0) == (target.CardCode == "") {
+		return nil, ErrBalanceTarget
+	}
+
+	adjustment := models.BalanceAdjustment{Delta: delta, Reason: reason}
+	if target.CardCode != "" {
+		card, err := findPrepaidCard(s.db, target.CardCode)
+		if err != nil {
+			return nil, err
+		}
+		adjustment.PrepaidCardID = &card.ID
+	} else {
+		var user models.User
+		if err := s.db.First(&user, target.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrUserNotFound
+			}
+			return nil, err
+		}
+		adjustment.UserID = &user.ID
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		return applyAdjustment(ctx, tx, operator, &adjustment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &adjustment, nil
+}
+
+// applyAdjustment modifie le solde visé par l'ajustement puis l'enregistre, avec le solde obtenu
+// et son auteur, dans la table des ajustements et dans le journal d'audit
+func applyAdjustment(ctx context.Context, tx *gorm.DB, operator models.User, adjustment *models.BalanceAdjustment) error {
+	query := tx.Model(&models.User{}).Where("id = ?", adjustment.UserID)
+	if adjustment.PrepaidCardID != nil {
+		query = tx.Model(&models.PrepaidCard{}).Where("id = ?", *adjustment.PrepaidCardID)
+	}
+
+	var res *gorm.DB
+	if adjustment.Delta < 0 {
+		res = query.Where("jetons >= ?", -adjustment.Delta).
+			Update("jetons", gorm.Expr("jetons - ?", -adjustment.Delta))
+	} else {
+		res = query.Update("jetons", gorm.Expr("jetons + ?", adjustment.Delta))
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotEnoughJetons
+	}
+
+	balance := tx.Model(&models.User{}).Where("id = ?", adjustment.UserID)
+	if adjustment.PrepaidCardID != nil {
+		balance = tx.Model(&models.PrepaidCard{}).Where("id = ?", *adjustment.PrepaidCardID)
+	}
+	if err := balance.Select("jetons").Scan(&adjustment.BalanceAfter).Error; err != nil {
+		return err
+	}
+	adjustment.Operator = operator.Email
+	if err := tx.Create(adjustment).Error; err != nil {
+		return err
+	}
+
+	entry := audit.Entry{
+		Operator:   operator,
+		Action:     audit.ActionBalanceAdjust,
+		EntityType: audit.EntityUser,
+		Before:     map[string]any{"jetons": int(adjustment.BalanceAfter) - adjustment.Delta},
+		After:      map[string]any{"jetons": adjustment.BalanceAfter, "reason": adjustment.Reason},
+	}
+	if adjustment.PrepaidCardID != nil {
+		entry.EntityType, entry.EntityID = audit.EntityPrepaidCard, *adjustment.PrepaidCardID
+	} else {
+		entry.EntityID = *adjustment.UserID
+	}
+	return audit.Record(ctx, tx, entry)
+}
+
+// Recompute recalcule les soldes des cartes prépayées et la conso des stands depuis l'historique
+// (recharges, achats non annulés, ajustements) et retourne les écarts. Avec apply, les valeurs
+// enregistrées sont remplacées par les valeurs recalculées.
+// Les soldes des comptes ne sont pas recalculés : les transferts entre parents et enfants et
+// les soldes de fin de kermesse ne laissent pas de trace suffisante. On les corrige avec Adjust.
+func (s *BalanceService) Recompute(apply bool) ([]BalanceDrift, error) {
+	var drifts []BalanceDrift
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		cards, err := cardDrifts(tx)
+		if err != nil {
+			return err
+		}
+		stands, err := standDrifts(tx)
+		if err != nil {
+			return err
+		}
+		drifts = append(cards, stands...)
+		if !apply {
+			return nil
+		}
+
+		for i, drift := range drifts {
+			if drift.Computed < 0 {
+				continue
+			}
+			var err error
+			if drift.Kind == BalanceKindStandConso {
+				err = tx.Model(&models.Stand{}).Where("id = ?", drift.ID).Update("conso", drift.Computed).Error
+			} else {
+				err = tx.Model(&models.PrepaidCard{}).Where("id = ?", drift.ID).Update("jetons", drift.Computed).Error
+			}
+			if err != nil {
+				return err
+			}
+			drifts[i].Fixed = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return drifts, nil
+}
+
+func cardDrifts(tx *gorm.DB) ([]BalanceDrift, error) {
+	var rows []struct {
+		ID       uint
+		Code     string
+		Jetons   uint
+		Computed int64
+	}
+	err := tx.Raw(`
+		SELECT c.id, c.code, c.jetons,
+			COALESCE((SELECT SUM(t.quantity) FROM transactions t
+				WHERE t.prepaid_card_id = c.id AND t.type = ? AND t.status = ?), 0)
+			- COALESCE((SELECT SUM(h.nb_jetons) FROM histories h
+				WHERE h.prepaid_card_id = c.id AND h.type IN ? AND h.reversed_at IS NULL), 0)
+			+ COALESCE((SELECT SUM(a.delta) FROM balance_adjustments a
+				WHERE a.prepaid_card_id = c.id), 0) AS computed
+		FROM prepaid_cards c
+		ORDER BY c.id`,
+		models.TransactionTypeJetons, models.TransactionStatusSucceeded,
+		[]string{models.HistoryTypeInteraction, models.HistoryTypePurchase}).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []BalanceDrift
+	for _, row := range rows {
+		if int64(row.Jetons) != row.Computed {
+			drifts = append(drifts, BalanceDrift{
+				Kind: BalanceKindPrepaidCard, ID: row.ID, Label: row.Code, Stored: row.Jetons, Computed: row.Computed,
+			})
+		}
+	}
+	return drifts, nil
+}
+
+func standDrifts(tx *gorm.DB) ([]BalanceDrift, error) {
+	var rows []struct {
+		ID       uint
+		Name     string
+		Conso    uint
+		Computed int64
+	}
+	err := tx.Raw(`
+		SELECT s.id, s.name, s.conso,
+			COALESCE((SELECT SUM(h.nb_jetons) FROM histories h
+				WHERE h.stand_id = s.id AND h.type IN ? AND h.reversed_at IS NULL), 0) AS computed
+		FROM stands s
+		ORDER BY s.id`,
+		[]string{models.HistoryTypeInteraction, models.HistoryTypePurchase}).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []BalanceDrift
+	for _, row := range rows {
+		if int64(row.Conso) != row.Computed {
+			drifts = append(drifts, BalanceDrift{
+				Kind: BalanceKindStandConso, ID: row.ID, Label: row.Name, Stored: row.Conso, Computed: row.Computed,
+			})
+		}
+	}
+	return drifts, nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"gorm.io/gorm"
+	"project/internal/apperror"
+	"project/internal/models"
+)
+
+var ErrFinancesForbidden = apperror.Forbidden("finances_forbidden", "only the kermesse organisers can see its finances")
+
+// SalesSummary regroupe un montant encaissé et le nombre de jetons correspondant
+type SalesSummary struct {
+	Amount float64 `json:"amount"`
+	Jetons uint    `json:"jetons"`
+}
+
+// KermesseFinances sépare ce qui passe par les comptes des familles de ce qui passe par les cartes prépayées
+type KermesseFinances struct {
+	KermesseID uint `json:"kermesse_id"`
+
+	OnlineSales      SalesSummary `json:"online_sales"`
+	CashDeskSales    SalesSummary `json:"cash_desk_sales"`
+	PrepaidCardSales SalesSummary `json:"prepaid_card_sales"`
+
+	Refunds   SalesSummary `json:"refunds"`
+	Donations SalesSummary `json:"donations"`
+
+	AccountSpending     uint `json:"account_spending"`
+	PrepaidCardSpending uint `json:"prepaid_card_spending"`
+
+	PrepaidCardsIssued      int64 `json:"prepaid_cards_issued"`
+	PrepaidCardsLinked      int64 `json:"prepaid_cards_linked"`
+	PrepaidCardsOutstanding uint  `json:"prepaid_cards_outstanding"`
+}
+
+type FinanceService struct {
+	db *gorm.DB
+}
+
+func NewFinanceService(db *gorm.DB) *FinanceService {
+	return &FinanceService{db: db}
+}
+
+// KermesseFinances calcule le bilan d'une kermesse, réservé à ses organisateurs et aux admins
+func (s *FinanceService) KermesseFinances(operator models.User, kermesseID uint) (*KermesseFinances, error) {
+	var kermesse models.Kermesse
+	if err := s.db.Preload("Organisateurs").First(&kermesse, kermesseID).Error; err != nil {
+		return nil, ErrKermesseNotFound
+	}
+	if !isKermesseOrganisateur(operator, kermesse) {
+		return nil, ErrFinancesForbidden
+	}
+
+	finances := KermesseFinances{KermesseID: kermesse.ID}
+
+	sales := s.db.Model(&models.Transaction{}).
+		Where("kermesse_id = ? AND type = ? AND status = ?", kermesse.ID, models.TransactionTypeJetons, models.TransactionStatusSucceeded)
+	if err := sumTransactions(sales.Session(&gorm.Session{}).
+		Where("till_session_id IS NULL AND prepaid_card_id IS NULL"), &finances.OnlineSales); err != nil {
+		return nil, err
+	}
+	if err := sumTransactions(sales.Session(&gorm.Session{}).
+		Where("till_session_id IS NOT NULL AND prepaid_card_id IS NULL"), &finances.CashDeskSales); err != nil {
+		return nil, err
+	}
+	if err := sumTransactions(sales.Session(&gorm.Session{}).
+		Where("prepaid_card_id IS NOT NULL"), &finances.PrepaidCardSales); err != nil {
+		return nil, err
+	}
+
+	if err := sumTransactions(s.db.Model(&models.Transaction{}).
+		Where("kermesse_id = ? AND type = ?", kermesse.ID, models.TransactionTypeRefund), &finances.Refunds); err != nil {
+		return nil, err
+	}
+	if err := sumTransactions(s.db.Model(&models.Transaction{}).
+		Where("kermesse_id = ? AND type = ?", kermesse.ID, models.TransactionTypeDonation), &finances.Donations); err != nil {
+		return nil, err
+	}
+
+	// Dépenses sur les stands de la kermesse, hors achats annulés
+	spending := s.db.Model(&models.History{}).
+		Select("COALESCE(SUM(nb_jetons), 0)").
+		Where("type IN ? AND reversed_at IS NULL", []string{models.HistoryTypeInteraction, models.HistoryTypePurchase}).
+		Where("stand_id IN (?)", s.db.Table("kermesse_stands").Select("stand_id").Where("kermesse_id = ?", kermesse.ID))
+	if err := spending.Session(&gorm.Session{}).Where("prepaid_card_id IS NULL").
+		Scan(&finances.AccountSpending).Error; err != nil {
+		return nil, err
+	}
+	if err := spending.Session(&gorm.Session{}).Where("prepaid_card_id IS NOT NULL").
+		Scan(&finances.PrepaidCardSpending).Error; err != nil {
+		return nil, err
+	}
+
+	cards := s.db.Model(&models.PrepaidCard{}).Where("kermesse_id = ?", kermesse.ID)
+	if err := cards.Session(&gorm.Session{}).Count(&finances.PrepaidCardsIssued).Error; err != nil {
+		return nil, err
+	}
+	if err := cards.Session(&gorm.Session{}).Where("user_id IS NOT NULL").Count(&finances.PrepaidCardsLinked).Error; err != nil {
+		return nil, err
+	}
+	if err := cards.Session(&gorm.Session{}).Select("COALESCE(SUM(jetons), 0)").
+		Scan(&finances.PrepaidCardsOutstanding).Error; err != nil {
+		return nil, err
+	}
+
+	finances.OnlineSales.Amount = roundCents(finances.OnlineSales.Amount)
+	finances.CashDeskSales.Amount = roundCents(finances.CashDeskSales.Amount)
+	finances.PrepaidCardSales.Amount = roundCents(finances.PrepaidCardSales.Amount)
+	finances.Refunds.Amount = roundCents(finances.Refunds.Amount)
+	finances.Donations.Amount = roundCents(finances.Donations.Amount)
+	return &finances, nil
+}
+
+func sumTransactions(query *gorm.DB, summary *SalesSummary) error {
+	return query.Select("COALESCE(SUM(price), 0) AS amount, COALESCE(SUM(quantity), 0) AS jetons").
+		Scan(summary).Error
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"project/internal/migrate"
+)
+
+// HealthService vérifie que l'application peut servir des requêtes
+type HealthService struct {
+	db *gorm.DB
+}
+
+func NewHealthService(db *gorm.DB) *HealthService {
+	return &HealthService{db: db}
+}
+
+// Ready vérifie que la base répond et que toutes les migrations y sont appliquées
+func (s *HealthService) Ready(ctx context.Context) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("database unreachable: %w", err)
+	}
+	return migrate.CheckCurrent(s.db.WithContext(ctx))
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"project/internal/apperror"
+	"project/internal/audit"
+	"project/internal/models"
+	"project/repository"
+)
+
+var ErrJetonsNotFound = apperror.NotFound("jetons_not_found", "jeton not found")
+
+// JetonsService gère les packs de jetons proposés à la vente
+type JetonsService struct {
+	repos *repository.Repositories
+	packs repository.JetonsRepository
+}
+
+func NewJetonsService(repos *repository.Repositories) *JetonsService {
+	return &JetonsService{repos: repos, packs: repos.Jetons}
+}
+
+// Create ajoute un pack, réservé aux admins
+func (s *JetonsService) Create(operator models.User, pack models.Jetons) (*models.Jetons, error) {
+	if operator.Role != models.RoleAdmin {
+		return nil, ErrAdminOnly
+	}
+	pack.ID = 0
+	if err := s.packs.Create(&pack); err != nil {
+		return nil, err
+	}
+	return &pack, nil
+}
+
+// List retourne tous les packs, visibles sans connexion
+func (s *JetonsService) List() ([]models.Jetons, error) {
+	return s.packs.List()
+}
+
+// Update modifie le nombre de jetons ou le prix d'un pack, réservé aux admins
+func (s *JetonsService) Update(operator models.User, id uint, changes models.Jetons) (*models.Jetons, error) {
+	if operator.Role != models.RoleAdmin {
+		return nil, ErrAdminOnly
+	}
+	if _, err := s.find(id); err != nil {
+		return nil, err
+	}
+	if err := s.packs.Update(id, models.Jetons{NbJetons: changes.NbJetons, Price: changes.Price}); err != nil {
+		return nil, err
+	}
+	return s.find(id)
+}
+
+// Delete supprime un pack, réservé aux admins
+func (s *JetonsService) Delete(ctx context.Context, operator models.User, id uint) error {
+	if operator.Role != models.RoleAdmin {
+		return ErrAdminOnly
+	}
+	pack, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Jetons.Delete(id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrJetonsNotFound
+			}
+			return err
+		}
+		return tx.Audit.Record(ctx, audit.Entry{
+			Operator: operator, Action: audit.ActionJetonsPackDelete, EntityType: audit.EntityJetonsPack, EntityID: id, Before: pack,
+		})
+	})
+}
+
+func (s *JetonsService) find(id uint) (*models.Jetons, error) {
+	pack, err := s.packs.FindById(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrJetonsNotFound
+		}
+		return nil, err
+	}
+	return pack, nil
+}
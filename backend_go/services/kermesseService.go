@@ -1,10 +1,66 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
 	"gorm.io/gorm"
+	"project/internal/apperror"
+	"project/internal/audit"
+	"project/internal/listing"
 	"project/internal/models"
 )
 
+var (
+	ErrKermesseForbidden     = apperror.Forbidden("kermesse_forbidden", "you don't have permission to manage this kermesse")
+	ErrKermesseAlreadyClosed = apperror.Conflict("kermesse_already_closed", "kermesse already closed")
+	ErrNoStandsFound         = apperror.NotFound("stands_not_found", "no stand found for the given ids")
+	ErrNoUsersFound          = apperror.NotFound("users_not_found", "no user found for the given ids")
+	ErrInvalidMemberType     = apperror.Validation("invalid_member_type", "type must be participants or organisateurs")
+)
+
+// KermesseListing décrit la pagination, le tri et les filtres de la liste des kermesses
+var KermesseListing = listing.Spec{
+	Sorts:       map[string]string{"id": "id", "name": "name", "status": "status", "closed_at": "closed_at"},
+	DefaultSort: "id",
+	Filters: map[string]listing.Filter{
+		"status": {Where: "status = ?", Values: []string{models.KermesseStatusOpen, models.KermesseStatusClosed}},
+	},
+	Search: []string{"name"},
+}
+
+// Types de membres d'une kermesse
+const (
+	KermesseMemberParticipants  = "participants"
+	KermesseMemberOrganisateurs = "organisateurs"
+)
+
+// ExportedUser est la partie d'un compte reprise dans un export (sans mot de passe)
+type ExportedUser struct {
+	ID        uint   `json:"id"`
+	Firstname string `json:"firstname"`
+	Lastname  string `json:"lastname"`
+	Email     string `json:"email"`
+	Role      uint   `json:"role"`
+	Jetons    uint   `json:"jetons"`
+}
+
+// KermesseExport rassemble tout ce qu'une kermesse a produit, pour l'archiver ou l'analyser hors de l'application
+type KermesseExport struct {
+	ExportedAt    time.Time            `json:"exported_at"`
+	Kermesse      models.Kermesse      `json:"kermesse"`
+	Organisateurs []ExportedUser       `json:"organisateurs"`
+	Participants  []ExportedUser       `json:"participants"`
+	Products      []models.Product     `json:"products"`
+	Transactions  []models.Transaction `json:"transactions"`
+	History       []models.History     `json:"history"`
+	PrepaidCards  []models.PrepaidCard `json:"prepaid_cards"`
+	TillSessions  []models.TillSession `json:"till_sessions"`
+	Finances      *KermesseFinances    `json:"finances"`
+}
+
 type KermesseService struct {
 	db *gorm.DB
 }
@@ -12,22 +68,289 @@ type KermesseService struct {
 func NewKermesseService(db *gorm.DB) *KermesseService {
 	return &KermesseService{db: db}
 }
-func (s *KermesseService) CreateKermesse(kermesse *models.Kermesse) error {
-	return s.db.Create(kermesse).Error
+
+// Create crée une kermesse dont l'opérateur est le créateur, réservé aux admins et organisateurs
+func (s *KermesseService) Create(operator models.User, name, picture string) (*models.Kermesse, error) {
+	if operator.Role != models.RoleAdmin && operator.Role != models.RoleOrganisateur {
+		return nil, ErrKermesseForbidden
+	}
+	kermesse := models.Kermesse{Name: name, Picture: picture, UserID: operator.ID}
+	if err := s.db.Create(&kermesse).Error; err != nil {
+		return nil, err
+	}
+	return &kermesse, nil
+}
+
+// List retourne une page des kermesses : toutes pour un admin, celles qu'il organise pour un
+// organisateur et celles auxquelles il participe pour les autres rôles
+func (s *KermesseService) List(operator models.User, q listing.Query) (listing.Page[models.Kermesse], error) {
+	preloads := []string{"Organisateurs", "Participants", "Stands"}
+	query := s.db
+	switch {
+	case operator.Role == models.RoleAdmin:
+	case operator.Role == models.RoleOrganisateur:
+		query = query.Where("(user_id = ? OR id IN (SELECT kermesse_id FROM kermesse_organisateurs WHERE user_id = ?))",
+			operator.ID, operator.ID)
+	case operator.Role >= 3:
+		query = query.Where("id IN (SELECT kermesse_id FROM kermesse_participants WHERE user_id = ?)", operator.ID)
+		preloads = nil
+	default:
+		return listing.Page[models.Kermesse]{Items: []models.Kermesse{}}, nil
+	}
+	return listing.Find[models.Kermesse](query, q, preloads...)
 }
 
-func (s *KermesseService) GetKermesseById(id uint) (*models.Kermesse, error) {
+// Get retourne la kermesse à un admin, à son créateur, à ses organisateurs et à ses participants
+func (s *KermesseService) Get(operator models.User, id uint) (*models.Kermesse, error) {
 	var kermesse models.Kermesse
-	if err := s.db.First(&kermesse, "id = ?", id).Error; err != nil {
+	if err := s.db.Preload("Organisateurs").Preload("Participants").Preload("Stands").
+		First(&kermesse, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrKermesseNotFound
+		}
 		return nil, err
 	}
+
+	if isKermesseOrganisateur(operator, kermesse) {
+		return &kermesse, nil
+	}
+	for _, participant := range kermesse.Participants {
+		if participant.ID == operator.ID {
+			return &kermesse, nil
+		}
+	}
+	return nil, ErrKermesseForbidden
+}
+
+// Stands retourne une page des stands de la kermesse, visible de ceux qui peuvent voir la kermesse
+func (s *KermesseService) Stands(operator models.User, id uint, q listing.Query) (listing.Page[models.Stand], error) {
+	if _, err := s.Get(operator, id); err != nil {
+		return listing.Page[models.Stand]{}, err
+	}
+	return listing.Find[models.Stand](s.db, q.With("kermesse_id", strconv.FormatUint(uint64(id), 10)))
+}
+
+// Update renomme la kermesse ou change son image, réservé à son créateur et aux admins
+func (s *KermesseService) Update(operator models.User, id uint, name, picture string) (*models.Kermesse, error) {
+	kermesse, err := s.owned(operator, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if name != "" {
+		updates["name"] = name
+	}
+	if picture != "" {
+		updates["picture"] = picture
+	}
+	if len(updates) > 0 {
+		if err := s.db.Model(kermesse).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+	return kermesse, nil
+}
+
+// Delete supprime la kermesse, réservé à son créateur et aux admins
+func (s *KermesseService) Delete(ctx context.Context, operator models.User, id uint) error {
+	kermesse, err := s.owned(operator, id)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(kermesse).Error; err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Entry{
+			Operator: operator, Action: audit.ActionKermesseDelete, EntityType: audit.EntityKermesse, EntityID: kermesse.ID, Before: kermesse,
+		})
+	})
+}
+
+// AddStands rattache des stands existants à la kermesse. Chaque rattachement, qui vaut approbation
+// du stand, est journalisé dans la même transaction.
+func (s *KermesseService) AddStands(ctx context.Context, operator models.User, id uint, standIDs []uint) ([]models.Stand, error) {
+	kermesse, err := s.manageable(operator, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(standIDs) == 0 {
+		return nil, ErrNoStandsFound
+	}
+
+	var stands []models.Stand
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id IN ?", standIDs).Find(&stands).Error; err != nil {
+			return err
+		}
+		if len(stands) == 0 {
+			return ErrNoStandsFound
+		}
+		if err := tx.Model(kermesse).Association("Stands").Append(&stands); err != nil {
+			return err
+		}
+		for _, stand := range stands {
+			if err := audit.Record(ctx, tx, audit.Entry{
+				Operator: operator, Action: audit.ActionKermesseStandAdd, EntityType: audit.EntityKermesse, EntityID: kermesse.ID,
+				After: map[string]any{"stand_id": stand.ID, "stand": stand.Name},
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stands, nil
+}
+
+// AddMembers ajoute des participants ou des organisateurs à la kermesse
+func (s *KermesseService) AddMembers(operator models.User, id uint, memberType string, userIDs []uint) ([]models.User, error) {
+	association := ""
+	switch memberType {
+	case KermesseMemberParticipants:
+		association = "Participants"
+	case KermesseMemberOrganisateurs:
+		association = "Organisateurs"
+	default:
+		return nil, ErrInvalidMemberType
+	}
+
+	kermesse, err := s.manageable(operator, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(userIDs) == 0 {
+		return nil, ErrNoUsersFound
+	}
+
+	var users []models.User
+	if err := s.db.Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, ErrNoUsersFound
+	}
+	if err := s.db.Model(kermesse).Association(association).Append(&users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// owned charge la kermesse si l'opérateur en est le créateur ou un admin
+func (s *KermesseService) owned(operator models.User, id uint) (*models.Kermesse, error) {
+	var kermesse models.Kermesse
+	if err := s.db.First(&kermesse, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrKermesseNotFound
+		}
+		return nil, err
+	}
+	if operator.Role != models.RoleAdmin && kermesse.UserID != operator.ID {
+		return nil, ErrKermesseForbidden
+	}
 	return &kermesse, nil
 }
 
-func (s *KermesseService) UpdateKermesse(kermesse *models.Kermesse) error {
-	return s.db.Save(kermesse).Error
+// Close clôture la kermesse : plus de ventes, les familles peuvent alors être remboursées
+func (s *KermesseService) Close(operator models.User, id uint) (*models.Kermesse, error) {
+	kermesse, err := s.manageable(operator, id)
+	if err != nil {
+		return nil, err
+	}
+	if kermesse.Status == models.KermesseStatusClosed {
+		return nil, ErrKermesseAlreadyClosed
+	}
+
+	now := time.Now()
+	res := s.db.Model(&models.Kermesse{}).
+		Where("id = ? AND status <> ?", kermesse.ID, models.KermesseStatusClosed).
+		Updates(map[string]interface{}{"status": models.KermesseStatusClosed, "closed_at": now})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrKermesseAlreadyClosed
+	}
+
+	kermesse.Status = models.KermesseStatusClosed
+	kermesse.ClosedAt = &now
+	return kermesse, nil
+}
+
+// Export retourne la kermesse avec ses stands, produits, ventes, historique, cartes, caisses et son bilan
+func (s *KermesseService) Export(operator models.User, id uint) (*KermesseExport, error) {
+	kermesse, err := s.manageable(operator, id)
+	if err != nil {
+		return nil, err
+	}
+
+	export := KermesseExport{ExportedAt: time.Now()}
+	if err := s.db.Preload("Stands").Preload("Organisateurs").Preload("Participants").
+		First(&export.Kermesse, kermesse.ID).Error; err != nil {
+		return nil, err
+	}
+	export.Organisateurs = exportUsers(export.Kermesse.Organisateurs)
+	export.Participants = exportUsers(export.Kermesse.Participants)
+	export.Kermesse.Organisateurs = nil
+	export.Kermesse.Participants = nil
+
+	standIDs := make([]uint, 0, len(export.Kermesse.Stands))
+	for _, stand := range export.Kermesse.Stands {
+		standIDs = append(standIDs, stand.ID)
+	}
+
+	if err := s.db.Where("stand_id IN ?", standIDs).Order("id").Find(&export.Products).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("stand_id IN ?", standIDs).Order("id").Find(&export.History).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("kermesse_id = ?", kermesse.ID).Order("id").Find(&export.Transactions).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("kermesse_id = ?", kermesse.ID).Order("id").Find(&export.PrepaidCards).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("kermesse_id = ?", kermesse.ID).Order("id").Find(&export.TillSessions).Error; err != nil {
+		return nil, err
+	}
+
+	export.Finances, err = NewFinanceService(s.db).KermesseFinances(operator, kermesse.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// manageable charge la kermesse si l'opérateur en est le créateur, un organisateur ou un admin
+func (s *KermesseService) manageable(operator models.User, id uint) (*models.Kermesse, error) {
+	var kermesse models.Kermesse
+	if err := s.db.Preload("Organisateurs").First(&kermesse, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrKermesseNotFound
+		}
+		return nil, err
+	}
+	if !isKermesseOrganisateur(operator, kermesse) {
+		return nil, ErrKermesseForbidden
+	}
+	return &kermesse, nil
 }
 
-func (s *KermesseService) DeleteKermesse(id uint) error {
-	return s.db.Delete(&models.Kermesse{}, "id = ?", id).Error
+func exportUsers(users []models.User) []ExportedUser {
+	exported := make([]ExportedUser, 0, len(users))
+	for _, user := range users {
+		exported = append(exported, ExportedUser{
+			ID:        user.ID,
+			Firstname: user.Firstname,
+			Lastname:  user.Lastname,
+			Email:     user.Email,
+			Role:      user.Role,
+			Jetons:    user.Jetons,
+		})
+	}
+	return exported
 }
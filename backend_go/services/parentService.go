@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"project/internal/apperror"
+	"project/internal/models"
+)
+
+var (
+	ErrNotParent          = apperror.Forbidden("not_parent", "you need to have the correct role (parent one)")
+	ErrNoChildrenFound    = apperror.NotFound("children_not_found", "no child found for the given ids")
+	ErrChildNotFound      = apperror.NotFound("child_not_found", "child not found")
+	ErrChildNotLinked     = apperror.Forbidden("child_not_linked", "you are not authorized to give coins to this child")
+	ErrGiveCoinsForbidden = apperror.Forbidden("give_coins_forbidden", "you do not have permission to give coins")
+)
+
+// CoinTransfer donne les soldes du parent et de l'enfant après un don de jetons
+type CoinTransfer struct {
+	ParentJetons uint
+	ChildJetons  uint
+}
+
+type ParentService struct {
+	db *gorm.DB
+}
+
+func NewParentService(db *gorm.DB) *ParentService {
+	return &ParentService{db: db}
+}
+
+// AddChildren rattache des comptes élèves au parent, dans les deux sens de la relation
+func (s *ParentService) AddChildren(parent models.User, childIDs []uint) ([]models.User, error) {
+	if parent.Role != models.RoleParent {
+		return nil, ErrNotParent
+	}
+	if len(childIDs) == 0 {
+		return nil, ErrNoChildrenFound
+	}
+
+	var children []models.User
+	if err := s.db.Where("id IN ?", childIDs).Find(&children).Error; err != nil {
+		return nil, err
+	}
+	if len(children) == 0 {
+		return nil, ErrNoChildrenFound
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&parent).Association("Enfants").Append(children); err != nil {
+			return err
+		}
+		return tx.Model(&children).Association("Parents").Append(&parent)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return children, nil
+}
+
+// GiveCoins transfère des jetons du compte du parent vers celui d'un de ses enfants
+func (s *ParentService) GiveCoins(parent models.User, childID uint, nbJetons uint) (*CoinTransfer, error) {
+	if parent.Role > 4 {
+		return nil, ErrGiveCoinsForbidden
+	}
+
+	var child models.User
+	if err := s.db.First(&child, childID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChildNotFound
+		}
+		return nil, err
+	}
+
+	var linked int64
+	if err := s.db.Table("user_enfants").Where("user_id = ? AND enfant_id = ?", parent.ID, child.ID).
+		Count(&linked).Error; err != nil {
+		return nil, err
+	}
+	if linked == 0 {
+		return nil, ErrChildNotLinked
+	}
+
+	var transfer CoinTransfer
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Le débit conditionnel empêche deux dons simultanés de passer le solde du parent en négatif
+		res := tx.Model(&models.User{}).
+			Where("id = ? AND jetons >= ?", parent.ID, nbJetons).
+			Update("jetons", gorm.Expr("jetons - ?", nbJetons))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrNotEnoughJetons
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", child.ID).
+			Update("jetons", gorm.Expr("jetons + ?", nbJetons)).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", parent.ID).Select("jetons").Scan(&transfer.ParentJetons).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", child.ID).Select("jetons").Scan(&transfer.ChildJetons).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"project/internal/apperror"
+	"project/internal/audit"
+	"project/internal/listing"
+	"project/internal/metrics"
+	"project/internal/models"
+	"project/internal/payment"
+)
+
+var (
+	ErrInvalidPaymentType  = apperror.Validation("invalid_payment_type", "invalid payment type")
+	ErrTransactionNotFound = apperror.NotFound("transaction_not_found", "transaction not found")
+	ErrPaymentForbidden    = apperror.Forbidden("payment_forbidden", "you don't have permission to confirm this payment")
+	// ErrPaymentProvider enveloppe les erreurs du fournisseur de paiement (réseau, refus de l'API)
+	ErrPaymentProvider = apperror.Unavailable("payment_provider_error", "payment provider error")
+)
+
+// TransactionListing décrit la pagination, le tri et les filtres de la liste des transactions
+var TransactionListing = listing.Spec{
+	Sorts:       map[string]string{"id": "id", "date": "date_transaction", "price": "price", "quantity": "quantity"},
+	DefaultSort: "-date,-id",
+	Filters: map[string]listing.Filter{
+		"type": {Where: "type = ?", Values: []string{
+			models.TransactionTypeJetons, models.TransactionTypeTombola,
+			models.TransactionTypeRefund, models.TransactionTypeDonation,
+		}},
+		"status": {Where: "status = ?", Values: []string{
+			models.TransactionStatusPending, models.TransactionStatusSucceeded, models.TransactionStatusFailed,
+		}},
+		"kermesse_id": {Where: "kermesse_id = ?", Numeric: true},
+	},
+	Date: "date_transaction",
+}
+
+type PaymentService struct {
+	db       *gorm.DB
+	payments *payment.Registry
+	metrics  *metrics.Metrics
+}
+
+func NewPaymentService(db *gorm.DB, payments *payment.Registry, m *metrics.Metrics) *PaymentService {
+	return &PaymentService{db: db, payments: payments, metrics: m}
+}
+
+// CreatePayment crée une intention de paiement carte et la transaction en attente associée.
+// Les jetons ne sont crédités qu'une fois le paiement confirmé.
+func (s *PaymentService) CreatePayment(user models.User, paymentType string, quantity uint, price float32, kermesseID uint) (*models.Transaction, *payment.Intent, error) {
+	if paymentType != models.TransactionTypeJetons && paymentType != models.TransactionTypeTombola {
+		return nil, nil, ErrInvalidPaymentType
+	}
+
+	provider := s.payments.Card()
+	intent, err := provider.CreateIntent(payment.IntentParams{
+		AmountCents: int64(math.Round(float64(price) * 100)),
+		Currency:    "eur",
+		Metadata: map[string]string{
+			"user_id":  strconv.FormatUint(uint64(user.ID), 10),
+			"type":     paymentType,
+			"quantity": strconv.FormatUint(uint64(quantity), 10),
+		},
+	})
+	if err != nil {
+		s.metrics.RecordPaymentFailure(provider.Name(), "error")
+		return nil, nil, ErrPaymentProvider.Wrap(err)
+	}
+
+	transaction := models.Transaction{
+		Type:            paymentType,
+		DateTransaction: time.Now(),
+		Price:           price,
+		Quantity:        quantity,
+		Status:          models.TransactionStatusPending,
+		Provider:        provider.Name(),
+		PaymentIntentID: intent.ID,
+		UserID:          &user.ID,
+	}
+	if kermesseID != 0 {
+		transaction.KermesseID = &kermesseID
+	}
+	if err := s.db.Create(&transaction).Error; err != nil {
+		return nil, nil, err
+	}
+	return &transaction, intent, nil
+}
+
+// ConfirmPayment interroge le fournisseur et crédite les jetons si le paiement a abouti
+func (s *PaymentService) ConfirmPayment(ctx context.Context, user models.User, transactionID uint) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := s.db.First(&transaction, transactionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, err
+	}
+	if (transaction.UserID == nil || *transaction.UserID != user.ID) && user.Role != models.RoleAdmin {
+		return nil, ErrPaymentForbidden
+	}
+	if transaction.Status != models.TransactionStatusPending {
+		return &transaction, nil
+	}
+
+	provider, err := s.payments.Get(transaction.Provider)
+	if err != nil {
+		return nil, err
+	}
+	intent, err := provider.ConfirmIntent(transaction.PaymentIntentID)
+	if err != nil {
+		s.metrics.RecordPaymentFailure(provider.Name(), "error")
+		return nil, ErrPaymentProvider.Wrap(err)
+	}
+
+	if err := s.applyIntentStatus(ctx, user, transaction, intent.Status); err != nil {
+		return nil, err
+	}
+	if err := s.db.First(&transaction, transaction.ID).Error; err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// HandleWebhook vérifie la signature de l'événement et met à jour la transaction concernée
+func (s *PaymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	provider := s.payments.Card()
+	event, err := provider.VerifyWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	var status string
+	switch event.Type {
+	case payment.EventIntentSucceeded:
+		status = payment.StatusSucceeded
+	case payment.EventIntentFailed:
+		status = payment.StatusFailed
+	default:
+		return nil
+	}
+
+	var transaction models.Transaction
+	if err := s.db.Where("payment_intent_id = ? AND type IN ?", event.IntentID,
+		[]string{models.TransactionTypeJetons, models.TransactionTypeTombola}).
+		First(&transaction).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTransactionNotFound
+		}
+		return err
+	}
+	// Le webhook n'a pas de compte : le journal d'audit le désigne par son fournisseur
+	return s.applyIntentStatus(ctx, models.User{Email: "webhook:" + provider.Name()}, transaction, status)
+}
+
+// applyIntentStatus fait passer une transaction en attente à réussie ou échouée.
+// Le passage conditionnel sur le statut garantit qu'un paiement n'est crédité qu'une fois,
+// même si la confirmation et le webhook arrivent en même temps.
+func (s *PaymentService) applyIntentStatus(ctx context.Context, operator models.User, transaction models.Transaction, status string) error {
+	switch status {
+	case payment.StatusSucceeded:
+		var credited *models.Transaction
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			credited, err = completeTransaction(ctx, tx, operator, transaction.ID)
+			return err
+		})
+		if err == nil && credited != nil {
+			s.metrics.RecordJetonsSold(*credited)
+		}
+		return err
+	case payment.StatusFailed, payment.StatusCanceled:
+		res := s.db.Model(&models.Transaction{}).
+			Where("id = ? AND status = ?", transaction.ID, models.TransactionStatusPending).
+			Update("status", models.TransactionStatusFailed)
+		if res.Error == nil && res.RowsAffected > 0 {
+			s.metrics.RecordPaymentFailure(transaction.Provider, status)
+		}
+		return res.Error
+	default:
+		return nil
+	}
+}
+
+// completeTransaction passe la transaction en réussie, le journalise au nom d'operator et crédite ses jetons.
+// Retourne la transaction si des jetons ont été crédités, nil si elle était déjà traitée.
+func completeTransaction(ctx context.Context, tx *gorm.DB, operator models.User, transactionID uint) (*models.Transaction, error) {
+	res := tx.Model(&models.Transaction{}).
+		Where("id = ? AND status = ?", transactionID, models.TransactionStatusPending).
+		Update("status", models.TransactionStatusSucceeded)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	var transaction models.Transaction
+	if err := tx.First(&transaction, transactionID).Error; err != nil {
+		return nil, err
+	}
+	pending := transaction
+	pending.Status = models.TransactionStatusPending
+	if err := audit.Record(ctx, tx, audit.Entry{
+		Operator:   operator,
+		Action:     audit.ActionPaymentSucceeded,
+		EntityType: audit.EntityTransaction,
+		EntityID:   transaction.ID,
+		Before:     pending,
+		After:      transaction,
+	}); err != nil {
+		return nil, err
+	}
+	if transaction.Type != models.TransactionTypeJetons {
+		return nil, nil
+	}
+
+	// Les jetons vont sur la carte prépayée rechargée, sinon sur le compte de l'acheteur
+	var err error
+	if transaction.PrepaidCardID != nil {
+		err = tx.Model(&models.PrepaidCard{}).Where("id = ?", *transaction.PrepaidCardID).
+			Update("jetons", gorm.Expr("jetons + ?", transaction.Quantity)).Error
+	} else {
+		err = tx.Model(&models.User{}).Where("id = ?", transaction.UserID).
+			Update("jetons", gorm.Expr("jetons + ?", transaction.Quantity)).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// Transactions retourne une page des paiements, ventes en caisse, remboursements et dons de l'utilisateur
+func (s *PaymentService) Transactions(user models.User, q listing.Query) (listing.Page[models.Transaction], error) {
+	return listing.Find[models.Transaction](s.db.Where("user_id = ?", user.ID), q)
+}
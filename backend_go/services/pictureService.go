@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"project/internal/apperror"
+	"project/internal/imaging"
+	"project/internal/models"
+	"project/internal/storage"
+)
+
+var (
+	ErrPictureTooLarge   = apperror.TooLarge("picture_too_large", "picture file is too large")
+	ErrPictureDimensions = apperror.TooLarge("picture_dimensions_too_large", "picture has too many pixels")
+	ErrPictureType       = apperror.Validation("unsupported_picture_type", "picture must be a JPEG, PNG or GIF image")
+	ErrPictureInvalid    = apperror.Validation("invalid_picture", "picture could not be decoded")
+	ErrFileNotFound      = apperror.NotFound("file_not_found", "file not found")
+	ErrFileLinkInvalid   = apperror.Forbidden("invalid_file_link", "file link is invalid or has expired")
+)
+
+// Une photo enregistrée est stockée dans la colonne Picture sous la forme storage:<clé>.
+// Les autres valeurs sont des URL saisies par les clients avant les envois de fichiers,
+// renvoyées telles quelles.
+const storedPicturePrefix = "storage:"
+
+// Suffixe de la clé de la miniature, avant l'extension
+const thumbnailSuffix = "_thumb"
+
+// PictureSettings règle les envois de photos
+type PictureSettings struct {
+	Limits imaging.Limits
+	// Durée de validité des liens signés
+	URLTTL time.Duration
+}
+
+// PictureLinks sont les liens signés d'une photo et de sa miniature
+type PictureLinks struct {
+	URL       string
+	Thumbnail string
+	ExpiresAt time.Time
+}
+
+type PictureService struct {
+	db       *gorm.DB
+	store    storage.Store
+	settings PictureSettings
+	now      func() time.Time
+}
+
+func NewPictureService(db *gorm.DB, store storage.Store, settings PictureSettings) *PictureService {
+	return &PictureService{db: db, store: store, settings: settings, now: time.Now}
+}
+
+// MaxBytes est la taille maximale d'un fichier envoyé
+func (s *PictureService) MaxBytes() int64 {
+	return s.settings.Limits.MaxBytes
+}
+
+// SetUserPicture remplace la photo du compte connecté
+func (s *PictureService) SetUserPicture(operator models.User, file io.Reader) (*PictureLinks, error) {
+	return s.replace(&models.User{ID: operator.ID}, fmt.Sprintf("users/%d", operator.ID), operator.Picture, file)
+}
+
+// SetKermessePicture remplace la photo d'une kermesse, réservé à ses organisateurs
+func (s *PictureService) SetKermessePicture(operator models.User, kermesseID uint, file io.Reader) (*PictureLinks, error) {
+	var kermesse models.Kermesse
+	if err := s.db.Preload("Organisateurs").First(&kermesse, kermesseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrKermesseNotFound
+		}
+		return nil, err
+	}
+	if !isKermesseOrganisateur(operator, kermesse) {
+		return nil, ErrKermesseForbidden
+	}
+	return s.replace(&models.Kermesse{ID: kermesse.ID}, fmt.Sprintf("kermesses/%d", kermesse.ID), kermesse.Picture, file)
+}
+
+// SetProductPicture remplace la photo d'un produit, réservé aux admins comme les autres modifications
+func (s *PictureService) SetProductPicture(operator models.User, productID uint, file io.Reader) (*PictureLinks, error) {
+	if operator.Role != models.RoleAdmin {
+		return nil, ErrAdminOnly
+	}
+	var product models.Product
+	if err := s.db.First(&product, productID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	return s.replace(&models.Product{ID: product.ID}, fmt.Sprintf("products/%d", product.ID), product.Picture, file)
+}
+
+// URLs retourne les liens à servir pour la valeur d'une colonne Picture : des liens signés
+// pour une photo enregistrée, la valeur elle-même sinon
+func (s *PictureService) URLs(picture string) (url, thumbnail string) {
+	key, ok := strings.CutPrefix(picture, storedPicturePrefix)
+	if !ok {
+		return picture, picture
+	}
+	links, err := s.links(key)
+	if err != nil {
+		return "", ""
+	}
+	return links.URL, links.Thumbnail
+}
+
+// Open ouvre un fichier du stockage local d'après son lien signé.
+// Les autres stockages servent eux-mêmes leurs liens : le fichier y est introuvable ici.
+func (s *PictureService) Open(key, expires, signature string) (*storage.Object, error) {
+	local, ok := s.store.(*storage.LocalStore)
+	if !ok {
+		return nil, ErrFileNotFound
+	}
+	obj, err := local.Open(key, expires, signature)
+	switch {
+	case errors.Is(err, storage.ErrInvalidSignature):
+		return nil, ErrFileLinkInvalid
+	case errors.Is(err, storage.ErrNotFound), errors.Is(err, storage.ErrInvalidKey):
+		return nil, ErrFileNotFound
+	case err != nil:
+		return nil, err
+	}
+	return obj, nil
+}
+
+// replace prépare et stocke la nouvelle photo sous prefix, l'enregistre sur owner puis supprime
+// l'ancienne. Un échec de suppression ne laisse qu'un fichier orphelin, sans effet pour les clients.
+func (s *PictureService) replace(owner any, prefix, previous string, file io.Reader) (*PictureLinks, error) {
+	processed, err := imaging.Process(file, s.settings.Limits)
+	if err != nil {
+		return nil, pictureError(err)
+	}
+
+	name := make([]byte, 12)
+	if _, err := rand.Read(name); err != nil {
+		return nil, err
+	}
+	key := prefix + "/" + hex.EncodeToString(name) + processed.Ext
+	ctx := context.Background()
+	if err := s.store.Put(ctx, key, processed.ContentType, processed.Full.Data); err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(ctx, thumbnailKey(key), processed.ContentType, processed.Thumbnail.Data); err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(owner).Update("picture", storedPicturePrefix+key).Error; err != nil {
+		return nil, err
+	}
+
+	if old, ok := strings.CutPrefix(previous, storedPicturePrefix); ok && old != key {
+		s.store.Delete(ctx, old)
+		s.store.Delete(ctx, thumbnailKey(old))
+	}
+	return s.links(key)
+}
+
+func (s *PictureService) links(key string) (*PictureLinks, error) {
+	url, err := s.store.SignedURL(key, s.settings.URLTTL)
+	if err != nil {
+		return nil, err
+	}
+	thumbnail, err := s.store.SignedURL(thumbnailKey(key), s.settings.URLTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &PictureLinks{URL: url, Thumbnail: thumbnail, ExpiresAt: s.now().Add(s.settings.URLTTL).UTC()}, nil
+}
+
+// thumbnailKey retourne la clé de la miniature : users/1/ab12.jpg devient users/1/ab12_thumb.jpg
+func thumbnailKey(key string) string {
+	dot := strings.LastIndex(key, ".")
+	if dot <= strings.LastIndex(key, "/") {
+		return key + thumbnailSuffix
+	}
+	return key[:dot] + thumbnailSuffix + key[dot:]
+}
+
+func pictureError(err error) error {
+	switch {
+	case errors.Is(err, imaging.ErrTooLarge):
+		return ErrPictureTooLarge
+	case errors.Is(err, imaging.ErrTooManyPixels):
+		return ErrPictureDimensions
+	case errors.Is(err, imaging.ErrUnsupportedType):
+		return ErrPictureType
+	case errors.Is(err, imaging.ErrInvalidImage):
+		return ErrPictureInvalid
+	}
+	return err
+}
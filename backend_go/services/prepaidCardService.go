@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"project/internal/apperror"
+	"project/internal/models"
+)
+
+var (
+	ErrCardNotFound      = apperror.NotFound("card_not_found", "prepaid card not found")
+	ErrCardDisabled      = apperror.Unprocessable("card_disabled", "this prepaid card is disabled")
+	ErrCardOtherKermesse = apperror.Unprocessable("card_other_kermesse", "this prepaid card belongs to another kermesse")
+	ErrCardAlreadyLinked = apperror.Conflict("card_already_linked", "this prepaid card is already linked to another account")
+	ErrCardUsedMeanwhile = apperror.Conflict("card_used_meanwhile", "the prepaid card was used while it was being linked, try again")
+)
+
+// Alphabet des codes imprimés : sans 0/O ni 1/I pour éviter les erreurs de saisie
+const cardCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+type PrepaidCardService struct {
+	db *gorm.DB
+}
+
+func NewPrepaidCardService(db *gorm.DB) *PrepaidCardService {
+	return &PrepaidCardService{db: db}
+}
+
+// Get retourne la carte correspondant au code imprimé
+func (s *PrepaidCardService) Get(code string) (*models.PrepaidCard, error) {
+	return findPrepaidCard(s.db, code)
+}
+
+// Link rattache une carte au compte de l'utilisateur. Avec mergeBalance, le solde de la carte
+// est transféré sur le compte ; sinon la carte garde ses jetons et reste utilisable.
+func (s *PrepaidCardService) Link(ctx context.Context, user models.User, code string, mergeBalance bool) (*models.PrepaidCard, error) {
+	card, err := findPrepaidCard(s.db, code)
+	if err != nil {
+		return nil, err
+	}
+	if card.UserID != nil && *card.UserID != user.ID {
+		return nil, ErrCardAlreadyLinked
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Model(&models.PrepaidCard{}).
+			Where("id = ? AND (user_id IS NULL OR user_id = ?)", card.ID, user.ID).
+			Updates(map[string]interface{}{"user_id": user.ID, "linked_at": now})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrCardAlreadyLinked
+		}
+
+		// Les achats et recharges passés de la carte apparaissent désormais dans l'historique du compte
+		if err := tx.Model(&models.History{}).Where("prepaid_card_id = ? AND user_id IS NULL", card.ID).
+			Update("user_id", user.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Transaction{}).Where("prepaid_card_id = ? AND user_id IS NULL", card.ID).
+			Update("user_id", user.ID).Error; err != nil {
+			return err
+		}
+
+		if !mergeBalance {
+			return nil
+		}
+
+		var fresh models.PrepaidCard
+		if err := tx.First(&fresh, card.ID).Error; err != nil {
+			return err
+		}
+		if fresh.Jetons == 0 {
+			return nil
+		}
+		// Le transfert est tracé des deux côtés pour que le solde de la carte reste recalculable
+		err := applyAdjustment(ctx, tx, user, &models.BalanceAdjustment{
+			Delta:         -int(fresh.Jetons),
+			Reason:        "merged into account " + user.Email,
+			PrepaidCardID: &fresh.ID,
+		})
+		if errors.Is(err, ErrNotEnoughJetons) {
+			return ErrCardUsedMeanwhile
+		}
+		if err != nil {
+			return err
+		}
+		return applyAdjustment(ctx, tx, user, &models.BalanceAdjustment{
+			Delta:  int(fresh.Jetons),
+			Reason: "merged from prepaid card " + fresh.Code,
+			UserID: &user.ID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return findPrepaidCard(s.db, code)
+}
+
+func findPrepaidCard(db *gorm.DB, code string) (*models.PrepaidCard, error) {
+	var card models.PrepaidCard
+	if err := db.Where("code = ?", normalizeCardCode(code)).First(&card).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCardNotFound
+		}
+		return nil, err
+	}
+	if card.Disabled {
+		return nil, ErrCardDisabled
+	}
+	return &card, nil
+}
+
+func createPrepaidCard(db *gorm.DB, kermesseID uint) (*models.PrepaidCard, error) {
+	code, err := generateCardCode()
+	if err != nil {
+		return nil, err
+	}
+	card := models.PrepaidCard{Code: code, KermesseID: kermesseID}
+	if err := db.Create(&card).Error; err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// generateCardCode retourne un code de la forme XXXX-XXXX-XXXX
+func generateCardCode() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	var code strings.Builder
+	for i, b := range buf {
+		if i > 0 && i%4 == 0 {
+			code.WriteByte('-')
+		}
+		code.WriteByte(cardCodeAlphabet[int(b)%len(cardCodeAlphabet)])
+	}
+	return code.String(), nil
+}
+
+// Les codes sont saisis à la main : on accepte les minuscules et l'absence de tirets
+func normalizeCardCode(code string) string {
+	code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if len(code) != 12 {
+		return code
+	}
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12]
+}
@@ -1 +1,130 @@
 package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"project/internal/audit"
+	"project/internal/listing"
+	"project/internal/models"
+	"project/repository"
+)
+
+// ProductListing décrit la pagination, le tri et les filtres de la liste des produits
+var ProductListing = listing.Spec{
+	Sorts: map[string]string{
+		"id": "id", "name": "name", "type": "type", "jetons_requis": "jetons_requis",
+		"nb_products": "nb_products", "updated_at": "updated_at",
+	},
+	DefaultSort: "id",
+	Filters: map[string]listing.Filter{
+		"stand_id": {Where: "stand_id = ?", Numeric: true},
+		"type":     {Where: "type = ?"},
+	},
+	Search: []string{"name"},
+}
+
+type ProductService struct {
+	repos    *repository.Repositories
+	products repository.ProductRepository
+	stands   repository.StandRepository
+}
+
+func NewProductService(repos *repository.Repositories) *ProductService {
+	return &ProductService{repos: repos, products: repos.Products, stands: repos.Stands}
+}
+
+// Create ajoute un produit au stock d'un stand, réservé aux admins
+func (s *ProductService) Create(operator models.User, product models.Product) (*models.Product, error) {
+	if operator.Role != models.RoleAdmin {
+		return nil, ErrAdminOnly
+	}
+	if _, err := s.stands.FindById(uint(product.StandID)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrStandNotFound
+		}
+		return nil, err
+	}
+
+	product.ID = 0
+	if err := s.products.Create(&product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// List retourne tous les produits, réservé aux admins
+func (s *ProductService) List(operator models.User, q listing.Query) (listing.Page[models.Product], error) {
+	if operator.Role != models.RoleAdmin {
+		return listing.Page[models.Product]{}, ErrAdminOnly
+	}
+	return s.products.List(q)
+}
+
+// ListByStand retourne une page des produits du stand, visible de tout utilisateur connecté
+func (s *ProductService) ListByStand(standID uint, q listing.Query) (listing.Page[models.Product], error) {
+	if _, err := s.stands.FindById(standID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return listing.Page[models.Product]{}, ErrStandNotFound
+		}
+		return listing.Page[models.Product]{}, err
+	}
+	return s.products.List(q.With("stand_id", strconv.FormatUint(uint64(standID), 10)))
+}
+
+// Update modifie les champs renseignés d'un produit, réservé aux admins.
+// Le stock se corrige ici ; les ventes passent par les achats.
+func (s *ProductService) Update(operator models.User, id uint, changes models.Product) (*models.Product, error) {
+	if operator.Role != models.RoleAdmin {
+		return nil, ErrAdminOnly
+	}
+	if _, err := s.find(id); err != nil {
+		return nil, err
+	}
+
+	err := s.products.Update(id, models.Product{
+		Name:         changes.Name,
+		Picture:      changes.Picture,
+		Type:         changes.Type,
+		JetonsRequis: changes.JetonsRequis,
+		Nb_Products:  changes.Nb_Products,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.find(id)
+}
+
+// Delete supprime un produit, réservé aux admins
+func (s *ProductService) Delete(ctx context.Context, operator models.User, id uint) error {
+	if operator.Role != models.RoleAdmin {
+		return ErrAdminOnly
+	}
+	product, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Products.Delete(id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrProductNotFound
+			}
+			return err
+		}
+		return tx.Audit.Record(ctx, audit.Entry{
+			Operator: operator, Action: audit.ActionProductDelete, EntityType: audit.EntityProduct, EntityID: id, Before: product,
+		})
+	})
+}
+
+func (s *ProductService) find(id uint) (*models.Product, error) {
+	product, err := s.products.FindById(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	return product, nil
+}
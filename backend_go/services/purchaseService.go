@@ -0,0 +1,138 @@
+package services
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"project/internal/apperror"
+	"project/internal/metrics"
+	"project/internal/models"
+)
+
+var (
+	ErrStandNotFound     = apperror.NotFound("stand_not_found", "stand not found")
+	ErrProductNotFound   = apperror.NotFound("product_not_found", "product not found")
+	ErrInsufficientStock = apperror.OutOfStock("out_of_stock", "insufficient stock")
+	ErrNotEnoughJetons   = apperror.InsufficientFunds("not_enough_jetons", "not enough tokens")
+	ErrCardForbidden     = apperror.Forbidden("card_forbidden", "only the stand holder can charge a prepaid card")
+)
+
+type PurchaseService struct {
+	db      *gorm.DB
+	metrics *metrics.Metrics
+}
+
+func NewPurchaseService(db *gorm.DB, m *metrics.Metrics) *PurchaseService {
+	return &PurchaseService{db: db, metrics: m}
+}
+
+// BuyProduct débite les jetons de l'acheteur, décrémente le stock et historise l'achat.
+// Avec un code de carte prépayée, c'est le teneur du stand qui débite la carte du visiteur.
+func (s *PurchaseService) BuyProduct(buyer models.User, standID, productID uint, quantity uint, cardCode string) (*models.History, error) {
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	var stand models.Stand
+	if err := s.db.Preload("Kermesses").First(&stand, standID).Error; err != nil {
+		return nil, ErrStandNotFound
+	}
+	var product models.Product
+	if err := s.db.First(&product, productID).Error; err != nil || uint(product.StandID) != stand.ID {
+		return nil, ErrProductNotFound
+	}
+
+	var card *models.PrepaidCard
+	if cardCode != "" {
+		if buyer.Role != models.RoleAdmin && stand.UserID != buyer.ID {
+			return nil, ErrCardForbidden
+		}
+		found, err := findPrepaidCard(s.db, cardCode)
+		if err != nil {
+			return nil, err
+		}
+		if !standInKermesse(stand, found.KermesseID) {
+			return nil, ErrCardOtherKermesse
+		}
+		card = found
+	}
+
+	var historique *models.History
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		historique, err = chargePurchase(tx, stand, product, quantity, buyer.ID, card, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.metrics.RecordSpending(*historique)
+
+	return historique, nil
+}
+
+// chargePurchase débite la carte, ou à défaut le compte userID, et historise l'achat à la date donnée.
+// Les mises à jour conditionnelles empêchent deux achats simultanés de passer le stock ou le solde en négatif.
+func chargePurchase(tx *gorm.DB, stand models.Stand, product models.Product, quantity uint, userID uint, card *models.PrepaidCard, date time.Time) (*models.History, error) {
+	totalJetons := product.JetonsRequis * quantity
+	historique := models.History{
+		Type:      models.HistoryTypePurchase,
+		NbJetons:  totalJetons,
+		StandName: stand.Name,
+		StandID:   stand.ID,
+		ProductID: &product.ID,
+		Quantity:  quantity,
+		Date:      date,
+	}
+	if card != nil {
+		historique.PrepaidCardID = &card.ID
+		historique.UserID = card.UserID
+	} else {
+		historique.UserID = &userID
+	}
+
+	res := tx.Model(&models.Product{}).
+		Where("id = ? AND nb_products >= ?", product.ID, quantity).
+		Update("nb_products", gorm.Expr("nb_products - ?", quantity))
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrInsufficientStock
+	}
+
+	if card != nil {
+		res = tx.Model(&models.PrepaidCard{}).
+			Where("id = ? AND jetons >= ?", card.ID, totalJetons).
+			Update("jetons", gorm.Expr("jetons - ?", totalJetons))
+	} else {
+		res = tx.Model(&models.User{}).
+			Where("id = ? AND jetons >= ?", userID, totalJetons).
+			Update("jetons", gorm.Expr("jetons - ?", totalJetons))
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrNotEnoughJetons
+	}
+
+	if err := tx.Model(&models.Stand{}).Where("id = ?", stand.ID).
+		Update("conso", gorm.Expr("conso + ?", totalJetons)).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Create(&historique).Error; err != nil {
+		return nil, err
+	}
+	return &historique, nil
+}
+
+func standInKermesse(stand models.Stand, kermesseID uint) bool {
+	for _, kermesse := range stand.Kermesses {
+		if kermesse.ID == kermesseID {
+			return true
+		}
+	}
+	return false
+}
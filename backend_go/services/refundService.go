@@ -0,0 +1,460 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/internal/apperror"
+	"project/internal/audit"
+	"project/internal/models"
+	"project/internal/payment"
+)
+
+var (
+	ErrKermesseNotFound  = apperror.NotFound("kermesse_not_found", "kermesse not found")
+	ErrKermesseNotClosed = apperror.Conflict("kermesse_not_closed", "the kermesse must be closed before refunding tokens")
+	ErrRefundForbidden   = apperror.Forbidden("refund_forbidden", "you don't have permission to refund this kermesse")
+	ErrNotInKermesse     = apperror.Forbidden("not_in_kermesse", "your family does not take part in this kermesse")
+)
+
+// FamilyRefund résume le solde de jetons non dépensés d'une famille
+// (le parent qui a acheté les jetons et ses enfants) et son remboursement.
+type FamilyRefund struct {
+	ParentID           uint    `json:"parent_id"`
+	Firstname          string  `json:"firstname"`
+	Lastname           string  `json:"lastname"`
+	MemberIDs          []uint  `json:"member_ids"`
+	UnspentJetons      uint    `json:"unspent_jetons"`
+	RefundableJetons   uint    `json:"refundable_jetons"`
+	RefundableAmount   float64 `json:"refundable_amount"`
+	UnrefundableJetons uint    `json:"unrefundable_jetons"`
+	Settled            bool    `json:"settled"`
+	Donated            bool    `json:"donated"`
+	Error              string  `json:"error,omitempty"`
+}
+
+type refundAllocation struct {
+	purchase    models.Transaction
+	jetons      uint
+	amountCents int64
+}
+
+type RefundService struct {
+	db       *gorm.DB
+	payments *payment.Registry
+}
+
+func NewRefundService(db *gorm.DB, payments *payment.Registry) *RefundService {
+	return &RefundService{db: db, payments: payments}
+}
+
+// Preview calcule, sans rien modifier, ce qui serait remboursé à chaque famille
+func (s *RefundService) Preview(operator models.User, kermesseID uint) ([]FamilyRefund, error) {
+	kermesse, err := s.findKermesse(kermesseID)
+	if err != nil {
+		return nil, err
+	}
+	if !isKermesseOrganisateur(operator, *kermesse) {
+		return nil, ErrRefundForbidden
+	}
+
+	families, err := s.families(*kermesse)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]FamilyRefund, 0, len(families))
+	for _, family := range families {
+		summary := newFamilyRefund(family.parent, family.members)
+		purchases, err := s.refundablePurchases(s.db, memberIDs(family.members), kermesse.ID)
+		if err != nil {
+			return nil, err
+		}
+		allocations := allocateRefund(summary.UnspentJetons, purchases)
+		summary.addAllocations(allocations)
+		result = append(result, summary)
+	}
+	return result, nil
+}
+
+// RefundAll rembourse par carte toutes les familles de la kermesse ayant encore des jetons
+func (s *RefundService) RefundAll(ctx context.Context, operator models.User, kermesseID uint) ([]FamilyRefund, error) {
+	kermesse, err := s.findKermesse(kermesseID)
+	if err != nil {
+		return nil, err
+	}
+	if !isKermesseOrganisateur(operator, *kermesse) {
+		return nil, ErrRefundForbidden
+	}
+	if kermesse.Status != models.KermesseStatusClosed {
+		return nil, ErrKermesseNotClosed
+	}
+
+	families, err := s.families(*kermesse)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]FamilyRefund, 0, len(families))
+	for _, family := range families {
+		summary, err := s.settle(ctx, operator, family, kermesse.ID, false)
+		if err != nil {
+			summary.Error = err.Error()
+		}
+		result = append(result, summary)
+	}
+	return result, nil
+}
+
+// SettleFamily rembourse ou donne à l'école le solde de la famille de l'utilisateur connecté
+func (s *RefundService) SettleFamily(ctx context.Context, user models.User, kermesseID uint, donate bool) (*FamilyRefund, error) {
+	kermesse, err := s.findKermesse(kermesseID)
+	if err != nil {
+		return nil, err
+	}
+	if kermesse.Status != models.KermesseStatusClosed {
+		return nil, ErrKermesseNotClosed
+	}
+
+	families, err := s.families(*kermesse)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, family := range families {
+		if family.parent.ID == user.ID {
+			summary, err := s.settle(ctx, user, family, kermesse.ID, donate)
+			if err != nil {
+				return nil, err
+			}
+			return &summary, nil
+		}
+	}
+	return nil, ErrNotInKermesse
+}
+
+// settle solde la part d'une famille revenant à la kermesse : les jetons achetés par carte pour
+// cette kermesse et pas encore soldés, dans la limite du solde de la famille. Les autres jetons
+// restent sur les comptes. Un don est enregistré en une transaction ; un remboursement est d'abord
+// réservé (voir reserve), puis émis auprès du fournisseur hors de toute transaction (voir issueRefunds).
+func (s *RefundService) settle(ctx context.Context, operator models.User, family refundFamily, kermesseID uint, donate bool) (FamilyRefund, error) {
+	summary, err := s.reserve(ctx, operator, family, kermesseID, donate)
+	if err != nil || donate {
+		return summary, err
+	}
+	// Les remboursements laissés en attente par une tentative précédente sont émis avec ceux-ci
+	return summary, s.issueRefunds(ctx, operator, family.parent.ID, kermesseID)
+}
+
+// reserve verrouille les comptes de la famille, répartit sa part sur les achats de la kermesse,
+// marque ces achats comme soldés et débite les jetons. Un remboursement est enregistré en attente
+// par achat, un don est enregistré et journalisé au nom d'operator.
+func (s *RefundService) reserve(ctx context.Context, operator models.User, family refundFamily, kermesseID uint, donate bool) (FamilyRefund, error) {
+	ids := memberIDs(family.members)
+	summary := newFamilyRefund(family.parent, family.members)
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var members []models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Order("id").Find(&members).Error; err != nil {
+			return err
+		}
+		summary = newFamilyRefund(family.parent, members)
+
+		purchases, err := s.refundablePurchases(tx, ids, kermesseID)
+		if err != nil {
+			return err
+		}
+		allocations := allocateRefund(summary.UnspentJetons, purchases)
+		summary.addAllocations(allocations)
+		if len(allocations) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		for _, allocation := range allocations {
+			purchase := allocation.purchase
+			res := tx.Model(&models.Transaction{}).
+				Where("id = ? AND refunded_quantity = ?", purchase.ID, purchase.RefundedQuantity).
+				Update("refunded_quantity", purchase.RefundedQuantity+allocation.jetons)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return fmt.Errorf("transaction %d was refunded concurrently", purchase.ID)
+			}
+			if donate {
+				continue
+			}
+			refund := models.Transaction{
+				Type:            models.TransactionTypeRefund,
+				DateTransaction: now,
+				Price:           float32(allocation.amountCents) / 100,
+				Quantity:        allocation.jetons,
+				Status:          models.TransactionStatusPending,
+				Provider:        purchase.Provider,
+				PaymentIntentID: purchase.PaymentIntentID,
+				RefundOfID:      &purchase.ID,
+				KermesseID:      &kermesseID,
+				UserID:          &family.parent.ID,
+			}
+			if err := tx.Create(&refund).Error; err != nil {
+				return err
+			}
+		}
+
+		if donate {
+			donation := models.Transaction{
+				Type:            models.TransactionTypeDonation,
+				DateTransaction: now,
+				Price:           float32(summary.RefundableAmount),
+				Quantity:        summary.RefundableJetons,
+				UserID:          &family.parent.ID,
+				KermesseID:      &kermesseID,
+			}
+			if err := tx.Create(&donation).Error; err != nil {
+				return err
+			}
+			if err := audit.Record(ctx, tx, audit.Entry{
+				Operator: operator, Action: audit.ActionDonation, EntityType: audit.EntityTransaction, EntityID: donation.ID, After: donation,
+			}); err != nil {
+				return err
+			}
+			summary.Donated = true
+		}
+
+		if err := debitFamily(tx, members, summary.RefundableJetons); err != nil {
+			return err
+		}
+		summary.Settled = true
+		return nil
+	})
+	return summary, err
+}
+
+// debitFamily retire jetons des comptes de la famille, en commençant par le parent
+func debitFamily(tx *gorm.DB, members []models.User, jetons uint) error {
+	for _, member := range members {
+		if jetons == 0 {
+			break
+		}
+		debit := min(member.Jetons, jetons)
+		if debit == 0 {
+			continue
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", member.ID).
+			Update("jetons", gorm.Expr("jetons - ?", debit)).Error; err != nil {
+			return err
+		}
+		jetons -= debit
+	}
+	return nil
+}
+
+// issueRefunds émet auprès du fournisseur les remboursements en attente de la famille pour la kermesse.
+// La clé d'idempotence est dérivée du remboursement : une nouvelle tentative, après une panne ou un
+// refus temporaire, retrouve le remboursement déjà émis au lieu d'en créer un second.
+func (s *RefundService) issueRefunds(ctx context.Context, operator models.User, parentID uint, kermesseID uint) error {
+	var pending []models.Transaction
+	if err := s.db.Where("type = ? AND status = ? AND user_id = ? AND kermesse_id = ?",
+		models.TransactionTypeRefund, models.TransactionStatusPending, parentID, kermesseID).
+		Order("id").Find(&pending).Error; err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, refund := range pending {
+		if err := s.issueRefund(ctx, operator, refund); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// issueRefund émet un remboursement en attente puis l'enregistre comme réussi et le journalise.
+// En cas d'échec il reste en attente, les jetons restant débités jusqu'à la prochaine tentative.
+func (s *RefundService) issueRefund(ctx context.Context, operator models.User, refund models.Transaction) error {
+	provider, err := s.payments.Get(refund.Provider)
+	if err != nil {
+		return err
+	}
+	issued, err := provider.Refund(refund.PaymentIntentID, int64(math.Round(float64(refund.Price)*100)), fmt.Sprintf("refund-%d", refund.ID))
+	if err != nil {
+		return ErrPaymentProvider.Wrap(fmt.Errorf("refund %d of transaction %d failed: %w", refund.ID, *refund.RefundOfID, err))
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Transaction{}).
+			Where("id = ? AND status = ?", refund.ID, models.TransactionStatusPending).
+			Updates(map[string]interface{}{"status": models.TransactionStatusSucceeded, "refund_id": issued.ID})
+		if res.Error != nil || res.RowsAffected == 0 {
+			// Déjà enregistré par une tentative concurrente
+			return res.Error
+		}
+		pending := refund
+		refund.Status = models.TransactionStatusSucceeded
+		refund.RefundID = issued.ID
+		return audit.Record(ctx, tx, audit.Entry{
+			Operator: operator, Action: audit.ActionRefund, EntityType: audit.EntityTransaction, EntityID: refund.ID, Before: pending, After: refund,
+		})
+	})
+}
+
+func (s *RefundService) findKermesse(kermesseID uint) (*models.Kermesse, error) {
+	var kermesse models.Kermesse
+	if err := s.db.Preload("Organisateurs").First(&kermesse, kermesseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrKermesseNotFound
+		}
+		return nil, err
+	}
+	return &kermesse, nil
+}
+
+// refundFamily est un parent responsable d'un solde et les comptes dont il le solde : lui-même
+// et ses enfants
+type refundFamily struct {
+	parent  models.User
+	members []models.User
+}
+
+// families retourne les familles participantes : les parents des participants, ou le participant
+// lui-même s'il n'a pas de parent. Un enfant de deux parents n'est compté que dans la famille du
+// premier, pour que son solde ne soit pas soldé deux fois.
+func (s *RefundService) families(kermesse models.Kermesse) ([]refundFamily, error) {
+	var participants []models.User
+	if err := s.db.Preload("Parents").
+		Where("id IN (SELECT user_id FROM kermesse_participants WHERE kermesse_id = ?)", kermesse.ID).
+		Find(&participants).Error; err != nil {
+		return nil, err
+	}
+
+	seen := map[uint]bool{}
+	var parentIDs []uint
+	for _, participant := range participants {
+		heads := participant.Parents
+		if len(heads) == 0 {
+			heads = []models.User{participant}
+		}
+		for _, head := range heads {
+			if !seen[head.ID] {
+				seen[head.ID] = true
+				parentIDs = append(parentIDs, head.ID)
+			}
+		}
+	}
+	if len(parentIDs) == 0 {
+		return nil, nil
+	}
+
+	var parents []models.User
+	if err := s.db.Preload("Enfants", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("id IN ?", parentIDs).Order("id").Find(&parents).Error; err != nil {
+		return nil, err
+	}
+
+	counted := map[uint]bool{}
+	families := make([]refundFamily, 0, len(parents))
+	for _, parent := range parents {
+		family := refundFamily{parent: parent}
+		for _, member := range append([]models.User{parent}, parent.Enfants...) {
+			if !counted[member.ID] {
+				counted[member.ID] = true
+				family.members = append(family.members, member)
+			}
+		}
+		families = append(families, family)
+	}
+	return families, nil
+}
+
+// Les achats carte réussis pour la kermesse et pas encore soldés, du plus récent au plus ancien
+func (s *RefundService) refundablePurchases(db *gorm.DB, userIDs []uint, kermesseID uint) ([]models.Transaction, error) {
+	var purchases []models.Transaction
+	err := db.Where("user_id IN ? AND kermesse_id = ? AND type = ? AND status = ? AND payment_intent_id <> '' AND refunded_quantity < quantity",
+		userIDs, kermesseID, models.TransactionTypeJetons, models.TransactionStatusSucceeded).
+		Where("provider IS NULL OR provider <> ?", payment.ProviderCash).
+		Order("date_transaction desc, id desc").
+		Find(&purchases).Error
+	return purchases, err
+}
+
+// allocateRefund répartit les jetons non dépensés sur les achats, en remboursant
+// d'abord les plus récents, au prix unitaire payé lors de chaque achat.
+func allocateRefund(unspent uint, purchases []models.Transaction) []refundAllocation {
+	var allocations []refundAllocation
+	for _, purchase := range purchases {
+		if unspent == 0 {
+			break
+		}
+		jetons := purchase.Quantity - purchase.RefundedQuantity
+		if jetons > unspent {
+			jetons = unspent
+		}
+		allocations = append(allocations, refundAllocation{
+			purchase:    purchase,
+			jetons:      jetons,
+			amountCents: refundCents(purchase, jetons),
+		})
+		unspent -= jetons
+	}
+	return allocations
+}
+
+// refundCents calcule le montant à rembourser pour des jetons d'un achat. Le calcul se fait
+// sur le cumul remboursé pour que la somme des remboursements partiels ne dépasse jamais le prix payé.
+func refundCents(purchase models.Transaction, jetons uint) int64 {
+	total := int64(math.Round(float64(purchase.Price) * 100))
+	quantity := int64(purchase.Quantity)
+	before := total * int64(purchase.RefundedQuantity) / quantity
+	after := total * int64(purchase.RefundedQuantity+jetons) / quantity
+	return after - before
+}
+
+func memberIDs(members []models.User) []uint {
+	ids := make([]uint, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.ID)
+	}
+	return ids
+}
+
+func newFamilyRefund(parent models.User, members []models.User) FamilyRefund {
+	summary := FamilyRefund{
+		ParentID:  parent.ID,
+		Firstname: parent.Firstname,
+		Lastname:  parent.Lastname,
+		MemberIDs: memberIDs(members),
+	}
+	for _, member := range members {
+		summary.UnspentJetons += member.Jetons
+	}
+	return summary
+}
+
+func (f *FamilyRefund) addAllocations(allocations []refundAllocation) {
+	var cents int64
+	for _, allocation := range allocations {
+		f.RefundableJetons += allocation.jetons
+		cents += allocation.amountCents
+	}
+	f.RefundableAmount = float64(cents) / 100
+	f.UnrefundableJetons = f.UnspentJetons - f.RefundableJetons
+}
+
+// Un organisateur est l'admin, le créateur ou un organisateur de la kermesse
+func isKermesseOrganisateur(user models.User, kermesse models.Kermesse) bool {
+	if user.Role == models.RoleAdmin || kermesse.UserID == user.ID {
+		return true
+	}
+	for _, organisateur := range kermesse.Organisateurs {
+		if organisateur.ID == user.ID {
+			return true
+		}
+	}
+	return false
+}
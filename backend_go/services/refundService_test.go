@@ -0,0 +1,207 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"project/internal/apperror"
+	"project/internal/models"
+	"project/internal/testserver"
+	"project/services"
+)
+
+// buyFor achète des jetons par carte pour une kermesse et confirme le paiement
+func buyFor(t *testing.T, s *testserver.Server, user *testserver.User, kermesseID uint, quantity uint, price float32) {
+	t.Helper()
+	var created struct {
+		Transaction models.Transaction `json:"transaction"`
+	}
+	s.Request(http.MethodPost, "/api/v1/payments", user, gin.H{"type": "jetons", "quantity": quantity, "price": price, "kermesse_id": kermesseID}).
+		Expect(http.StatusCreated).
+		Data(&created)
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/payments/%d/confirm", created.Transaction.ID), user, nil).
+		Expect(http.StatusOK)
+}
+
+// kermesseWith crée une kermesse dont les comptes donnés sont participants
+func kermesseWith(t *testing.T, s *testserver.Server, organisateur *testserver.User, participants ...*testserver.User) models.Kermesse {
+	t.Helper()
+	var kermesse models.Kermesse
+	s.Request(http.MethodPost, "/api/v1/kermesses", organisateur, gin.H{"name": "Kermesse"}).
+		Expect(http.StatusCreated).
+		Data(&kermesse)
+	ids := make([]uint, 0, len(participants))
+	for _, participant := range participants {
+		ids = append(ids, participant.ID)
+	}
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/kermesses/%d/members", kermesse.ID), organisateur,
+		gin.H{"type": "participants", "user_ids": ids}).Expect(http.StatusOK)
+	return kermesse
+}
+
+func closeKermesse(t *testing.T, s *testserver.Server, organisateur *testserver.User, kermesse models.Kermesse) {
+	t.Helper()
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/kermesses/%d/close", kermesse.ID), organisateur, nil).Expect(http.StatusOK)
+}
+
+func refundsOf(t *testing.T, s *testserver.Server, kermesseID uint) []models.Transaction {
+	t.Helper()
+	var refunds []models.Transaction
+	if err := s.DB.Where("type = ? AND kermesse_id = ?", models.TransactionTypeRefund, kermesseID).Order("id").Find(&refunds).Error; err != nil {
+		t.Fatal(err)
+	}
+	return refunds
+}
+
+// Un enfant de deux parents n'est compté que dans une famille
+func TestRefundPreviewCountsSharedChildOnce(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	organisateur := s.Organisateur()
+	mother, father, child := s.Parent(), s.Parent(), s.Eleve()
+	kermesse := kermesseWith(t, s, organisateur, child)
+	for _, parent := range []*testserver.User{mother, father} {
+		s.Request(http.MethodPost, "/api/v1/me/children", parent, gin.H{"children_ids": []uint{child.ID}}).Expect(http.StatusOK)
+	}
+	buyFor(t, s, mother, kermesse.ID, 10, 5)
+	s.Request(http.MethodPost, fmt.Sprintf("/api/v1/users/%d/coins", child.ID), mother, gin.H{"nb_jetons": 4}).Expect(http.StatusOK)
+
+	families, err := s.App.Services.Refunds.Preview(organisateur.User, kermesse.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 2 {
+		t.Fatalf("deux familles attendues : %+v", families)
+	}
+	var unspent uint
+	counted := 0
+	for _, family := range families {
+		unspent += family.UnspentJetons
+		for _, id := range family.MemberIDs {
+			if id == child.ID {
+				counted++
+			}
+		}
+	}
+	if counted != 1 || unspent != 10 {
+		t.Fatalf("l'enfant doit être compté une fois et 10 jetons au total : %+v", families)
+	}
+
+	closeKermesse(t, s, organisateur, kermesse)
+	if _, err := s.App.Services.Refunds.RefundAll(context.Background(), organisateur.User, kermesse.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Payments.Refunds()) != 1 || s.Balance(mother)+s.Balance(child) != 0 {
+		t.Fatalf("un seul remboursement des 10 jetons attendu : %+v", s.Payments.Refunds())
+	}
+}
+
+// Le remboursement d'une kermesse ne porte que sur les jetons achetés pour elle
+func TestRefundOnlySettlesTheKermesseAllocation(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	organisateur := s.Organisateur()
+	parent := s.Parent()
+	closed := kermesseWith(t, s, organisateur, parent)
+	other := kermesseWith(t, s, organisateur, parent)
+	buyFor(t, s, parent, closed.ID, 10, 5)
+	buyFor(t, s, parent, other.ID, 4, 4)
+	closeKermesse(t, s, organisateur, closed)
+
+	families, err := s.App.Services.Refunds.RefundAll(context.Background(), organisateur.User, closed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 1 || families[0].RefundableJetons != 10 || families[0].RefundableAmount != 5 || !families[0].Settled {
+		t.Fatalf("10 jetons remboursés pour 5 € attendus : %+v", families)
+	}
+	if s.Balance(parent) != 4 {
+		t.Fatalf("les jetons de l'autre kermesse doivent rester, %d en base", s.Balance(parent))
+	}
+	refunds := s.Payments.Refunds()
+	if len(refunds) != 1 || refunds[0].AmountCents != 500 {
+		t.Fatalf("un remboursement de 500 centimes attendu : %+v", refunds)
+	}
+	if stored := refundsOf(t, s, closed.ID); len(stored) != 1 || stored[0].Status != models.TransactionStatusSucceeded || stored[0].RefundID != refunds[0].ID {
+		t.Fatalf("remboursement enregistré inattendu : %+v", stored)
+	}
+
+	// Une seconde passe ne rembourse rien de plus
+	if _, err := s.App.Services.Refunds.RefundAll(context.Background(), organisateur.User, closed.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Payments.Refunds()) != 1 || s.Balance(parent) != 4 {
+		t.Fatalf("rien ne doit être remboursé deux fois : %+v, solde %d", s.Payments.Refunds(), s.Balance(parent))
+	}
+}
+
+// Un remboursement refusé par le fournisseur reste en attente, jetons débités, et aboutit à la
+// tentative suivante sans être émis deux fois
+func TestRefundIsRetriedAfterProviderFailure(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	organisateur := s.Organisateur()
+	parent := s.Parent()
+	kermesse := kermesseWith(t, s, organisateur, parent)
+	buyFor(t, s, parent, kermesse.ID, 6, 3)
+	closeKermesse(t, s, organisateur, kermesse)
+
+	s.Payments.SetUnavailable(true)
+	_, err := s.App.Services.Refunds.SettleFamily(context.Background(), parent.User, kermesse.ID, false)
+	if !errors.Is(err, services.ErrPaymentProvider) || apperror.From(err).Status() != http.StatusServiceUnavailable {
+		t.Fatalf("ErrPaymentProvider attendue, reçu %v", err)
+	}
+	pending := refundsOf(t, s, kermesse.ID)
+	if len(pending) != 1 || pending[0].Status != models.TransactionStatusPending || s.Balance(parent) != 0 {
+		t.Fatalf("un remboursement en attente et un solde débité attendus : %+v, solde %d", pending, s.Balance(parent))
+	}
+
+	s.Payments.SetUnavailable(false)
+	summary, err := s.App.Services.Refunds.SettleFamily(context.Background(), parent.User, kermesse.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.RefundableJetons != 0 {
+		t.Fatalf("plus rien à réserver, le remboursement en attente est repris : %+v", summary)
+	}
+	stored := refundsOf(t, s, kermesse.ID)
+	if len(stored) != 1 || stored[0].Status != models.TransactionStatusSucceeded || len(s.Payments.Refunds()) != 1 {
+		t.Fatalf("le remboursement en attente doit aboutir une seule fois : %+v", stored)
+	}
+	var audited int64
+	s.DB.Model(&models.AuditEntry{}).Where("action = ? AND entity_id = ?", "refund.create", stored[0].ID).Count(&audited)
+	if audited != 1 {
+		t.Fatalf("le remboursement doit être journalisé une fois, %d entrées", audited)
+	}
+}
+
+// Un don solde la part de la kermesse sans remboursement
+func TestDonationSettlesTheKermesseAllocation(t *testing.T) {
+	t.Parallel()
+	s := testserver.New(t)
+	organisateur := s.Organisateur()
+	parent := s.Parent()
+	kermesse := kermesseWith(t, s, organisateur, parent)
+	buyFor(t, s, parent, kermesse.ID, 8, 4)
+	closeKermesse(t, s, organisateur, kermesse)
+
+	summary, err := s.App.Services.Refunds.SettleFamily(context.Background(), parent.User, kermesse.ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !summary.Donated || summary.RefundableJetons != 8 || s.Balance(parent) != 0 || len(s.Payments.Refunds()) != 0 {
+		t.Fatalf("don des 8 jetons attendu : %+v, solde %d", summary, s.Balance(parent))
+	}
+
+	summary, err = s.App.Services.Refunds.SettleFamily(context.Background(), parent.User, kermesse.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Settled || len(s.Payments.Refunds()) != 0 {
+		t.Fatalf("des jetons donnés ne peuvent plus être remboursés : %+v", summary)
+	}
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"project/internal/apperror"
+	"project/internal/audit"
+	"project/internal/models"
+)
+
+// Délai pendant lequel un teneur de stand peut annuler un achat sur son stand.
+// Les organisateurs et les admins peuvent annuler sans limite de temps.
+const ReversalWindow = 15 * time.Minute
+
+var (
+	ErrHistoryNotFound       = apperror.NotFound("history_not_found", "history entry not found")
+	ErrNotReversible         = apperror.Unprocessable("not_reversible", "this history entry cannot be reversed")
+	ErrAlreadyReversed       = apperror.Conflict("already_reversed", "this history entry has already been reversed")
+	ErrReversalForbidden     = apperror.Forbidden("reversal_forbidden", "you don't have permission to reverse this entry")
+	ErrReversalWindowExpired = apperror.Forbidden("reversal_window_expired", "the reversal window for stand holders has expired")
+)
+
+type ReversalService struct {
+	db *gorm.DB
+}
+
+func NewReversalService(db *gorm.DB) *ReversalService {
+	return &ReversalService{db: db}
+}
+
+// Reverse annule un achat ou une interaction : les jetons sont rendus à l'utilisateur,
+// le stock et la conso du stand sont restaurés et une entrée "reversal" liée est créée.
+func (s *ReversalService) Reverse(ctx context.Context, operator models.User, historyID uint, reason string) (*models.History, error) {
+	var original models.History
+	if err := s.db.First(&original, historyID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrHistoryNotFound
+		}
+		return nil, err
+	}
+
+	if original.Type == models.HistoryTypeReversal || original.StandID == 0 {
+		return nil, ErrNotReversible
+	}
+	if original.ReversedAt != nil {
+		return nil, ErrAlreadyReversed
+	}
+
+	var stand models.Stand
+	if err := s.db.Preload("Kermesses.Organisateurs").First(&stand, original.StandID).Error; err != nil {
+		return nil, ErrNotReversible
+	}
+
+	if err := s.canReverse(operator, stand, original); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	reversal := models.History{
+		Date:          now,
+		Type:          models.HistoryTypeReversal,
+		NbJetons:      original.NbJetons,
+		StandName:     original.StandName,
+		StandID:       original.StandID,
+		ProductID:     original.ProductID,
+		Quantity:      original.Quantity,
+		UserID:        original.UserID,
+		PrepaidCardID: original.PrepaidCardID,
+		ReversalOfID:  &original.ID,
+		OperatorID:    &operator.ID,
+		Reason:        reason,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Le WHERE sur reversed_at empêche deux annulations concurrentes de la même entrée
+		res := tx.Model(&models.History{}).
+			Where("id = ? AND reversed_at IS NULL", original.ID).
+			Update("reversed_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrAlreadyReversed
+		}
+
+		// Les jetons retournent sur la carte prépayée qui a payé, sinon sur le compte
+		refund := tx.Model(&models.User{}).Where("id = ?", original.UserID)
+		if original.PrepaidCardID != nil {
+			refund = tx.Model(&models.PrepaidCard{}).Where("id = ?", *original.PrepaidCardID)
+		}
+		if err := refund.Update("jetons", gorm.Expr("jetons + ?", original.NbJetons)).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Stand{}).Where("id = ?", original.StandID).
+			Update("conso", gorm.Expr("CASE WHEN conso >= ? THEN conso - ? ELSE 0 END", original.NbJetons, original.NbJetons)).Error; err != nil {
+			return err
+		}
+
+		if original.ProductID != nil && original.Quantity > 0 {
+			if err := tx.Model(&models.Product{}).Where("id = ?", *original.ProductID).
+				Update("nb_products", gorm.Expr("nb_products + ?", original.Quantity)).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(&reversal).Error; err != nil {
+			return err
+		}
+		// L'entrée d'annulation porte le motif et l'entrée annulée
+		return audit.Record(ctx, tx, audit.Entry{
+			Operator: operator, Action: audit.ActionHistoryReverse, EntityType: audit.EntityHistory, EntityID: reversal.ID, After: reversal,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &reversal, nil
+}
+
+// GetStandHistory retourne l'historique d'un stand, réservé au teneur du stand,
+// aux organisateurs des kermesses du stand et aux admins.
+func (s *ReversalService) GetStandHistory(operator models.User, standID uint) ([]models.History, error) {
+	var stand models.Stand
+	if err := s.db.Preload("Kermesses.Organisateurs").First(&stand, standID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStandNotFound
+		}
+		return nil, err
+	}
+
+	if operator.Role != models.RoleAdmin && stand.UserID != operator.ID && !isStandOrganisateur(operator, stand) {
+		return nil, ErrReversalForbidden
+	}
+
+	var history []models.History
+	if err := s.db.Where("stand_id = ?", standID).Order("date desc").Find(&history).Error; err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (s *ReversalService) canReverse(operator models.User, stand models.Stand, original models.History) error {
+	if operator.Role == models.RoleAdmin || isStandOrganisateur(operator, stand) {
+		return nil
+	}
+
+	if stand.UserID == operator.ID {
+		if time.Since(original.Date) > ReversalWindow {
+			return ErrReversalWindowExpired
+		}
+		return nil
+	}
+
+	return ErrReversalForbidden
+}
+
+// Un organisateur est le créateur ou un organisateur d'une des kermesses du stand
+func isStandOrganisateur(user models.User, stand models.Stand) bool {
+	for _, kermesse := range stand.Kermesses {
+		if isKermesseOrganisateur(user, kermesse) {
+			return true
+		}
+	}
+	return false
+}
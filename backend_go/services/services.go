@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"io"
+
+	"gorm.io/gorm"
+	"project/internal/apperror"
+	"project/internal/listing"
+	"project/internal/metrics"
+	"project/internal/models"
+	"project/internal/payment"
+	"project/internal/storage"
+	"project/repository/postgres"
+)
+
+// ErrAdminOnly est retourné par les actions réservées aux administrateurs
+var ErrAdminOnly = apperror.Forbidden("admin_only", "you are not authorized to perform this action")
+
+// Les contrôleurs ne dépendent que de ces interfaces : un test peut leur substituer des doublures.
+// Les services d'un seul agrégat (comptes, produits, packs) travaillent sur les dépôts et se testent
+// avec repository/memory ; les autres écrivent plusieurs agrégats par transaction, directement avec GORM.
+
+type Auth interface {
+	Signup(input SignupInput) (*models.User, error)
+	Login(email, password string) (string, error)
+	Profile(user models.User) (*models.User, error)
+	UpdateProfile(user models.User, input SignupInput) (*models.User, error)
+}
+
+// Les actions privilégiées et financières reçoivent le contexte de la requête : le journal
+// d'audit y lit l'IP du client et l'identifiant de la requête.
+
+type Users interface {
+	Create(ctx context.Context, operator models.User, input SignupInput) (*models.User, error)
+	List(operator models.User, q listing.Query) (listing.Page[models.User], error)
+	Get(operator models.User, id uint) (*models.User, error)
+	Update(ctx context.Context, operator models.User, id uint, input SignupInput) (*models.User, error)
+	Delete(ctx context.Context, operator models.User, id uint) error
+	Students(q listing.Query) (listing.Page[models.User], error)
+	CreateAdmin(ctx context.Context, operator models.User, firstname, lastname, email, password string) (*models.User, error)
+	ResetPassword(ctx context.Context, operator models.User, email, password string) (*models.User, error)
+}
+
+type Kermesses interface {
+	Create(operator models.User, name, picture string) (*models.Kermesse, error)
+	List(operator models.User, q listing.Query) (listing.Page[models.Kermesse], error)
+	Get(operator models.User, id uint) (*models.Kermesse, error)
+	Stands(operator models.User, id uint, q listing.Query) (listing.Page[models.Stand], error)
+	Update(operator models.User, id uint, name, picture string) (*models.Kermesse, error)
+	Delete(ctx context.Context, operator models.User, id uint) error
+	AddStands(ctx context.Context, operator models.User, id uint, standIDs []uint) ([]models.Stand, error)
+	AddMembers(operator models.User, id uint, memberType string, userIDs []uint) ([]models.User, error)
+	Close(operator models.User, id uint) (*models.Kermesse, error)
+	Export(operator models.User, id uint) (*KermesseExport, error)
+}
+
+type Stands interface {
+	Create(operator models.User, input StandInput) (*models.Stand, error)
+	List(operator models.User, q listing.Query) (listing.Page[models.Stand], error)
+	Get(id uint) (*models.Stand, error)
+	Update(operator models.User, id uint, input StandInput) (*models.Stand, error)
+	Delete(ctx context.Context, operator models.User, id uint) error
+	Interact(user models.User, id uint) (*Interaction, error)
+	GivePoints(operator models.User, standID, userID uint, points uint) (*models.User, error)
+}
+
+type Products interface {
+	Create(operator models.User, product models.Product) (*models.Product, error)
+	List(operator models.User, q listing.Query) (listing.Page[models.Product], error)
+	ListByStand(standID uint, q listing.Query) (listing.Page[models.Product], error)
+	Update(operator models.User, id uint, changes models.Product) (*models.Product, error)
+	Delete(ctx context.Context, operator models.User, id uint) error
+}
+
+type JetonsPacks interface {
+	Create(operator models.User, pack models.Jetons) (*models.Jetons, error)
+	List() ([]models.Jetons, error)
+	Update(operator models.User, id uint, changes models.Jetons) (*models.Jetons, error)
+	Delete(ctx context.Context, operator models.User, id uint) error
+}
+
+type Parents interface {
+	AddChildren(parent models.User, childIDs []uint) ([]models.User, error)
+	GiveCoins(parent models.User, childID uint, nbJetons uint) (*CoinTransfer, error)
+}
+
+type Purchases interface {
+	BuyProduct(buyer models.User, standID, productID uint, quantity uint, cardCode string) (*models.History, error)
+}
+
+type Payments interface {
+	CreatePayment(user models.User, paymentType string, quantity uint, price float32, kermesseID uint) (*models.Transaction, *payment.Intent, error)
+	ConfirmPayment(ctx context.Context, user models.User, transactionID uint) (*models.Transaction, error)
+	HandleWebhook(ctx context.Context, payload []byte, signature string) error
+	Transactions(user models.User, q listing.Query) (listing.Page[models.Transaction], error)
+}
+
+type Refunds interface {
+	Preview(operator models.User, kermesseID uint) ([]FamilyRefund, error)
+	RefundAll(ctx context.Context, operator models.User, kermesseID uint) ([]FamilyRefund, error)
+	SettleFamily(ctx context.Context, user models.User, kermesseID uint, donate bool) (*FamilyRefund, error)
+}
+
+type Reversals interface {
+	Reverse(ctx context.Context, operator models.User, historyID uint, reason string) (*models.History, error)
+	GetStandHistory(operator models.User, standID uint) ([]models.History, error)
+}
+
+type Tills interface {
+	Open(cashier models.User, kermesseID uint, openingFloat float64) (*models.TillSession, error)
+	Current(cashier models.User) (*TillReport, error)
+	SellJetons(ctx context.Context, cashier models.User, sale TillSale) (*models.Transaction, error)
+	IssueCard(ctx context.Context, cashier models.User, sale TillSale) (*models.PrepaidCard, *models.Transaction, error)
+	Close(cashier models.User, closingCount float64) (*TillReport, error)
+	Report(operator models.User, sessionID uint) (*TillReport, error)
+	ListForKermesse(operator models.User, kermesseID uint) ([]TillReport, error)
+}
+
+type PrepaidCards interface {
+	Get(code string) (*models.PrepaidCard, error)
+	Link(ctx context.Context, user models.User, code string, mergeBalance bool) (*models.PrepaidCard, error)
+}
+
+type Balances interface {
+	Adjust(ctx context.Context, target BalanceTarget, delta int, reason string, operator models.User) (*models.BalanceAdjustment, error)
+	Recompute(apply bool) ([]BalanceDrift, error)
+}
+
+type Finances interface {
+	KermesseFinances(operator models.User, kermesseID uint) (*KermesseFinances, error)
+}
+
+type Sync interface {
+	RegisterDevice(operator models.User, standID uint, name string) (*models.StandDevice, string, error)
+	Upload(operator models.User, deviceID uint, operations []OfflineOperation) ([]models.SyncOperation, error)
+	Changes(operator models.User, deviceID uint, cursor string) (*SyncChanges, error)
+	Conflicts(operator models.User, standID uint) ([]models.SyncOperation, error)
+}
+
+type Pictures interface {
+	MaxBytes() int64
+	SetUserPicture(operator models.User, file io.Reader) (*PictureLinks, error)
+	SetKermessePicture(operator models.User, kermesseID uint, file io.Reader) (*PictureLinks, error)
+	SetProductPicture(operator models.User, productID uint, file io.Reader) (*PictureLinks, error)
+	URLs(picture string) (url, thumbnail string)
+	Open(key, expires, signature string) (*storage.Object, error)
+}
+
+type Audit interface {
+	List(operator models.User, q listing.Query) (listing.Page[models.AuditEntry], error)
+	Export(operator models.User, q listing.Query, fn func([]models.AuditEntry) error) error
+}
+
+type Health interface {
+	Ready(ctx context.Context) error
+}
+
+// Services regroupe les services injectés dans les contrôleurs
+type Services struct {
+	Auth         Auth
+	Users        Users
+	Kermesses    Kermesses
+	Stands       Stands
+	Products     Products
+	JetonsPacks  JetonsPacks
+	Parents      Parents
+	Purchases    Purchases
+	Payments     Payments
+	Refunds      Refunds
+	Reversals    Reversals
+	Tills        Tills
+	PrepaidCards PrepaidCards
+	Balances     Balances
+	Finances     Finances
+	Sync         Sync
+	Pictures     Pictures
+	Audit        Audit
+	Health       Health
+}
+
+// New construit tous les services sur la même base de données ; l'activité est comptée dans m
+// et les photos sont rangées dans store
+func New(db *gorm.DB, payments *payment.Registry, jwtSecret string, m *metrics.Metrics, store storage.Store, pictures PictureSettings) *Services {
+	repos := postgres.New(db)
+	return &Services{
+		Auth:         NewAuthService(repos.Users, jwtSecret),
+		Users:        NewUserService(repos),
+		Kermesses:    NewKermesseService(db),
+		Stands:       NewStandService(db, m),
+		Products:     NewProductService(repos),
+		JetonsPacks:  NewJetonsService(repos),
+		Parents:      NewParentService(db),
+		Purchases:    NewPurchaseService(db, m),
+		Payments:     NewPaymentService(db, payments, m),
+		Refunds:      NewRefundService(db, payments),
+		Reversals:    NewReversalService(db),
+		Tills:        NewTillService(db, payments, m),
+		PrepaidCards: NewPrepaidCardService(db),
+		Balances:     NewBalanceService(db),
+		Finances:     NewFinanceService(db),
+		Sync:         NewSyncService(db, m),
+		Pictures:     NewPictureService(db, store, pictures),
+		Audit:        NewAuditService(db),
+		Health:       NewHealthService(db),
+	}
+}
@@ -1,34 +1,225 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"gorm.io/gorm"
+	"project/internal/apperror"
+	"project/internal/audit"
+	"project/internal/listing"
+	"project/internal/metrics"
 	"project/internal/models"
 )
 
+var (
+	ErrStandForbidden = apperror.Forbidden("stand_forbidden", "you don't have permission to do that")
+	ErrNotStandOwner  = apperror.Forbidden("not_stand_owner", "you are not the owner of this stand")
+)
+
+// StandListing décrit la pagination, le tri et les filtres de la liste des stands
+var StandListing = listing.Spec{
+	Sorts: map[string]string{
+		"id": "id", "name": "name", "type": "type", "jetons_requis": "jetons_requis", "conso": "conso",
+	},
+	DefaultSort: "id",
+	Filters: map[string]listing.Filter{
+		"type":        {Where: "type = ?"},
+		"kermesse_id": {Where: "id IN (SELECT stand_id FROM kermesse_stands WHERE kermesse_id = ?)", Numeric: true},
+	},
+	Search: []string{"name"},
+}
+
+// StandInput porte les champs modifiables d'un stand
+type StandInput struct {
+	Name         string
+	Type         string
+	JetonsRequis uint
+}
+
+// Interaction est le résultat d'une participation à un stand : l'entrée d'historique,
+// la conso du stand et le solde de l'utilisateur après débit
+type Interaction struct {
+	History    models.History
+	StandConso uint
+	Jetons     uint
+}
+
 type StandService struct {
-	db *gorm.DB
+	db      *gorm.DB
+	metrics *metrics.Metrics
+}
+
+func NewStandService(db *gorm.DB, m *metrics.Metrics) *StandService {
+	return &StandService{db: db, metrics: m}
 }
 
-func NewStandService(db *gorm.DB) *StandService {
-	return &StandService{db: db}
+// Create crée un stand tenu par l'opérateur, réservé aux admins et aux teneurs de stand
+func (s *StandService) Create(operator models.User, input StandInput) (*models.Stand, error) {
+	if operator.Role != models.RoleAdmin && operator.Role != models.RoleTeneur {
+		return nil, ErrStandForbidden
+	}
+	stand := models.Stand{
+		Name:         input.Name,
+		Type:         input.Type,
+		JetonsRequis: input.JetonsRequis,
+		UserID:       operator.ID,
+	}
+	if err := s.db.Create(&stand).Error; err != nil {
+		return nil, err
+	}
+	return &stand, nil
 }
 
-func (s *StandService) CreateStand(stand *models.Stand) error {
-	return s.db.Create(stand).Error
+// List retourne tous les stands, réservé aux admins
+func (s *StandService) List(operator models.User, q listing.Query) (listing.Page[models.Stand], error) {
+	if operator.Role != models.RoleAdmin {
+		return listing.Page[models.Stand]{}, ErrStandForbidden
+	}
+	return listing.Find[models.Stand](s.db, q)
 }
 
-func (s *StandService) GetStandByID(id uint) (*models.Stand, error) {
+// Get retourne un stand avec ses produits
+func (s *StandService) Get(id uint) (*models.Stand, error) {
 	var stand models.Stand
-	if err := s.db.Where("id = ?", id).First(&stand).Error; err != nil {
+	if err := s.db.Preload("Stock").First(&stand, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStandNotFound
+		}
 		return nil, err
 	}
 	return &stand, nil
 }
 
-func (s *StandService) UpdateStand(stand *models.Stand) error {
-	return s.db.Save(stand).Error
+// Update modifie les champs renseignés du stand, réservé à son teneur et aux admins
+func (s *StandService) Update(operator models.User, id uint, input StandInput) (*models.Stand, error) {
+	stand, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if operator.Role != models.RoleAdmin && stand.UserID != operator.ID {
+		return nil, ErrStandForbidden
+	}
+
+	updates := map[string]interface{}{}
+	if input.Name != "" {
+		updates["name"] = input.Name
+	}
+	if input.Type != "" {
+		updates["type"] = input.Type
+	}
+	if input.JetonsRequis != 0 {
+		updates["jetons_requis"] = input.JetonsRequis
+	}
+	if len(updates) > 0 {
+		if err := s.db.Model(stand).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+	return stand, nil
+}
+
+// Delete supprime un stand, réservé aux admins
+func (s *StandService) Delete(ctx context.Context, operator models.User, id uint) error {
+	if operator.Role != models.RoleAdmin {
+		return ErrStandForbidden
+	}
+	stand, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(stand).Error; err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Entry{
+			Operator: operator, Action: audit.ActionStandDelete, EntityType: audit.EntityStand, EntityID: stand.ID, Before: stand,
+		})
+	})
+}
+
+// Interact débite le prix d'entrée du stand sur le compte de l'utilisateur et historise sa participation
+func (s *StandService) Interact(user models.User, id uint) (*Interaction, error) {
+	stand, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := Interaction{History: models.History{
+		Date:      time.Now(),
+		Type:      models.HistoryTypeInteraction,
+		NbJetons:  stand.JetonsRequis,
+		StandName: stand.Name,
+		StandID:   stand.ID,
+		UserID:    &user.ID,
+	}}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.User{}).
+			Where("id = ? AND jetons >= ?", user.ID, stand.JetonsRequis).
+			Update("jetons", gorm.Expr("jetons - ?", stand.JetonsRequis))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrNotEnoughJetons
+		}
+		// La conso compte les jetons dépensés sur le stand, comme pour un achat : l'annulation la
+		// restaure et la réconciliation des soldes la recalcule depuis l'historique
+		if err := tx.Model(&models.Stand{}).Where("id = ?", stand.ID).
+			Update("conso", gorm.Expr("conso + ?", stand.JetonsRequis)).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&result.History).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Stand{}).Where("id = ?", stand.ID).Select("conso").Scan(&result.StandConso).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", user.ID).Select("jetons").Scan(&result.Jetons).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.metrics.RecordSpending(result.History)
+	return &result, nil
+}
+
+// GivePoints attribue des points à un utilisateur, réservé au teneur du stand
+func (s *StandService) GivePoints(operator models.User, standID, userID uint, points uint) (*models.User, error) {
+	stand, err := s.find(standID)
+	if err != nil {
+		return nil, err
+	}
+	if stand.UserID != operator.ID {
+		return nil, ErrNotStandOwner
+	}
+
+	res := s.db.Model(&models.User{}).Where("id = ?", userID).
+		Update("pts_attribues", gorm.Expr("pts_attribues + ?", points))
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrUserNotFound
+	}
+	s.metrics.RecordPoints(stand.ID, points)
+
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
 }
 
-func (s *StandService) DeleteStand(id uint) error {
-	return s.db.Delete(&models.Stand{}, id).Error
+func (s *StandService) find(id uint) (*models.Stand, error) {
+	var stand models.Stand
+	if err := s.db.First(&stand, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStandNotFound
+		}
+		return nil, err
+	}
+	return &stand, nil
 }
@@ -0,0 +1,420 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"project/internal/apperror"
+	"project/internal/metrics"
+	"project/internal/models"
+)
+
+var (
+	ErrDeviceNotFound = apperror.NotFound("device_not_found", "stand device not found")
+	ErrSyncForbidden  = apperror.Forbidden("sync_forbidden", "only the stand holder can sync this stand")
+	ErrInvalidCursor  = apperror.Validation("invalid_cursor", "invalid sync cursor")
+
+	errDuplicateOperation = errors.New("operation already synced")
+)
+
+// Avance tolérée de l'horloge d'un appareil sur celle du serveur
+const SyncClockSkew = 5 * time.Minute
+
+// Recouvrement des curseurs : une modification validée pendant une synchronisation est renvoyée à la suivante
+const syncCursorOverlap = 5 * time.Second
+
+// OfflineOperation est une opération telle qu'enregistrée et signée par l'appareil du stand
+type OfflineOperation struct {
+	ClientID   string
+	Type       string
+	RecordedAt time.Time
+	UserID     uint
+	CardCode   string
+	ProductID  uint
+	Quantity   uint
+	Points     uint
+	Signature  string
+}
+
+// AccountBalance est le solde d'un compte tel que téléchargé par les appareils de stand
+type AccountBalance struct {
+	ID        uint   `json:"id"`
+	Firstname string `json:"firstname"`
+	Lastname  string `json:"lastname"`
+	Jetons    uint   `json:"jetons"`
+}
+
+// SyncChanges contient le catalogue et les soldes modifiés depuis le curseur de l'appareil
+type SyncChanges struct {
+	Cursor   string               `json:"cursor"`
+	Products []models.Product     `json:"products"`
+	Accounts []AccountBalance     `json:"accounts"`
+	Cards    []models.PrepaidCard `json:"cards"`
+}
+
+type SyncService struct {
+	db      *gorm.DB
+	metrics *metrics.Metrics
+}
+
+func NewSyncService(db *gorm.DB, m *metrics.Metrics) *SyncService {
+	return &SyncService{db: db, metrics: m}
+}
+
+// RegisterDevice enregistre un appareil pour le stand et retourne le secret de signature,
+// qui n'est communiqué qu'une seule fois
+func (s *SyncService) RegisterDevice(operator models.User, standID uint, name string) (*models.StandDevice, string, error) {
+	var stand models.Stand
+	if err := s.db.First(&stand, standID).Error; err != nil {
+		return nil, "", ErrStandNotFound
+	}
+	if operator.Role != models.RoleAdmin && stand.UserID != operator.ID {
+		return nil, "", ErrSyncForbidden
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", err
+	}
+	secret := hex.EncodeToString(buf)
+
+	device := models.StandDevice{Name: name, Secret: secret, StandID: stand.ID}
+	if err := s.db.Create(&device).Error; err != nil {
+		return nil, "", err
+	}
+	return &device, secret, nil
+}
+
+// Upload applique un lot d'opérations hors ligne dans l'ordre où elles ont été enregistrées.
+// Chaque opération est appliquée, rejetée ou signalée en conflit indépendamment des autres.
+func (s *SyncService) Upload(operator models.User, deviceID uint, operations []OfflineOperation) ([]models.SyncOperation, error) {
+	device, stand, err := s.device(operator, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := make([]OfflineOperation, len(operations))
+	copy(ordered, operations)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].RecordedAt.Before(ordered[j].RecordedAt)
+	})
+
+	results := make([]models.SyncOperation, 0, len(ordered))
+	for _, operation := range ordered {
+		result, err := s.apply(*device, *stand, operation)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *result)
+	}
+
+	now := time.Now()
+	if err := s.db.Model(device).Update("last_sync_at", now).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Changes retourne le catalogue du stand et les soldes des comptes et cartes de ses kermesses
+// modifiés depuis le curseur. Sans curseur, le dernier curseur envoyé à l'appareil est utilisé ;
+// sans aucun curseur, tout est renvoyé.
+func (s *SyncService) Changes(operator models.User, deviceID uint, cursor string) (*SyncChanges, error) {
+	device, stand, err := s.device(operator, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if cursor == "" {
+		cursor = device.Cursor
+	}
+
+	var since *time.Time
+	if cursor != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		parsed = parsed.Add(-syncCursorOverlap)
+		since = &parsed
+	}
+
+	now := time.Now().UTC()
+	changes := SyncChanges{Cursor: now.Format(time.RFC3339Nano)}
+
+	kermesseIDs := make([]uint, 0, len(stand.Kermesses))
+	for _, kermesse := range stand.Kermesses {
+		kermesseIDs = append(kermesseIDs, kermesse.ID)
+	}
+
+	products := s.db.Where("stand_id = ?", stand.ID)
+	participants := s.db.Table("kermesse_participants").Select("user_id").Where("kermesse_id IN ?", kermesseIDs)
+	accounts := s.db.Model(&models.User{}).Select("id", "firstname", "lastname", "jetons").
+		Where(s.db.Where("id IN (?)", participants).
+			Or("id IN (?)", s.db.Table("user_enfants").Select("enfant_id").Where("user_id IN (?)", participants)))
+	cards := s.db.Where("kermesse_id IN ?", kermesseIDs)
+	if since != nil {
+		products = products.Where("updated_at > ?", *since)
+		accounts = accounts.Where("updated_at > ?", *since)
+		cards = cards.Where("updated_at > ?", *since)
+	}
+
+	if err := products.Order("id").Find(&changes.Products).Error; err != nil {
+		return nil, err
+	}
+	if err := accounts.Order("id").Scan(&changes.Accounts).Error; err != nil {
+		return nil, err
+	}
+	if err := cards.Order("id").Find(&changes.Cards).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(device).Updates(map[string]interface{}{"cursor": changes.Cursor, "last_sync_at": now}).Error; err != nil {
+		return nil, err
+	}
+	return &changes, nil
+}
+
+// Conflicts liste les opérations hors ligne d'un stand qui n'ont pas pu être appliquées
+func (s *SyncService) Conflicts(operator models.User, standID uint) ([]models.SyncOperation, error) {
+	var stand models.Stand
+	if err := s.db.Preload("Kermesses.Organisateurs").First(&stand, standID).Error; err != nil {
+		return nil, ErrStandNotFound
+	}
+	if operator.Role != models.RoleAdmin && stand.UserID != operator.ID && !isStandOrganisateur(operator, stand) {
+		return nil, ErrSyncForbidden
+	}
+
+	var operations []models.SyncOperation
+	if err := s.db.Where("stand_id = ? AND status = ?", stand.ID, models.SyncStatusConflict).
+		Order("recorded_at").Find(&operations).Error; err != nil {
+		return nil, err
+	}
+	return operations, nil
+}
+
+// SignOperation calcule la signature attendue d'une opération : HMAC-SHA256 en hexadécimal, avec le secret
+// de l'appareil, des champs séparés par des retours à la ligne (date en millisecondes Unix)
+func SignOperation(secret string, operation OfflineOperation) string {
+	payload := strings.Join([]string{
+		operation.ClientID,
+		operation.Type,
+		strconv.FormatInt(operation.RecordedAt.UnixMilli(), 10),
+		strconv.FormatUint(uint64(operation.UserID), 10),
+		operation.CardCode,
+		strconv.FormatUint(uint64(operation.ProductID), 10),
+		strconv.FormatUint(uint64(operation.Quantity), 10),
+		strconv.FormatUint(uint64(operation.Points), 10),
+	}, "\n")
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *SyncService) device(operator models.User, deviceID uint) (*models.StandDevice, *models.Stand, error) {
+	var device models.StandDevice
+	if err := s.db.First(&device, deviceID).Error; err != nil {
+		return nil, nil, ErrDeviceNotFound
+	}
+	var stand models.Stand
+	if err := s.db.Preload("Kermesses").First(&stand, device.StandID).Error; err != nil {
+		return nil, nil, ErrStandNotFound
+	}
+	if operator.Role != models.RoleAdmin && stand.UserID != operator.ID {
+		return nil, nil, ErrSyncForbidden
+	}
+	return &device, &stand, nil
+}
+
+func (s *SyncService) apply(device models.StandDevice, stand models.Stand, operation OfflineOperation) (*models.SyncOperation, error) {
+	record := models.SyncOperation{
+		ClientID:   operation.ClientID,
+		DeviceID:   device.ID,
+		StandID:    stand.ID,
+		Type:       operation.Type,
+		RecordedAt: operation.RecordedAt,
+		ReceivedAt: time.Now(),
+		Signature:  operation.Signature,
+		CardCode:   operation.CardCode,
+		Quantity:   operation.Quantity,
+		Points:     operation.Points,
+	}
+	if operation.UserID != 0 {
+		record.UserID = &operation.UserID
+	}
+	if operation.ProductID != 0 {
+		record.ProductID = &operation.ProductID
+	}
+
+	// Une opération invalide ou mal signée n'est pas enregistrée : elle ne réserve pas son client_id,
+	// et l'appareil peut la renvoyer une fois corrigée
+	if reason := s.validate(device, operation); reason != "" {
+		record.Status = models.SyncStatusRejected
+		record.Reason = reason
+		return &record, nil
+	}
+
+	// Les métriques ne comptent que les opérations effectivement appliquées, une fois la transaction validée
+	if operation.Type == models.SyncOperationPurchase {
+		var charged *models.History
+		result, err := s.store(record, func(tx *gorm.DB, record *models.SyncOperation) error {
+			var err error
+			charged, err = s.applyPurchase(tx, stand, operation, record)
+			return err
+		})
+		if err == nil && charged != nil {
+			s.metrics.RecordSpending(*charged)
+		}
+		return result, err
+	}
+	var awarded bool
+	result, err := s.store(record, func(tx *gorm.DB, record *models.SyncOperation) error {
+		res := tx.Model(&models.User{}).Where("id = ?", operation.UserID).
+			Update("pts_attribues", gorm.Expr("pts_attribues + ?", operation.Points))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			record.Status = models.SyncStatusRejected
+			record.Reason = ErrCustomerNotFound.Error()
+			return nil
+		}
+		awarded = true
+		return nil
+	})
+	if err == nil && awarded {
+		s.metrics.RecordPoints(stand.ID, operation.Points)
+	}
+	return result, err
+}
+
+func (s *SyncService) validate(device models.StandDevice, operation OfflineOperation) string {
+	if operation.ClientID == "" {
+		return "missing client_id"
+	}
+	expected := SignOperation(device.Secret, operation)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(operation.Signature))) {
+		return "invalid signature"
+	}
+	if operation.RecordedAt.After(time.Now().Add(SyncClockSkew)) || operation.RecordedAt.Before(device.CreatedAt.Add(-SyncClockSkew)) {
+		return "recorded_at is outside the device's lifetime"
+	}
+	switch operation.Type {
+	case models.SyncOperationPurchase:
+		if operation.ProductID == 0 || (operation.UserID == 0 && operation.CardCode == "") {
+			return "a purchase needs a product_id and a user_id or card_code"
+		}
+	case models.SyncOperationPoints:
+		if operation.UserID == 0 || operation.Points == 0 {
+			return "points need a user_id and a number of points"
+		}
+	default:
+		return "unknown operation type"
+	}
+	return ""
+}
+
+// applyPurchase retourne l'achat débité, nil si l'opération est rejetée ou en conflit
+func (s *SyncService) applyPurchase(tx *gorm.DB, stand models.Stand, operation OfflineOperation, record *models.SyncOperation) (*models.History, error) {
+	var product models.Product
+	if err := tx.First(&product, operation.ProductID).Error; err != nil || uint(product.StandID) != stand.ID {
+		record.Status = models.SyncStatusRejected
+		record.Reason = ErrProductNotFound.Error()
+		return nil, nil
+	}
+
+	var card *models.PrepaidCard
+	if operation.CardCode != "" {
+		found, err := findPrepaidCard(tx, operation.CardCode)
+		if err == nil && !standInKermesse(stand, found.KermesseID) {
+			err = ErrCardOtherKermesse
+		}
+		if err != nil {
+			record.Status = models.SyncStatusRejected
+			record.Reason = err.Error()
+			return nil, nil
+		}
+		card = found
+	} else if err := tx.First(&models.User{}, operation.UserID).Error; err != nil {
+		record.Status = models.SyncStatusRejected
+		record.Reason = ErrCustomerNotFound.Error()
+		return nil, nil
+	}
+
+	quantity := operation.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	// L'achat est débité dans un point de sauvegarde : en cas de solde ou de stock insuffisant,
+	// seul l'achat est annulé et l'opération est enregistrée en conflit
+	var historique *models.History
+	err := tx.Transaction(func(nested *gorm.DB) error {
+		var err error
+		historique, err = chargePurchase(nested, stand, product, quantity, operation.UserID, card, operation.RecordedAt)
+		return err
+	})
+	switch {
+	case errors.Is(err, ErrInsufficientStock), errors.Is(err, ErrNotEnoughJetons):
+		record.Status = models.SyncStatusConflict
+		record.Reason = err.Error()
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	record.HistoryID = &historique.ID
+	return historique, nil
+}
+
+// store enregistre l'opération et l'applique dans la même transaction SQL.
+// L'index unique (appareil, client_id) fait qu'une opération renvoyée n'est jamais appliquée deux fois :
+// le résultat déjà enregistré est alors retourné. Une opération rejetée (produit ou client inconnu)
+// est en revanche retentée : son enregistrement est remplacé.
+func (s *SyncService) store(record models.SyncOperation, applyFn func(tx *gorm.DB, record *models.SyncOperation) error) (*models.SyncOperation, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		record.Status = models.SyncStatusApplied
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			retried := tx.Where("device_id = ? AND client_id = ? AND status = ?", record.DeviceID, record.ClientID, models.SyncStatusRejected).
+				Delete(&models.SyncOperation{})
+			if retried.Error != nil {
+				return retried.Error
+			}
+			if retried.RowsAffected == 0 {
+				return errDuplicateOperation
+			}
+			if err := tx.Create(&record).Error; err != nil {
+				return err
+			}
+		}
+		if err := applyFn(tx, &record); err != nil {
+			return err
+		}
+		return tx.Model(&models.SyncOperation{}).Where("id = ?", record.ID).Updates(map[string]interface{}{
+			"status":     record.Status,
+			"reason":     record.Reason,
+			"history_id": record.HistoryID,
+		}).Error
+	})
+	if errors.Is(err, errDuplicateOperation) {
+		var existing models.SyncOperation
+		if err := s.db.Where("device_id = ? AND client_id = ?", record.DeviceID, record.ClientID).First(&existing).Error; err != nil {
+			return nil, err
+		}
+		return &existing, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
@@ -0,0 +1,362 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"project/internal/apperror"
+	"project/internal/metrics"
+	"project/internal/models"
+	"project/internal/payment"
+)
+
+var (
+	ErrTillForbidden        = apperror.Forbidden("till_forbidden", "only cashiers can use the cash desk")
+	ErrTillAlreadyOpen      = apperror.Conflict("till_already_open", "you already have an open till session")
+	ErrNoOpenTill           = apperror.Conflict("no_open_till", "you don't have an open till session")
+	ErrTillNotFound         = apperror.NotFound("till_not_found", "till session not found")
+	ErrKermesseClosed       = apperror.Conflict("kermesse_closed", "this kermesse is closed")
+	ErrJetonsPackNotFound   = apperror.NotFound("jetons_pack_not_found", "token pack not found")
+	ErrCustomerNotFound     = apperror.NotFound("customer_not_found", "customer not found")
+	ErrInvalidPaymentMethod = apperror.Validation("invalid_payment_method", "payment method must be cash or card_terminal")
+	ErrSaleTarget           = apperror.Validation("sale_target", "a sale needs either a user_id or a card_code")
+	ErrNegativeTillAmount   = apperror.Validation("negative_till_amount", "opening float and closing count can't be negative")
+)
+
+// TillSale décrit une vente en caisse, au compte d'une famille ou sur une carte prépayée
+type TillSale struct {
+	UserID        uint
+	CardCode      string
+	JetonsID      uint
+	Packs         uint
+	PaymentMethod string
+}
+
+// TillReport résume une session de caisse et l'écart entre le comptage et le montant attendu
+type TillReport struct {
+	Session           models.TillSession `json:"session"`
+	SalesCount        int                `json:"sales_count"`
+	JetonsSold        uint               `json:"jetons_sold"`
+	CashSales         float64            `json:"cash_sales"`
+	CardTerminalSales float64            `json:"card_terminal_sales"`
+	ExpectedCash      float64            `json:"expected_cash"`
+	ClosingCount      *float64           `json:"closing_count"`
+	Discrepancy       *float64           `json:"discrepancy"`
+}
+
+type TillService struct {
+	db       *gorm.DB
+	payments *payment.Registry
+	metrics  *metrics.Metrics
+}
+
+func NewTillService(db *gorm.DB, payments *payment.Registry, m *metrics.Metrics) *TillService {
+	return &TillService{db: db, payments: payments, metrics: m}
+}
+
+// Open ouvre une session de caisse avec son fond de caisse
+func (s *TillService) Open(cashier models.User, kermesseID uint, openingFloat float64) (*models.TillSession, error) {
+	if !isCashier(cashier) {
+		return nil, ErrTillForbidden
+	}
+	if openingFloat < 0 {
+		return nil, ErrNegativeTillAmount
+	}
+
+	var kermesse models.Kermesse
+	if err := s.db.First(&kermesse, kermesseID).Error; err != nil {
+		return nil, ErrKermesseNotFound
+	}
+	if kermesse.Status == models.KermesseStatusClosed {
+		return nil, ErrKermesseClosed
+	}
+
+	if _, err := s.current(cashier); err == nil {
+		return nil, ErrTillAlreadyOpen
+	}
+
+	session := models.TillSession{
+		Status:       models.TillSessionOpen,
+		OpenedAt:     time.Now(),
+		OpeningFloat: openingFloat,
+		KermesseID:   kermesse.ID,
+		CashierID:    cashier.ID,
+	}
+	// L'index unique partiel idx_till_sessions_open_cashier départage deux ouvertures simultanées :
+	// celle qui échoue trouve la session de l'autre
+	if err := s.db.Create(&session).Error; err != nil {
+		if _, err := s.current(cashier); err == nil {
+			return nil, ErrTillAlreadyOpen
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Current retourne le rapport de la session ouverte du caissier
+func (s *TillService) Current(cashier models.User) (*TillReport, error) {
+	session, err := s.current(cashier)
+	if err != nil {
+		return nil, err
+	}
+	return s.report(*session)
+}
+
+// SellJetons vend des packs de jetons encaissés sur place et crédite le compte du client
+// ou la carte prépayée
+func (s *TillService) SellJetons(ctx context.Context, cashier models.User, sale TillSale) (*models.Transaction, error) {
+	session, err := s.current(cashier)
+	if err != nil {
+		return nil, err
+	}
+	return s.sell(ctx, cashier, *session, sale)
+}
+
+// IssueCard crée une carte prépayée pour la kermesse de la session, chargée si un pack est vendu avec
+func (s *TillService) IssueCard(ctx context.Context, cashier models.User, sale TillSale) (*models.PrepaidCard, *models.Transaction, error) {
+	session, err := s.current(cashier)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	card, err := createPrepaidCard(s.db, session.KermesseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sale.JetonsID == 0 {
+		return card, nil, nil
+	}
+
+	sale.UserID = 0
+	sale.CardCode = card.Code
+	transaction, err := s.sell(ctx, cashier, *session, sale)
+	if err != nil {
+		return card, nil, err
+	}
+	if err := s.db.First(card, card.ID).Error; err != nil {
+		return nil, nil, err
+	}
+	return card, transaction, nil
+}
+
+func (s *TillService) sell(ctx context.Context, cashier models.User, session models.TillSession, sale TillSale) (*models.Transaction, error) {
+	method := sale.PaymentMethod
+	if method != models.PaymentMethodCash && method != models.PaymentMethodCardTerminal {
+		return nil, ErrInvalidPaymentMethod
+	}
+	packs := sale.Packs
+	if packs == 0 {
+		packs = 1
+	}
+
+	var pack models.Jetons
+	if err := s.db.First(&pack, sale.JetonsID).Error; err != nil {
+		return nil, ErrJetonsPackNotFound
+	}
+
+	var userID, cardID *uint
+	switch {
+	case sale.CardCode != "":
+		card, err := findPrepaidCard(s.db, sale.CardCode)
+		if err != nil {
+			return nil, err
+		}
+		if card.KermesseID != session.KermesseID {
+			return nil, ErrCardOtherKermesse
+		}
+		cardID = &card.ID
+		userID = card.UserID
+	case sale.UserID != 0:
+		var customer models.User
+		if err := s.db.First(&customer, sale.UserID).Error; err != nil {
+			return nil, ErrCustomerNotFound
+		}
+		userID = &customer.ID
+	default:
+		return nil, ErrSaleTarget
+	}
+
+	// La vente passe par le fournisseur "caisse" : l'argent est déjà dans le tiroir
+	provider, err := s.payments.Get(payment.ProviderCash)
+	if err != nil {
+		return nil, err
+	}
+
+	price := pack.Price * float64(packs)
+	intent, err := provider.CreateIntent(payment.IntentParams{
+		AmountCents: int64(math.Round(price * 100)),
+		Currency:    "eur",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := provider.ConfirmIntent(intent.ID); err != nil {
+		return nil, err
+	}
+
+	transaction := models.Transaction{
+		Type:            models.TransactionTypeJetons,
+		DateTransaction: time.Now(),
+		Price:           float32(price),
+		Quantity:        pack.NbJetons * packs,
+		Status:          models.TransactionStatusPending,
+		Provider:        provider.Name(),
+		PaymentIntentID: intent.ID,
+		PaymentMethod:   method,
+		KermesseID:      &session.KermesseID,
+		TillSessionID:   &session.ID,
+		PrepaidCardID:   cardID,
+		UserID:          userID,
+	}
+
+	// Les jetons sont crédités dans la même transaction SQL que la vente
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&transaction).Error; err != nil {
+			return err
+		}
+		_, err := completeTransaction(ctx, tx, cashier, transaction.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.Status = models.TransactionStatusSucceeded
+	s.metrics.RecordJetonsSold(transaction)
+	return &transaction, nil
+}
+
+// Close ferme la session avec le comptage du tiroir et calcule l'écart
+func (s *TillService) Close(cashier models.User, closingCount float64) (*TillReport, error) {
+	if closingCount < 0 {
+		return nil, ErrNegativeTillAmount
+	}
+	session, err := s.current(cashier)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.report(*session)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	discrepancy := roundCents(closingCount - report.ExpectedCash)
+	res := s.db.Model(&models.TillSession{}).
+		Where("id = ? AND status = ?", session.ID, models.TillSessionOpen).
+		Updates(map[string]interface{}{
+			"status":        models.TillSessionClosed,
+			"closed_at":     now,
+			"expected_cash": report.ExpectedCash,
+			"closing_count": closingCount,
+			"discrepancy":   discrepancy,
+		})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrNoOpenTill
+	}
+
+	if err := s.db.First(session, session.ID).Error; err != nil {
+		return nil, err
+	}
+	return s.report(*session)
+}
+
+// Report retourne le rapport d'une session, pour son caissier, les organisateurs de la kermesse et les admins
+func (s *TillService) Report(operator models.User, sessionID uint) (*TillReport, error) {
+	var session models.TillSession
+	if err := s.db.First(&session, sessionID).Error; err != nil {
+		return nil, ErrTillNotFound
+	}
+
+	if session.CashierID != operator.ID {
+		var kermesse models.Kermesse
+		if err := s.db.Preload("Organisateurs").First(&kermesse, session.KermesseID).Error; err != nil {
+			return nil, err
+		}
+		if !isKermesseOrganisateur(operator, kermesse) {
+			return nil, ErrTillForbidden
+		}
+	}
+	return s.report(session)
+}
+
+// ListForKermesse retourne les rapports de toutes les sessions d'une kermesse
+func (s *TillService) ListForKermesse(operator models.User, kermesseID uint) ([]TillReport, error) {
+	var kermesse models.Kermesse
+	if err := s.db.Preload("Organisateurs").First(&kermesse, kermesseID).Error; err != nil {
+		return nil, ErrKermesseNotFound
+	}
+	if !isKermesseOrganisateur(operator, kermesse) {
+		return nil, ErrTillForbidden
+	}
+
+	var sessions []models.TillSession
+	if err := s.db.Where("kermesse_id = ?", kermesseID).Order("opened_at").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	reports := make([]TillReport, 0, len(sessions))
+	for _, session := range sessions {
+		report, err := s.report(session)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+	return reports, nil
+}
+
+func (s *TillService) current(cashier models.User) (*models.TillSession, error) {
+	var session models.TillSession
+	err := s.db.Where("cashier_id = ? AND status = ?", cashier.ID, models.TillSessionOpen).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoOpenTill
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *TillService) report(session models.TillSession) (*TillReport, error) {
+	var sales []models.Transaction
+	if err := s.db.Where("till_session_id = ? AND status = ?", session.ID, models.TransactionStatusSucceeded).
+		Order("date_transaction").Find(&sales).Error; err != nil {
+		return nil, err
+	}
+
+	report := TillReport{Session: session, SalesCount: len(sales)}
+	var cashCents, terminalCents int64
+	for _, sale := range sales {
+		cents := int64(math.Round(float64(sale.Price) * 100))
+		if sale.PaymentMethod == models.PaymentMethodCash {
+			cashCents += cents
+		} else {
+			terminalCents += cents
+		}
+		report.JetonsSold += sale.Quantity
+	}
+
+	report.CashSales = float64(cashCents) / 100
+	report.CardTerminalSales = float64(terminalCents) / 100
+	report.ExpectedCash = roundCents(session.OpeningFloat + report.CashSales)
+	report.ClosingCount = session.ClosingCount
+	report.Discrepancy = session.Discrepancy
+	report.Session.Sales = sales
+	return &report, nil
+}
+
+func isCashier(user models.User) bool {
+	return user.Role == models.RoleAdmin || user.Role == models.RoleCaissier
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
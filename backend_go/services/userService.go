@@ -1,38 +1,222 @@
 package services
 
 import (
-	"gorm.io/gorm"
+	"context"
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+	"project/internal/apperror"
+	"project/internal/audit"
+	"project/internal/listing"
 	"project/internal/models"
+	"project/repository"
 )
 
+var ErrEmailTaken = apperror.Conflict("email_taken", "a user with this email already exists")
+
+// kermesseMemberWhere retient les participants et les organisateurs d'une kermesse
+const kermesseMemberWhere = "id IN (SELECT user_id FROM kermesse_participants WHERE kermesse_id = ?" +
+	" UNION SELECT user_id FROM kermesse_organisateurs WHERE kermesse_id = ?)"
+
+var userSorts = map[string]string{
+	"id": "id", "firstname": "firstname", "lastname": "lastname", "email": "email", "role": "role", "jetons": "jetons",
+}
+
+// UserListing décrit la pagination, le tri et les filtres de la liste des comptes
+var UserListing = listing.Spec{
+	Sorts:       userSorts,
+	DefaultSort: "id",
+	Filters: map[string]listing.Filter{
+		"role":        {Where: "role = ?", Numeric: true},
+		"kermesse_id": {Where: kermesseMemberWhere, Numeric: true},
+	},
+	Search: []string{"firstname", "lastname", "email"},
+}
+
+// StudentListing décrit la liste des élèves : celle des comptes, sans filtre de rôle
+var StudentListing = listing.Spec{
+	Sorts:       userSorts,
+	DefaultSort: "lastname,firstname",
+	Filters: map[string]listing.Filter{
+		"kermesse_id": {Where: kermesseMemberWhere, Numeric: true},
+	},
+	Search: []string{"firstname", "lastname", "email"},
+}
+
+// UserService gère les comptes pour les admins. Les créations, modifications et suppressions sont
+// journalisées dans la transaction qui les écrit.
 type UserService struct {
-	db *gorm.DB
+	repos *repository.Repositories
+	users repository.UserRepository
+}
+
+func NewUserService(repos *repository.Repositories) *UserService {
+	return &UserService{repos: repos, users: repos.Users}
+}
+
+// Create crée un compte avec le rôle demandé, réservé aux admins
+func (s *UserService) Create(ctx context.Context, operator models.User, input SignupInput) (*models.User, error) {
+	if operator.Role != models.RoleAdmin {
+		return nil, ErrAdminOnly
+	}
+	return s.create(ctx, operator, input)
+}
+
+// List retourne une page des comptes, réservé aux admins
+func (s *UserService) List(operator models.User, q listing.Query) (listing.Page[models.User], error) {
+	if operator.Role != models.RoleAdmin {
+		return listing.Page[models.User]{}, ErrAdminOnly
+	}
+	return s.users.List(q)
+}
+
+// Get retourne un compte avec ses relations, réservé aux admins
+func (s *UserService) Get(operator models.User, id uint) (*models.User, error) {
+	if operator.Role != models.RoleAdmin {
+		return nil, ErrAdminOnly
+	}
+	user, err := s.users.FindProfile(id)
+	if err != nil {
+		return nil, userNotFound(err)
+	}
+	return user, nil
+}
+
+// Update modifie les champs renseignés d'un compte, rôle compris, réservé aux admins
+func (s *UserService) Update(ctx context.Context, operator models.User, id uint, input SignupInput) (*models.User, error) {
+	if operator.Role != models.RoleAdmin {
+		return nil, ErrAdminOnly
+	}
+	user, err := s.users.FindById(id)
+	if err != nil {
+		return nil, userNotFound(err)
+	}
+	var updated *models.User
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if updated, err = updateUser(tx.Users, *user, input); err != nil {
+			return err
+		}
+		return auditUpdate(ctx, tx.Audit, operator, *user, *updated, input.Password != "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete supprime un compte, réservé aux admins
+func (s *UserService) Delete(ctx context.Context, operator models.User, id uint) error {
+	if operator.Role != models.RoleAdmin {
+		return ErrAdminOnly
+	}
+	user, err := s.users.FindById(id)
+	if err != nil {
+		return userNotFound(err)
+	}
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Users.Delete(id); err != nil {
+			return userNotFound(err)
+		}
+		return tx.Audit.Record(ctx, audit.Entry{
+			Operator: operator, Action: audit.ActionUserDelete, EntityType: audit.EntityUser, EntityID: id, Before: user,
+		})
+	})
 }
 
-func NewUserService(db *gorm.DB) *UserService {
-	return &UserService{db: db}
+// Students retourne une page des comptes élèves
+func (s *UserService) Students(q listing.Query) (listing.Page[models.User], error) {
+	return s.users.ListByRole(5, q)
 }
 
-// Créatiion d'un nouveau User
-func (s *UserService) CreateUser(user models.User) error {
-	return s.db.Create(user).Error
+// CreateAdmin crée un compte administrateur avec le mot de passe donné
+func (s *UserService) CreateAdmin(ctx context.Context, operator models.User, firstname, lastname, email, password string) (*models.User, error) {
+	return s.create(ctx, operator, SignupInput{
+		Firstname: firstname,
+		Lastname:  lastname,
+		Email:     email,
+		Password:  password,
+		Role:      1,
+	})
 }
 
-// Récupérer un user par ID
-func (s *UserService) GetUserById(id uint) (*models.User, error) {
-	var user models.User
-	if err := s.db.First(&user, id).Error; err != nil {
+// ResetPassword remplace le mot de passe du compte associé à l'email
+func (s *UserService) ResetPassword(ctx context.Context, operator models.User, email, password string) (*models.User, error) {
+	user, err := s.users.FindByEmail(email)
+	if err != nil {
+		return nil, userNotFound(err)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Users.Update(user.ID, models.User{Password: string(hash)}); err != nil {
+			return err
+		}
+		return tx.Audit.Record(ctx, audit.Entry{
+			Operator: operator, Action: audit.ActionUserPasswordReset, EntityType: audit.EntityUser, EntityID: user.ID,
+		})
+	})
+	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	return user, nil
 }
 
-// Mise à jour d'un user
-func (s *UserService) UpdateUser(user models.User) error {
-	return s.db.Save(&user).Error
+func (s *UserService) create(ctx context.Context, operator models.User, input SignupInput) (*models.User, error) {
+	var user *models.User
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		var err error
+		if user, err = createUser(tx.Users, input); err != nil {
+			return err
+		}
+		return tx.Audit.Record(ctx, audit.Entry{
+			Operator: operator, Action: audit.ActionUserCreate, EntityType: audit.EntityUser, EntityID: user.ID, After: user,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
-// Suppression d'un utilisateur
-func (s *UserService) DeleteUserById(id uint) error {
-	return s.db.Delete(&models.User{}, id).Error
+// auditUpdate journalise la modification d'un compte. Le changement de rôle et le remplacement
+// du mot de passe, dont la valeur n'est jamais journalisée, ont leur propre action.
+func auditUpdate(ctx context.Context, log repository.AuditRepository, operator models.User, before, after models.User, passwordChanged bool) error {
+	var entries []audit.Entry
+	if before.Role != after.Role {
+		entries = append(entries, audit.Entry{
+			Action: audit.ActionUserRoleChange,
+			Before: map[string]uint{"role": before.Role},
+			After:  map[string]uint{"role": after.Role},
+		})
+		before.Role = after.Role
+	}
+	changes, err := audit.Diff(before, after)
+	if err != nil {
+		return err
+	}
+	if len(changes) > 0 {
+		entries = append(entries, audit.Entry{Action: audit.ActionUserUpdate, Before: before, After: after})
+	}
+	if passwordChanged {
+		entries = append(entries, audit.Entry{Action: audit.ActionUserPasswordReset})
+	}
+
+	for _, entry := range entries {
+		entry.Operator, entry.EntityType, entry.EntityID = operator, audit.EntityUser, after.ID
+		if err := log.Record(ctx, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// userNotFound traduit l'absence du compte dans le dépôt en ErrUserNotFound
+func userNotFound(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrUserNotFound
+	}
+	return err
 }
@@ -0,0 +1,143 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+	"project/internal/audit"
+	"project/internal/listing"
+	"project/internal/models"
+	"project/repository"
+	"project/repository/memory"
+	"project/services"
+)
+
+var admin = models.User{ID: 1, Role: models.RoleAdmin, Email: "admin@test.local"}
+
+// newUserService construit le service sur des dépôts en mémoire, sans base de données
+func newUserService(t *testing.T) (*services.UserService, *repository.Repositories, *memory.AuditRepository) {
+	t.Helper()
+	repos := memory.New()
+	return services.NewUserService(repos), repos, repos.Audit.(*memory.AuditRepository)
+}
+
+func actions(entries []audit.Entry) []string {
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Action)
+	}
+	return names
+}
+
+// La création est réservée aux admins et journalisée ; un email déjà pris n'écrit rien
+func TestUserServiceCreate(t *testing.T) {
+	users, repos, log := newUserService(t)
+	ctx := context.Background()
+	input := services.SignupInput{Firstname: "Alice", Lastname: "Martin", Email: "alice@test.local", Password: "secret", Role: models.RoleParent}
+
+	if _, err := users.Create(ctx, models.User{ID: 2, Role: models.RoleOrganisateur}, input); !errors.Is(err, services.ErrAdminOnly) {
+		t.Fatalf("ErrAdminOnly attendue, reçu %v", err)
+	}
+	created, err := users.Create(ctx, admin, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == 0 || created.Role != models.RoleParent || bcrypt.CompareHashAndPassword([]byte(created.Password), []byte("secret")) != nil {
+		t.Fatalf("compte parent au mot de passe haché attendu : %+v", created)
+	}
+
+	if _, err := users.Create(ctx, admin, input); !errors.Is(err, services.ErrEmailTaken) {
+		t.Fatalf("ErrEmailTaken attendue, reçu %v", err)
+	}
+	page, err := repos.Users.List(listing.Query{})
+	if err != nil || page.Total != 1 {
+		t.Fatalf("un seul compte attendu : %+v, %v", page, err)
+	}
+	entries := log.Entries()
+	if len(entries) != 1 || entries[0].Action != audit.ActionUserCreate || entries[0].EntityID != created.ID || entries[0].Operator.ID != admin.ID {
+		t.Fatalf("une création journalisée attendue : %+v", entries)
+	}
+}
+
+// Un changement de rôle et de mot de passe est journalisé par action ; un email pris annule tout
+func TestUserServiceUpdate(t *testing.T) {
+	users, _, log := newUserService(t)
+	ctx := context.Background()
+	alice, err := users.Create(ctx, admin, services.SignupInput{Firstname: "Alice", Email: "alice@test.local", Password: "secret", Role: models.RoleParent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.Create(ctx, admin, services.SignupInput{Firstname: "Bob", Email: "bob@test.local", Password: "secret", Role: models.RoleParent}); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := users.Update(ctx, admin, alice.ID, services.SignupInput{Role: models.RoleOrganisateur, Password: "nouveau"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Role != models.RoleOrganisateur || updated.Firstname != "Alice" || bcrypt.CompareHashAndPassword([]byte(updated.Password), []byte("nouveau")) != nil {
+		t.Fatalf("rôle et mot de passe modifiés, prénom conservé attendus : %+v", updated)
+	}
+	want := []string{audit.ActionUserCreate, audit.ActionUserCreate, audit.ActionUserRoleChange, audit.ActionUserPasswordReset}
+	if got := actions(log.Entries()); len(got) != len(want) || got[2] != want[2] || got[3] != want[3] {
+		t.Fatalf("actions %v, %v attendues", got, want)
+	}
+
+	if _, err := users.Update(ctx, admin, alice.ID, services.SignupInput{Email: "bob@test.local"}); !errors.Is(err, services.ErrEmailTaken) {
+		t.Fatalf("ErrEmailTaken attendue, reçu %v", err)
+	}
+	if stored, _ := users.Get(admin, alice.ID); stored.Email != "alice@test.local" || len(log.Entries()) != len(want) {
+		t.Fatalf("rien ne doit être écrit : %+v, %v", stored, actions(log.Entries()))
+	}
+	if _, err := users.Update(ctx, admin, 999, services.SignupInput{Firstname: "X"}); !errors.Is(err, services.ErrUserNotFound) {
+		t.Fatalf("ErrUserNotFound attendue, reçu %v", err)
+	}
+}
+
+// La suppression et la réinitialisation du mot de passe sont journalisées ; les élèves se listent par rôle
+func TestUserServiceDeleteResetAndStudents(t *testing.T) {
+	users, repos, log := newUserService(t)
+	ctx := context.Background()
+	eleve, err := users.Create(ctx, admin, services.SignupInput{Firstname: "Léa", Email: "lea@test.local", Password: "secret", Role: models.RoleEnfant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, err := users.Create(ctx, admin, services.SignupInput{Firstname: "Paul", Email: "paul@test.local", Password: "secret", Role: models.RoleParent})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q, err := listing.Parse(url.Values{}, services.StudentListing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	students, err := users.Students(q)
+	if err != nil || students.Total != 1 || students.Items[0].ID != eleve.ID {
+		t.Fatalf("seule l'élève attendue : %+v, %v", students, err)
+	}
+
+	if _, err := users.ResetPassword(ctx, admin, "paul@test.local", "nouveau"); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := repos.Users.FindById(parent.ID)
+	if bcrypt.CompareHashAndPassword([]byte(stored.Password), []byte("nouveau")) != nil {
+		t.Fatal("le nouveau mot de passe doit être enregistré haché")
+	}
+	if _, err := users.ResetPassword(ctx, admin, "inconnu@test.local", "x"); !errors.Is(err, services.ErrUserNotFound) {
+		t.Fatalf("ErrUserNotFound attendue, reçu %v", err)
+	}
+
+	if err := users.Delete(ctx, admin, eleve.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.Get(admin, eleve.ID); !errors.Is(err, services.ErrUserNotFound) {
+		t.Fatalf("compte supprimé attendu, reçu %v", err)
+	}
+	got := actions(log.Entries())
+	if len(got) != 4 || got[2] != audit.ActionUserPasswordReset || got[3] != audit.ActionUserDelete {
+		t.Fatalf("réinitialisation puis suppression journalisées attendues : %v", got)
+	}
+}
@@ -0,0 +1,95 @@
+import 'package:flutter/material.dart';
+import 'services/payment_service.dart';
+
+// Achat de jetons par carte. Un achat garde sa clé d'idempotence jusqu'à sa confirmation :
+// « Réessayer » renvoie la même clé et ne peut donc pas débiter deux fois.
+class BuyJetonsPage extends StatefulWidget {
+  @override
+  _BuyJetonsPageState createState() => _BuyJetonsPageState();
+}
+
+class _BuyJetonsPageState extends State<BuyJetonsPage> {
+  final PaymentService _payments = PaymentService();
+  late Future<List<Map<String, dynamic>>> _futurePacks;
+
+  // Achat en cours : pack choisi et clé réutilisée à chaque nouvel essai
+  Map<String, dynamic>? _pendingPack;
+  String? _pendingKey;
+  bool _isLoading = false;
+
+  @override
+  void initState() {
+    super.initState();
+    _futurePacks = _payments.fetchPacks();
+  }
+
+  Future<void> _buy(Map<String, dynamic> pack) async {
+    // Un autre pack est un nouvel achat, avec une nouvelle clé
+    if (_pendingPack?['id'] != pack['id']) {
+      _pendingPack = pack;
+      _pendingKey = PaymentService.newIdempotencyKey();
+    }
+
+    setState(() {
+      _isLoading = true;
+    });
+    try {
+      await _payments.buyJetons(
+        pack['nb_jetons'] as int,
+        (pack['price'] as num).toDouble(),
+        _pendingKey!,
+      );
+      _pendingPack = null;
+      _pendingKey = null;
+      ScaffoldMessenger.of(context).showSnackBar(SnackBar(
+        content: Text('${pack['nb_jetons']} jetons ajoutés à votre compte.'),
+      ));
+    } catch (e) {
+      ScaffoldMessenger.of(context).showSnackBar(SnackBar(
+        content: Text("L'achat n'a pas abouti : $e"),
+        action: SnackBarAction(label: 'Réessayer', onPressed: () => _buy(pack)),
+      ));
+    } finally {
+      setState(() {
+        _isLoading = false;
+      });
+    }
+  }
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: Text('Acheter des jetons'),
+      ),
+      body: FutureBuilder<List<Map<String, dynamic>>>(
+        future: _futurePacks,
+        builder: (context, snapshot) {
+          if (snapshot.connectionState == ConnectionState.waiting) {
+            return Center(child: CircularProgressIndicator());
+          } else if (snapshot.hasError) {
+            return Center(child: Text('Erreur : ${snapshot.error}'));
+          } else if (!snapshot.hasData || snapshot.data!.isEmpty) {
+            return Center(child: Text('Aucun pack de jetons disponible.'));
+          }
+          return ListView.builder(
+            itemCount: snapshot.data!.length,
+            itemBuilder: (context, index) {
+              final pack = snapshot.data![index];
+              return Card(
+                child: ListTile(
+                  title: Text('${pack['nb_jetons']} jetons'),
+                  subtitle: Text('${pack['price']} €'),
+                  trailing: ElevatedButton(
+                    onPressed: _isLoading ? null : () => _buy(pack),
+                    child: Text('Acheter'),
+                  ),
+                ),
+              );
+            },
+          );
+        },
+      ),
+    );
+  }
+}
@@ -2,10 +2,13 @@ import 'package:flutter/material.dart';
 import 'package:shared_preferences/shared_preferences.dart';
 import 'login_page.dart';
 import 'package:flutter_dotenv/flutter_dotenv.dart';
+import 'package:flutter_stripe/flutter_stripe.dart';
 import 'profile_page.dart';
 
 void main() async {
 await dotenv.load(fileName: '.env');
+  // Clé publique utilisée par PaymentService pour présenter le paiement Stripe
+  Stripe.publishableKey = dotenv.env['STRIPE_PUBLIC_KEY'] ?? '';
   runApp(MyApp());
 }
 
@@ -4,6 +4,7 @@ import 'dart:convert'; // Pour décoder la réponse JSON
 import 'package:http/http.dart' as http;
 import 'package:jwt_decode/jwt_decode.dart'; // Pour décoder le token JWT
 import 'package:flutter_dotenv/flutter_dotenv.dart';
+import 'buy_jetons_page.dart';
 
 
 class ProfilePage extends StatefulWidget {
@@ -103,6 +104,14 @@ class _ProfilePageState extends State<ProfilePage> {
                         style: TextStyle(fontSize: 18),
                       ),
                       SizedBox(height: 16),
+                      ElevatedButton(
+                        onPressed: () => Navigator.push(
+                          context,
+                          MaterialPageRoute(builder: (context) => BuyJetonsPage()),
+                        ),
+                        child: Text('Acheter des jetons'),
+                      ),
+                      SizedBox(height: 8),
                       ElevatedButton(
                         onPressed: _logout,
                         child: Text('Déconnexion'),
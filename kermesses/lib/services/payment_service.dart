@@ -0,0 +1,84 @@
+import 'dart:convert';
+import 'dart:math';
+import 'package:http/http.dart' as http;
+import 'package:flutter_dotenv/flutter_dotenv.dart';
+import 'package:flutter_stripe/flutter_stripe.dart';
+import 'package:shared_preferences/shared_preferences.dart';
+
+// Achat de jetons ou de tickets de tombola en trois temps : l'API crée une intention de paiement
+// et une transaction en attente, le client fait payer l'intention, puis demande à l'API de confirmer.
+// Les jetons ne sont crédités qu'à la confirmation (ou au webhook du fournisseur).
+class PaymentService {
+  final String apiUrl = '${dotenv.env['API_PROTOCOL']}://${dotenv.env['API_HOST']}:${dotenv.env['API_PORT']}/api/v1';
+
+  // Achète [quantity] jetons pour [price] euros et retourne la transaction confirmée.
+  // [idempotencyKey] identifie l'achat : un nouvel essai du même achat doit réutiliser la même clé
+  // (voir newIdempotencyKey), pour que le serveur rejoue la réponse au lieu de créer un second paiement.
+  Future<Map<String, dynamic>> buyJetons(int quantity, double price, String idempotencyKey, {int? kermesseId}) async {
+    final token = await _token();
+    final payment = await _post('/payments', token, idempotencyKey, {
+      'type': 'jetons',
+      'quantity': quantity,
+      'price': price,
+      if (kermesseId != null) 'kermesse_id': kermesseId,
+    });
+
+    final intent = payment['paymentIntent'] as Map<String, dynamic>;
+    final transaction = payment['transaction'] as Map<String, dynamic>;
+
+    // Le fournisseur fake du serveur de développement paie l'intention à la confirmation
+    if (intent['provider'] == 'stripe') {
+      await Stripe.instance.initPaymentSheet(
+        paymentSheetParameters: SetupPaymentSheetParameters(
+          paymentIntentClientSecret: intent['client_secret'],
+          merchantDisplayName: 'Kermesses',
+        ),
+      );
+      await Stripe.instance.presentPaymentSheet();
+    }
+
+    return _post('/payments/${transaction['id']}/confirm', token, '$idempotencyKey-confirm', null);
+  }
+
+  // Clé d'un nouvel achat, à conserver jusqu'à sa confirmation
+  static String newIdempotencyKey() {
+    final random = Random.secure();
+    return List.generate(16, (_) => random.nextInt(256).toRadixString(16).padLeft(2, '0')).join();
+  }
+
+  Future<String> _token() async {
+    SharedPreferences prefs = await SharedPreferences.getInstance();
+    final token = prefs.getString('token');
+    if (token == null) {
+      throw Exception('Not logged in');
+    }
+    return token;
+  }
+
+  Future<Map<String, dynamic>> _post(String path, String token, String idempotencyKey, Map<String, dynamic>? body) async {
+    final response = await http.post(
+      Uri.parse('$apiUrl$path'),
+      headers: {
+        'Content-Type': 'application/json',
+        'Authorization': 'Bearer $token',
+        'Idempotency-Key': idempotencyKey,
+      },
+      body: body == null ? null : jsonEncode(body),
+    );
+
+    final decoded = jsonDecode(response.body);
+    if (response.statusCode != 200 && response.statusCode != 201) {
+      throw Exception('Payment failed: ${decoded['error']?['message'] ?? response.statusCode}');
+    }
+    return decoded['data'] as Map<String, dynamic>;
+  }
+
+  // Packs de jetons proposés à l'achat
+  Future<List<Map<String, dynamic>>> fetchPacks() async {
+    final response = await http.get(Uri.parse('$apiUrl/jetons'));
+    if (response.statusCode != 200) {
+      throw Exception('Failed to load token packs');
+    }
+    return (jsonDecode(response.body)['data'] as List<dynamic>).cast<Map<String, dynamic>>();
+  }
+}